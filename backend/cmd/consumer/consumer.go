@@ -13,3 +13,10 @@ func RunGenerateReportingAttendance(ctx context.Context) {
 	con := consumer.NewConsumer(b)
 	con.Start(con.GenerateReport)
 }
+
+func RunDispatchNotifications(ctx context.Context) {
+	b := bootstrap.NewBootstrap(ctx).BuildConsumerDispatchNotificationsBootstrap()
+
+	con := consumer.NewConsumer(b)
+	con.Start(con.DispatchNotifications)
+}
@@ -7,6 +7,7 @@ import (
 	"os"
 
 	"github.com/aldotp/employee-attendance-system/internal/adapter/bootstrap"
+	"github.com/aldotp/employee-attendance-system/internal/adapter/config"
 	"github.com/aldotp/employee-attendance-system/internal/adapter/handler/http"
 	"github.com/aldotp/employee-attendance-system/internal/adapter/router"
 	"github.com/aldotp/employee-attendance-system/internal/core/service"
@@ -16,35 +17,55 @@ func RunHTTPServer(ctx context.Context) {
 	f := bootstrap.NewBootstrap(ctx).BuildRestBootstrap()
 
 	// Services
-	userService := service.NewUserService(f.UserRepo, f.EmployeeRepo, f.DepartmentRepo, f.Cache, f.Token, f.Log)
-	authService := service.NewAuthService(f.UserRepo, f.EmployeeRepo, f.Token, f.Log)
-	attendanceService := service.NewAttendanceService(f.AttendanceRepo)
-	leaveService := service.NewLeaveService(f.LeaveRequestRepo, f.NotificationRepo)
-	scheduleService := service.NewScheduleService(f.ScheduleRepo)
-	monitoringService := service.NewMonitoringService(f.MonitoringRepo, f.UserRepo, f.AttendanceRepo)
-	notificationService := service.NewNotificationService(f.NotificationRepo, f.UserRepo)
+	tokenVersionService := service.NewTokenVersionService(f.Cache)
+	emailVerificationService := service.NewEmailVerificationService(f.UserRepo, f.Token, f.Cache, f.Email, config.EmailVerificationPolicy(), f.Log)
+	mfaService := service.NewMFAService(f.MFARepo, f.UserRepo, f.Cache, config.MFAPolicy(), f.Log)
+	authorizationService := service.NewAuthorizationService(f.PermissionRepo, f.EmployeeRepo, f.Cache, f.Log)
+	apiKeyService := service.NewAPIKeyService(f.APIKeyRepo, authorizationService, config.APIKeyPolicy(), f.Log)
+	notificationService := service.NewNotificationService(f.NotificationRepo, f.UserRepo, f.EmployeeRepo, f.NotificationChannels, f.PubSub, config.NotificationPolicy())
+	streamTicketService := service.NewStreamTicketService(f.Cache, f.Log)
+	loginProtectionService := service.NewLoginProtectionService(f.Cache, f.UserRepo, notificationService, config.LoginProtectionPolicy(), f.Log)
+	userService := service.NewUserService(f.UserRepo, f.EmployeeRepo, f.DepartmentRepo, f.Cache, f.Token, tokenVersionService, emailVerificationService, f.Log)
+	authService := service.NewAuthService(f.UserRepo, f.EmployeeRepo, f.DepartmentRepo, f.Token, f.Cache, tokenVersionService, mfaService, f.OIDC, config.OIDCPolicy(), loginProtectionService, f.Log)
+	passwordResetService := service.NewPasswordResetService(f.PasswordResetRepo, f.UserRepo, f.Cache, tokenVersionService, f.Email, config.PasswordResetPolicy(), f.Log)
+	attendanceService := service.NewAttendanceService(f.AttendanceRepo, f.ScheduleRepo, f.EmployeeRepo, f.LeaveRequestRepo, notificationService, authorizationService, config.AttendancePolicy())
+	leaveService := service.NewLeaveService(f.LeaveRequestRepo, f.LeaveAttachmentRepo, f.EmployeeRepo, f.ScheduleRepo, notificationService, authorizationService, f.Minio, config.LeaveAttachmentPolicy(), config.LeaveCoveragePolicy())
+	scheduleService := service.NewScheduleService(f.ScheduleRepo, f.RotationRepo, f.ShiftTemplateRepo, f.ScheduleRuleRepo, f.OpenShiftRepo, f.EmployeeRepo, f.LeaveRequestRepo, notificationService, authorizationService, config.ScheduleRulePolicy())
+	rotationService := service.NewRotationService(f.RotationRepo)
+	calendarFeedService := service.NewCalendarFeedService(f.CalendarFeedRepo, f.ScheduleRepo, f.LeaveRequestRepo, f.EmployeeRepo, f.WorkLocationRepo, config.CalendarFeedPolicy())
+	monitoringService := service.NewMonitoringService(f.MonitoringRepo, f.UserRepo, f.AttendanceRepo, f.ScheduleRepo, f.EmployeeRepo, f.LeaveRequestRepo)
 
 	// Handlers
 	userHandler := http.NewUserHandler(userService, f.Log)
-	authHandler := http.NewAuthHandler(authService, f.Log)
+	authHandler := http.NewAuthHandler(authService, passwordResetService, emailVerificationService, mfaService, loginProtectionService, f.Log)
 	attendanceHandler := http.NewAttendanceHandler(attendanceService)
 	leaveHandler := http.NewLeaveHandler(leaveService)
-	scheduleHandler := http.NewScheduleHandler(scheduleService)
+	scheduleHandler := http.NewScheduleHandler(scheduleService, calendarFeedService)
+	rotationHandler := http.NewRotationHandler(rotationService)
 	monitoringHandler := http.NewMonitoringHandler(monitoringService)
-	notificationHandler := http.NewNotificationHandler(notificationService)
+	notificationHandler := http.NewNotificationHandler(notificationService, streamTicketService)
 	deparmentHandler := http.NewDepartmentHandler(f.DepartmentRepo)
+	permissionHandler := http.NewPermissionHandler(authorizationService)
+	apiKeyHandler := http.NewAPIKeyHandler(apiKeyService)
 
 	// HTTP server
 	routes, err := router.NewRouter(
 		f.Token,
+		tokenVersionService,
+		authorizationService,
+		apiKeyService,
+		streamTicketService,
 		authHandler,
 		userHandler,
 		attendanceHandler,
 		leaveHandler,
 		scheduleHandler,
+		rotationHandler,
 		monitoringHandler,
 		notificationHandler,
 		deparmentHandler,
+		permissionHandler,
+		apiKeyHandler,
 	)
 	if err != nil {
 		slog.Error("Error creating router", "error", err)
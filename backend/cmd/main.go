@@ -45,13 +45,22 @@ func main() {
 		},
 	}
 
+	consumerDispatchNotifications := cobra.Command{
+		Use:   "dispatch_notifications",
+		Short: "Consumer is a command to start the notification dispatcher",
+		Run: func(cmd *cobra.Command, args []string) {
+			consumer.RunDispatchNotifications(ctx)
+		},
+	}
+
 	rootCmd.AddCommand(
 		&restCmd,
 		&consumerCmd,
 	)
 
 	consumerCmd.AddCommand(
-		&consumerGenerateReporting)
+		&consumerGenerateReporting,
+		&consumerDispatchNotifications)
 
 	if err := rootCmd.Execute(); err != nil {
 		log.Fatalf("failed to execute command: %v", err)
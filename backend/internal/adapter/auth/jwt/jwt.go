@@ -2,121 +2,279 @@ package jwt
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"log/slog"
+	"slices"
 	"time"
 
 	"github.com/aldotp/employee-attendance-system/internal/adapter/config"
 	"github.com/aldotp/employee-attendance-system/internal/core/domain"
 	"github.com/aldotp/employee-attendance-system/internal/core/port"
-	"github.com/dgrijalva/jwt-go"
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"github.com/google/uuid"
 )
 
+// Token types, carried in the typ claim. Refresh and activation tokens are only ever read by this
+// service, so they are issued for the issuer itself rather than for the access token audience.
+const (
+	accessTokenType     = "access"
+	refreshTokenType    = "refresh"
+	activationTokenType = "activation"
+)
+
+// claims are the claims of every token this service issues, the registered ones and ours
+type claims struct {
+	jwt.Claims
+	Type      string `json:"typ"`
+	UserID    string `json:"user_id"`
+	Email     string `json:"email,omitempty"`
+	Role      string `json:"role,omitempty"`
+	Version   int64  `json:"ver,omitempty"`
+	SessionID string `json:"sid,omitempty"`
+}
+
+// JWTToken issues and verifies the tokens of this service. They are signed with asymmetric keys,
+// so other services verify access tokens with the public keys published at /.well-known/jwks.json.
 type JWTToken struct {
+	keys     []signingKey
+	signer   jose.Signer
+	issuer   string
+	audience []string
+	jwks     []byte
 }
 
-// New creates a new JWT instance with a specified duration.
+// New loads the signing keys of JWT_SIGNING_KEYS. Without keys, outside production, it signs with
+// a key generated at startup, and tokens stop working when the process restarts.
 func New() (port.TokenInterface, error) {
-	return &JWTToken{}, nil
-}
+	keys, err := loadSigningKeys(config.JWTSigningKeys())
+	if err != nil {
+		return nil, err
+	}
+
+	if len(keys) == 0 {
+		if config.AppEnv() == "production" {
+			return nil, errors.New("JWT_SIGNING_KEYS is required in production")
+		}
+
+		key, err := generateSigningKey()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate signing key: %w", err)
+		}
+
+		slog.Warn("JWT_SIGNING_KEYS is not set, tokens are signed with a temporary key")
+		keys = append(keys, key)
+	}
 
-// CreateToken generates a new JWT access token for the given user.
-func (pt *JWTToken) GenerateAccessToken(user *domain.User) (string, error) {
-	claims := jwt.MapClaims{
-		"user_id": user.ID,
-		"email":   user.Email,
-		"exp":     time.Now().Add(time.Minute * time.Duration(config.AccessTokenExpired())).Unix(),
-		"role":    user.Role,
+	active := keys[0]
+	signer, err := jose.NewSigner(
+		jose.SigningKey{Algorithm: active.algorithm, Key: jose.JSONWebKey{Key: active.key, KeyID: active.id}},
+		(&jose.SignerOptions{}).WithType("JWT"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create signer: %w", err)
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	jwks := jose.JSONWebKeySet{Keys: make([]jose.JSONWebKey, 0, len(keys))}
+	for _, key := range keys {
+		jwks.Keys = append(jwks.Keys, key.publicKey())
+	}
 
-	signedToken, err := token.SignedString([]byte(config.SecretKey()))
+	encodedJWKS, err := json.Marshal(jwks)
 	if err != nil {
-		return "", fmt.Errorf("failed to sign access token: %w", err)
+		return nil, fmt.Errorf("failed to encode JWKS: %w", err)
 	}
 
-	return signedToken, nil
+	return &JWTToken{
+		keys:     keys,
+		signer:   signer,
+		issuer:   config.JWTIssuer(),
+		audience: config.JWTAudience(),
+		jwks:     encodedJWKS,
+	}, nil
 }
 
-// GenerateRefreshToken creates a long-lived refresh token for the given user.
-func (pt *JWTToken) GenerateRefreshToken(user *domain.User) (string, error) {
-	claims := jwt.MapClaims{
-		"user_id": user.ID,
-		"email":   user.Email,
-		"exp":     time.Now().Add(time.Minute * time.Duration(config.RefreshTokenExpired())).Unix(),
+// GenerateAccessToken generates a new JWT access token for the given user, carrying the user's
+// current token version.
+func (pt *JWTToken) GenerateAccessToken(user *domain.User, version int64) (string, error) {
+	now := time.Now()
+	signedToken, err := pt.sign(claims{
+		Claims:  pt.registeredClaims(user.ID, pt.audience, uuid.New().String(), now, now.Add(time.Minute*time.Duration(config.AccessTokenExpired()))),
+		Type:    accessTokenType,
+		UserID:  user.ID,
+		Email:   user.Email,
+		Role:    string(user.Role),
+		Version: version,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to sign access token: %w", err)
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return signedToken, nil
+}
+
+// GenerateRefreshToken creates a long-lived refresh token for the given user, identifying the
+// session it belongs to and the token within that session. It returns when the token expires.
+func (pt *JWTToken) GenerateRefreshToken(user *domain.User, sessionID, tokenID string) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(time.Minute * time.Duration(config.RefreshTokenExpired()))
 
-	signedToken, err := token.SignedString([]byte(config.RefreshKey()))
+	signedToken, err := pt.sign(claims{
+		Claims:    pt.registeredClaims(user.ID, []string{pt.issuer}, tokenID, now, expiresAt),
+		Type:      refreshTokenType,
+		UserID:    user.ID,
+		Email:     user.Email,
+		SessionID: sessionID,
+	})
 	if err != nil {
-		return "", fmt.Errorf("failed to sign refresh token: %w", err)
+		return "", time.Time{}, fmt.Errorf("failed to sign refresh token: %w", err)
 	}
 
-	return signedToken, nil
+	return signedToken, expiresAt, nil
 }
 
 // VerifyAccessToken validates a JWT access token and returns the decoded payload.
 func (pt *JWTToken) VerifyAccessToken(encodedToken string) (*domain.TokenPayload, error) {
-	secretKey := config.SecretKey()
+	c, err := pt.verify(encodedToken, accessTokenType, pt.audience)
+	if err != nil {
+		return nil, fmt.Errorf("invalid access token: %w", err)
+	}
 
-	token, err := jwt.Parse(encodedToken, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("invalid signing method")
-		}
-		return []byte(secretKey), nil
+	if c.Role == "" {
+		return nil, errors.New("access token has no role")
+	}
+
+	return &domain.TokenPayload{
+		UserID:  c.UserID,
+		Email:   c.Email,
+		Role:    domain.UserRole(c.Role),
+		Version: c.Version,
+	}, nil
+}
+
+// VerifyRefreshToken validates the signature and expiration of a JWT refresh token. Whether the
+// token is still the current one of its session is for the caller to check.
+func (pt *JWTToken) VerifyRefreshToken(ctx context.Context, refreshToken string) (*domain.RefreshTokenPayload, error) {
+	c, err := pt.verify(refreshToken, refreshTokenType, []string{pt.issuer})
+	if err != nil {
+		return nil, fmt.Errorf("invalid refresh token: %w", err)
+	}
+
+	if c.SessionID == "" || c.ID == "" || c.Expiry == nil {
+		return nil, errors.New("refresh token has no session")
+	}
+
+	return &domain.RefreshTokenPayload{
+		UserID:    c.UserID,
+		SessionID: c.SessionID,
+		TokenID:   c.ID,
+		ExpiresAt: c.Expiry.Time(),
+	}, nil
+}
+
+// GenerateActivationToken creates the token of an email verification link. It carries the email
+// address, so a link stops working once the user's address changes.
+func (pt *JWTToken) GenerateActivationToken(user *domain.User) (string, error) {
+	now := time.Now()
+	signedToken, err := pt.sign(claims{
+		Claims: pt.registeredClaims(user.ID, []string{pt.issuer}, uuid.New().String(), now, now.Add(time.Minute*time.Duration(config.ActivationTokenExpired()))),
+		Type:   activationTokenType,
+		UserID: user.ID,
+		Email:  user.Email,
 	})
+	if err != nil {
+		return "", fmt.Errorf("failed to sign activation token: %w", err)
+	}
+
+	return signedToken, nil
+}
 
+// VerifyActivationToken validates an email verification token and returns the user and the email
+// address it was issued for.
+func (pt *JWTToken) VerifyActivationToken(encodedToken string) (*domain.TokenPayload, error) {
+	c, err := pt.verify(encodedToken, activationTokenType, []string{pt.issuer})
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse access token: %w", err)
+		return nil, fmt.Errorf("invalid activation token: %w", err)
 	}
 
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if !ok || !token.Valid {
-		return nil, errors.New("invalid or expired access token")
+	if c.Email == "" {
+		return nil, errors.New("activation token has no email")
 	}
 
 	return &domain.TokenPayload{
-		UserID: claims["user_id"].(string),
-		Email:  claims["email"].(string),
-		Role:   domain.UserRole(claims["role"].(string)),
+		UserID: c.UserID,
+		Email:  c.Email,
 	}, nil
 }
 
-// VerifyRefreshToken validates a JWT refresh token and checks its expiration status.
-func (pt *JWTToken) VerifyRefreshToken(ctx context.Context, refreshToken string) (*domain.RefreshTokenPayload, bool, error) {
-	token, err := jwt.Parse(refreshToken, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("invalid signing method")
+// JWKS returns the JSON Web Key Set of the public keys verifying the tokens
+func (pt *JWTToken) JWKS() []byte {
+	return pt.jwks
+}
+
+func (pt *JWTToken) registeredClaims(subject string, audience []string, id string, issuedAt, expiresAt time.Time) jwt.Claims {
+	return jwt.Claims{
+		Issuer:   pt.issuer,
+		Subject:  subject,
+		Audience: audience,
+		IssuedAt: jwt.NewNumericDate(issuedAt),
+		Expiry:   jwt.NewNumericDate(expiresAt),
+		ID:       id,
+	}
+}
+
+func (pt *JWTToken) sign(c claims) (string, error) {
+	return jwt.Signed(pt.signer).Claims(c).Serialize()
+}
+
+// verify checks the signature of a token with the key named by its kid header, then its issuer,
+// audience, lifetime and type
+func (pt *JWTToken) verify(encodedToken, tokenType string, audience []string) (*claims, error) {
+	algorithms := make([]jose.SignatureAlgorithm, 0, len(pt.keys))
+	for _, key := range pt.keys {
+		if !slices.Contains(algorithms, key.algorithm) {
+			algorithms = append(algorithms, key.algorithm)
 		}
-		return []byte(config.RefreshKey()), nil
-	})
+	}
 
+	token, err := jwt.ParseSigned(encodedToken, algorithms)
 	if err != nil {
-		return nil, false, fmt.Errorf("failed to parse refresh token: %w", err)
+		return nil, err
 	}
 
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if !ok || !token.Valid {
-		return nil, false, errors.New("invalid or expired refresh token")
+	keyID := token.Headers[0].KeyID
+	index := slices.IndexFunc(pt.keys, func(key signingKey) bool { return key.id == keyID })
+	if index < 0 {
+		return nil, fmt.Errorf("unknown signing key %q", keyID)
 	}
 
-	almostExpired := claims.VerifyExpiresAt(time.Now().Add(24*time.Hour).Unix(), true)
+	var c claims
+	if err := token.Claims(pt.keys[index].key.Public(), &c); err != nil {
+		return nil, err
+	}
 
-	payload := &domain.RefreshTokenPayload{
-		UserID: claims["user_id"].(string),
+	err = c.ValidateWithLeeway(jwt.Expected{
+		Issuer:      pt.issuer,
+		AnyAudience: audience,
+		Time:        time.Now(),
+	}, 0)
+	if err != nil {
+		return nil, err
 	}
 
-	return payload, almostExpired, nil
-}
+	if c.Expiry == nil {
+		return nil, errors.New("token has no expiry")
+	}
+
+	if c.Type != tokenType {
+		return nil, fmt.Errorf("token type %q is not %q", c.Type, tokenType)
+	}
 
-func (*JWTToken) GenerateActivationToken(userID uint64) (string, error) {
-	claims := jwt.MapClaims{
-		"user_id": userID,
-		"exp":     time.Now().Add(24 * time.Hour).Unix(),
+	if c.UserID == "" || c.UserID != c.Subject {
+		return nil, errors.New("token has no user")
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(config.SecretKey()))
+	return &c, nil
 }
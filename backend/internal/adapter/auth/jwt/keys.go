@@ -0,0 +1,113 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/go-jose/go-jose/v4"
+)
+
+// minRSABits is the smallest RSA key accepted for signing
+const minRSABits = 2048
+
+// signingKey is a private key tokens are signed with, its public half is published in the JWKS
+type signingKey struct {
+	id        string
+	algorithm jose.SignatureAlgorithm
+	key       crypto.Signer
+}
+
+// publicKey is the JWKS entry of the key
+func (k signingKey) publicKey() jose.JSONWebKey {
+	return jose.JSONWebKey{
+		Key:       k.key.Public(),
+		KeyID:     k.id,
+		Algorithm: string(k.algorithm),
+		Use:       "sig",
+	}
+}
+
+// loadSigningKeys reads the "kid:path" entries of PEM encoded private keys
+func loadSigningKeys(entries []string) ([]signingKey, error) {
+	keys := make([]signingKey, 0, len(entries))
+	seen := make(map[string]bool, len(entries))
+
+	for _, entry := range entries {
+		id, path, ok := strings.Cut(entry, ":")
+		id, path = strings.TrimSpace(id), strings.TrimSpace(path)
+		if !ok || id == "" || path == "" {
+			return nil, fmt.Errorf("signing key %q is not formatted as kid:path", entry)
+		}
+
+		if seen[id] {
+			return nil, fmt.Errorf("signing key id %q is used twice", id)
+		}
+		seen[id] = true
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read signing key %q: %w", id, err)
+		}
+
+		key, algorithm, err := parsePrivateKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("signing key %q: %w", id, err)
+		}
+
+		keys = append(keys, signingKey{id: id, algorithm: algorithm, key: key})
+	}
+
+	return keys, nil
+}
+
+// parsePrivateKey decodes an RSA key, signing with RS256, or an Ed25519 key, signing with EdDSA
+func parsePrivateKey(data []byte) (crypto.Signer, jose.SignatureAlgorithm, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, "", errors.New("no PEM block found")
+	}
+
+	var parsed any
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		return nil, "", fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	switch key := parsed.(type) {
+	case *rsa.PrivateKey:
+		if key.N.BitLen() < minRSABits {
+			return nil, "", fmt.Errorf("RSA key must have at least %d bits", minRSABits)
+		}
+		return key, jose.RS256, nil
+	case ed25519.PrivateKey:
+		return key, jose.EdDSA, nil
+	default:
+		return nil, "", fmt.Errorf("unsupported key type %T, use RSA or Ed25519", parsed)
+	}
+}
+
+// generateSigningKey creates an Ed25519 key that only lives as long as the process
+func generateSigningKey() (signingKey, error) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return signingKey{}, err
+	}
+
+	return signingKey{id: "ephemeral", algorithm: jose.EdDSA, key: key}, nil
+}
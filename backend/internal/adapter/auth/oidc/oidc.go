@@ -0,0 +1,273 @@
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/aldotp/employee-attendance-system/internal/core/domain"
+	"github.com/aldotp/employee-attendance-system/internal/core/port"
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"golang.org/x/oauth2"
+)
+
+// keysRefreshInterval keeps tokens signed with unknown keys from fetching the key set on every login
+const keysRefreshInterval = time.Minute
+
+// signatureAlgorithms are the ID token signatures accepted, the asymmetric ones identity providers sign with
+var signatureAlgorithms = []jose.SignatureAlgorithm{jose.RS256, jose.RS384, jose.RS512, jose.PS256, jose.ES256, jose.ES384}
+
+// discovery is the part of the provider's discovery document the login flow needs
+type discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// idTokenClaims are the claims of the ID token besides the registered ones
+type idTokenClaims struct {
+	Nonce           string `json:"nonce"`
+	AuthorizedParty string `json:"azp"`
+	Email           string `json:"email"`
+	EmailVerified   any    `json:"email_verified"`
+	Name            string `json:"name"`
+}
+
+// Provider is an OpenID Connect identity provider. Its discovery document and signing keys are
+// fetched on first use, so the API starts while the provider is unreachable.
+type Provider struct {
+	issuer          string
+	clientID        string
+	clientSecret    string
+	redirectURL     string
+	scopes          []string
+	departmentClaim string
+	client          *http.Client
+
+	mu            sync.Mutex
+	config        *oauth2.Config
+	jwksURI       string
+	keys          jose.JSONWebKeySet
+	keysFetchedAt time.Time
+}
+
+func New(issuer, clientID, clientSecret, redirectURL string, scopes []string, departmentClaim string) port.OIDCProvider {
+	return &Provider{
+		issuer:          issuer,
+		clientID:        clientID,
+		clientSecret:    clientSecret,
+		redirectURL:     redirectURL,
+		scopes:          scopes,
+		departmentClaim: departmentClaim,
+		client:          &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// AuthCodeURL returns the address of the provider's sign-in page, sending the S256 challenge of
+// the PKCE verifier
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error) {
+	config, err := p.oauth2Config(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return config.AuthCodeURL(state, oauth2.S256ChallengeOption(verifier), oauth2.SetAuthURLParam("nonce", nonce)), nil
+}
+
+// Exchange redeems the authorization code with the PKCE verifier and returns the identity of the
+// verified ID token, which must carry the nonce the login was started with
+func (p *Provider) Exchange(ctx context.Context, code, verifier, nonce string) (*domain.OIDCIdentity, error) {
+	config, err := p.oauth2Config(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := config.Exchange(context.WithValue(ctx, oauth2.HTTPClient, p.client), code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange authorization code: %w", err)
+	}
+
+	rawIDToken, _ := token.Extra("id_token").(string)
+	if rawIDToken == "" {
+		return nil, errors.New("token response has no id_token")
+	}
+
+	return p.verify(ctx, rawIDToken, nonce)
+}
+
+// verify checks the signature, issuer, audience, lifetime and nonce of an ID token
+func (p *Provider) verify(ctx context.Context, rawIDToken, nonce string) (*domain.OIDCIdentity, error) {
+	idToken, err := jwt.ParseSigned(rawIDToken, signatureAlgorithms)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse id token: %w", err)
+	}
+
+	key, err := p.key(ctx, idToken.Headers[0].KeyID)
+	if err != nil {
+		return nil, err
+	}
+
+	var registered jwt.Claims
+	var claims idTokenClaims
+	var extra map[string]any
+	if err := idToken.Claims(key, &registered, &claims, &extra); err != nil {
+		return nil, fmt.Errorf("failed to verify id token: %w", err)
+	}
+
+	err = registered.Validate(jwt.Expected{
+		Issuer:      p.issuer,
+		AnyAudience: jwt.Audience{p.clientID},
+		Time:        time.Now(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid id token: %w", err)
+	}
+
+	// a token issued to several clients must name this one as the party it was issued for
+	if len(registered.Audience) > 1 && claims.AuthorizedParty != p.clientID {
+		return nil, errors.New("id token was issued for another client")
+	}
+
+	if registered.Subject == "" {
+		return nil, errors.New("id token has no subject")
+	}
+
+	if claims.Nonce != nonce {
+		return nil, errors.New("id token nonce does not match")
+	}
+
+	identity := &domain.OIDCIdentity{
+		Subject: registered.Subject,
+		Email:   claims.Email,
+		Name:    claims.Name,
+	}
+
+	// some providers send email_verified as a string
+	switch verified := claims.EmailVerified.(type) {
+	case bool:
+		identity.EmailVerified = verified
+	case string:
+		identity.EmailVerified = verified == "true"
+	}
+
+	if p.departmentClaim != "" {
+		identity.Department, _ = extra[p.departmentClaim].(string)
+	}
+
+	return identity, nil
+}
+
+// oauth2Config discovers the endpoints of the provider the first time it is used
+func (p *Provider) oauth2Config(ctx context.Context) (*oauth2.Config, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.config != nil {
+		return p.config, nil
+	}
+
+	var doc discovery
+	if err := p.getJSON(ctx, p.issuer+"/.well-known/openid-configuration", &doc); err != nil {
+		return nil, fmt.Errorf("failed to discover provider: %w", err)
+	}
+
+	if doc.Issuer != p.issuer {
+		return nil, fmt.Errorf("provider issuer %q does not match %q", doc.Issuer, p.issuer)
+	}
+
+	if doc.AuthorizationEndpoint == "" || doc.TokenEndpoint == "" || doc.JWKSURI == "" {
+		return nil, errors.New("provider discovery document is incomplete")
+	}
+
+	p.jwksURI = doc.JWKSURI
+	p.config = &oauth2.Config{
+		ClientID:     p.clientID,
+		ClientSecret: p.clientSecret,
+		RedirectURL:  p.redirectURL,
+		Scopes:       p.scopes,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  doc.AuthorizationEndpoint,
+			TokenURL: doc.TokenEndpoint,
+		},
+	}
+
+	return p.config, nil
+}
+
+// key finds the signing key of an ID token, fetching the key set again when the provider has
+// rotated to a key not seen yet
+func (p *Provider) key(ctx context.Context, keyID string) (*jose.JSONWebKey, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if key := p.findKey(keyID); key != nil {
+		return key, nil
+	}
+
+	if time.Since(p.keysFetchedAt) < keysRefreshInterval {
+		return nil, fmt.Errorf("unknown signing key %q", keyID)
+	}
+
+	var keys jose.JSONWebKeySet
+	if err := p.getJSON(ctx, p.jwksURI, &keys); err != nil {
+		return nil, fmt.Errorf("failed to fetch provider keys: %w", err)
+	}
+
+	p.keys = keys
+	p.keysFetchedAt = time.Now()
+
+	if key := p.findKey(keyID); key != nil {
+		return key, nil
+	}
+
+	return nil, fmt.Errorf("unknown signing key %q", keyID)
+}
+
+// findKey looks up a signing key of the current key set, a token without a key ID only matches a
+// set holding a single signing key
+func (p *Provider) findKey(keyID string) *jose.JSONWebKey {
+	keys := slices.DeleteFunc(slices.Clone(p.keys.Keys), func(key jose.JSONWebKey) bool {
+		return key.Use == "enc" || !key.IsPublic()
+	})
+
+	if keyID == "" {
+		if len(keys) == 1 {
+			return &keys[0]
+		}
+		return nil
+	}
+
+	for i := range keys {
+		if keys[i].KeyID == keyID {
+			return &keys[i]
+		}
+	}
+
+	return nil
+}
+
+func (p *Provider) getJSON(ctx context.Context, url string, dest any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d from %s", resp.StatusCode, url)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(dest)
+}
@@ -0,0 +1,346 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+)
+
+const (
+	testClientID = "attendance"
+	testVerifier = "verifier-verifier-verifier-verifier-verifier-verifier"
+	testNonce    = "nonce"
+)
+
+// mockProvider is an identity provider serving discovery, its key set and a token endpoint that
+// answers with the ID token set by the test
+type mockProvider struct {
+	*httptest.Server
+
+	mu      sync.Mutex
+	keys    []jose.JSONWebKey
+	idToken string
+}
+
+func newMockProvider(t *testing.T) *mockProvider {
+	t.Helper()
+
+	m := &mockProvider{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(discovery{
+			Issuer:                m.URL,
+			AuthorizationEndpoint: m.URL + "/authorize",
+			TokenEndpoint:         m.URL + "/token",
+			JWKSURI:               m.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		json.NewEncoder(w).Encode(jose.JSONWebKeySet{Keys: m.keys})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if r.PostFormValue("code") != "code" || r.PostFormValue("code_verifier") != testVerifier {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"access_token": "access",
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+			"id_token":     m.idToken,
+		})
+	})
+
+	m.Server = httptest.NewServer(mux)
+	t.Cleanup(m.Close)
+
+	return m
+}
+
+// publish replaces the key set of the provider with the public keys of the signers
+func (m *mockProvider) publish(signers ...*testSigner) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.keys = nil
+	for _, s := range signers {
+		m.keys = append(m.keys, jose.JSONWebKey{Key: &s.key.PublicKey, KeyID: s.keyID, Algorithm: string(jose.RS256), Use: "sig"})
+	}
+}
+
+func (m *mockProvider) respondWith(idToken string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.idToken = idToken
+}
+
+// claims returns valid claims of an ID token the provider issues to the test client
+func (m *mockProvider) claims() map[string]any {
+	now := time.Now()
+	return map[string]any{
+		"iss":            m.URL,
+		"sub":            "subject-1",
+		"aud":            testClientID,
+		"iat":            now.Unix(),
+		"exp":            now.Add(5 * time.Minute).Unix(),
+		"nonce":          testNonce,
+		"email":          "jane@example.com",
+		"email_verified": true,
+		"name":           "Jane Doe",
+		"department":     "Engineering",
+	}
+}
+
+type testSigner struct {
+	keyID string
+	key   *rsa.PrivateKey
+}
+
+func newTestSigner(t *testing.T, keyID string) *testSigner {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	return &testSigner{keyID: keyID, key: key}
+}
+
+func (s *testSigner) sign(t *testing.T, claims map[string]any) string {
+	t.Helper()
+
+	signer, err := jose.NewSigner(
+		jose.SigningKey{Algorithm: jose.RS256, Key: jose.JSONWebKey{Key: s.key, KeyID: s.keyID}},
+		(&jose.SignerOptions{}).WithType("JWT"),
+	)
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+
+	token, err := jwt.Signed(signer).Claims(claims).Serialize()
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+
+	return token
+}
+
+func newTestProvider(issuer string) *Provider {
+	return New(issuer, testClientID, "secret", "http://127.0.0.1/callback", []string{"openid", "email"}, "department").(*Provider)
+}
+
+func TestExchange(t *testing.T) {
+	mock := newMockProvider(t)
+	signer := newTestSigner(t, "key-1")
+	mock.publish(signer)
+
+	mock.respondWith(signer.sign(t, mock.claims()))
+
+	identity, err := newTestProvider(mock.URL).Exchange(context.Background(), "code", testVerifier, testNonce)
+	if err != nil {
+		t.Fatalf("Exchange() error = %v", err)
+	}
+
+	if identity.Subject != "subject-1" || identity.Email != "jane@example.com" || !identity.EmailVerified ||
+		identity.Name != "Jane Doe" || identity.Department != "Engineering" {
+		t.Errorf("Exchange() identity = %+v", identity)
+	}
+}
+
+func TestExchangeSendsVerifier(t *testing.T) {
+	mock := newMockProvider(t)
+	signer := newTestSigner(t, "key-1")
+	mock.publish(signer)
+	mock.respondWith(signer.sign(t, mock.claims()))
+
+	if _, err := newTestProvider(mock.URL).Exchange(context.Background(), "code", "another-verifier", testNonce); err == nil {
+		t.Error("Exchange() error = nil with the wrong PKCE verifier")
+	}
+}
+
+func TestExchangeRefusesInvalidIDTokens(t *testing.T) {
+	mock := newMockProvider(t)
+	signer := newTestSigner(t, "key-1")
+	mock.publish(signer)
+
+	tests := []struct {
+		name  string
+		token func() string
+	}{
+		{"bad signature", func() string {
+			// same key ID, different key
+			return newTestSigner(t, "key-1").sign(t, mock.claims())
+		}},
+		{"wrong issuer", func() string {
+			claims := mock.claims()
+			claims["iss"] = "https://evil.example.com"
+			return signer.sign(t, claims)
+		}},
+		{"wrong audience", func() string {
+			claims := mock.claims()
+			claims["aud"] = "another-client"
+			return signer.sign(t, claims)
+		}},
+		{"several audiences without azp", func() string {
+			claims := mock.claims()
+			claims["aud"] = []string{testClientID, "another-client"}
+			return signer.sign(t, claims)
+		}},
+		{"several audiences for another azp", func() string {
+			claims := mock.claims()
+			claims["aud"] = []string{testClientID, "another-client"}
+			claims["azp"] = "another-client"
+			return signer.sign(t, claims)
+		}},
+		{"nonce mismatch", func() string {
+			claims := mock.claims()
+			claims["nonce"] = "another-nonce"
+			return signer.sign(t, claims)
+		}},
+		{"expired", func() string {
+			claims := mock.claims()
+			claims["iat"] = time.Now().Add(-time.Hour).Unix()
+			claims["exp"] = time.Now().Add(-30 * time.Minute).Unix()
+			return signer.sign(t, claims)
+		}},
+		{"no subject", func() string {
+			claims := mock.claims()
+			delete(claims, "sub")
+			return signer.sign(t, claims)
+		}},
+		{"unsigned", func() string {
+			return strings.Join(strings.Split(signer.sign(t, mock.claims()), ".")[:2], ".") + "."
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock.respondWith(tt.token())
+
+			if identity, err := newTestProvider(mock.URL).Exchange(context.Background(), "code", testVerifier, testNonce); err == nil {
+				t.Errorf("Exchange() = %+v, want an error", identity)
+			}
+		})
+	}
+}
+
+func TestExchangeAcceptsSeveralAudiencesForThisClient(t *testing.T) {
+	mock := newMockProvider(t)
+	signer := newTestSigner(t, "key-1")
+	mock.publish(signer)
+
+	claims := mock.claims()
+	claims["aud"] = []string{testClientID, "another-client"}
+	claims["azp"] = testClientID
+	mock.respondWith(signer.sign(t, claims))
+
+	if _, err := newTestProvider(mock.URL).Exchange(context.Background(), "code", testVerifier, testNonce); err != nil {
+		t.Errorf("Exchange() error = %v", err)
+	}
+}
+
+func TestExchangeEmailVerifiedString(t *testing.T) {
+	mock := newMockProvider(t)
+	signer := newTestSigner(t, "key-1")
+	mock.publish(signer)
+
+	claims := mock.claims()
+	claims["email_verified"] = "true"
+	mock.respondWith(signer.sign(t, claims))
+
+	identity, err := newTestProvider(mock.URL).Exchange(context.Background(), "code", testVerifier, testNonce)
+	if err != nil {
+		t.Fatalf("Exchange() error = %v", err)
+	}
+	if !identity.EmailVerified {
+		t.Error("EmailVerified = false, want true")
+	}
+}
+
+func TestExchangeKeyRotation(t *testing.T) {
+	mock := newMockProvider(t)
+	oldSigner := newTestSigner(t, "key-1")
+	newSigner := newTestSigner(t, "key-2")
+	mock.publish(oldSigner)
+
+	provider := newTestProvider(mock.URL)
+	exchange := func(signer *testSigner) error {
+		mock.respondWith(signer.sign(t, mock.claims()))
+		_, err := provider.Exchange(context.Background(), "code", testVerifier, testNonce)
+		return err
+	}
+
+	if err := exchange(oldSigner); err != nil {
+		t.Fatalf("Exchange() with the published key error = %v", err)
+	}
+
+	mock.publish(oldSigner, newSigner)
+
+	// the key set was just fetched, unknown keys do not fetch it again right away
+	if err := exchange(newSigner); err == nil {
+		t.Fatal("Exchange() with a new key right after fetching the key set error = nil")
+	}
+
+	provider.mu.Lock()
+	provider.keysFetchedAt = time.Now().Add(-keysRefreshInterval)
+	provider.mu.Unlock()
+
+	if err := exchange(newSigner); err != nil {
+		t.Fatalf("Exchange() with the rotated key error = %v", err)
+	}
+
+	// keys still published keep working without another fetch
+	if err := exchange(oldSigner); err != nil {
+		t.Errorf("Exchange() with the previous key error = %v", err)
+	}
+}
+
+func TestDiscoveryIssuerMismatch(t *testing.T) {
+	mock := newMockProvider(t)
+
+	// the provider is configured with another issuer than the one its discovery document names
+	provider := newTestProvider(mock.URL)
+	provider.issuer = mock.URL + "/"
+	if _, err := provider.AuthCodeURL(context.Background(), "state", testNonce, testVerifier); err == nil {
+		t.Error("AuthCodeURL() error = nil with a mismatching issuer")
+	}
+}
+
+func TestAuthCodeURL(t *testing.T) {
+	mock := newMockProvider(t)
+
+	url, err := newTestProvider(mock.URL).AuthCodeURL(context.Background(), "state", testNonce, testVerifier)
+	if err != nil {
+		t.Fatalf("AuthCodeURL() error = %v", err)
+	}
+
+	for _, param := range []string{"state=state", "nonce=" + testNonce, "code_challenge_method=S256", "code_challenge="} {
+		if !strings.Contains(url, param) {
+			t.Errorf("AuthCodeURL() = %s, missing %s", url, param)
+		}
+	}
+	if strings.Contains(url, testVerifier) {
+		t.Errorf("AuthCodeURL() = %s sends the PKCE verifier", url)
+	}
+}
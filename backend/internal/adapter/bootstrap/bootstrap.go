@@ -6,6 +6,7 @@ import (
 	"github.com/aldotp/employee-attendance-system/internal/adapter/config"
 	"github.com/aldotp/employee-attendance-system/internal/adapter/storage/postgres"
 	"github.com/aldotp/employee-attendance-system/internal/core/port"
+	"github.com/aldotp/employee-attendance-system/pkg/email"
 	"github.com/aldotp/employee-attendance-system/pkg/gcs"
 	"github.com/aldotp/employee-attendance-system/pkg/minio"
 
@@ -18,22 +19,37 @@ type Bootstrap struct {
 	Config     *config.Config
 	PostgresDB *postgres.DB
 	GCS        *gcs.GCS
-	minio      *minio.MinioClient
-
-	AttendanceRepo   port.AttendanceRepository
-	DepartmentRepo   port.DepartmentRepository
-	DeviceLogRepo    port.DeviceLogRepository
-	DeviceRepo       port.DeviceRepository
-	EmployeeRepo     port.EmployeeRepository
-	LeaveRequestRepo port.LeaveRequestRepository
-	NotificationRepo port.NotificationRepository
-	UserRepo         port.UserRepository
-	WorkLocationRepo port.WorkLocationRepository
-	ScheduleRepo     port.ScheduleRepository
-	MonitoringRepo   port.MonitoringRepository
-
-	Token port.TokenInterface
-	Cache port.CacheInterface
+	Minio      *minio.MinioClient
+	Email      *email.Email
+
+	AttendanceRepo      port.AttendanceRepository
+	DepartmentRepo      port.DepartmentRepository
+	DeviceLogRepo       port.DeviceLogRepository
+	DeviceRepo          port.DeviceRepository
+	EmployeeRepo        port.EmployeeRepository
+	LeaveRequestRepo    port.LeaveRequestRepository
+	LeaveAttachmentRepo port.LeaveAttachmentRepository
+	NotificationRepo    port.NotificationRepository
+	UserRepo            port.UserRepository
+	WorkLocationRepo    port.WorkLocationRepository
+	ScheduleRepo        port.ScheduleRepository
+	RotationRepo        port.RotationRepository
+	ShiftTemplateRepo   port.ShiftTemplateRepository
+	ScheduleRuleRepo    port.ScheduleRuleRepository
+	OpenShiftRepo       port.OpenShiftRepository
+	CalendarFeedRepo    port.CalendarFeedRepository
+	PasswordResetRepo   port.PasswordResetRepository
+	MFARepo             port.MFARepository
+	PermissionRepo      port.PermissionRepository
+	APIKeyRepo          port.APIKeyRepository
+	MonitoringRepo      port.MonitoringRepository
+
+	NotificationChannels []port.NotificationChannel
+
+	Token  port.TokenInterface
+	Cache  port.CacheInterface
+	PubSub port.PubSubInterface
+	OIDC   port.OIDCProvider
 }
 
 func NewBootstrap(ctx context.Context) *Bootstrap {
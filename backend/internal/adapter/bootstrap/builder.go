@@ -7,8 +7,14 @@ func (b *Bootstrap) BuildRestBootstrap() *Bootstrap {
 	b.setRestApiRepository()
 	b.setLogger()
 	b.setJWTToken()
+	b.setOIDC()
 	b.setCache()
 	b.SetMinio()
+	b.setPubSub()
+	b.setEmail()
+	b.checkEmailVerification()
+	b.checkMFA()
+	b.setNotificationChannels()
 	// b.setGCS()
 	// b.setRabbitMQ()
 
@@ -24,8 +30,24 @@ func (b *Bootstrap) BuildConsumerGenerateReportingBootstrap() *Bootstrap {
 	b.setJWTToken()
 	b.setCache()
 	b.SetMinio()
+	b.setPubSub()
+	b.setEmail()
+	b.setNotificationChannels()
 	// b.setGCS()
 	// b.setRabbitMQ()
 
 	return b
 }
+
+func (b *Bootstrap) BuildConsumerDispatchNotificationsBootstrap() *Bootstrap {
+	// set dependencies
+	b.setConfig()
+	b.setPostgresDB()
+	b.setRestApiRepository()
+	b.setLogger()
+	b.setPubSub()
+	b.setEmail()
+	b.setNotificationChannels()
+
+	return b
+}
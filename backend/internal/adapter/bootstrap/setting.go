@@ -6,10 +6,15 @@ import (
 	"os"
 
 	"github.com/aldotp/employee-attendance-system/internal/adapter/auth/jwt"
+	"github.com/aldotp/employee-attendance-system/internal/adapter/auth/oidc"
 	"github.com/aldotp/employee-attendance-system/internal/adapter/config"
+	"github.com/aldotp/employee-attendance-system/internal/adapter/notification"
+	"github.com/aldotp/employee-attendance-system/internal/adapter/pubsub"
 	"github.com/aldotp/employee-attendance-system/internal/adapter/storage/postgres"
 	postgresRepo "github.com/aldotp/employee-attendance-system/internal/adapter/storage/postgres/repository"
 	"github.com/aldotp/employee-attendance-system/internal/adapter/storage/redis"
+	"github.com/aldotp/employee-attendance-system/internal/core/port"
+	"github.com/aldotp/employee-attendance-system/pkg/email"
 	"github.com/aldotp/employee-attendance-system/pkg/gcs"
 	"github.com/aldotp/employee-attendance-system/pkg/logger"
 	"github.com/aldotp/employee-attendance-system/pkg/minio"
@@ -60,6 +65,15 @@ func (b *Bootstrap) setJWTToken() {
 	b.Token = token
 }
 
+// setOIDC configures the identity provider of single sign-on, which stays disabled without an issuer
+func (b *Bootstrap) setOIDC() {
+	if config.OIDCIssuer() == "" {
+		return
+	}
+
+	b.OIDC = oidc.New(config.OIDCIssuer(), config.OIDCClientID(), config.OIDCClientSecret(), config.OIDCRedirectURL(), config.OIDCScopes(), config.OIDCDepartmentClaim())
+}
+
 func (b *Bootstrap) setCache() {
 	cache, err := redis.New(b.ctx, &config.Redis{
 		Addr:     config.RedisAddr(),
@@ -72,6 +86,27 @@ func (b *Bootstrap) setCache() {
 	b.Cache = cache
 }
 
+// setPubSub picks the pub/sub broadcasting notifications to the connected clients
+func (b *Bootstrap) setPubSub() {
+	switch driver := config.PubSubDriver(); driver {
+	case "memory":
+		b.PubSub = pubsub.NewMemory()
+	case "redis":
+		ps, err := pubsub.NewRedis(b.ctx, &config.Redis{
+			Addr:     config.RedisAddr(),
+			Password: config.RedisPassword(),
+		})
+		if err != nil {
+			panic(err)
+		}
+
+		b.PubSub = ps
+	default:
+		slog.Error("Error initializing pub/sub", "error", "unknown PUBSUB_DRIVER "+driver)
+		os.Exit(1)
+	}
+}
+
 func (b *Bootstrap) setRestApiRepository() {
 	b.UserRepo = postgresRepo.NewUserRepository(b.PostgresDB)
 	b.AttendanceRepo = postgresRepo.NewAttendanceRepository(b.PostgresDB)
@@ -80,9 +115,19 @@ func (b *Bootstrap) setRestApiRepository() {
 	b.DeviceRepo = postgresRepo.NewDeviceRepository(b.PostgresDB)
 	b.EmployeeRepo = postgresRepo.NewEmployeeRepository(b.PostgresDB)
 	b.LeaveRequestRepo = postgresRepo.NewLeaveRequestRepository(b.PostgresDB)
+	b.LeaveAttachmentRepo = postgresRepo.NewLeaveAttachmentRepository(b.PostgresDB)
 	b.NotificationRepo = postgresRepo.NewNotificationRepository(b.PostgresDB)
 	b.WorkLocationRepo = postgresRepo.NewWorkLocationRepository(b.PostgresDB)
 	b.ScheduleRepo = postgresRepo.NewScheduleRepository(b.PostgresDB)
+	b.RotationRepo = postgresRepo.NewRotationRepository(b.PostgresDB)
+	b.ShiftTemplateRepo = postgresRepo.NewShiftTemplateRepository(b.PostgresDB)
+	b.ScheduleRuleRepo = postgresRepo.NewScheduleRuleRepository(b.PostgresDB)
+	b.OpenShiftRepo = postgresRepo.NewOpenShiftRepository(b.PostgresDB)
+	b.CalendarFeedRepo = postgresRepo.NewCalendarFeedRepository(b.PostgresDB)
+	b.PasswordResetRepo = postgresRepo.NewPasswordResetRepository(b.PostgresDB)
+	b.MFARepo = postgresRepo.NewMFARepository(b.PostgresDB)
+	b.PermissionRepo = postgresRepo.NewPermissionRepository(b.PostgresDB)
+	b.APIKeyRepo = postgresRepo.NewAPIKeyRepository(b.PostgresDB)
 	b.MonitoringRepo = postgresRepo.NewMonitoringRepository(b.PostgresDB)
 }
 
@@ -102,5 +147,49 @@ func (b *Bootstrap) SetMinio() {
 		log.Fatalf("error minio %v", err.Error())
 	}
 
-	b.minio = minio
+	b.Minio = minio
+}
+
+func (b *Bootstrap) setEmail() {
+	if config.SMTPHost() == "" {
+		return
+	}
+
+	b.Email = email.NewEmailSender(config.SMTPHost(), config.SMTPPort(), config.SMTPUsername(), config.SMTPPassword(), config.SMTPFrom())
+}
+
+// checkEmailVerification refuses to start without SMTP, which new accounts need to verify their
+// address, unless verification is explicitly disabled
+func (b *Bootstrap) checkEmailVerification() {
+	if config.EmailVerificationDisabled() {
+		b.Log.Warn("email verification is disabled, new accounts are verified without an email")
+		return
+	}
+
+	if b.Email == nil {
+		slog.Error("Error initializing email verification", "error", "SMTP_HOST is required unless EMAIL_VERIFICATION_DISABLED=true")
+		os.Exit(1)
+	}
+}
+
+// checkMFA refuses to start without a dedicated key encrypting the stored TOTP secrets
+func (b *Bootstrap) checkMFA() {
+	key := config.MFAEncryptionKey()
+	if key == "" || key == config.SecretKey() {
+		slog.Error("Error initializing MFA", "error", "MFA_ENCRYPTION_KEY is required and must differ from SECRET_KEY")
+		os.Exit(1)
+	}
+}
+
+// setNotificationChannels registers the channels notifications can be delivered through, email
+// only when SMTP is configured
+func (b *Bootstrap) setNotificationChannels() {
+	b.NotificationChannels = []port.NotificationChannel{
+		notification.NewInAppChannel(b.PubSub),
+		notification.NewWebhookChannel(config.NotificationWebhookSecret(), config.NotificationWebhookTimeout()),
+	}
+
+	if b.Email != nil {
+		b.NotificationChannels = append(b.NotificationChannels, notification.NewEmailChannel(b.Email, config.NotificationEmailTemplate(), config.AppName()))
+	}
 }
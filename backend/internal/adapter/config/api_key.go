@@ -0,0 +1,37 @@
+package config
+
+import (
+	"time"
+
+	"github.com/aldotp/employee-attendance-system/internal/core/domain"
+	"github.com/spf13/viper"
+)
+
+// API key related configuration
+
+// APIKeyDefaultTTL reads API_KEY_DEFAULT_TTL_DAYS, the lifetime of keys created without an expiry
+func APIKeyDefaultTTL() time.Duration {
+	days := viper.GetInt("API_KEY_DEFAULT_TTL_DAYS")
+	if days <= 0 {
+		return 90 * 24 * time.Hour
+	}
+
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// APIKeyMaxTTL reads API_KEY_MAX_TTL_DAYS, the longest lifetime a key can be created with
+func APIKeyMaxTTL() time.Duration {
+	days := viper.GetInt("API_KEY_MAX_TTL_DAYS")
+	if days <= 0 {
+		return 365 * 24 * time.Hour
+	}
+
+	return time.Duration(days) * 24 * time.Hour
+}
+
+func APIKeyPolicy() domain.APIKeyPolicy {
+	return domain.APIKeyPolicy{
+		DefaultTTL: APIKeyDefaultTTL(),
+		MaxTTL:     max(APIKeyMaxTTL(), APIKeyDefaultTTL()),
+	}
+}
@@ -18,7 +18,3 @@ func AppVersion() string {
 func SecretKey() string {
 	return viper.GetString("SECRET_KEY")
 }
-
-func RefreshKey() string {
-	return viper.GetString("REFRESH_KEY")
-}
@@ -0,0 +1,56 @@
+package config
+
+import (
+	"time"
+
+	"github.com/aldotp/employee-attendance-system/internal/core/domain"
+	"github.com/spf13/viper"
+)
+
+// Attendance related configuration
+
+// AttendanceEarlyCheckIn reads ATTENDANCE_EARLY_CHECK_IN, the number of minutes before the
+// start of a shift from which a check-in is matched to it
+func AttendanceEarlyCheckIn() time.Duration {
+	minutes := viper.GetInt("ATTENDANCE_EARLY_CHECK_IN")
+	if minutes <= 0 {
+		return 2 * time.Hour
+	}
+
+	return time.Duration(minutes) * time.Minute
+}
+
+// AttendanceLateGrace reads ATTENDANCE_LATE_GRACE, the number of minutes after the start of a
+// shift during which a check-in is not counted as late
+func AttendanceLateGrace() time.Duration {
+	return time.Duration(viper.GetInt("ATTENDANCE_LATE_GRACE")) * time.Minute
+}
+
+// AttendanceCheckInReminder reads ATTENDANCE_CHECK_IN_REMINDER, the number of minutes before the
+// start of a shift at which an employee who has not checked in is reminded
+func AttendanceCheckInReminder() time.Duration {
+	if !viper.IsSet("ATTENDANCE_CHECK_IN_REMINDER") {
+		return 15 * time.Minute
+	}
+
+	return time.Duration(max(viper.GetInt("ATTENDANCE_CHECK_IN_REMINDER"), 0)) * time.Minute
+}
+
+// AttendanceMissedCheckOut reads ATTENDANCE_MISSED_CHECK_OUT, the number of minutes after the end
+// of a shift at which an employee who has not checked out is warned
+func AttendanceMissedCheckOut() time.Duration {
+	if !viper.IsSet("ATTENDANCE_MISSED_CHECK_OUT") {
+		return time.Hour
+	}
+
+	return time.Duration(max(viper.GetInt("ATTENDANCE_MISSED_CHECK_OUT"), 0)) * time.Minute
+}
+
+func AttendancePolicy() domain.AttendancePolicy {
+	return domain.AttendancePolicy{
+		EarlyCheckIn:        AttendanceEarlyCheckIn(),
+		LateGrace:           AttendanceLateGrace(),
+		CheckInReminder:     AttendanceCheckInReminder(),
+		MissedCheckOutAfter: AttendanceMissedCheckOut(),
+	}
+}
@@ -0,0 +1,41 @@
+package config
+
+import (
+	"github.com/aldotp/employee-attendance-system/internal/core/domain"
+	"github.com/spf13/viper"
+)
+
+// Calendar feed related configuration
+
+// CalendarFeedBaseURL reads CALENDAR_FEED_BASE_URL, the public address of the API that calendar
+// clients subscribe to
+func CalendarFeedBaseURL() string {
+	return viper.GetString("CALENDAR_FEED_BASE_URL")
+}
+
+// CalendarFeedPastDays reads CALENDAR_FEED_PAST_DAYS, how many days of history the feed renders
+func CalendarFeedPastDays() int {
+	if !viper.IsSet("CALENDAR_FEED_PAST_DAYS") {
+		return 30
+	}
+
+	return max(viper.GetInt("CALENDAR_FEED_PAST_DAYS"), 0)
+}
+
+// CalendarFeedFutureDays reads CALENDAR_FEED_FUTURE_DAYS, how many days ahead the feed renders
+func CalendarFeedFutureDays() int {
+	days := viper.GetInt("CALENDAR_FEED_FUTURE_DAYS")
+	if days <= 0 {
+		return 180
+	}
+
+	return days
+}
+
+func CalendarFeedPolicy() domain.CalendarFeedPolicy {
+	return domain.CalendarFeedPolicy{
+		BaseURL:    CalendarFeedBaseURL(),
+		PastDays:   CalendarFeedPastDays(),
+		FutureDays: CalendarFeedFutureDays(),
+	}
+}
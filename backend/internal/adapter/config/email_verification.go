@@ -0,0 +1,75 @@
+package config
+
+import (
+	"time"
+
+	"github.com/aldotp/employee-attendance-system/internal/core/domain"
+	"github.com/spf13/viper"
+)
+
+// Email verification related configuration
+
+// EmailVerificationURL reads EMAIL_VERIFICATION_URL, the page of the client application that
+// verifies the email address of a new account
+func EmailVerificationURL() string {
+	return viper.GetString("EMAIL_VERIFICATION_URL")
+}
+
+// EmailVerificationEmailTemplate reads EMAIL_VERIFICATION_EMAIL_TEMPLATE, the HTML template of verification emails
+func EmailVerificationEmailTemplate() string {
+	if path := viper.GetString("EMAIL_VERIFICATION_EMAIL_TEMPLATE"); path != "" {
+		return path
+	}
+
+	return "templates/email/email_verification.html"
+}
+
+// EmailVerificationEmailLimit reads EMAIL_VERIFICATION_EMAIL_LIMIT, the resend requests allowed
+// per email address within the rate window
+func EmailVerificationEmailLimit() int {
+	if !viper.IsSet("EMAIL_VERIFICATION_EMAIL_LIMIT") {
+		return 3
+	}
+
+	return max(viper.GetInt("EMAIL_VERIFICATION_EMAIL_LIMIT"), 0)
+}
+
+// EmailVerificationIPLimit reads EMAIL_VERIFICATION_IP_LIMIT, the resend requests allowed per
+// client IP within the rate window
+func EmailVerificationIPLimit() int {
+	if !viper.IsSet("EMAIL_VERIFICATION_IP_LIMIT") {
+		return 10
+	}
+
+	return max(viper.GetInt("EMAIL_VERIFICATION_IP_LIMIT"), 0)
+}
+
+// EmailVerificationRateWindow reads EMAIL_VERIFICATION_RATE_WINDOW, the number of minutes the
+// resend limits are counted over
+func EmailVerificationRateWindow() time.Duration {
+	minutes := viper.GetInt("EMAIL_VERIFICATION_RATE_WINDOW")
+	if minutes <= 0 {
+		return time.Hour
+	}
+
+	return time.Duration(minutes) * time.Minute
+}
+
+// EmailVerificationDisabled reads EMAIL_VERIFICATION_DISABLED, which must be set to true to run
+// without SMTP, verifying new addresses without an email
+func EmailVerificationDisabled() bool {
+	return viper.GetBool("EMAIL_VERIFICATION_DISABLED")
+}
+
+func EmailVerificationPolicy() domain.EmailVerificationPolicy {
+	return domain.EmailVerificationPolicy{
+		URL:           EmailVerificationURL(),
+		EmailTemplate: EmailVerificationEmailTemplate(),
+		AppName:       AppName(),
+		TokenTTL:      time.Duration(ActivationTokenExpired()) * time.Minute,
+		EmailLimit:    EmailVerificationEmailLimit(),
+		IPLimit:       EmailVerificationIPLimit(),
+		RateWindow:    EmailVerificationRateWindow(),
+		Disabled:      EmailVerificationDisabled(),
+	}
+}
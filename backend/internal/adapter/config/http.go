@@ -1,6 +1,10 @@
 package config
 
-import "github.com/spf13/viper"
+import (
+	"strings"
+
+	"github.com/spf13/viper"
+)
 
 // HTTP related configuration
 func HTTPPort() string {
@@ -14,3 +18,17 @@ func HTTPURL() string {
 func HTTPAllowedOrigins() string {
 	return viper.GetString("HTTP_ALLOWED_ORIGINS")
 }
+
+// HTTPTrustedProxies reads HTTP_TRUSTED_PROXIES, the comma separated addresses or CIDR ranges of
+// the reverse proxies whose X-Forwarded-For header gives the client IP. Without any the client IP
+// is the address of the connection.
+func HTTPTrustedProxies() []string {
+	var proxies []string
+	for _, proxy := range strings.Split(viper.GetString("HTTP_TRUSTED_PROXIES"), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+
+	return proxies
+}
@@ -1,6 +1,10 @@
 package config
 
-import "github.com/spf13/viper"
+import (
+	"strings"
+
+	"github.com/spf13/viper"
+)
 
 // Token related configuration
 func TokenDuration() string {
@@ -14,3 +18,54 @@ func AccessTokenExpired() int {
 func RefreshTokenExpired() int {
 	return viper.GetInt("REFRESH_TOKEN_EXPIRED")
 }
+
+// ActivationTokenExpired reads ACTIVATION_TOKEN_EXPIRED, the number of minutes an email
+// verification link stays valid
+func ActivationTokenExpired() int {
+	minutes := viper.GetInt("ACTIVATION_TOKEN_EXPIRED")
+	if minutes <= 0 {
+		return 24 * 60
+	}
+
+	return minutes
+}
+
+// JWTSigningKeys reads JWT_SIGNING_KEYS, the comma separated "kid:path" entries of the PEM private
+// keys signing tokens. The first key signs new tokens, the others only verify tokens issued before
+// a rotation.
+func JWTSigningKeys() []string {
+	var keys []string
+	for _, entry := range strings.Split(viper.GetString("JWT_SIGNING_KEYS"), ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			keys = append(keys, entry)
+		}
+	}
+
+	return keys
+}
+
+// JWTIssuer reads JWT_ISSUER, the iss claim of issued tokens, falling back to APP_NAME
+func JWTIssuer() string {
+	if issuer := viper.GetString("JWT_ISSUER"); issuer != "" {
+		return issuer
+	}
+
+	return AppName()
+}
+
+// JWTAudience reads JWT_AUDIENCE, the comma separated services access tokens are issued for,
+// falling back to the issuer
+func JWTAudience() []string {
+	var audience []string
+	for _, entry := range strings.Split(viper.GetString("JWT_AUDIENCE"), ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			audience = append(audience, entry)
+		}
+	}
+
+	if len(audience) == 0 {
+		return []string{JWTIssuer()}
+	}
+
+	return audience
+}
@@ -0,0 +1,95 @@
+package config
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aldotp/employee-attendance-system/internal/core/domain"
+	"github.com/spf13/viper"
+)
+
+// Leave related configuration
+
+// LeaveAttachmentRequiredAfterDays parses LEAVE_ATTACHMENT_REQUIRED, a comma separated
+// list of "type:days" pairs such as "sick:2,maternity:0"
+func LeaveAttachmentRequiredAfterDays() map[domain.LeaveType]int {
+	rules := make(map[domain.LeaveType]int)
+
+	for _, rule := range strings.Split(viper.GetString("LEAVE_ATTACHMENT_REQUIRED"), ",") {
+		parts := strings.SplitN(strings.TrimSpace(rule), ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+
+		days, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+		if err != nil {
+			continue
+		}
+
+		rules[domain.LeaveType(strings.TrimSpace(parts[0]))] = days
+	}
+
+	return rules
+}
+
+func LeaveAttachmentMaxSize() int64 {
+	size := viper.GetInt64("LEAVE_ATTACHMENT_MAX_SIZE")
+	if size <= 0 {
+		return 5 << 20
+	}
+
+	return size
+}
+
+func LeaveAttachmentAllowedTypes() []string {
+	types := viper.GetString("LEAVE_ATTACHMENT_ALLOWED_TYPES")
+	if types == "" {
+		return []string{"application/pdf", "image/jpeg", "image/png"}
+	}
+
+	var allowed []string
+	for _, contentType := range strings.Split(types, ",") {
+		if contentType = strings.TrimSpace(contentType); contentType != "" {
+			allowed = append(allowed, contentType)
+		}
+	}
+
+	return allowed
+}
+
+func LeaveAttachmentURLExpiry() time.Duration {
+	minutes := viper.GetInt("LEAVE_ATTACHMENT_URL_EXPIRED")
+	if minutes <= 0 {
+		minutes = 15
+	}
+
+	return time.Duration(minutes) * time.Minute
+}
+
+// LeaveAttachmentPolicy builds the attachment policy from the leave configuration
+func LeaveAttachmentPolicy() domain.LeaveAttachmentPolicy {
+	return domain.LeaveAttachmentPolicy{
+		RequiredAfterDays:   LeaveAttachmentRequiredAfterDays(),
+		MaxSize:             LeaveAttachmentMaxSize(),
+		AllowedContentTypes: LeaveAttachmentAllowedTypes(),
+		DownloadURLExpiry:   LeaveAttachmentURLExpiry(),
+	}
+}
+
+// LeaveMinCoverage is the minimum share (0-1) of a department that must stay available
+func LeaveMinCoverage() float64 {
+	return viper.GetFloat64("LEAVE_MIN_COVERAGE")
+}
+
+func LeaveCoverageBlockApproval() bool {
+	return viper.GetBool("LEAVE_COVERAGE_BLOCK_APPROVAL")
+}
+
+// LeaveCoveragePolicy builds the department coverage policy from the leave configuration
+func LeaveCoveragePolicy() domain.LeaveCoveragePolicy {
+	return domain.LeaveCoveragePolicy{
+		MinCoverage:   LeaveMinCoverage(),
+		BlockApproval: LeaveCoverageBlockApproval(),
+	}
+}
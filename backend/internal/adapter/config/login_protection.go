@@ -0,0 +1,92 @@
+package config
+
+import (
+	"time"
+
+	"github.com/aldotp/employee-attendance-system/internal/core/domain"
+	"github.com/spf13/viper"
+)
+
+// Login protection related configuration
+
+// LoginAccountLimit reads LOGIN_ACCOUNT_LIMIT, the failed logins of one email address within the
+// attempt window that lock it
+func LoginAccountLimit() int {
+	if !viper.IsSet("LOGIN_ACCOUNT_LIMIT") {
+		return 10
+	}
+
+	return max(viper.GetInt("LOGIN_ACCOUNT_LIMIT"), 0)
+}
+
+// LoginIPLimit reads LOGIN_IP_LIMIT, the failed logins from one client IP within the attempt
+// window that lock it out
+func LoginIPLimit() int {
+	if !viper.IsSet("LOGIN_IP_LIMIT") {
+		return 50
+	}
+
+	return max(viper.GetInt("LOGIN_IP_LIMIT"), 0)
+}
+
+// LoginAttemptWindow reads LOGIN_ATTEMPT_WINDOW, the number of minutes failed logins are counted over
+func LoginAttemptWindow() time.Duration {
+	minutes := viper.GetInt("LOGIN_ATTEMPT_WINDOW")
+	if minutes <= 0 {
+		return 15 * time.Minute
+	}
+
+	return time.Duration(minutes) * time.Minute
+}
+
+// LoginLockoutDuration reads LOGIN_LOCKOUT_DURATION, the number of minutes a lockout lasts
+func LoginLockoutDuration() time.Duration {
+	minutes := viper.GetInt("LOGIN_LOCKOUT_DURATION")
+	if minutes <= 0 {
+		return 15 * time.Minute
+	}
+
+	return time.Duration(minutes) * time.Minute
+}
+
+// LoginDelayAfter reads LOGIN_DELAY_AFTER, the failed logins of an email address after which
+// further attempts are delayed
+func LoginDelayAfter() int {
+	if !viper.IsSet("LOGIN_DELAY_AFTER") {
+		return 3
+	}
+
+	return max(viper.GetInt("LOGIN_DELAY_AFTER"), 0)
+}
+
+// LoginBaseDelay reads LOGIN_BASE_DELAY, the number of seconds of the first delay
+func LoginBaseDelay() time.Duration {
+	seconds := viper.GetInt("LOGIN_BASE_DELAY")
+	if seconds <= 0 {
+		return time.Second
+	}
+
+	return time.Duration(seconds) * time.Second
+}
+
+// LoginMaxDelay reads LOGIN_MAX_DELAY, the number of seconds the doubling delay stops growing at
+func LoginMaxDelay() time.Duration {
+	seconds := viper.GetInt("LOGIN_MAX_DELAY")
+	if seconds <= 0 {
+		return 30 * time.Second
+	}
+
+	return time.Duration(seconds) * time.Second
+}
+
+func LoginProtectionPolicy() domain.LoginProtectionPolicy {
+	return domain.LoginProtectionPolicy{
+		AccountLimit:    LoginAccountLimit(),
+		IPLimit:         LoginIPLimit(),
+		Window:          LoginAttemptWindow(),
+		LockoutDuration: LoginLockoutDuration(),
+		DelayAfter:      LoginDelayAfter(),
+		BaseDelay:       LoginBaseDelay(),
+		MaxDelay:        LoginMaxDelay(),
+	}
+}
@@ -0,0 +1,84 @@
+package config
+
+import (
+	"strings"
+	"time"
+
+	"github.com/aldotp/employee-attendance-system/internal/core/domain"
+	"github.com/spf13/viper"
+)
+
+// MFA related configuration
+
+// MFARequiredRoles reads MFA_REQUIRED_ROLES, the comma separated roles that must use two-factor
+// authentication. Admins and HR, who can export the data of the whole workforce, by default.
+func MFARequiredRoles() []domain.UserRole {
+	if !viper.IsSet("MFA_REQUIRED_ROLES") {
+		return []domain.UserRole{domain.Admin, domain.HR}
+	}
+
+	var roles []domain.UserRole
+	for _, role := range strings.Split(viper.GetString("MFA_REQUIRED_ROLES"), ",") {
+		if role = strings.TrimSpace(role); role != "" {
+			roles = append(roles, domain.UserRole(role))
+		}
+	}
+
+	return roles
+}
+
+// MFAIssuer reads MFA_ISSUER, the name accounts are listed under in authenticator apps
+func MFAIssuer() string {
+	if issuer := viper.GetString("MFA_ISSUER"); issuer != "" {
+		return issuer
+	}
+
+	return AppName()
+}
+
+// MFAEncryptionKey reads MFA_ENCRYPTION_KEY, the key encrypting stored TOTP secrets. It is kept
+// apart from SECRET_KEY so a leaked token signing key does not also expose the secrets.
+func MFAEncryptionKey() string {
+	return viper.GetString("MFA_ENCRYPTION_KEY")
+}
+
+// MFAChallengeTTL reads MFA_CHALLENGE_TTL, the number of minutes a login may wait for its second factor
+func MFAChallengeTTL() time.Duration {
+	minutes := viper.GetInt("MFA_CHALLENGE_TTL")
+	if minutes <= 0 {
+		return 5 * time.Minute
+	}
+
+	return time.Duration(minutes) * time.Minute
+}
+
+// MFAMaxAttempts reads MFA_MAX_ATTEMPTS, the codes that may be tried for one login
+func MFAMaxAttempts() int {
+	attempts := viper.GetInt("MFA_MAX_ATTEMPTS")
+	if attempts <= 0 {
+		return 5
+	}
+
+	return attempts
+}
+
+// MFARecoveryCodes reads MFA_RECOVERY_CODES, the number of recovery codes issued when enrolling
+func MFARecoveryCodes() int {
+	codes := viper.GetInt("MFA_RECOVERY_CODES")
+	if codes <= 0 {
+		return 10
+	}
+
+	return codes
+}
+
+func MFAPolicy() domain.MFAPolicy {
+	return domain.MFAPolicy{
+		RequiredRoles: MFARequiredRoles(),
+		Issuer:        MFAIssuer(),
+		EncryptionKey: MFAEncryptionKey(),
+		ChallengeTTL:  MFAChallengeTTL(),
+		MaxAttempts:   MFAMaxAttempts(),
+		RecoveryCodes: MFARecoveryCodes(),
+	}
+}
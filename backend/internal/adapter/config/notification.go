@@ -0,0 +1,109 @@
+package config
+
+import (
+	"strings"
+	"time"
+
+	"github.com/aldotp/employee-attendance-system/internal/core/domain"
+	"github.com/spf13/viper"
+)
+
+// Notification related configuration
+
+// NotificationQuietHoursStart reads NOTIFICATION_QUIET_HOURS_START, the time of day in the
+// recipient's timezone from which notifications are held back
+func NotificationQuietHoursStart() string {
+	return viper.GetString("NOTIFICATION_QUIET_HOURS_START")
+}
+
+// NotificationQuietHoursEnd reads NOTIFICATION_QUIET_HOURS_END, the time of day in the
+// recipient's timezone at which held notifications are delivered
+func NotificationQuietHoursEnd() string {
+	return viper.GetString("NOTIFICATION_QUIET_HOURS_END")
+}
+
+// NotificationMaxAttempts reads NOTIFICATION_MAX_ATTEMPTS, the number of failed deliveries after
+// which a notification is given up
+func NotificationMaxAttempts() int {
+	if !viper.IsSet("NOTIFICATION_MAX_ATTEMPTS") {
+		return 5
+	}
+
+	return max(viper.GetInt("NOTIFICATION_MAX_ATTEMPTS"), 1)
+}
+
+// NotificationRetryBackoff reads NOTIFICATION_RETRY_BACKOFF, the number of minutes before the
+// first retry of a failed delivery
+func NotificationRetryBackoff() time.Duration {
+	minutes := viper.GetInt("NOTIFICATION_RETRY_BACKOFF")
+	if minutes <= 0 {
+		return time.Minute
+	}
+
+	return time.Duration(minutes) * time.Minute
+}
+
+// NotificationBatchSize reads NOTIFICATION_BATCH_SIZE, the number of notifications delivered by
+// one run of the dispatcher
+func NotificationBatchSize() uint64 {
+	size := viper.GetInt("NOTIFICATION_BATCH_SIZE")
+	if size <= 0 {
+		return 100
+	}
+
+	return uint64(size)
+}
+
+// NotificationDefaultChannels reads NOTIFICATION_DEFAULT_CHANNELS, the comma separated channels
+// used for the notification types a user has set no preference for
+func NotificationDefaultChannels() []domain.NotificationChannelType {
+	value := viper.GetString("NOTIFICATION_DEFAULT_CHANNELS")
+	if value == "" {
+		return []domain.NotificationChannelType{domain.NotificationChannelInApp}
+	}
+
+	var channels []domain.NotificationChannelType
+	for _, channel := range strings.Split(value, ",") {
+		if channel = strings.TrimSpace(channel); channel != "" {
+			channels = append(channels, domain.NotificationChannelType(channel))
+		}
+	}
+
+	return channels
+}
+
+// NotificationEmailTemplate reads NOTIFICATION_EMAIL_TEMPLATE, the HTML template of notification emails
+func NotificationEmailTemplate() string {
+	if path := viper.GetString("NOTIFICATION_EMAIL_TEMPLATE"); path != "" {
+		return path
+	}
+
+	return "templates/email/notification.html"
+}
+
+// NotificationWebhookSecret reads NOTIFICATION_WEBHOOK_SECRET, the key signing webhook requests
+func NotificationWebhookSecret() string {
+	return viper.GetString("NOTIFICATION_WEBHOOK_SECRET")
+}
+
+// NotificationWebhookTimeout reads NOTIFICATION_WEBHOOK_TIMEOUT, the number of seconds a webhook
+// may take to respond
+func NotificationWebhookTimeout() time.Duration {
+	seconds := viper.GetInt("NOTIFICATION_WEBHOOK_TIMEOUT")
+	if seconds <= 0 {
+		return 10 * time.Second
+	}
+
+	return time.Duration(seconds) * time.Second
+}
+
+func NotificationPolicy() domain.NotificationPolicy {
+	return domain.NotificationPolicy{
+		QuietStart:      NotificationQuietHoursStart(),
+		QuietEnd:        NotificationQuietHoursEnd(),
+		MaxAttempts:     NotificationMaxAttempts(),
+		RetryBackoff:    NotificationRetryBackoff(),
+		BatchSize:       NotificationBatchSize(),
+		DefaultChannels: NotificationDefaultChannels(),
+	}
+}
@@ -0,0 +1,105 @@
+package config
+
+import (
+	"strings"
+	"time"
+
+	"github.com/aldotp/employee-attendance-system/internal/core/domain"
+	"github.com/spf13/viper"
+)
+
+// OIDC related configuration
+
+// OIDCIssuer reads OIDC_ISSUER, the identity provider whose discovery document is served under
+// /.well-known/openid-configuration. Single sign-on is disabled while it is empty.
+func OIDCIssuer() string {
+	return strings.TrimSuffix(viper.GetString("OIDC_ISSUER"), "/")
+}
+
+func OIDCClientID() string {
+	return viper.GetString("OIDC_CLIENT_ID")
+}
+
+func OIDCClientSecret() string {
+	return viper.GetString("OIDC_CLIENT_SECRET")
+}
+
+// OIDCRedirectURL reads OIDC_REDIRECT_URL, the page of the client application the identity provider
+// sends users back to with the code and the state
+func OIDCRedirectURL() string {
+	return viper.GetString("OIDC_REDIRECT_URL")
+}
+
+// OIDCScopes reads OIDC_SCOPES, the space or comma separated scopes requested from the identity
+// provider, openid is always requested
+func OIDCScopes() []string {
+	scopes := []string{"openid"}
+	value := viper.GetString("OIDC_SCOPES")
+	if !viper.IsSet("OIDC_SCOPES") {
+		value = "email profile"
+	}
+
+	for _, scope := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' }) {
+		if scope != "openid" {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	return scopes
+}
+
+// OIDCDepartmentClaim reads OIDC_DEPARTMENT_CLAIM, the ID token claim naming the department of
+// provisioned employees
+func OIDCDepartmentClaim() string {
+	return viper.GetString("OIDC_DEPARTMENT_CLAIM")
+}
+
+// OIDCAutoProvision reads OIDC_AUTO_PROVISION, whether users signing in without an account get one
+func OIDCAutoProvision() bool {
+	if !viper.IsSet("OIDC_AUTO_PROVISION") {
+		return true
+	}
+
+	return viper.GetBool("OIDC_AUTO_PROVISION")
+}
+
+// OIDCDefaultDepartment reads OIDC_DEFAULT_DEPARTMENT, the department of provisioned employees the
+// ID token does not place in a known department
+func OIDCDefaultDepartment() string {
+	if department := viper.GetString("OIDC_DEFAULT_DEPARTMENT"); department != "" {
+		return department
+	}
+
+	return "Information Technology"
+}
+
+// OIDCRequireVerifiedEmail reads OIDC_REQUIRE_VERIFIED_EMAIL, whether ID tokens must mark the email
+// verified. Only turn it off for providers that never send email_verified and own every address
+// they issue.
+func OIDCRequireVerifiedEmail() bool {
+	if !viper.IsSet("OIDC_REQUIRE_VERIFIED_EMAIL") {
+		return true
+	}
+
+	return viper.GetBool("OIDC_REQUIRE_VERIFIED_EMAIL")
+}
+
+// OIDCStateTTL reads OIDC_STATE_TTL, the number of minutes a user may take to sign in at the
+// identity provider
+func OIDCStateTTL() time.Duration {
+	minutes := viper.GetInt("OIDC_STATE_TTL")
+	if minutes <= 0 {
+		return 10 * time.Minute
+	}
+
+	return time.Duration(minutes) * time.Minute
+}
+
+func OIDCPolicy() domain.OIDCPolicy {
+	return domain.OIDCPolicy{
+		AutoProvision:        OIDCAutoProvision(),
+		DefaultDepartment:    OIDCDefaultDepartment(),
+		RequireVerifiedEmail: OIDCRequireVerifiedEmail(),
+		StateTTL:             OIDCStateTTL(),
+	}
+}
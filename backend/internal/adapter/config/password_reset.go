@@ -0,0 +1,78 @@
+package config
+
+import (
+	"time"
+
+	"github.com/aldotp/employee-attendance-system/internal/core/domain"
+	"github.com/spf13/viper"
+)
+
+// Password reset related configuration
+
+// PasswordResetURL reads PASSWORD_RESET_URL, the page of the client application where users
+// choose their new password
+func PasswordResetURL() string {
+	return viper.GetString("PASSWORD_RESET_URL")
+}
+
+// PasswordResetEmailTemplate reads PASSWORD_RESET_EMAIL_TEMPLATE, the HTML template of password reset emails
+func PasswordResetEmailTemplate() string {
+	if path := viper.GetString("PASSWORD_RESET_EMAIL_TEMPLATE"); path != "" {
+		return path
+	}
+
+	return "templates/email/password_reset.html"
+}
+
+// PasswordResetTokenTTL reads PASSWORD_RESET_TOKEN_TTL, the number of minutes a reset link stays valid
+func PasswordResetTokenTTL() time.Duration {
+	minutes := viper.GetInt("PASSWORD_RESET_TOKEN_TTL")
+	if minutes <= 0 {
+		return 30 * time.Minute
+	}
+
+	return time.Duration(minutes) * time.Minute
+}
+
+// PasswordResetEmailLimit reads PASSWORD_RESET_EMAIL_LIMIT, the password reset requests allowed per
+// email address within the rate window
+func PasswordResetEmailLimit() int {
+	if !viper.IsSet("PASSWORD_RESET_EMAIL_LIMIT") {
+		return 3
+	}
+
+	return max(viper.GetInt("PASSWORD_RESET_EMAIL_LIMIT"), 0)
+}
+
+// PasswordResetIPLimit reads PASSWORD_RESET_IP_LIMIT, the password reset requests allowed per
+// client IP within the rate window
+func PasswordResetIPLimit() int {
+	if !viper.IsSet("PASSWORD_RESET_IP_LIMIT") {
+		return 10
+	}
+
+	return max(viper.GetInt("PASSWORD_RESET_IP_LIMIT"), 0)
+}
+
+// PasswordResetRateWindow reads PASSWORD_RESET_RATE_WINDOW, the number of minutes the rate limits
+// are counted over
+func PasswordResetRateWindow() time.Duration {
+	minutes := viper.GetInt("PASSWORD_RESET_RATE_WINDOW")
+	if minutes <= 0 {
+		return time.Hour
+	}
+
+	return time.Duration(minutes) * time.Minute
+}
+
+func PasswordResetPolicy() domain.PasswordResetPolicy {
+	return domain.PasswordResetPolicy{
+		URL:           PasswordResetURL(),
+		EmailTemplate: PasswordResetEmailTemplate(),
+		AppName:       AppName(),
+		TokenTTL:      PasswordResetTokenTTL(),
+		EmailLimit:    PasswordResetEmailLimit(),
+		IPLimit:       PasswordResetIPLimit(),
+		RateWindow:    PasswordResetRateWindow(),
+	}
+}
@@ -0,0 +1,13 @@
+package config
+
+import "github.com/spf13/viper"
+
+// PubSubDriver reads PUBSUB_DRIVER, "memory" to broadcast within a single process or "redis" to
+// broadcast across every instance connected to REDIS_ADDR
+func PubSubDriver() string {
+	if !viper.IsSet("PUBSUB_DRIVER") || viper.GetString("PUBSUB_DRIVER") == "" {
+		return "memory"
+	}
+
+	return viper.GetString("PUBSUB_DRIVER")
+}
@@ -0,0 +1,46 @@
+package config
+
+import (
+	"github.com/aldotp/employee-attendance-system/internal/core/domain"
+	"github.com/spf13/viper"
+)
+
+// Schedule related configuration
+
+// ScheduleMinRestHours reads SCHEDULE_MIN_REST_HOURS, the default minimum rest between two shifts
+func ScheduleMinRestHours() int {
+	if !viper.IsSet("SCHEDULE_MIN_REST_HOURS") {
+		return 11
+	}
+
+	return max(viper.GetInt("SCHEDULE_MIN_REST_HOURS"), 0)
+}
+
+// ScheduleMaxWeeklyHours reads SCHEDULE_MAX_WEEKLY_HOURS, the default maximum hours scheduled
+// from Monday to Sunday
+func ScheduleMaxWeeklyHours() int {
+	if !viper.IsSet("SCHEDULE_MAX_WEEKLY_HOURS") {
+		return 48
+	}
+
+	return max(viper.GetInt("SCHEDULE_MAX_WEEKLY_HOURS"), 0)
+}
+
+// ScheduleMaxConsecutiveDays reads SCHEDULE_MAX_CONSECUTIVE_DAYS, the default maximum number of
+// working days in a row
+func ScheduleMaxConsecutiveDays() int {
+	if !viper.IsSet("SCHEDULE_MAX_CONSECUTIVE_DAYS") {
+		return 6
+	}
+
+	return max(viper.GetInt("SCHEDULE_MAX_CONSECUTIVE_DAYS"), 0)
+}
+
+// ScheduleRulePolicy is the rule applied to employees whose department and country have none
+func ScheduleRulePolicy() domain.ScheduleRule {
+	return domain.ScheduleRule{
+		MinRestHours:       ScheduleMinRestHours(),
+		MaxWeeklyHours:     ScheduleMaxWeeklyHours(),
+		MaxConsecutiveDays: ScheduleMaxConsecutiveDays(),
+	}
+}
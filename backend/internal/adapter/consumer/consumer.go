@@ -23,6 +23,7 @@ type Consumer interface {
 	Stop() error
 
 	GenerateReport()
+	DispatchNotifications()
 }
 
 func NewConsumer(b *bootstrap.Bootstrap) Consumer {
@@ -63,3 +64,9 @@ func (c *consumer) GenerateReport() {
 
 	worker.NewReportWorker(c.bootstrap).Run()
 }
+
+func (c *consumer) DispatchNotifications() {
+	c.log.Info("Consumer registered...", zap.String("job_name", "dispatch_notifications"))
+
+	worker.NewNotificationWorker(c.bootstrap).Run()
+}
@@ -0,0 +1,19 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/aldotp/employee-attendance-system/internal/core/domain"
+)
+
+type CreateAPIKeyRequest struct {
+	Name        string              `json:"name" binding:"required,max=100"`
+	Permissions []domain.Permission `json:"permissions" binding:"required,min=1"`
+	// ExpiresAt defaults to the configured lifetime when it is left out
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+type ListAPIKeyAuditLogsRequest struct {
+	Page  uint64 `form:"page"`
+	Limit uint64 `form:"limit" binding:"max=200"`
+}
@@ -48,5 +48,7 @@ type AttendanceResponse struct {
 	AttendanceID string    `json:"attendance_id"`
 	UserID       string    `json:"user_id"`
 	Time         time.Time `json:"time"`
+	BusinessDate string    `json:"business_date"`
 	Status       string    `json:"status"`
+	HoursWorked  float64   `json:"hours_worked,omitempty"`
 }
@@ -12,10 +12,64 @@ type AuthResponse struct {
 }
 
 type RefreshTokenRequest struct {
-	RefreshToken string `json:"refresh_token"`
+	RefreshToken string `json:"refresh_token" binding:"required"`
 }
 
-type LoginResponse struct {
+// RefreshTokenResponse carries the rotated token pair, Token repeats the access token for clients
+// written before refresh tokens were rotated
+type RefreshTokenResponse struct {
+	Token        string `json:"token"`
 	AccessToken  string `json:"access_token"`
 	RefreshToken string `json:"refresh_token"`
 }
+
+// LoginResponse carries the tokens of the new session, or the MFA challenge token when the login
+// needs a second factor first
+type LoginResponse struct {
+	AccessToken           string   `json:"access_token,omitempty"`
+	RefreshToken          string   `json:"refresh_token,omitempty"`
+	MFARequired           bool     `json:"mfa_required,omitempty"`
+	MFAEnrollmentRequired bool     `json:"mfa_enrollment_required,omitempty"`
+	MFAToken              string   `json:"mfa_token,omitempty"`
+	RecoveryCodes         []string `json:"recovery_codes,omitempty"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,min=8"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type ResendVerificationRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type MFATokenRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+}
+
+type LoginMFARequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+type OIDCCallbackRequest struct {
+	State string `json:"state" binding:"required"`
+	Code  string `json:"code" binding:"required"`
+}
+
+type MFACodeRequest struct {
+	Code string `json:"code"`
+}
+
+type MFAStatusResponse struct {
+	Enabled  bool `json:"enabled"`
+	Required bool `json:"required"`
+}
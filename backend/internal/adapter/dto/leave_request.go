@@ -1,22 +1,31 @@
 package dto
 
-import "time"
+import (
+	"mime/multipart"
+	"time"
+
+	"github.com/aldotp/employee-attendance-system/internal/core/domain"
+)
 
 type LeaveRequest struct {
-	UserID    string `json:"user_id"`
-	StartDate string `json:"start_date"`
-	EndDate   string `json:"end_date"`
-	Type      string `json:"type"`
-	Reason    string `json:"reason"`
+	UserID      string                  `json:"user_id" form:"user_id"`
+	StartDate   string                  `json:"start_date" form:"start_date"`
+	EndDate     string                  `json:"end_date" form:"end_date"`
+	Type        string                  `json:"type" form:"type"`
+	Reason      string                  `json:"reason" form:"reason"`
+	Attachments []*multipart.FileHeader `json:"-" form:"attachments"`
 }
 
 type LeaveResponse struct {
-	LeaveID   string    `json:"leave_id"`
-	Status    string    `json:"status"`
-	StartDate time.Time `json:"start_date"`
-	EndDate   time.Time `json:"end_date"`
-	Type      string    `json:"type"`
-	Reason    string    `json:"reason"`
+	LeaveID     string                   `json:"leave_id"`
+	Status      string                   `json:"status"`
+	StartDate   time.Time                `json:"start_date"`
+	EndDate     time.Time                `json:"end_date"`
+	Type        string                   `json:"type"`
+	Reason      string                   `json:"reason"`
+	Attachments []domain.LeaveAttachment `json:"attachments,omitempty"`
+	// CoverageWarnings lists the days on which the department drops below its minimum coverage
+	CoverageWarnings []domain.LeaveCoverage `json:"coverage_warnings,omitempty"`
 }
 
 type RejectLeaveRequest struct {
@@ -26,3 +35,18 @@ type RejectLeaveRequest struct {
 type LeaveBalanceRequest struct {
 	Type string `json:"type"`
 }
+
+type LeaveAttachmentResponse struct {
+	ID          string    `json:"id"`
+	FileName    string    `json:"file_name"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	DownloadURL string    `json:"download_url"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+type LeaveCalendarRequest struct {
+	DepartmentID string `json:"department_id" form:"department_id"`
+	StartDate    string `json:"start_date" form:"start_date"`
+	EndDate      string `json:"end_date" form:"end_date"`
+}
@@ -1,5 +1,7 @@
 package dto
 
+import "github.com/aldotp/employee-attendance-system/internal/core/domain"
+
 type NotificationRequest struct {
 	RecipientID string `json:"recipient_id"`
 	Title       string `json:"title"`
@@ -11,7 +13,40 @@ type NotificationResponse struct {
 	Status         string `json:"status"`
 }
 
+// ListNotificationRequest filters the in-app notifications of the current user. Cursor, the
+// next_cursor of the previous page, takes precedence over Skip.
 type ListNotificationRequest struct {
-	Skip  uint64 `json:"skip" form:"skip"`
-	Limit uint64 `json:"limit" form:"form"`
+	Skip   uint64 `json:"skip" form:"skip"`
+	Limit  uint64 `json:"limit" form:"limit" binding:"max=100"`
+	Type   string `json:"type" form:"type" binding:"omitempty,oneof=reminder warning info security"`
+	IsRead *bool  `json:"is_read" form:"is_read"`
+	Cursor string `json:"cursor" form:"cursor"`
+}
+
+// ListNotificationResponse is a page of notifications, NextCursor is empty on the last page
+type ListNotificationResponse struct {
+	Notifications []domain.Notification `json:"notifications"`
+	NextCursor    string                `json:"next_cursor,omitempty"`
+}
+
+type UpdateNotificationRequest struct {
+	IsRead *bool `json:"is_read" binding:"required"`
+}
+
+// NotificationIDsRequest selects several notifications of the current user
+type NotificationIDsRequest struct {
+	IDs []string `json:"ids" binding:"required,min=1,max=100,dive,uuid"`
+}
+
+// NotificationTypeRequest limits an action on every notification to one type when Type is set
+type NotificationTypeRequest struct {
+	Type string `json:"type" form:"type" binding:"omitempty,oneof=reminder warning info security"`
+}
+
+// NotificationPreferenceRequest sets the channels one type of notification is delivered through,
+// WebhookURL is required when the webhook channel is chosen
+type NotificationPreferenceRequest struct {
+	Type       string   `json:"type" binding:"required"`
+	Channels   []string `json:"channels"`
+	WebhookURL string   `json:"webhook_url"`
 }
@@ -0,0 +1,13 @@
+package dto
+
+import "github.com/aldotp/employee-attendance-system/internal/core/domain"
+
+type UpdateRolePermissionsRequest struct {
+	Permissions []domain.Permission `json:"permissions" binding:"required"`
+}
+
+type RolePermissionsResponse struct {
+	Roles []domain.RolePermissions `json:"roles"`
+	// Available are all the permissions roles can be granted
+	Available []domain.Permission `json:"available"`
+}
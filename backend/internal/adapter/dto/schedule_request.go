@@ -1,6 +1,10 @@
 package dto
 
-import "time"
+import (
+	"time"
+
+	"github.com/aldotp/employee-attendance-system/internal/core/domain"
+)
 
 type ScheduleRequest struct {
 	UserID    string    `json:"user_id"`
@@ -18,3 +22,91 @@ type GetWorkCalendar struct {
 	Year  int `json:"year" form:"year"`
 	Month int `json:"month" form:"month"`
 }
+
+type ScheduleSwapRequest struct {
+	ProposedScheduleID string `json:"proposed_schedule_id" binding:"required"`
+	TargetScheduleID   string `json:"target_schedule_id" binding:"required"`
+	Reason             string `json:"reason"`
+}
+
+type ScheduleSwapResponse struct {
+	Accept bool `json:"accept"`
+}
+
+type ScheduleSwapReview struct {
+	Note string `json:"note"`
+}
+
+type ListScheduleSwapRequest struct {
+	Status string `form:"status"`
+}
+
+// RotationPatternRequest describes a rotation either day by day through Days, or as
+// OnDays working days followed by OffDays days off, all sharing the same shift
+type RotationPatternRequest struct {
+	Name        string               `json:"name" binding:"required"`
+	Description string               `json:"description"`
+	Days        []domain.RotationDay `json:"days"`
+	OnDays      int                  `json:"on_days"`
+	OffDays     int                  `json:"off_days"`
+	ShiftStart  string               `json:"shift_start"`
+	ShiftEnd    string               `json:"shift_end"`
+	BreakStart  string               `json:"break_start"`
+	BreakEnd    string               `json:"break_end"`
+}
+
+type RotationAssignmentRequest struct {
+	UserID            string `json:"user_id" binding:"required"`
+	RotationPatternID string `json:"rotation_pattern_id" binding:"required"`
+	StartDate         string `json:"start_date" binding:"required"`
+	EndDate           string `json:"end_date"`
+	WorkLocationID    string `json:"work_location_id"`
+}
+
+type ShiftTemplateRequest struct {
+	Name               string `json:"name" binding:"required"`
+	ShiftStart         string `json:"shift_start" binding:"required"`
+	ShiftEnd           string `json:"shift_end" binding:"required"`
+	BreakStart         string `json:"break_start"`
+	BreakEnd           string `json:"break_end"`
+	CoreStart          string `json:"core_start"`
+	CoreEnd            string `json:"core_end"`
+	MinDurationMinutes int    `json:"min_duration_minutes"`
+	WorkLocationID     string `json:"work_location_id"`
+	ScheduleType       string `json:"schedule_type"`
+}
+
+// BulkScheduleRequest generates schedules from a shift template for either a department or a
+// list of employees, on the given weekdays (0 is Sunday) between StartDate and EndDate
+type BulkScheduleRequest struct {
+	TemplateID   string   `json:"template_id" binding:"required"`
+	DepartmentID string   `json:"department_id"`
+	UserIDs      []string `json:"user_ids"`
+	StartDate    string   `json:"start_date" binding:"required"`
+	EndDate      string   `json:"end_date" binding:"required"`
+	Weekdays     []int    `json:"weekdays"`
+	DryRun       bool     `json:"dry_run"`
+}
+
+// ScheduleRuleRequest sets the labor limits of either a department or a country, zero disables a limit
+type ScheduleRuleRequest struct {
+	DepartmentID       string `json:"department_id"`
+	Country            string `json:"country"`
+	MinRestHours       int    `json:"min_rest_hours" binding:"min=0"`
+	MaxWeeklyHours     int    `json:"max_weekly_hours" binding:"min=0"`
+	MaxConsecutiveDays int    `json:"max_consecutive_days" binding:"min=0"`
+}
+
+// OpenShiftRequest posts a schedule as an open shift. The department defaults to the one of the
+// schedule's current owner and the work location to the schedule's own.
+type OpenShiftRequest struct {
+	ScheduleID     string `json:"schedule_id" binding:"required"`
+	DepartmentID   string `json:"department_id"`
+	WorkLocationID string `json:"work_location_id"`
+	Note           string `json:"note"`
+}
+
+type ListOpenShiftRequest struct {
+	DepartmentID string `form:"department_id"`
+	Status       string `form:"status"`
+}
@@ -61,5 +61,5 @@ type UpdateUserRequest struct {
 	Location string            `json:"location"`
 	Timezone string            `json:"timezone"`
 	PhotoURL string            `json:"photo_url"`
-	Status   domain.UserStatus `json:"status"`
+	Status   domain.UserStatus `json:"status" binding:"omitempty,oneof=active inactive terminated suspended"`
 }
@@ -0,0 +1,96 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/aldotp/employee-attendance-system/internal/adapter/dto"
+	"github.com/aldotp/employee-attendance-system/internal/adapter/helper"
+	"github.com/aldotp/employee-attendance-system/internal/core/port"
+	"github.com/aldotp/employee-attendance-system/pkg/consts"
+	"github.com/aldotp/employee-attendance-system/pkg/util"
+	"github.com/gin-gonic/gin"
+)
+
+type APIKeyHandler struct {
+	svc port.APIKeyService
+}
+
+func NewAPIKeyHandler(svc port.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{
+		svc: svc,
+	}
+}
+
+// CreateAPIKey issues a new API key, the response is the only time the key is shown
+func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
+	payload := util.GetAuthPayload(c, consts.AuthorizationKey)
+	if payload == nil {
+		c.JSON(http.StatusUnauthorized, util.APIResponse("Unauthorized", http.StatusUnauthorized, "error", nil))
+		return
+	}
+
+	var req dto.CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	key, err := h.svc.CreateAPIKey(c.Request.Context(), payload, req.Name, req.Permissions, req.ExpiresAt)
+	if err != nil {
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	c.JSON(http.StatusCreated, util.APIResponse("API key created", http.StatusCreated, "success", key))
+}
+
+func (h *APIKeyHandler) ListAPIKeys(c *gin.Context) {
+	keys, err := h.svc.ListAPIKeys(c.Request.Context())
+	if err != nil {
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	c.JSON(http.StatusOK, util.APIResponse("Success List API Keys", http.StatusOK, "success", keys))
+}
+
+func (h *APIKeyHandler) GetAPIKey(c *gin.Context) {
+	key, err := h.svc.GetAPIKey(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	c.JSON(http.StatusOK, util.APIResponse("Success Get API Key", http.StatusOK, "success", key))
+}
+
+func (h *APIKeyHandler) RevokeAPIKey(c *gin.Context) {
+	if err := h.svc.RevokeAPIKey(c.Request.Context(), c.Param("id")); err != nil {
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	c.JSON(http.StatusOK, util.APIResponse("API key revoked", http.StatusOK, "success", nil))
+}
+
+// ListAPIKeyAuditLogs lists the requests made with the key, newest first
+func (h *APIKeyHandler) ListAPIKeyAuditLogs(c *gin.Context) {
+	var req dto.ListAPIKeyAuditLogsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	logs, err := h.svc.ListAPIKeyAuditLogs(c.Request.Context(), c.Param("id"), req.Page, req.Limit)
+	if err != nil {
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	c.JSON(http.StatusOK, util.APIResponse("Success List API Key Audit Logs", http.StatusOK, "success", logs))
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/aldotp/employee-attendance-system/internal/adapter/dto"
+	"github.com/aldotp/employee-attendance-system/internal/adapter/helper"
 	"github.com/aldotp/employee-attendance-system/internal/core/domain"
 	"github.com/aldotp/employee-attendance-system/internal/core/service"
 	"github.com/aldotp/employee-attendance-system/pkg/consts"
@@ -21,15 +22,20 @@ func NewAttendanceHandler(svc *service.AttendanceService) *AttendanceHandler {
 }
 
 func (h *AttendanceHandler) ListAttendance(c *gin.Context) {
+	payload := util.GetAuthPayload(c, consts.AuthorizationKey)
+	if payload == nil {
+		c.JSON(http.StatusUnauthorized, util.APIResponse("Unauthorized", http.StatusUnauthorized, "error", nil))
+		return
+	}
 
 	var req domain.ListAttendanceRequest
 	if err := c.ShouldBindQuery(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	attendances, err := h.svc.ListAttendances(c.Request.Context(), req)
+	attendances, err := h.svc.ListAttendances(c.Request.Context(), payload, req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(helper.StatusCode(err), gin.H{"error": err.Error()})
 		return
 	}
 	c.JSON(http.StatusOK, util.APIResponse("List Attendances", http.StatusOK, "success", attendances))
@@ -56,42 +62,60 @@ func (h *AttendanceHandler) CreateAttendance(c *gin.Context) {
 
 	resp, err := h.svc.OpenAttendance(c, req, payload.UserID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(helper.StatusCode(err), gin.H{"error": err.Error()})
 		return
 	}
 	c.JSON(http.StatusCreated, resp)
 }
 
 func (h *AttendanceHandler) GetAttendance(c *gin.Context) {
+	payload := util.GetAuthPayload(c, consts.AuthorizationKey)
+	if payload == nil {
+		c.JSON(http.StatusUnauthorized, util.APIResponse("Unauthorized", http.StatusUnauthorized, "error", nil))
+		return
+	}
+
 	id := c.Param("id")
-	attendance, err := h.svc.GetAttendanceByID(c.Request.Context(), id)
+	attendance, err := h.svc.GetAttendanceByID(c.Request.Context(), payload, id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		c.JSON(helper.StatusCode(err), gin.H{"error": err.Error()})
 		return
 	}
 	c.JSON(http.StatusOK, attendance)
 }
 
 func (h *AttendanceHandler) UpdateAttendance(c *gin.Context) {
+	payload := util.GetAuthPayload(c, consts.AuthorizationKey)
+	if payload == nil {
+		c.JSON(http.StatusUnauthorized, util.APIResponse("Unauthorized", http.StatusUnauthorized, "error", nil))
+		return
+	}
+
 	id := c.Param("id")
 	var req dto.AttendanceRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	attendance, err := h.svc.UpdateAttendance(c.Request.Context(), id, req)
+	attendance, err := h.svc.UpdateAttendance(c.Request.Context(), payload, id, req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(helper.StatusCode(err), gin.H{"error": err.Error()})
 		return
 	}
 	c.JSON(http.StatusOK, attendance)
 }
 
 func (h *AttendanceHandler) DeleteAttendance(c *gin.Context) {
+	payload := util.GetAuthPayload(c, consts.AuthorizationKey)
+	if payload == nil {
+		c.JSON(http.StatusUnauthorized, util.APIResponse("Unauthorized", http.StatusUnauthorized, "error", nil))
+		return
+	}
+
 	id := c.Param("id")
-	err := h.svc.DeleteAttendance(c.Request.Context(), id)
+	err := h.svc.DeleteAttendance(c.Request.Context(), payload, id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(helper.StatusCode(err), gin.H{"error": err.Error()})
 		return
 	}
 	c.Status(http.StatusNoContent)
@@ -112,14 +136,20 @@ func (h *AttendanceHandler) GetAttendanceHistory(c *gin.Context) {
 }
 
 func (h *AttendanceHandler) GetUsersAttendanceStatus(c *gin.Context) {
+	payload := util.GetAuthPayload(c, consts.AuthorizationKey)
+	if payload == nil {
+		c.JSON(http.StatusUnauthorized, util.APIResponse("Unauthorized", http.StatusUnauthorized, "error", nil))
+		return
+	}
+
 	date := c.Query("date")
 	if date == "" {
 		date = time.Now().Format("2006-01-02")
 	}
 
-	statusMap, err := h.svc.GetUsersAttendanceStatus(c.Request.Context(), date)
+	statusMap, err := h.svc.GetUsersAttendanceStatus(c.Request.Context(), payload, date)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(helper.StatusCode(err), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -6,6 +6,7 @@ import (
 	"github.com/aldotp/employee-attendance-system/internal/adapter/dto"
 	"github.com/aldotp/employee-attendance-system/internal/adapter/helper"
 	"github.com/aldotp/employee-attendance-system/internal/core/port"
+	"github.com/aldotp/employee-attendance-system/pkg/consts"
 	"github.com/aldotp/employee-attendance-system/pkg/util"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
@@ -13,22 +14,30 @@ import (
 
 // AuthHandler represents the HTTP handler for authentication-related requests
 type AuthHandler struct {
-	svc    port.AuthService
-	logger *zap.Logger
+	svc             port.AuthService
+	passwordReset   port.PasswordResetService
+	verification    port.EmailVerificationService
+	mfa             port.MFAService
+	loginProtection port.LoginProtectionService
+	logger          *zap.Logger
 }
 
 // NewAuthHandler creates a new AuthHandler instance
-func NewAuthHandler(svc port.AuthService, logger *zap.Logger) *AuthHandler {
+func NewAuthHandler(svc port.AuthService, passwordReset port.PasswordResetService, verification port.EmailVerificationService, mfa port.MFAService, loginProtection port.LoginProtectionService, logger *zap.Logger) *AuthHandler {
 	return &AuthHandler{
-		svc:    svc,
-		logger: logger,
+		svc:             svc,
+		passwordReset:   passwordReset,
+		verification:    verification,
+		mfa:             mfa,
+		loginProtection: loginProtection,
+		logger:          logger,
 	}
 }
 
 // Login godoc
 //
 //	@Summary		User Login
-//	@Description	Authenticate user and return an access token
+//	@Description	Authenticate user and return an access token, or an MFA challenge token to finish with /auth/login/mfa
 //	@Tags			Auth
 //	@Accept			json
 //	@Produce		json
@@ -36,6 +45,8 @@ func NewAuthHandler(svc port.AuthService, logger *zap.Logger) *AuthHandler {
 //	@Success		200		{object}	dto.AuthResponse	"Successfully logged in"
 //	@Failure		400		{object}	util.ErrorResponse	"Bad request (validation error)"
 //	@Failure		401		{object}	util.ErrorResponse	"Unauthorized error"
+//	@Failure		403		{object}	util.ErrorResponse	"Email not verified or account inactive"
+//	@Failure		429		{object}	util.ErrorResponse	"Too many failed login attempts"
 //	@Failure		500		{object}	util.ErrorResponse	"Internal server error"
 //	@Router			/api/v1/auth/login [post]
 func (ah *AuthHandler) Login(c *gin.Context) {
@@ -50,7 +61,7 @@ func (ah *AuthHandler) Login(c *gin.Context) {
 	}
 
 	ah.logger.Info("User attempting login", zap.String("email", request.Email))
-	data, err := ah.svc.Login(c.Request.Context(), request.Email, request.Password)
+	data, err := ah.svc.Login(c.Request.Context(), request.Email, request.Password, c.ClientIP())
 	if err != nil {
 		ah.logger.Error("Login failed", zap.String("email", request.Email), zap.Error(err))
 		statusCode, response := helper.ErrorResponse(err)
@@ -58,22 +69,158 @@ func (ah *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
+	if data.MFARequired {
+		ah.logger.Info("Login waiting for second factor", zap.String("email", request.Email))
+		c.JSON(http.StatusOK, util.APIResponse("Two-factor authentication required", http.StatusOK, "success", data))
+		return
+	}
+
 	ah.logger.Info("Login successful", zap.String("email", request.Email))
 	c.JSON(http.StatusOK, util.APIResponse("Successfully logged in", http.StatusOK, "success", data))
 }
 
-// RefreshToken godoc
+// LoginMFA godoc
 //
-//	@Summary		Refresh Access Token
-//	@Description	Refresh expired access token using refresh token
+//	@Summary		Login Second Factor
+//	@Description	Finish a login with a code from the authenticator app or a recovery code, recovery codes are returned when the login confirmed a new authenticator
 //	@Tags			Auth
 //	@Accept			json
 //	@Produce		json
-//	@Param			request	body		dto.RefreshTokenRequest	true	"Refresh token request body"
-//	@Success		200		{object}	dto.AuthResponse	"Successfully refreshed token"
+//	@Param			request	body		dto.LoginMFARequest	true	"Login MFA request body"
+//	@Success		200		{object}	dto.LoginResponse	"Successfully logged in"
 //	@Failure		400		{object}	util.ErrorResponse	"Bad request (validation error)"
-//	@Failure		401		{object}	util.ErrorResponse	"Unauthorized error"
+//	@Failure		401		{object}	util.ErrorResponse	"Invalid code or challenge"
+//	@Failure		429		{object}	util.ErrorResponse	"Too many attempts"
 //	@Failure		500		{object}	util.ErrorResponse	"Internal server error"
+//	@Router			/api/v1/auth/login/mfa [post]
+func (ah *AuthHandler) LoginMFA(c *gin.Context) {
+	var request dto.LoginMFARequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		ah.logger.Warn("Failed to bind request", zap.Error(err))
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	data, err := ah.svc.LoginMFA(c.Request.Context(), request.MFAToken, request.Code)
+	if err != nil {
+		ah.logger.Warn("Second factor failed", zap.Error(err))
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	c.JSON(http.StatusOK, util.APIResponse("Successfully logged in", http.StatusOK, "success", data))
+}
+
+// EnrollMFAChallenge godoc
+//
+//	@Summary		Enroll Authenticator While Logging In
+//	@Description	Start enrolling an authenticator app for a login whose role requires one, the first code is sent to /auth/login/mfa
+//	@Tags			Auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		dto.MFATokenRequest		true	"MFA challenge request body"
+//	@Success		200		{object}	domain.MFAEnrollment	"Authenticator secret and provisioning URI"
+//	@Failure		400		{object}	util.ErrorResponse		"Bad request (validation error)"
+//	@Failure		401		{object}	util.ErrorResponse		"Invalid challenge"
+//	@Failure		409		{object}	util.ErrorResponse		"Authenticator already enabled"
+//	@Failure		500		{object}	util.ErrorResponse		"Internal server error"
+//	@Router			/api/v1/auth/login/mfa/enroll [post]
+func (ah *AuthHandler) EnrollMFAChallenge(c *gin.Context) {
+	var request dto.MFATokenRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		ah.logger.Warn("Failed to bind request", zap.Error(err))
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	enrollment, err := ah.svc.EnrollMFAChallenge(c.Request.Context(), request.MFAToken)
+	if err != nil {
+		ah.logger.Warn("Authenticator enrollment failed", zap.Error(err))
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	c.JSON(http.StatusOK, util.APIResponse("Scan the provisioning URI with an authenticator app", http.StatusOK, "success", enrollment))
+}
+
+// StartOIDCLogin godoc
+//
+//	@Summary		Start Single Sign-On
+//	@Description	Start a login at the identity provider, the client keeps the state, sends the user to the authorization URL and checks the provider returns the same state
+//	@Tags			Auth
+//	@Produce		json
+//	@Success		200	{object}	domain.OIDCAuthorization	"Identity provider authorization URL"
+//	@Failure		404	{object}	util.ErrorResponse			"Single sign-on not configured"
+//	@Failure		500	{object}	util.ErrorResponse			"Internal server error"
+//	@Router			/api/v1/auth/oidc/login [get]
+func (ah *AuthHandler) StartOIDCLogin(c *gin.Context) {
+	authorization, err := ah.svc.StartOIDCLogin(c.Request.Context())
+	if err != nil {
+		ah.logger.Error("Failed to start single sign-on", zap.Error(err))
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	c.JSON(http.StatusOK, util.APIResponse("Continue at the identity provider", http.StatusOK, "success", authorization))
+}
+
+// LoginOIDC godoc
+//
+//	@Summary		Finish Single Sign-On
+//	@Description	Finish a login with the code and state the identity provider returned, signing in the user with the same email or creating their account
+//	@Tags			Auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		dto.OIDCCallbackRequest	true	"Single sign-on callback request body"
+//	@Success		200		{object}	dto.LoginResponse		"Successfully logged in"
+//	@Failure		400		{object}	util.ErrorResponse		"Bad request or expired state"
+//	@Failure		401		{object}	util.ErrorResponse		"Identity provider login failed"
+//	@Failure		403		{object}	util.ErrorResponse		"No matching account, email not verified or account inactive"
+//	@Failure		404		{object}	util.ErrorResponse		"Single sign-on not configured"
+//	@Failure		500		{object}	util.ErrorResponse		"Internal server error"
+//	@Router			/api/v1/auth/oidc/callback [post]
+func (ah *AuthHandler) LoginOIDC(c *gin.Context) {
+	var request dto.OIDCCallbackRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		ah.logger.Warn("Failed to bind request", zap.Error(err))
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	data, err := ah.svc.LoginOIDC(c.Request.Context(), request.State, request.Code)
+	if err != nil {
+		ah.logger.Warn("Single sign-on login failed", zap.Error(err))
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	if data.MFARequired {
+		c.JSON(http.StatusOK, util.APIResponse("Two-factor authentication required", http.StatusOK, "success", data))
+		return
+	}
+
+	c.JSON(http.StatusOK, util.APIResponse("Successfully logged in", http.StatusOK, "success", data))
+}
+
+// RefreshToken godoc
+//
+//	@Summary		Refresh Access Token
+//	@Description	Exchange a refresh token for a new access token and a new refresh token, the old refresh token stops working
+//	@Tags			Auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		dto.RefreshTokenRequest		true	"Refresh token request body"
+//	@Success		200		{object}	dto.RefreshTokenResponse	"Successfully refreshed token"
+//	@Failure		400		{object}	util.ErrorResponse			"Bad request (validation error)"
+//	@Failure		401		{object}	util.ErrorResponse			"Unauthorized error"
+//	@Failure		500		{object}	util.ErrorResponse			"Internal server error"
 //	@Router			/api/v1/auth/refresh-token [post]
 func (ah *AuthHandler) RefreshToken(c *gin.Context) {
 	var request dto.RefreshTokenRequest
@@ -87,17 +234,368 @@ func (ah *AuthHandler) RefreshToken(c *gin.Context) {
 		return
 	}
 
-	// Log token yang digunakan untuk refresh
-	ah.logger.Info("Attempting token refresh", zap.String("refresh_token", request.RefreshToken))
-
-	token, err := ah.svc.RefreshToken(c.Request.Context(), request.RefreshToken)
+	data, err := ah.svc.RefreshToken(c.Request.Context(), request.RefreshToken)
 	if err != nil {
-		ah.logger.Error("Token refresh failed", zap.String("refresh_token", request.RefreshToken), zap.Error(err))
+		ah.logger.Error("Token refresh failed", zap.Error(err))
 		statusCode, response := helper.ErrorResponse(err)
 		c.JSON(statusCode, response)
 		return
 	}
 
 	ah.logger.Info("Token refreshed successfully")
-	c.JSON(http.StatusOK, util.APIResponse("Successfully refreshed token", http.StatusOK, "success", map[string]interface{}{"token": token}))
+	c.JSON(http.StatusOK, util.APIResponse("Successfully refreshed token", http.StatusOK, "success", dto.RefreshTokenResponse{
+		Token:        data.AccessToken,
+		AccessToken:  data.AccessToken,
+		RefreshToken: data.RefreshToken,
+	}))
+}
+
+// Logout godoc
+//
+//	@Summary		Logout
+//	@Description	Revoke the refresh token session of the current device
+//	@Tags			Auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		dto.RefreshTokenRequest	true	"Refresh token request body"
+//	@Success		200		{object}	util.Response			"Successfully logged out"
+//	@Failure		400		{object}	util.ErrorResponse		"Bad request (validation error)"
+//	@Failure		401		{object}	util.ErrorResponse		"Unauthorized error"
+//	@Failure		500		{object}	util.ErrorResponse		"Internal server error"
+//	@Router			/api/v1/auth/logout [post]
+func (ah *AuthHandler) Logout(c *gin.Context) {
+	var request dto.RefreshTokenRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		ah.logger.Warn("Failed to bind request", zap.Error(err))
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	if err := ah.svc.Logout(c.Request.Context(), request.RefreshToken); err != nil {
+		ah.logger.Error("Logout failed", zap.Error(err))
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	c.JSON(http.StatusOK, util.APIResponse("Successfully logged out", http.StatusOK, "success", nil))
+}
+
+// LogoutAll godoc
+//
+//	@Summary		Logout All Devices
+//	@Description	Revoke every refresh token session of the current user
+//	@Tags			Auth
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{object}	util.Response		"Successfully logged out of all devices"
+//	@Failure		401	{object}	util.ErrorResponse	"Unauthorized error"
+//	@Failure		500	{object}	util.ErrorResponse	"Internal server error"
+//	@Router			/api/v1/auth/logout-all [post]
+func (ah *AuthHandler) LogoutAll(c *gin.Context) {
+	userSession := util.GetAuthPayload(c, consts.AuthorizationKey)
+	if userSession == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := ah.svc.LogoutAll(c.Request.Context(), userSession.UserID); err != nil {
+		ah.logger.Error("Logout of all devices failed", zap.String("user_id", userSession.UserID), zap.Error(err))
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	c.JSON(http.StatusOK, util.APIResponse("Successfully logged out of all devices", http.StatusOK, "success", nil))
+}
+
+// ForgotPassword godoc
+//
+//	@Summary		Forgot Password
+//	@Description	Email a single-use password reset link, the response is the same whether or not the email is registered
+//	@Tags			Auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		dto.ForgotPasswordRequest	true	"Forgot password request body"
+//	@Success		200		{object}	util.Response				"Password reset requested"
+//	@Failure		400		{object}	util.ErrorResponse			"Bad request (validation error)"
+//	@Failure		429		{object}	util.ErrorResponse			"Too many requests"
+//	@Failure		500		{object}	util.ErrorResponse			"Internal server error"
+//	@Router			/api/v1/auth/forgot-password [post]
+func (ah *AuthHandler) ForgotPassword(c *gin.Context) {
+	var request dto.ForgotPasswordRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		ah.logger.Warn("Failed to bind request", zap.Error(err))
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	if err := ah.passwordReset.ForgotPassword(c.Request.Context(), request.Email, c.ClientIP()); err != nil {
+		ah.logger.Warn("Password reset request refused", zap.Error(err))
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	c.JSON(http.StatusOK, util.APIResponse("If the email is registered, a password reset link has been sent", http.StatusOK, "success", nil))
+}
+
+// ResetPassword godoc
+//
+//	@Summary		Reset Password
+//	@Description	Set a new password with the token of a password reset link, signing the user out of every device
+//	@Tags			Auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		dto.ResetPasswordRequest	true	"Reset password request body"
+//	@Success		200		{object}	util.Response				"Password reset"
+//	@Failure		400		{object}	util.ErrorResponse			"Bad request (validation error or invalid token)"
+//	@Failure		429		{object}	util.ErrorResponse			"Too many requests"
+//	@Failure		500		{object}	util.ErrorResponse			"Internal server error"
+//	@Router			/api/v1/auth/reset-password [post]
+func (ah *AuthHandler) ResetPassword(c *gin.Context) {
+	var request dto.ResetPasswordRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		ah.logger.Warn("Failed to bind request", zap.Error(err))
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	if err := ah.passwordReset.ResetPassword(c.Request.Context(), request.Token, request.Password, c.ClientIP()); err != nil {
+		ah.logger.Warn("Password reset failed", zap.Error(err))
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	c.JSON(http.StatusOK, util.APIResponse("Password has been reset", http.StatusOK, "success", nil))
+}
+
+// VerifyEmail godoc
+//
+//	@Summary		Verify Email
+//	@Description	Verify the email address of an account with the token of a verification link
+//	@Tags			Auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		dto.VerifyEmailRequest	true	"Verify email request body"
+//	@Success		200		{object}	util.Response			"Email verified"
+//	@Failure		400		{object}	util.ErrorResponse		"Bad request (validation error or invalid token)"
+//	@Failure		500		{object}	util.ErrorResponse		"Internal server error"
+//	@Router			/api/v1/auth/verify-email [post]
+func (ah *AuthHandler) VerifyEmail(c *gin.Context) {
+	var request dto.VerifyEmailRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		ah.logger.Warn("Failed to bind request", zap.Error(err))
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	if err := ah.verification.VerifyEmail(c.Request.Context(), request.Token); err != nil {
+		ah.logger.Warn("Email verification failed", zap.Error(err))
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	c.JSON(http.StatusOK, util.APIResponse("Email has been verified", http.StatusOK, "success", nil))
+}
+
+// ResendVerification godoc
+//
+//	@Summary		Resend Verification Email
+//	@Description	Send a new verification link to an unverified account, the response is the same whether or not the email is registered
+//	@Tags			Auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		dto.ResendVerificationRequest	true	"Resend verification request body"
+//	@Success		200		{object}	util.Response					"Verification email requested"
+//	@Failure		400		{object}	util.ErrorResponse				"Bad request (validation error)"
+//	@Failure		429		{object}	util.ErrorResponse				"Too many requests"
+//	@Failure		500		{object}	util.ErrorResponse				"Internal server error"
+//	@Router			/api/v1/auth/resend-verification [post]
+func (ah *AuthHandler) ResendVerification(c *gin.Context) {
+	var request dto.ResendVerificationRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		ah.logger.Warn("Failed to bind request", zap.Error(err))
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	if err := ah.verification.ResendVerificationEmail(c.Request.Context(), request.Email, c.ClientIP()); err != nil {
+		ah.logger.Warn("Verification email request refused", zap.Error(err))
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	c.JSON(http.StatusOK, util.APIResponse("If the email is registered and not verified yet, a verification link has been sent", http.StatusOK, "success", nil))
+}
+
+// GetMFAStatus godoc
+//
+//	@Summary		Two-Factor Status
+//	@Description	Tell whether the current user has an authenticator enabled and whether their role requires one
+//	@Tags			Auth
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{object}	dto.MFAStatusResponse	"Two-factor status"
+//	@Failure		401	{object}	util.ErrorResponse		"Unauthorized error"
+//	@Failure		500	{object}	util.ErrorResponse		"Internal server error"
+//	@Router			/api/v1/auth/mfa [get]
+func (ah *AuthHandler) GetMFAStatus(c *gin.Context) {
+	userSession := util.GetAuthPayload(c, consts.AuthorizationKey)
+	if userSession == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	enabled, required, err := ah.mfa.MFAStatus(c.Request.Context(), userSession.UserID, userSession.Role)
+	if err != nil {
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	c.JSON(http.StatusOK, util.APIResponse("Success", http.StatusOK, "success", dto.MFAStatusResponse{
+		Enabled:  enabled,
+		Required: required,
+	}))
+}
+
+// EnrollMFA godoc
+//
+//	@Summary		Enroll Authenticator
+//	@Description	Start enrolling an authenticator app, it protects logins once confirmed with a first code
+//	@Tags			Auth
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{object}	domain.MFAEnrollment	"Authenticator secret and provisioning URI"
+//	@Failure		401	{object}	util.ErrorResponse		"Unauthorized error"
+//	@Failure		409	{object}	util.ErrorResponse		"Authenticator already enabled"
+//	@Failure		500	{object}	util.ErrorResponse		"Internal server error"
+//	@Router			/api/v1/auth/mfa/enroll [post]
+func (ah *AuthHandler) EnrollMFA(c *gin.Context) {
+	userSession := util.GetAuthPayload(c, consts.AuthorizationKey)
+	if userSession == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	enrollment, err := ah.mfa.EnrollMFA(c.Request.Context(), userSession.UserID)
+	if err != nil {
+		ah.logger.Warn("Authenticator enrollment failed", zap.String("user_id", userSession.UserID), zap.Error(err))
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	c.JSON(http.StatusOK, util.APIResponse("Scan the provisioning URI with an authenticator app", http.StatusOK, "success", enrollment))
+}
+
+// ConfirmMFA godoc
+//
+//	@Summary		Confirm Authenticator
+//	@Description	Enable the enrolled authenticator with a first code, returning recovery codes that are only shown once
+//	@Tags			Auth
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		dto.MFACodeRequest	true	"MFA code request body"
+//	@Success		200		{object}	util.Response		"Authenticator enabled"
+//	@Failure		400		{object}	util.ErrorResponse	"Bad request (validation error)"
+//	@Failure		401		{object}	util.ErrorResponse	"Invalid code"
+//	@Failure		409		{object}	util.ErrorResponse	"Authenticator already enabled"
+//	@Failure		500		{object}	util.ErrorResponse	"Internal server error"
+//	@Router			/api/v1/auth/mfa/confirm [post]
+func (ah *AuthHandler) ConfirmMFA(c *gin.Context) {
+	userSession := util.GetAuthPayload(c, consts.AuthorizationKey)
+	if userSession == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var request dto.MFACodeRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	recoveryCodes, err := ah.mfa.ConfirmMFA(c.Request.Context(), userSession.UserID, request.Code)
+	if err != nil {
+		ah.logger.Warn("Authenticator confirmation failed", zap.String("user_id", userSession.UserID), zap.Error(err))
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	c.JSON(http.StatusOK, util.APIResponse("Two-factor authentication enabled", http.StatusOK, "success", gin.H{"recovery_codes": recoveryCodes}))
+}
+
+// DisableMFA godoc
+//
+//	@Summary		Disable Authenticator
+//	@Description	Remove the authenticator after checking a code from it or a recovery code, not allowed for roles that require two-factor authentication
+//	@Tags			Auth
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		dto.MFACodeRequest	true	"MFA code request body"
+//	@Success		200		{object}	util.Response		"Authenticator disabled"
+//	@Failure		400		{object}	util.ErrorResponse	"Bad request (validation error)"
+//	@Failure		401		{object}	util.ErrorResponse	"Invalid code"
+//	@Failure		403		{object}	util.ErrorResponse	"Required for the role"
+//	@Failure		500		{object}	util.ErrorResponse	"Internal server error"
+//	@Router			/api/v1/auth/mfa/disable [post]
+func (ah *AuthHandler) DisableMFA(c *gin.Context) {
+	userSession := util.GetAuthPayload(c, consts.AuthorizationKey)
+	if userSession == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var request dto.MFACodeRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	if err := ah.mfa.DisableMFA(c.Request.Context(), userSession.UserID, request.Code); err != nil {
+		ah.logger.Warn("Disabling authenticator failed", zap.String("user_id", userSession.UserID), zap.Error(err))
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	c.JSON(http.StatusOK, util.APIResponse("Two-factor authentication disabled", http.StatusOK, "success", nil))
+}
+
+// UnlockUser godoc
+//
+//	@Summary		Unlock User Login
+//	@Description	Lift the lockout a user's email address got from repeated failed logins
+//	@Tags			Auth
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		string				true	"User ID"
+//	@Success		200	{object}	util.Response		"User unlocked"
+//	@Failure		404	{object}	util.ErrorResponse	"User not found"
+//	@Failure		500	{object}	util.ErrorResponse	"Internal server error"
+//	@Router			/api/v1/admin/users/{id}/unlock [post]
+func (ah *AuthHandler) UnlockUser(c *gin.Context) {
+	userID := c.Param("id")
+
+	if err := ah.loginProtection.UnlockAccount(c.Request.Context(), userID); err != nil {
+		ah.logger.Warn("Unlocking user failed", zap.String("user_id", userID), zap.Error(err))
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	c.JSON(http.StatusOK, util.APIResponse("User unlocked", http.StatusOK, "success", nil))
 }
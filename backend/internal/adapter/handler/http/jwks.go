@@ -0,0 +1,35 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/aldotp/employee-attendance-system/internal/core/port"
+	"github.com/gin-gonic/gin"
+)
+
+// jwksMaxAge lets other services cache the keys, a rotation is published before the new key signs
+// anything so they pick it up in time
+const jwksMaxAge = "public, max-age=300"
+
+type JWKSHandler struct {
+	token port.TokenInterface
+}
+
+func NewJWKSHandler(token port.TokenInterface) *JWKSHandler {
+	return &JWKSHandler{
+		token: token,
+	}
+}
+
+// GetJWKS godoc
+//
+//	@Summary		JSON Web Key Set
+//	@Description	Public keys verifying the access tokens, looked up by the kid header of a token
+//	@Tags			Auth
+//	@Produce		json
+//	@Success		200	{object}	map[string]interface{}	"JSON Web Key Set"
+//	@Router			/.well-known/jwks.json [get]
+func (h *JWKSHandler) GetJWKS(c *gin.Context) {
+	c.Header("Cache-Control", jwksMaxAge)
+	c.Data(http.StatusOK, "application/jwk-set+json", h.token.JWKS())
+}
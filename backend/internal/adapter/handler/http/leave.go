@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/aldotp/employee-attendance-system/internal/adapter/dto"
+	"github.com/aldotp/employee-attendance-system/internal/adapter/helper"
 	"github.com/aldotp/employee-attendance-system/internal/core/port"
 	"github.com/aldotp/employee-attendance-system/pkg/consts"
 	"github.com/aldotp/employee-attendance-system/pkg/util"
@@ -19,9 +20,15 @@ func NewLeaveHandler(svc port.LeaveService) *LeaveHandler {
 }
 
 func (h *LeaveHandler) ListLeaves(c *gin.Context) {
-	leaves, err := h.svc.ListLeaves(c.Request.Context())
+	userSession := util.GetAuthPayload(c, consts.AuthorizationKey)
+	if userSession == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	leaves, err := h.svc.ListLeaves(c.Request.Context(), userSession)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(helper.StatusCode(err), gin.H{"error": err.Error()})
 		return
 	}
 	c.JSON(http.StatusOK, util.APIResponse("success", http.StatusOK, "success", leaves))
@@ -34,8 +41,9 @@ func (h *LeaveHandler) CreateLeave(c *gin.Context) {
 		return
 	}
 
+	// supporting documents are sent as multipart/form-data, plain requests as JSON
 	var req dto.LeaveRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := c.ShouldBind(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
@@ -43,42 +51,60 @@ func (h *LeaveHandler) CreateLeave(c *gin.Context) {
 	req.UserID = userSession.UserID
 	leave, err := h.svc.SubmitLeaveRequest(c.Request.Context(), req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(helper.StatusCode(err), gin.H{"error": err.Error()})
 		return
 	}
 	c.JSON(http.StatusCreated, leave)
 }
 
 func (h *LeaveHandler) GetLeave(c *gin.Context) {
+	userSession := util.GetAuthPayload(c, consts.AuthorizationKey)
+	if userSession == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
 	id := c.Param("id")
-	leave, err := h.svc.GetLeaveByID(c.Request.Context(), id)
+	leave, err := h.svc.GetLeaveByID(c.Request.Context(), userSession, id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		c.JSON(helper.StatusCode(err), gin.H{"error": err.Error()})
 		return
 	}
 	c.JSON(http.StatusOK, leave)
 }
 
 func (h *LeaveHandler) UpdateLeave(c *gin.Context) {
+	userSession := util.GetAuthPayload(c, consts.AuthorizationKey)
+	if userSession == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
 	id := c.Param("id")
 	var req dto.LeaveRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	leave, err := h.svc.UpdateLeave(c.Request.Context(), id, req)
+	leave, err := h.svc.UpdateLeave(c.Request.Context(), userSession, id, req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(helper.StatusCode(err), gin.H{"error": err.Error()})
 		return
 	}
 	c.JSON(http.StatusOK, leave)
 }
 
 func (h *LeaveHandler) DeleteLeave(c *gin.Context) {
+	userSession := util.GetAuthPayload(c, consts.AuthorizationKey)
+	if userSession == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
 	id := c.Param("id")
-	err := h.svc.DeleteLeave(c.Request.Context(), id)
+	err := h.svc.DeleteLeave(c.Request.Context(), userSession, id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(helper.StatusCode(err), gin.H{"error": err.Error()})
 		return
 	}
 	c.Status(http.StatusNoContent)
@@ -93,7 +119,7 @@ func (h *LeaveHandler) GetLeaveBalance(c *gin.Context) {
 
 	balance, err := h.svc.GetLeaveBalance(c.Request.Context(), req.Type)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(helper.StatusCode(err), gin.H{"error": err.Error()})
 		return
 	}
 	c.JSON(http.StatusOK, balance)
@@ -102,12 +128,12 @@ func (h *LeaveHandler) GetLeaveBalance(c *gin.Context) {
 func (h *LeaveHandler) ApproveLeave(c *gin.Context) {
 	leaveID := c.Param("id")
 
-	err := h.svc.ApproveLeave(c, leaveID)
+	warnings, err := h.svc.ApproveLeave(c, leaveID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(helper.StatusCode(err), gin.H{"error": err.Error(), "coverage_warnings": warnings})
 		return
 	}
-	c.JSON(http.StatusOK, util.APIResponse("Approve Leave Request Success", http.StatusOK, "success", nil))
+	c.JSON(http.StatusOK, util.APIResponse("Approve Leave Request Success", http.StatusOK, "success", gin.H{"coverage_warnings": warnings}))
 }
 
 func (h *LeaveHandler) RejectLeave(c *gin.Context) {
@@ -121,8 +147,85 @@ func (h *LeaveHandler) RejectLeave(c *gin.Context) {
 
 	err := h.svc.RejectLeave(c, leaveID, req.Reason)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(helper.StatusCode(err), gin.H{"error": err.Error()})
 		return
 	}
 	c.JSON(http.StatusOK, util.APIResponse("Reject Leave Request Success", http.StatusOK, "success", nil))
 }
+
+func (h *LeaveHandler) UploadLeaveAttachments(c *gin.Context) {
+	userSession := util.GetAuthPayload(c, consts.AuthorizationKey)
+	if userSession == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	form, err := c.MultipartForm()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	leaveID := c.Param("id")
+	attachments, err := h.svc.AddLeaveAttachments(c.Request.Context(), leaveID, userSession.UserID, form.File["attachments"])
+	if err != nil {
+		c.JSON(helper.StatusCode(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, util.APIResponse("Upload Leave Attachment Success", http.StatusCreated, "success", attachments))
+}
+
+func (h *LeaveHandler) ListLeaveAttachments(c *gin.Context) {
+	userSession := util.GetAuthPayload(c, consts.AuthorizationKey)
+	if userSession == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	leaveID := c.Param("id")
+
+	attachments, err := h.svc.ListLeaveAttachments(c.Request.Context(), userSession, leaveID)
+	if err != nil {
+		c.JSON(helper.StatusCode(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, util.APIResponse("success", http.StatusOK, "success", attachments))
+}
+
+func (h *LeaveHandler) GetLeaveCalendar(c *gin.Context) {
+	userSession := util.GetAuthPayload(c, consts.AuthorizationKey)
+	if userSession == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req dto.LeaveCalendarRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	calendar, err := h.svc.GetDepartmentLeaveCalendar(c.Request.Context(), userSession, req)
+	if err != nil {
+		c.JSON(helper.StatusCode(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, util.APIResponse("success", http.StatusOK, "success", calendar))
+}
+
+func (h *LeaveHandler) GetLeaveCoverage(c *gin.Context) {
+	userSession := util.GetAuthPayload(c, consts.AuthorizationKey)
+	if userSession == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	leaveID := c.Param("id")
+
+	coverage, err := h.svc.CheckLeaveCoverage(c.Request.Context(), userSession, leaveID)
+	if err != nil {
+		c.JSON(helper.StatusCode(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, util.APIResponse("success", http.StatusOK, "success", coverage))
+}
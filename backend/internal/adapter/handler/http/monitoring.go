@@ -5,6 +5,7 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/aldotp/employee-attendance-system/internal/adapter/helper"
 	"github.com/aldotp/employee-attendance-system/internal/core/domain"
 	"github.com/aldotp/employee-attendance-system/internal/core/port"
 	"github.com/aldotp/employee-attendance-system/pkg/util"
@@ -68,9 +69,15 @@ func (h *MonitoringHandler) GenerateAttendanceReport(c *gin.Context) {
 }
 
 func (h *MonitoringHandler) DetectAnomalies(c *gin.Context) {
-	anomalies, err := h.svc.DetectAnomalies(c.Request.Context())
+	date := c.Query("date")
+	if date == "" {
+		date = time.Now().Format("2006-01-02")
+	}
+
+	anomalies, err := h.svc.DetectAnomalies(c.Request.Context(), date)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, util.APIResponse(err.Error(), http.StatusInternalServerError, "error", nil))
+		statusCode := helper.StatusCode(err)
+		c.JSON(statusCode, util.APIResponse(err.Error(), statusCode, "error", nil))
 		return
 	}
 	c.JSON(http.StatusOK, util.APIResponse("Success", http.StatusOK, "success", anomalies))
@@ -1,9 +1,13 @@
 package http
 
 import (
+	"fmt"
+	"io"
 	"net/http"
+	"time"
 
 	"github.com/aldotp/employee-attendance-system/internal/adapter/dto"
+	"github.com/aldotp/employee-attendance-system/internal/adapter/helper"
 	"github.com/aldotp/employee-attendance-system/internal/core/domain"
 	"github.com/aldotp/employee-attendance-system/internal/core/port"
 	"github.com/aldotp/employee-attendance-system/pkg/consts"
@@ -12,12 +16,14 @@ import (
 )
 
 type NotificationHandler struct {
-	svc port.NotificationService
+	svc           port.NotificationService
+	streamTickets port.StreamTicketService
 }
 
-func NewNotificationHandler(svc port.NotificationService) *NotificationHandler {
+func NewNotificationHandler(svc port.NotificationService, streamTickets port.StreamTicketService) *NotificationHandler {
 	return &NotificationHandler{
-		svc: svc,
+		svc:           svc,
+		streamTickets: streamTickets,
 	}
 }
 
@@ -30,7 +36,8 @@ func (h *NotificationHandler) CreateNotification(c *gin.Context) {
 
 	id, err := h.svc.CreateNotification(c.Request.Context(), &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, util.APIResponse(err.Error(), http.StatusInternalServerError, "error", nil))
+		statusCode := helper.StatusCode(err)
+		c.JSON(statusCode, util.APIResponse(err.Error(), statusCode, "error", nil))
 		return
 	}
 
@@ -38,10 +45,16 @@ func (h *NotificationHandler) CreateNotification(c *gin.Context) {
 }
 
 func (h *NotificationHandler) GetNotificationByID(c *gin.Context) {
+	userSession := util.GetAuthPayload(c, consts.AuthorizationKey)
+	if userSession == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
 	id := c.Param("id")
-	notification, err := h.svc.GetNotificationByID(c.Request.Context(), id)
+	notification, err := h.svc.GetNotificationByID(c.Request.Context(), userSession.UserID, id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, util.APIResponse(err.Error(), http.StatusInternalServerError, "error", nil))
+		c.JSON(helper.StatusCode(err), util.APIResponse(err.Error(), helper.StatusCode(err), "error", nil))
 		return
 	}
 	c.JSON(http.StatusOK, util.APIResponse("Success", http.StatusOK, "success", notification))
@@ -61,38 +74,238 @@ func (h *NotificationHandler) ListNotifications(c *gin.Context) {
 		return
 	}
 
-	notifications, err := h.svc.ListNotifications(c.Request.Context(), userSession.UserID, input.Skip, input.Limit)
+	notifications, err := h.svc.ListNotifications(c.Request.Context(), userSession.UserID, input)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, util.APIResponse(err.Error(), http.StatusInternalServerError, "error", nil))
+		c.JSON(helper.StatusCode(err), util.APIResponse(err.Error(), helper.StatusCode(err), "error", nil))
 		return
 	}
 	c.JSON(http.StatusOK, util.APIResponse("Success Get Notifications", http.StatusOK, "success", notifications))
 }
 
+// CountUnreadNotifications counts the unread notifications of the current user
+func (h *NotificationHandler) CountUnreadNotifications(c *gin.Context) {
+	userSession := util.GetAuthPayload(c, consts.AuthorizationKey)
+	if userSession == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var input dto.NotificationTypeRequest
+	if err := c.ShouldBindQuery(&input); err != nil {
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	count, err := h.svc.CountUnreadNotifications(c.Request.Context(), userSession.UserID, input.Type)
+	if err != nil {
+		c.JSON(helper.StatusCode(err), util.APIResponse(err.Error(), helper.StatusCode(err), "error", nil))
+		return
+	}
+	c.JSON(http.StatusOK, util.APIResponse("Success Count Unread Notifications", http.StatusOK, "success", gin.H{"unread": count}))
+}
+
+// UpdateNotificationStatus marks a notification of the current user read or unread
 func (h *NotificationHandler) UpdateNotificationStatus(c *gin.Context) {
+	userSession := util.GetAuthPayload(c, consts.AuthorizationKey)
+	if userSession == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
 	id := c.Param("id")
-	var req struct {
-		Status string `json:"status"`
+	var req dto.UpdateNotificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
 	}
+
+	notification, err := h.svc.MarkNotificationRead(c.Request.Context(), userSession.UserID, id, *req.IsRead)
+	if err != nil {
+		c.JSON(helper.StatusCode(err), util.APIResponse(err.Error(), helper.StatusCode(err), "error", nil))
+		return
+	}
+	c.JSON(http.StatusOK, util.APIResponse("Success Update Notification", http.StatusOK, "success", notification))
+}
+
+// MarkNotificationsRead marks the given notifications of the current user read
+func (h *NotificationHandler) MarkNotificationsRead(c *gin.Context) {
+	userSession := util.GetAuthPayload(c, consts.AuthorizationKey)
+	if userSession == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req dto.NotificationIDsRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
 		return
 	}
 
-	err := h.svc.UpdateNotificationStatus(c.Request.Context(), id, req.Status)
+	updated, err := h.svc.MarkNotificationsRead(c.Request.Context(), userSession.UserID, req.IDs)
+	if err != nil {
+		c.JSON(helper.StatusCode(err), util.APIResponse(err.Error(), helper.StatusCode(err), "error", nil))
+		return
+	}
+	c.JSON(http.StatusOK, util.APIResponse("Success Mark Notifications Read", http.StatusOK, "success", gin.H{"updated": updated}))
+}
+
+// MarkAllNotificationsRead marks every notification of the current user read, optionally of one type
+func (h *NotificationHandler) MarkAllNotificationsRead(c *gin.Context) {
+	userSession := util.GetAuthPayload(c, consts.AuthorizationKey)
+	if userSession == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req dto.NotificationTypeRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+			return
+		}
+	}
+
+	updated, err := h.svc.MarkAllNotificationsRead(c.Request.Context(), userSession.UserID, req.Type)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, util.APIResponse(err.Error(), http.StatusInternalServerError, "error", nil))
+		c.JSON(helper.StatusCode(err), util.APIResponse(err.Error(), helper.StatusCode(err), "error", nil))
 		return
 	}
-	c.JSON(http.StatusOK, util.APIResponse("Success Update Notification", http.StatusOK, "success", nil))
+	c.JSON(http.StatusOK, util.APIResponse("Success Mark All Notifications Read", http.StatusOK, "success", gin.H{"updated": updated}))
 }
 
 func (h *NotificationHandler) DeleteNotification(c *gin.Context) {
+	userSession := util.GetAuthPayload(c, consts.AuthorizationKey)
+	if userSession == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
 	id := c.Param("id")
-	err := h.svc.DeleteNotification(c.Request.Context(), id)
+	err := h.svc.DeleteNotification(c.Request.Context(), userSession.UserID, id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, util.APIResponse(err.Error(), http.StatusInternalServerError, "error", nil))
+		c.JSON(helper.StatusCode(err), util.APIResponse(err.Error(), helper.StatusCode(err), "error", nil))
 		return
 	}
 	c.JSON(http.StatusOK, util.APIResponse("Success Delete Notification", http.StatusOK, "success", nil))
 }
+
+// DeleteNotifications deletes the given notifications of the current user
+func (h *NotificationHandler) DeleteNotifications(c *gin.Context) {
+	userSession := util.GetAuthPayload(c, consts.AuthorizationKey)
+	if userSession == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req dto.NotificationIDsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	deleted, err := h.svc.DeleteNotifications(c.Request.Context(), userSession.UserID, req.IDs)
+	if err != nil {
+		c.JSON(helper.StatusCode(err), util.APIResponse(err.Error(), helper.StatusCode(err), "error", nil))
+		return
+	}
+	c.JSON(http.StatusOK, util.APIResponse("Success Delete Notifications", http.StatusOK, "success", gin.H{"deleted": deleted}))
+}
+
+// ListNotificationPreferences lists the channels of every notification type for the current user
+func (h *NotificationHandler) ListNotificationPreferences(c *gin.Context) {
+	userSession := util.GetAuthPayload(c, consts.AuthorizationKey)
+	if userSession == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	prefs, err := h.svc.ListNotificationPreferences(c.Request.Context(), userSession.UserID)
+	if err != nil {
+		c.JSON(helper.StatusCode(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, util.APIResponse("Success Get Notification Preferences", http.StatusOK, "success", prefs))
+}
+
+func (h *NotificationHandler) UpdateNotificationPreference(c *gin.Context) {
+	userSession := util.GetAuthPayload(c, consts.AuthorizationKey)
+	if userSession == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req dto.NotificationPreferenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	pref, err := h.svc.UpdateNotificationPreference(c.Request.Context(), userSession.UserID, req)
+	if err != nil {
+		c.JSON(helper.StatusCode(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, util.APIResponse("Success Update Notification Preference", http.StatusOK, "success", pref))
+}
+
+// notificationStreamHeartbeat is how often an idle stream sends a comment, keeping proxies from
+// closing the connection
+const notificationStreamHeartbeat = 30 * time.Second
+
+// CreateStreamTicket issues the ticket a client that cannot send headers opens the notification
+// stream with, in its ticket query parameter
+func (h *NotificationHandler) CreateStreamTicket(c *gin.Context) {
+	userSession := util.GetAuthPayload(c, consts.AuthorizationKey)
+	if userSession == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	ticket, err := h.streamTickets.CreateStreamTicket(c.Request.Context(), userSession)
+	if err != nil {
+		c.JSON(helper.StatusCode(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, util.APIResponse("Stream ticket created", http.StatusCreated, "success", ticket))
+}
+
+// StreamNotifications pushes the notifications of the current user as Server-Sent Events named
+// "notification" while the client stays connected. Clients list the notifications again after
+// reconnecting, since those delivered in between are not replayed.
+func (h *NotificationHandler) StreamNotifications(c *gin.Context) {
+	userSession := util.GetAuthPayload(c, consts.AuthorizationKey)
+	if userSession == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	notifications, err := h.svc.SubscribeNotifications(ctx, userSession.UserID)
+	if err != nil {
+		c.JSON(helper.StatusCode(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	heartbeat := time.NewTicker(notificationStreamHeartbeat)
+	defer heartbeat.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-ctx.Done():
+			return false
+		case notification, ok := <-notifications:
+			if !ok {
+				return false
+			}
+			c.SSEvent("notification", notification)
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": ping\n\n")
+		}
+		return true
+	})
+}
@@ -0,0 +1,55 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/aldotp/employee-attendance-system/internal/adapter/dto"
+	"github.com/aldotp/employee-attendance-system/internal/adapter/helper"
+	"github.com/aldotp/employee-attendance-system/internal/core/domain"
+	"github.com/aldotp/employee-attendance-system/internal/core/port"
+	"github.com/aldotp/employee-attendance-system/pkg/util"
+	"github.com/gin-gonic/gin"
+)
+
+type PermissionHandler struct {
+	svc port.AuthorizationService
+}
+
+func NewPermissionHandler(svc port.AuthorizationService) *PermissionHandler {
+	return &PermissionHandler{
+		svc: svc,
+	}
+}
+
+// ListRolePermissions lists the permissions of every role and all the permissions there are
+func (h *PermissionHandler) ListRolePermissions(c *gin.Context) {
+	roles, err := h.svc.ListRolePermissions(c.Request.Context())
+	if err != nil {
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	c.JSON(http.StatusOK, util.APIResponse("Success List Role Permissions", http.StatusOK, "success", dto.RolePermissionsResponse{
+		Roles:     roles,
+		Available: domain.Permissions,
+	}))
+}
+
+// UpdateRolePermissions replaces the permissions of the role in the path
+func (h *PermissionHandler) UpdateRolePermissions(c *gin.Context) {
+	var req dto.UpdateRolePermissionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	role, err := h.svc.UpdateRolePermissions(c.Request.Context(), domain.UserRole(c.Param("role")), req.Permissions)
+	if err != nil {
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	c.JSON(http.StatusOK, util.APIResponse("Role permissions updated", http.StatusOK, "success", role))
+}
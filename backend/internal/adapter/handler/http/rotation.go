@@ -0,0 +1,96 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/aldotp/employee-attendance-system/internal/adapter/dto"
+	"github.com/aldotp/employee-attendance-system/internal/adapter/helper"
+	"github.com/aldotp/employee-attendance-system/internal/core/port"
+	"github.com/aldotp/employee-attendance-system/pkg/util"
+	"github.com/gin-gonic/gin"
+)
+
+type RotationHandler struct {
+	rotationService port.RotationService
+}
+
+func NewRotationHandler(rotationService port.RotationService) *RotationHandler {
+	return &RotationHandler{
+		rotationService: rotationService,
+	}
+}
+
+func (h *RotationHandler) ListRotationPatterns(c *gin.Context) {
+	patterns, err := h.rotationService.ListRotationPatterns(c.Request.Context())
+	if err != nil {
+		c.JSON(helper.StatusCode(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, util.APIResponse("Success List Rotation Pattern", http.StatusOK, "success", patterns))
+}
+
+func (h *RotationHandler) CreateRotationPattern(c *gin.Context) {
+	var req dto.RotationPatternRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	pattern, err := h.rotationService.CreateRotationPattern(c.Request.Context(), req)
+	if err != nil {
+		c.JSON(helper.StatusCode(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, util.APIResponse("Rotation pattern created", http.StatusCreated, "success", pattern))
+}
+
+func (h *RotationHandler) GetRotationPattern(c *gin.Context) {
+	pattern, err := h.rotationService.GetRotationPattern(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(helper.StatusCode(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, util.APIResponse("Success", http.StatusOK, "success", pattern))
+}
+
+func (h *RotationHandler) DeleteRotationPattern(c *gin.Context) {
+	err := h.rotationService.DeleteRotationPattern(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(helper.StatusCode(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, util.APIResponse("Delete Rotation Pattern Success", http.StatusOK, "success", nil))
+}
+
+func (h *RotationHandler) AssignRotation(c *gin.Context) {
+	var req dto.RotationAssignmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	assignment, err := h.rotationService.AssignRotation(c.Request.Context(), req)
+	if err != nil {
+		c.JSON(helper.StatusCode(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, util.APIResponse("Rotation assigned", http.StatusCreated, "success", assignment))
+}
+
+func (h *RotationHandler) ListRotationAssignments(c *gin.Context) {
+	assignments, err := h.rotationService.ListRotationAssignments(c.Request.Context(), c.Param("userId"))
+	if err != nil {
+		c.JSON(helper.StatusCode(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, util.APIResponse("Success List Rotation Assignment", http.StatusOK, "success", assignments))
+}
+
+func (h *RotationHandler) DeleteRotationAssignment(c *gin.Context) {
+	err := h.rotationService.DeleteRotationAssignment(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(helper.StatusCode(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, util.APIResponse("Delete Rotation Assignment Success", http.StatusOK, "success", nil))
+}
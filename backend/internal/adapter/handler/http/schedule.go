@@ -1,9 +1,13 @@
 package http
 
 import (
+	"context"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/aldotp/employee-attendance-system/internal/adapter/dto"
+	"github.com/aldotp/employee-attendance-system/internal/adapter/helper"
 	"github.com/aldotp/employee-attendance-system/internal/core/domain"
 	"github.com/aldotp/employee-attendance-system/internal/core/port"
 	"github.com/aldotp/employee-attendance-system/pkg/consts"
@@ -12,28 +16,41 @@ import (
 )
 
 type ScheduleHandler struct {
-	scheduleService port.ScheduleService
+	scheduleService     port.ScheduleService
+	calendarFeedService port.CalendarFeedService
 }
 
-func NewScheduleHandler(scheduleService port.ScheduleService) *ScheduleHandler {
+func NewScheduleHandler(scheduleService port.ScheduleService, calendarFeedService port.CalendarFeedService) *ScheduleHandler {
 	return &ScheduleHandler{
-		scheduleService: scheduleService,
+		scheduleService:     scheduleService,
+		calendarFeedService: calendarFeedService,
 	}
 }
 
 func (h *ScheduleHandler) ListSchedules(c *gin.Context) {
-	schedules, err := h.scheduleService.ListSchedules(c.Request.Context())
+	userSession := util.GetAuthPayload(c, consts.AuthorizationKey)
+	if userSession == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	schedules, err := h.scheduleService.ListSchedules(c.Request.Context(), userSession)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(helper.StatusCode(err), gin.H{"error": err.Error()})
 		return
 	}
 	c.JSON(http.StatusOK, util.APIResponse("Success List Schedule", http.StatusOK, "success", schedules))
 }
 func (h *ScheduleHandler) GetWorkRotation(c *gin.Context) {
-	employeeID := c.Param("employeeId")
-	rotation, err := h.scheduleService.GetWorkRotation(c.Request.Context(), employeeID)
+	userSession := util.GetAuthPayload(c, consts.AuthorizationKey)
+	if userSession == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	rotation, err := h.scheduleService.GetWorkRotation(c.Request.Context(), userSession.UserID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(helper.StatusCode(err), gin.H{"error": err.Error()})
 		return
 	}
 	c.JSON(http.StatusOK, util.APIResponse("Success Get Work Rotation", http.StatusOK, "success", rotation))
@@ -53,12 +70,21 @@ func (h *ScheduleHandler) GetWorkCalendar(c *gin.Context) {
 		return
 	}
 
-	calendar, err := h.scheduleService.GetWorkCalendar(c, userSession.UserID, input.Year, input.Month)
+	// default to the current month
+	now := time.Now()
+	if input.Year == 0 {
+		input.Year = now.Year()
+	}
+	if input.Month == 0 {
+		input.Month = int(now.Month())
+	}
+
+	calendar, err := h.scheduleService.GetWorkCalendar(c, userSession.UserID, input.Month, input.Year)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(helper.StatusCode(err), gin.H{"error": err.Error()})
 		return
 	}
-	c.JSON(http.StatusOK, calendar)
+	c.JSON(http.StatusOK, util.APIResponse("Success Get Work Calendar", http.StatusOK, "success", calendar))
 }
 
 func (h *ScheduleHandler) RequestScheduleSwap(c *gin.Context) {
@@ -68,46 +94,166 @@ func (h *ScheduleHandler) RequestScheduleSwap(c *gin.Context) {
 		return
 	}
 
-	var req domain.ScheduleSwapRequest
+	var req dto.ScheduleSwapRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	err := h.scheduleService.RequestScheduleSwap(c, userSession.UserID, req.ScheduleID1, req.ScheduleID2)
+	swap, err := h.scheduleService.RequestScheduleSwap(c, userSession.UserID, req)
+	if err != nil {
+		c.JSON(helper.StatusCode(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, util.APIResponse("Schedule swap requested", http.StatusCreated, "success", swap))
+}
+
+func (h *ScheduleHandler) ListScheduleSwaps(c *gin.Context) {
+	userSession := util.GetAuthPayload(c, consts.AuthorizationKey)
+	if userSession == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req dto.ListScheduleSwapRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	swaps, err := h.scheduleService.ListScheduleSwapRequests(c, userSession.UserID, domain.SwapStatus(req.Status))
+	if err != nil {
+		c.JSON(helper.StatusCode(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, util.APIResponse("Success List Schedule Swap", http.StatusOK, "success", swaps))
+}
+
+// ListPendingScheduleSwaps lists the swaps accepted by both employees that wait for a manager
+func (h *ScheduleHandler) ListPendingScheduleSwaps(c *gin.Context) {
+	swaps, err := h.scheduleService.ListScheduleSwapRequests(c, "", domain.SwapAccepted)
+	if err != nil {
+		c.JSON(helper.StatusCode(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, util.APIResponse("Success List Schedule Swap", http.StatusOK, "success", swaps))
+}
+
+func (h *ScheduleHandler) RespondScheduleSwap(c *gin.Context) {
+	userSession := util.GetAuthPayload(c, consts.AuthorizationKey)
+	if userSession == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req dto.ScheduleSwapResponse
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	swap, err := h.scheduleService.RespondScheduleSwap(c, c.Param("id"), userSession.UserID, req.Accept)
+	if err != nil {
+		c.JSON(helper.StatusCode(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, util.APIResponse("Schedule swap responded", http.StatusOK, "success", swap))
+}
+
+func (h *ScheduleHandler) CancelScheduleSwap(c *gin.Context) {
+	userSession := util.GetAuthPayload(c, consts.AuthorizationKey)
+	if userSession == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	swap, err := h.scheduleService.CancelScheduleSwap(c, c.Param("id"), userSession.UserID)
+	if err != nil {
+		c.JSON(helper.StatusCode(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, util.APIResponse("Schedule swap cancelled", http.StatusOK, "success", swap))
+}
+
+func (h *ScheduleHandler) ApproveScheduleSwap(c *gin.Context) {
+	h.reviewScheduleSwap(c, h.scheduleService.ApproveScheduleSwap, "Schedule swap approved")
+}
+
+func (h *ScheduleHandler) RejectScheduleSwap(c *gin.Context) {
+	h.reviewScheduleSwap(c, func(ctx context.Context, swapID string, reviewerID string, note string) (*domain.ScheduleSwapRequest, []domain.ScheduleViolation, error) {
+		swap, err := h.scheduleService.RejectScheduleSwap(ctx, swapID, reviewerID, note)
+		return swap, nil, err
+	}, "Schedule swap rejected")
+}
+
+func (h *ScheduleHandler) reviewScheduleSwap(c *gin.Context, review func(ctx context.Context, swapID string, reviewerID string, note string) (*domain.ScheduleSwapRequest, []domain.ScheduleViolation, error), message string) {
+	userSession := util.GetAuthPayload(c, consts.AuthorizationKey)
+	if userSession == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	// the note is optional, so an empty body is accepted
+	var req dto.ScheduleSwapReview
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	swap, violations, err := review(c, c.Param("id"), userSession.UserID, req.Note)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(helper.StatusCode(err), gin.H{"error": err.Error(), "violations": violations})
 		return
 	}
-	c.JSON(http.StatusOK, nil)
+	c.JSON(http.StatusOK, util.APIResponse(message, http.StatusOK, "success", swap))
 }
 
 func (h *ScheduleHandler) CreateSchedule(c *gin.Context) {
+	userSession := util.GetAuthPayload(c, consts.AuthorizationKey)
+	if userSession == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
 	var req domain.Schedule
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	schedule, err := h.scheduleService.CreateSchedule(c.Request.Context(), &req)
+	schedule, violations, err := h.scheduleService.CreateSchedule(c.Request.Context(), userSession, &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(helper.StatusCode(err), gin.H{"error": err.Error(), "violations": violations})
 		return
 	}
 	c.JSON(http.StatusCreated, util.APIResponse("Schedule created", http.StatusCreated, "success", schedule))
 }
 
 func (h *ScheduleHandler) GetSchedule(c *gin.Context) {
+	userSession := util.GetAuthPayload(c, consts.AuthorizationKey)
+	if userSession == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
 	id := c.Param("id")
-	schedule, err := h.scheduleService.GetSchedule(c.Request.Context(), id)
+	schedule, err := h.scheduleService.GetSchedule(c.Request.Context(), userSession, id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(helper.StatusCode(err), gin.H{"error": err.Error()})
 		return
 	}
 	c.JSON(http.StatusOK, util.APIResponse("Success", http.StatusOK, "success", schedule))
 }
 
 func (h *ScheduleHandler) UpdateSchedule(c *gin.Context) {
+	userSession := util.GetAuthPayload(c, consts.AuthorizationKey)
+	if userSession == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
 	id := c.Param("id")
 	var req domain.Schedule
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -115,20 +261,263 @@ func (h *ScheduleHandler) UpdateSchedule(c *gin.Context) {
 		return
 	}
 
-	schedule, err := h.scheduleService.UpdateSchedule(c.Request.Context(), id, &req)
+	schedule, violations, err := h.scheduleService.UpdateSchedule(c.Request.Context(), userSession, id, &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(helper.StatusCode(err), gin.H{"error": err.Error(), "violations": violations})
 		return
 	}
 	c.JSON(http.StatusOK, util.APIResponse("Success", http.StatusOK, "success", schedule))
 }
 
 func (h *ScheduleHandler) DeleteSchedule(c *gin.Context) {
+	userSession := util.GetAuthPayload(c, consts.AuthorizationKey)
+	if userSession == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
 	id := c.Param("id")
-	err := h.scheduleService.DeleteSchedule(c.Request.Context(), id)
+	err := h.scheduleService.DeleteSchedule(c.Request.Context(), userSession, id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(helper.StatusCode(err), gin.H{"error": err.Error()})
 		return
 	}
 	c.JSON(http.StatusOK, util.APIResponse("Delete Schedule Success", http.StatusOK, "success", nil))
 }
+
+func (h *ScheduleHandler) ListShiftTemplates(c *gin.Context) {
+	templates, err := h.scheduleService.ListShiftTemplates(c.Request.Context())
+	if err != nil {
+		c.JSON(helper.StatusCode(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, util.APIResponse("Success List Shift Template", http.StatusOK, "success", templates))
+}
+
+func (h *ScheduleHandler) CreateShiftTemplate(c *gin.Context) {
+	var req dto.ShiftTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	template, err := h.scheduleService.CreateShiftTemplate(c.Request.Context(), req)
+	if err != nil {
+		c.JSON(helper.StatusCode(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, util.APIResponse("Shift template created", http.StatusCreated, "success", template))
+}
+
+func (h *ScheduleHandler) DeleteShiftTemplate(c *gin.Context) {
+	err := h.scheduleService.DeleteShiftTemplate(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(helper.StatusCode(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, util.APIResponse("Delete Shift Template Success", http.StatusOK, "success", nil))
+}
+
+func (h *ScheduleHandler) GenerateSchedules(c *gin.Context) {
+	var req dto.BulkScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.scheduleService.GenerateSchedules(c.Request.Context(), req)
+	if err != nil {
+		c.JSON(helper.StatusCode(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.DryRun {
+		c.JSON(http.StatusOK, util.APIResponse("Schedule generation preview", http.StatusOK, "success", result))
+		return
+	}
+	c.JSON(http.StatusCreated, util.APIResponse("Schedules generated", http.StatusCreated, "success", result))
+}
+
+func (h *ScheduleHandler) ListScheduleRules(c *gin.Context) {
+	rules, err := h.scheduleService.ListScheduleRules(c.Request.Context())
+	if err != nil {
+		c.JSON(helper.StatusCode(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, util.APIResponse("Success List Schedule Rule", http.StatusOK, "success", rules))
+}
+
+func (h *ScheduleHandler) CreateScheduleRule(c *gin.Context) {
+	var req dto.ScheduleRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rule, err := h.scheduleService.CreateScheduleRule(c.Request.Context(), req)
+	if err != nil {
+		c.JSON(helper.StatusCode(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, util.APIResponse("Schedule rule created", http.StatusCreated, "success", rule))
+}
+
+func (h *ScheduleHandler) DeleteScheduleRule(c *gin.Context) {
+	err := h.scheduleService.DeleteScheduleRule(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(helper.StatusCode(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, util.APIResponse("Delete Schedule Rule Success", http.StatusOK, "success", nil))
+}
+
+// ListAvailableOpenShifts lists the open shifts of the current user's department
+func (h *ScheduleHandler) ListAvailableOpenShifts(c *gin.Context) {
+	userSession := util.GetAuthPayload(c, consts.AuthorizationKey)
+	if userSession == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	openShifts, err := h.scheduleService.ListAvailableOpenShifts(c.Request.Context(), userSession.UserID)
+	if err != nil {
+		c.JSON(helper.StatusCode(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, util.APIResponse("Success List Open Shift", http.StatusOK, "success", openShifts))
+}
+
+func (h *ScheduleHandler) ClaimOpenShift(c *gin.Context) {
+	userSession := util.GetAuthPayload(c, consts.AuthorizationKey)
+	if userSession == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	claim, violations, err := h.scheduleService.ClaimOpenShift(c.Request.Context(), c.Param("id"), userSession.UserID)
+	if err != nil {
+		c.JSON(helper.StatusCode(err), gin.H{"error": err.Error(), "violations": violations})
+		return
+	}
+	c.JSON(http.StatusCreated, util.APIResponse("Open shift claimed", http.StatusCreated, "success", claim))
+}
+
+func (h *ScheduleHandler) ListOpenShifts(c *gin.Context) {
+	var req dto.ListOpenShiftRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	openShifts, err := h.scheduleService.ListOpenShifts(c.Request.Context(), req.DepartmentID, domain.OpenShiftStatus(req.Status))
+	if err != nil {
+		c.JSON(helper.StatusCode(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, util.APIResponse("Success List Open Shift", http.StatusOK, "success", openShifts))
+}
+
+func (h *ScheduleHandler) PostOpenShift(c *gin.Context) {
+	userSession := util.GetAuthPayload(c, consts.AuthorizationKey)
+	if userSession == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req dto.OpenShiftRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	openShift, err := h.scheduleService.PostOpenShift(c.Request.Context(), userSession.UserID, req)
+	if err != nil {
+		c.JSON(helper.StatusCode(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, util.APIResponse("Open shift posted", http.StatusCreated, "success", openShift))
+}
+
+func (h *ScheduleHandler) ListOpenShiftClaims(c *gin.Context) {
+	claims, err := h.scheduleService.ListOpenShiftClaims(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(helper.StatusCode(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, util.APIResponse("Success List Open Shift Claim", http.StatusOK, "success", claims))
+}
+
+func (h *ScheduleHandler) ConfirmOpenShiftClaim(c *gin.Context) {
+	userSession := util.GetAuthPayload(c, consts.AuthorizationKey)
+	if userSession == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	openShift, violations, err := h.scheduleService.ConfirmOpenShiftClaim(c.Request.Context(), c.Param("id"), userSession.UserID)
+	if err != nil {
+		c.JSON(helper.StatusCode(err), gin.H{"error": err.Error(), "violations": violations})
+		return
+	}
+	c.JSON(http.StatusOK, util.APIResponse("Open shift claim confirmed", http.StatusOK, "success", openShift))
+}
+
+func (h *ScheduleHandler) CancelOpenShift(c *gin.Context) {
+	userSession := util.GetAuthPayload(c, consts.AuthorizationKey)
+	if userSession == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	openShift, err := h.scheduleService.CancelOpenShift(c.Request.Context(), c.Param("id"), userSession.UserID)
+	if err != nil {
+		c.JSON(helper.StatusCode(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, util.APIResponse("Open shift cancelled", http.StatusOK, "success", openShift))
+}
+
+// CreateCalendarFeed issues the ICS subscription URL of the current user, revoking the previous one
+func (h *ScheduleHandler) CreateCalendarFeed(c *gin.Context) {
+	userSession := util.GetAuthPayload(c, consts.AuthorizationKey)
+	if userSession == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	feed, err := h.calendarFeedService.CreateCalendarFeed(c.Request.Context(), userSession.UserID)
+	if err != nil {
+		c.JSON(helper.StatusCode(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, util.APIResponse("Calendar feed created", http.StatusCreated, "success", feed))
+}
+
+func (h *ScheduleHandler) RevokeCalendarFeed(c *gin.Context) {
+	userSession := util.GetAuthPayload(c, consts.AuthorizationKey)
+	if userSession == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	err := h.calendarFeedService.RevokeCalendarFeed(c.Request.Context(), userSession.UserID)
+	if err != nil {
+		c.JSON(helper.StatusCode(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, util.APIResponse("Calendar feed revoked", http.StatusOK, "success", nil))
+}
+
+// GetCalendarFeed serves the ICS feed to calendar clients, which authenticate with the token in the URL
+func (h *ScheduleHandler) GetCalendarFeed(c *gin.Context) {
+	token := strings.TrimSuffix(c.Param("token"), ".ics")
+
+	feed, err := h.calendarFeedService.RenderCalendarFeed(c.Request.Context(), token)
+	if err != nil {
+		c.JSON(helper.StatusCode(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Cache-Control", "private, max-age=900")
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", feed)
+}
@@ -43,7 +43,7 @@ func (uh *UserHandler) Register(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, util.APIResponse("Registration failed", http.StatusInternalServerError, "error", nil))
 		return
 	}
-	c.JSON(http.StatusOK, util.APIResponse("Registration successful", http.StatusOK, "success", createdUser))
+	c.JSON(http.StatusOK, util.APIResponse("Registration successful, check your email to verify your account", http.StatusOK, "success", createdUser))
 }
 
 func (uh *UserHandler) GetProfile(c *gin.Context) {
@@ -0,0 +1,72 @@
+package worker
+
+import (
+	"context"
+	"log"
+
+	"github.com/aldotp/employee-attendance-system/internal/adapter/bootstrap"
+	"github.com/aldotp/employee-attendance-system/internal/adapter/config"
+	"github.com/aldotp/employee-attendance-system/internal/core/port"
+	"github.com/aldotp/employee-attendance-system/internal/core/service"
+	"github.com/mileusna/crontab"
+)
+
+type NotificationWorker struct {
+	notificationService port.NotificationService
+	attendanceService   port.AttendanceService
+	ctab                *crontab.Crontab
+}
+
+func NewNotificationWorker(b *bootstrap.Bootstrap) *NotificationWorker {
+	notificationService := service.NewNotificationService(b.NotificationRepo, b.UserRepo, b.EmployeeRepo, b.NotificationChannels, b.PubSub, config.NotificationPolicy())
+
+	return &NotificationWorker{
+		notificationService: notificationService,
+		attendanceService:   service.NewAttendanceService(b.AttendanceRepo, b.ScheduleRepo, b.EmployeeRepo, b.LeaveRequestRepo, notificationService, service.NewAuthorizationService(b.PermissionRepo, b.EmployeeRepo, b.Cache, b.Log), config.AttendancePolicy()),
+		ctab:                crontab.New(),
+	}
+}
+
+func (w *NotificationWorker) Run() {
+	err := w.ctab.AddJob("* * * * *", w.SendAttendanceReminders)
+	if err != nil {
+		log.Println(err)
+	} else {
+		log.Println("Scheduler Running: Attendance Reminders")
+	}
+
+	err = w.ctab.AddJob("* * * * *", w.DispatchNotifications)
+	if err != nil {
+		log.Println(err)
+	} else {
+		log.Println("Scheduler Running: Notification Dispatch")
+	}
+}
+
+func (w *NotificationWorker) SendAttendanceReminders() {
+	ctx := context.Background()
+
+	sent, err := w.attendanceService.SendAttendanceReminders(ctx)
+	if err != nil {
+		log.Println("Failed to send attendance reminders:", err)
+		return
+	}
+
+	if sent > 0 {
+		log.Println("Attendance reminders sent:", sent)
+	}
+}
+
+func (w *NotificationWorker) DispatchNotifications() {
+	ctx := context.Background()
+
+	delivered, err := w.notificationService.DispatchDueNotifications(ctx)
+	if err != nil {
+		log.Println("Failed to dispatch notifications:", err)
+		return
+	}
+
+	if delivered > 0 {
+		log.Println("Notifications delivered:", delivered)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/aldotp/employee-attendance-system/internal/adapter/bootstrap"
+	"github.com/aldotp/employee-attendance-system/internal/adapter/config"
 	"github.com/aldotp/employee-attendance-system/internal/core/domain"
 	"github.com/aldotp/employee-attendance-system/internal/core/port"
 	"github.com/aldotp/employee-attendance-system/internal/core/service"
@@ -23,8 +24,8 @@ type ReportWorker struct {
 
 func NewReportWorker(b *bootstrap.Bootstrap) *ReportWorker {
 	return &ReportWorker{
-		attendanceService: service.NewAttendanceService(b.AttendanceRepo),
-		monitoringService: service.NewMonitoringService(b.MonitoringRepo, b.UserRepo, b.AttendanceRepo),
+		attendanceService: service.NewAttendanceService(b.AttendanceRepo, b.ScheduleRepo, b.EmployeeRepo, b.LeaveRequestRepo, service.NewNotificationService(b.NotificationRepo, b.UserRepo, b.EmployeeRepo, b.NotificationChannels, b.PubSub, config.NotificationPolicy()), service.NewAuthorizationService(b.PermissionRepo, b.EmployeeRepo, b.Cache, b.Log), config.AttendancePolicy()),
+		monitoringService: service.NewMonitoringService(b.MonitoringRepo, b.UserRepo, b.AttendanceRepo, b.ScheduleRepo, b.EmployeeRepo, b.LeaveRequestRepo),
 		monitoringRepo:    b.MonitoringRepo,
 		ctab:              crontab.New(),
 	}
@@ -1,6 +1,7 @@
 package helper
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/aldotp/employee-attendance-system/pkg/consts"
@@ -12,19 +13,20 @@ func ErrorResponse(err error) (int, util.Response) {
 	message := "Internal server error"
 
 	switch err {
-	case consts.ErrDataNotFound:
+	case consts.ErrDataNotFound, consts.ErrOIDCNotConfigured:
 		statusCode = http.StatusNotFound
 		message = err.Error()
 	case consts.ErrNoUpdatedData:
 		statusCode = http.StatusNotModified
 		message = err.Error()
-	case consts.ErrConflictingData, consts.ErrEmailAlreadyExist:
+	case consts.ErrConflictingData, consts.ErrEmailAlreadyExist, consts.ErrInsufficientCoverage, consts.ErrScheduleSwapClosed, consts.ErrScheduleSwapConflict, consts.ErrMFAAlreadyEnabled, consts.ErrPermissionLockout:
 		statusCode = http.StatusConflict
 		message = err.Error()
 	case consts.ErrInsufficientStock, consts.ErrInsufficientPayment:
 		statusCode = http.StatusBadRequest
 		message = err.Error()
-	case consts.ErrTokenDuration, consts.ErrTokenCreation, consts.ErrInvalidToken, consts.ErrExpiredToken:
+	case consts.ErrTokenDuration, consts.ErrTokenCreation, consts.ErrInvalidToken, consts.ErrExpiredToken, consts.ErrInvalidRefreshToken, consts.ErrRevokedToken,
+		consts.ErrInvalidMFACode, consts.ErrInvalidMFAToken, consts.ErrOIDCLoginFailed, consts.ErrInvalidAPIKey:
 		statusCode = http.StatusUnauthorized
 		message = err.Error()
 	case consts.ErrInvalidCredentials:
@@ -39,14 +41,47 @@ func ErrorResponse(err error) (int, util.Response) {
 	case consts.ErrForbidden:
 		statusCode = http.StatusForbidden
 		message = err.Error()
-	case consts.ErrEmailNotVerified:
+	case consts.ErrEmailNotVerified, consts.ErrAccountInactive, consts.ErrMFARequired, consts.ErrOIDCAccountNotFound:
 		statusCode = http.StatusForbidden
 		message = err.Error()
+	case consts.ErrInvalidResetToken, consts.ErrInvalidActivationToken, consts.ErrMFANotEnabled, consts.ErrInvalidOIDCState, consts.ErrUnknownRole, consts.ErrUnknownPermission,
+		consts.ErrAPIKeyPermission, consts.ErrInvalidAPIKeyExpiry:
+		statusCode = http.StatusBadRequest
+		message = err.Error()
+	case consts.ErrTooManyRequests, consts.ErrTooManyLoginAttempts:
+		statusCode = http.StatusTooManyRequests
+		message = err.Error()
+	case consts.ErrLeaveAttachmentRequired, consts.ErrInvalidFileType:
+		statusCode = http.StatusBadRequest
+		message = err.Error()
+	case consts.ErrFileTooLarge:
+		statusCode = http.StatusRequestEntityTooLarge
+		message = err.Error()
 	case consts.ErrNotImplemented:
 		statusCode = http.StatusNotImplemented
 		message = err.Error()
-
+	default:
+		// wrapped sentinels and invalid input
+		if statusCode = StatusCode(err); statusCode != http.StatusInternalServerError {
+			message = err.Error()
+		}
 	}
 
 	return statusCode, util.APIResponse(message, statusCode, "error", nil)
 }
+
+// StatusCode returns the HTTP status code registered for err or the sentinel it wraps, falling
+// back to 500
+func StatusCode(err error) int {
+	if statusCode, ok := consts.ErrorToHTTPStatusCode[err]; ok {
+		return statusCode
+	}
+
+	for sentinel, statusCode := range consts.ErrorToHTTPStatusCode {
+		if errors.Is(err, sentinel) {
+			return statusCode
+		}
+	}
+
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,41 @@
+package helper
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/aldotp/employee-attendance-system/pkg/consts"
+)
+
+func TestStatusCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"sentinel", consts.ErrDataNotFound, http.StatusNotFound},
+		{"wrapped sentinel", fmt.Errorf("load leave: %w", consts.ErrDataNotFound), http.StatusNotFound},
+		{"invalid input", consts.InvalidInput("invalid start date format"), http.StatusBadRequest},
+		{"wrapped invalid input", fmt.Errorf("generate: %w", consts.InvalidInput("invalid month")), http.StatusBadRequest},
+		{"unknown", fmt.Errorf("connection reset"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := StatusCode(tt.err); got != tt.want {
+				t.Errorf("StatusCode() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestErrorResponseKeepsInputMessage(t *testing.T) {
+	statusCode, response := ErrorResponse(consts.InvalidInput("invalid end date format"))
+	if statusCode != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d", statusCode, http.StatusBadRequest)
+	}
+	if response.Meta.Message != "invalid end date format" {
+		t.Errorf("message = %q", response.Meta.Message)
+	}
+}
@@ -1,9 +1,10 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
-
 	"strings"
+	"time"
 
 	"github.com/aldotp/employee-attendance-system/internal/core/domain"
 	"github.com/aldotp/employee-attendance-system/internal/core/port"
@@ -15,10 +16,19 @@ import (
 const (
 	authorizationHeaderKey = "authorization"
 	authorizationType      = "bearer"
+	apiKeyHeaderKey        = "x-api-key"
 )
 
-func AuthMiddleware(token port.TokenInterface) gin.HandlerFunc {
+// AuthMiddleware verifies the bearer access token and rejects tokens issued before the user's
+// token version was bumped, so deleted, suspended or demoted users lose access right away.
+// Integrations send an API key in the X-API-Key header instead, see apiKeyAuth.
+func AuthMiddleware(token port.TokenInterface, tokenVersion port.TokenVersionService, apiKeys port.APIKeyService) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
+		if key := ctx.GetHeader(apiKeyHeaderKey); key != "" {
+			apiKeyAuth(ctx, apiKeys, key)
+			return
+		}
+
 		authorizationHeader := ctx.GetHeader(authorizationHeaderKey)
 
 		isEmpty := len(authorizationHeader) == 0
@@ -54,47 +64,141 @@ func AuthMiddleware(token port.TokenInterface) gin.HandlerFunc {
 			return
 		}
 
+		version, err := tokenVersion.TokenVersion(ctx.Request.Context(), payload.UserID)
+		if err != nil {
+			response := util.APIResponse(err.Error(), http.StatusInternalServerError, "error", nil)
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, response)
+			return
+		}
+
+		if payload.Version != version {
+			err := consts.ErrRevokedToken
+			response := util.APIResponse(err.Error(), http.StatusUnauthorized, "error", nil)
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, response)
+			return
+		}
+
 		ctx.Set(consts.AuthorizationKey, payload)
 		ctx.Next()
 	}
 }
 
-func AdminMiddleware() gin.HandlerFunc {
+// apiKeyAuth authenticates the request with an API key and audit logs it once it is handled,
+// whatever its outcome. The key only becomes the authorization payload of the request on routes
+// that accept keys through RequirePermissionOrAPIKey, elsewhere handlers see no user.
+func apiKeyAuth(ctx *gin.Context, apiKeys port.APIKeyService, key string) {
+	payload, err := apiKeys.Authenticate(ctx.Request.Context(), key)
+	if err != nil {
+		statusCode := http.StatusUnauthorized
+		if err != consts.ErrInvalidAPIKey {
+			statusCode = http.StatusInternalServerError
+		}
+		response := util.APIResponse(err.Error(), statusCode, "error", nil)
+		ctx.AbortWithStatusJSON(statusCode, response)
+		return
+	}
+
+	ctx.Set(consts.APIKeyPayloadKey, payload)
+	ctx.Next()
+
+	// recorded in line rather than in the background, so requests handled right before the server
+	// stops are audited too. RecordUsage logs its own failures.
+	apiKeys.RecordUsage(context.WithoutCancel(ctx.Request.Context()), &domain.APIKeyAuditLog{
+		APIKeyID:   payload.APIKeyID,
+		Method:     ctx.Request.Method,
+		Path:       ctx.Request.URL.Path,
+		StatusCode: ctx.Writer.Status(),
+		ClientIP:   ctx.ClientIP(),
+		CreatedAt:  time.Now(),
+	})
+}
+
+// StreamTicketMiddleware authenticates the request with the single-use ticket of the ticket
+// query parameter, for clients such as the browser's EventSource that cannot set headers. Requests
+// without one go through auth, AuthMiddleware on the routes that need it.
+func StreamTicketMiddleware(tickets port.StreamTicketService, auth gin.HandlerFunc) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		payload := util.GetAuthPayload(ctx, consts.AuthorizationKey)
+		ticket := ctx.Query("ticket")
+		if ticket == "" {
+			auth(ctx)
+			return
+		}
 
-		isAdmin := payload.Role == domain.Admin
-		if !isAdmin {
-			err := consts.ErrForbidden
-			response := util.APIResponse(err.Error(), http.StatusForbidden, "error", nil)
-			ctx.AbortWithStatusJSON(http.StatusForbidden, response)
+		payload, err := tickets.RedeemStreamTicket(ctx.Request.Context(), ticket)
+		if err != nil {
+			statusCode := http.StatusUnauthorized
+			if err != consts.ErrInvalidStreamTicket {
+				statusCode = http.StatusInternalServerError
+			}
+			response := util.APIResponse(err.Error(), statusCode, "error", nil)
+			ctx.AbortWithStatusJSON(statusCode, response)
 			return
 		}
 
+		ctx.Set(consts.AuthorizationKey, payload)
 		ctx.Next()
 	}
 }
 
-func VerifyRole(roles ...domain.UserRole) gin.HandlerFunc {
+// RequirePermission lets a user through when their role may perform the action in any scope.
+// Handlers and services narrow it down to the records that scope covers. API keys are refused,
+// the handlers behind it may act as the user.
+func RequirePermission(authz port.AuthorizationService, action domain.PermissionAction) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		payload := util.GetAuthPayload(c, consts.AuthorizationKey)
 		if payload == nil {
-			response := util.APIResponse("Unauthorized", http.StatusUnauthorized, "error", nil)
-			c.AbortWithStatusJSON(http.StatusUnauthorized, response)
+			payload = util.GetAuthPayload(c, consts.APIKeyPayloadKey)
+		}
+
+		if payload != nil && payload.APIKeyID != "" {
+			err := consts.ErrAPIKeyRouteNotAllowed
+			response := util.APIResponse(err.Error(), http.StatusForbidden, "error", nil)
+			c.AbortWithStatusJSON(http.StatusForbidden, response)
 			return
 		}
 
-		allowedRoles := make(map[domain.UserRole]struct{})
-		for _, role := range roles {
-			allowedRoles[role] = struct{}{}
+		if checkPermission(c, authz, action, payload) {
+			c.Next()
 		}
+	}
+}
 
-		if _, exists := allowedRoles[payload.Role]; !exists {
-			response := util.APIResponse(consts.ErrForbidden.Error(), http.StatusForbidden, "error", nil)
-			c.AbortWithStatusJSON(http.StatusForbidden, response)
-			return
+// RequirePermissionOrAPIKey is RequirePermission for routes that never act as the user, it also
+// lets through API keys granted the action and makes them the authorization payload.
+func RequirePermissionOrAPIKey(authz port.AuthorizationService, action domain.PermissionAction) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		payload := util.GetAuthPayload(c, consts.AuthorizationKey)
+		if payload == nil {
+			payload = util.GetAuthPayload(c, consts.APIKeyPayloadKey)
+		}
+
+		if checkPermission(c, authz, action, payload) {
+			c.Set(consts.AuthorizationKey, payload)
+			c.Next()
 		}
+	}
+}
 
-		c.Next()
+// checkPermission aborts the request unless the payload holds the action in some scope
+func checkPermission(c *gin.Context, authz port.AuthorizationService, action domain.PermissionAction, payload *domain.TokenPayload) bool {
+	if payload == nil {
+		response := util.APIResponse("Unauthorized", http.StatusUnauthorized, "error", nil)
+		c.AbortWithStatusJSON(http.StatusUnauthorized, response)
+		return false
 	}
+
+	scope, err := authz.Scope(c.Request.Context(), payload, action)
+	if err != nil {
+		response := util.APIResponse(err.Error(), http.StatusInternalServerError, "error", nil)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response)
+		return false
+	}
+
+	if scope == "" {
+		response := util.APIResponse(consts.ErrForbidden.Error(), http.StatusForbidden, "error", nil)
+		c.AbortWithStatusJSON(http.StatusForbidden, response)
+		return false
+	}
+
+	return true
 }
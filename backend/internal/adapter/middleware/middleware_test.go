@@ -0,0 +1,133 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/aldotp/employee-attendance-system/internal/core/domain"
+	"github.com/aldotp/employee-attendance-system/internal/core/port"
+	"github.com/aldotp/employee-attendance-system/pkg/consts"
+	"github.com/aldotp/employee-attendance-system/pkg/util"
+	"github.com/gin-gonic/gin"
+)
+
+type fakeAPIKeys struct {
+	port.APIKeyService
+
+	usage []domain.APIKeyAuditLog
+}
+
+func (s *fakeAPIKeys) Authenticate(ctx context.Context, key string) (*domain.TokenPayload, error) {
+	if key != "eas_valid" {
+		return nil, consts.ErrInvalidAPIKey
+	}
+
+	return &domain.TokenPayload{
+		APIKeyID: "key-1",
+		Permissions: []domain.Permission{
+			domain.ActionAttendanceRead.In(domain.ScopeAny),
+			domain.ActionAttendanceRecord.In(domain.ScopeAny),
+			domain.ActionLeaveWrite.In(domain.ScopeAny),
+			domain.ActionLeaveApprove.In(domain.ScopeAny),
+			domain.ActionScheduleRead.In(domain.ScopeAny),
+		},
+	}, nil
+}
+
+func (s *fakeAPIKeys) RecordUsage(ctx context.Context, log *domain.APIKeyAuditLog) error {
+	s.usage = append(s.usage, *log)
+	return nil
+}
+
+// fakeAuthz grants API keys the permissions they hold
+type fakeAuthz struct {
+	port.AuthorizationService
+}
+
+func (fakeAuthz) Scope(ctx context.Context, actor *domain.TokenPayload, action domain.PermissionAction) (domain.PermissionScope, error) {
+	if slices.Contains(actor.Permissions, action.In(domain.ScopeAny)) {
+		return domain.ScopeAny, nil
+	}
+	return "", nil
+}
+
+func newAPIKeyRouter(apiKeys *fakeAPIKeys) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	auth := AuthMiddleware(nil, nil, apiKeys)
+	require := func(action domain.PermissionAction) gin.HandlerFunc { return RequirePermission(fakeAuthz{}, action) }
+	requireOrKey := func(action domain.PermissionAction) gin.HandlerFunc {
+		return RequirePermissionOrAPIKey(fakeAuthz{}, action)
+	}
+	// handler answers like the handlers acting as the user do when the request has none
+	handler := func(c *gin.Context) {
+		if util.GetAuthPayload(c, consts.AuthorizationKey) == nil {
+			c.Status(http.StatusUnauthorized)
+			return
+		}
+		c.Status(http.StatusOK)
+	}
+
+	router := gin.New()
+	router.GET("/attendance", auth, requireOrKey(domain.ActionAttendanceRead), handler)
+	router.GET("/users", auth, requireOrKey(domain.ActionUsersManage), handler)
+	router.GET("/profile", auth, handler)
+	router.POST("/attendance", auth, require(domain.ActionAttendanceRecord), handler)
+	router.POST("/leave", auth, require(domain.ActionLeaveWrite), handler)
+	router.POST("/leave/admin/reject/:id", auth, require(domain.ActionLeaveApprove), handler)
+	router.POST("/schedule/swap", auth, requireOrKey(domain.ActionScheduleRead), require(domain.ActionScheduleRead), handler)
+
+	return router
+}
+
+func TestAPIKeyAuth(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		key        string
+		wantStatus int
+		wantAudit  bool
+	}{
+		{"permission held", http.MethodGet, "/attendance", "eas_valid", http.StatusOK, true},
+		{"permission not held", http.MethodGet, "/users", "eas_valid", http.StatusForbidden, true},
+		{"route without permission", http.MethodGet, "/profile", "eas_valid", http.StatusUnauthorized, true},
+		{"attendance recorded for the user", http.MethodPost, "/attendance", "eas_valid", http.StatusForbidden, true},
+		{"leave requested for the user", http.MethodPost, "/leave", "eas_valid", http.StatusForbidden, true},
+		{"leave rejected by the user", http.MethodPost, "/leave/admin/reject/leave-1", "eas_valid", http.StatusForbidden, true},
+		{"user route after a key check", http.MethodPost, "/schedule/swap", "eas_valid", http.StatusForbidden, true},
+		{"invalid key", http.MethodGet, "/attendance", "eas_invalid", http.StatusUnauthorized, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apiKeys := &fakeAPIKeys{}
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.Header.Set("X-API-Key", tt.key)
+			rec := httptest.NewRecorder()
+
+			newAPIKeyRouter(apiKeys).ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+
+			// the audit row is written before the request returns
+			if !tt.wantAudit {
+				if len(apiKeys.usage) != 0 {
+					t.Errorf("usage = %+v, want none", apiKeys.usage)
+				}
+				return
+			}
+			if len(apiKeys.usage) != 1 {
+				t.Fatalf("usage = %+v, want one entry", apiKeys.usage)
+			}
+			if usage := apiKeys.usage[0]; usage.APIKeyID != "key-1" || usage.Path != tt.path || usage.StatusCode != tt.wantStatus {
+				t.Errorf("usage = %+v", usage)
+			}
+		})
+	}
+}
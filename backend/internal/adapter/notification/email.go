@@ -0,0 +1,68 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aldotp/employee-attendance-system/internal/core/domain"
+	"github.com/aldotp/employee-attendance-system/pkg/email"
+)
+
+// EmailChannel sends notifications as HTML emails rendered from a template
+type EmailChannel struct {
+	sender       *email.Email
+	templatePath string
+	appName      string
+}
+
+func NewEmailChannel(sender *email.Email, templatePath string, appName string) *EmailChannel {
+	return &EmailChannel{
+		sender:       sender,
+		templatePath: templatePath,
+		appName:      appName,
+	}
+}
+
+type emailPayload struct {
+	AppName string
+	Subject string
+	Name    string
+	Type    string
+	Message string
+	SendAt  string
+}
+
+func (c *EmailChannel) Type() domain.NotificationChannelType {
+	return domain.NotificationChannelEmail
+}
+
+func (c *EmailChannel) Send(ctx context.Context, notif domain.Notification, recipient domain.NotificationRecipient) error {
+	if recipient.Email == "" {
+		return fmt.Errorf("recipient %s has no email address", recipient.UserID)
+	}
+
+	subject := fmt.Sprintf("[%s] %s", c.appName, typeLabel(notif.Type))
+
+	return c.sender.SendEmail(emailPayload{
+		AppName: c.appName,
+		Subject: subject,
+		Name:    recipient.Name,
+		Type:    typeLabel(notif.Type),
+		Message: notif.Message,
+		SendAt:  notif.SendAt.Format("02 Jan 2006 15:04"),
+	}, c.templatePath, []string{recipient.Email}, subject)
+}
+
+// typeLabel is the human readable name of a notification type
+func typeLabel(notificationType domain.NotificationType) string {
+	switch notificationType {
+	case domain.NotificationTypeReminder:
+		return "Reminder"
+	case domain.NotificationTypeWarning:
+		return "Warning"
+	case domain.NotificationTypeSecurity:
+		return "Security"
+	default:
+		return "Notification"
+	}
+}
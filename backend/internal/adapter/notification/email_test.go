@@ -0,0 +1,27 @@
+package notification
+
+import (
+	"testing"
+
+	"github.com/aldotp/employee-attendance-system/internal/core/domain"
+)
+
+func TestTypeLabel(t *testing.T) {
+	tests := []struct {
+		notificationType domain.NotificationType
+		want             string
+	}{
+		{domain.NotificationTypeReminder, "Reminder"},
+		{domain.NotificationTypeWarning, "Warning"},
+		{domain.NotificationTypeSecurity, "Security"},
+		{domain.NotificationTypeInfo, "Notification"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.notificationType), func(t *testing.T) {
+			if got := typeLabel(tt.notificationType); got != tt.want {
+				t.Errorf("typeLabel(%q) = %s, want %s", tt.notificationType, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/aldotp/employee-attendance-system/internal/core/domain"
+	"github.com/aldotp/employee-attendance-system/internal/core/port"
+)
+
+// InAppChannel shows notifications in the application. The stored notification is what the
+// application lists, sending only pushes it to the clients of the recipient streaming their
+// notifications.
+type InAppChannel struct {
+	pubsub port.PubSubInterface
+}
+
+func NewInAppChannel(pubsub port.PubSubInterface) *InAppChannel {
+	return &InAppChannel{
+		pubsub: pubsub,
+	}
+}
+
+func (c *InAppChannel) Type() domain.NotificationChannelType {
+	return domain.NotificationChannelInApp
+}
+
+func (c *InAppChannel) Send(ctx context.Context, notif domain.Notification, recipient domain.NotificationRecipient) error {
+	if c.pubsub == nil {
+		return nil
+	}
+
+	// clients receive the notification as the list shows it once this delivery is recorded
+	now := time.Now()
+	notif.Status = domain.NotificationDelivered
+	notif.DeliveredAt = &now
+
+	payload, err := json.Marshal(notif)
+	if err != nil {
+		return err
+	}
+
+	// the stream is a convenience on top of the stored notification, which clients list again
+	// when they reconnect, so a failed push does not fail the delivery
+	if err := c.pubsub.Publish(ctx, domain.NotificationTopic(notif.UserID), payload); err != nil {
+		fmt.Printf("failed to stream notification %s: %v", notif.ID, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,121 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+
+	"github.com/aldotp/employee-attendance-system/internal/core/domain"
+	"github.com/aldotp/employee-attendance-system/pkg/util"
+)
+
+// WebhookChannel posts notifications as JSON to the recipient's webhook URL. The text field is
+// understood by the incoming webhooks of most chat tools, other receivers can use the rest.
+type WebhookChannel struct {
+	client *http.Client
+	secret string
+}
+
+func NewWebhookChannel(secret string, timeout time.Duration) *WebhookChannel {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: publicAddressOnly,
+	}
+
+	// no proxy, so the dialer sees the webhook's own address
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &WebhookChannel{
+		client: &http.Client{Timeout: timeout, Transport: transport},
+		secret: secret,
+	}
+}
+
+// publicAddressOnly refuses connections to internal addresses right before they are made, which
+// also covers hosts re-resolved since the webhook was saved and redirects
+func publicAddressOnly(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+
+	if !util.IsPublicAddr(addr) {
+		return fmt.Errorf("webhook address %s is not public", addr)
+	}
+
+	return nil
+}
+
+type webhookPayload struct {
+	ID      string                  `json:"id"`
+	UserID  string                  `json:"user_id"`
+	Type    domain.NotificationType `json:"type"`
+	Message string                  `json:"message"`
+	SendAt  time.Time               `json:"send_at"`
+	Text    string                  `json:"text"`
+}
+
+func (c *WebhookChannel) Type() domain.NotificationChannelType {
+	return domain.NotificationChannelWebhook
+}
+
+func (c *WebhookChannel) Send(ctx context.Context, notif domain.Notification, recipient domain.NotificationRecipient) error {
+	if recipient.WebhookURL == "" {
+		return fmt.Errorf("recipient %s has no webhook url", recipient.UserID)
+	}
+
+	body, err := json.Marshal(webhookPayload{
+		ID:      notif.ID,
+		UserID:  notif.UserID,
+		Type:    notif.Type,
+		Message: notif.Message,
+		SendAt:  notif.SendAt,
+		Text:    fmt.Sprintf("[%s] %s", typeLabel(notif.Type), notif.Message),
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, recipient.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	// lets the receiver check that the request comes from this application
+	if c.secret != "" {
+		mac := hmac.New(sha256.New, []byte(c.secret))
+		mac.Write(body)
+		req.Header.Set("X-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+
+	return nil
+}
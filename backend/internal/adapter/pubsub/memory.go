@@ -0,0 +1,73 @@
+package pubsub
+
+import (
+	"context"
+	"sync"
+
+	"github.com/aldotp/employee-attendance-system/internal/core/port"
+)
+
+// subscriberBuffer is how many messages a subscriber can fall behind before it starts missing
+// messages, so a slow subscriber never blocks the publisher
+const subscriberBuffer = 16
+
+// Memory broadcasts messages within the current process, for deployments running a single
+// instance of every command
+type Memory struct {
+	mu          sync.RWMutex
+	subscribers map[string]map[chan []byte]struct{}
+}
+
+// NewMemory creates a new in-process pub/sub
+func NewMemory() port.PubSubInterface {
+	return &Memory{
+		subscribers: map[string]map[chan []byte]struct{}{},
+	}
+}
+
+// Publish hands the message to the current subscribers of the topic
+func (m *Memory) Publish(ctx context.Context, topic string, message []byte) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for ch := range m.subscribers[topic] {
+		select {
+		case ch <- message:
+		default:
+		}
+	}
+
+	return nil
+}
+
+// Subscribe registers a subscriber of the topic until ctx is done
+func (m *Memory) Subscribe(ctx context.Context, topic string) (<-chan []byte, error) {
+	ch := make(chan []byte, subscriberBuffer)
+
+	m.mu.Lock()
+	if m.subscribers[topic] == nil {
+		m.subscribers[topic] = map[chan []byte]struct{}{}
+	}
+	m.subscribers[topic][ch] = struct{}{}
+	m.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		delete(m.subscribers[topic], ch)
+		if len(m.subscribers[topic]) == 0 {
+			delete(m.subscribers, topic)
+		}
+		close(ch)
+	}()
+
+	return ch, nil
+}
+
+// Close does nothing, subscriptions end with their context
+func (m *Memory) Close() error {
+	return nil
+}
@@ -0,0 +1,77 @@
+package pubsub
+
+import (
+	"context"
+
+	"github.com/aldotp/employee-attendance-system/internal/adapter/config"
+	"github.com/aldotp/employee-attendance-system/internal/core/port"
+	"github.com/redis/go-redis/v9"
+)
+
+// Redis broadcasts messages through Redis channels, reaching the subscribers of every instance
+// connected to the same Redis
+type Redis struct {
+	client *redis.Client
+}
+
+// NewRedis creates a new Redis backed pub/sub
+func NewRedis(ctx context.Context, config *config.Redis) (port.PubSubInterface, error) {
+	client := redis.NewClient(&redis.Options{
+		Addr:     config.Addr,
+		Password: config.Password,
+		DB:       0,
+	})
+
+	_, err := client.Ping(ctx).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Redis{client}, nil
+}
+
+// Publish sends the message to the Redis channel of the topic
+func (r *Redis) Publish(ctx context.Context, topic string, message []byte) error {
+	return r.client.Publish(ctx, topic, message).Err()
+}
+
+// Subscribe listens to the Redis channel of the topic until ctx is done
+func (r *Redis) Subscribe(ctx context.Context, topic string) (<-chan []byte, error) {
+	sub := r.client.Subscribe(ctx, topic)
+
+	// wait for the confirmation, so nothing published after Subscribe returns is missed
+	if _, err := sub.Receive(ctx); err != nil {
+		sub.Close()
+		return nil, err
+	}
+
+	ch := make(chan []byte, subscriberBuffer)
+	go func() {
+		defer close(ch)
+		defer sub.Close()
+
+		messages := sub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-messages:
+				if !ok {
+					return
+				}
+
+				select {
+				case ch <- []byte(msg.Payload):
+				default:
+				}
+			}
+		}
+	}()
+
+	return ch, nil
+}
+
+// Close closes the connections to the redis database
+func (r *Redis) Close() error {
+	return r.client.Close()
+}
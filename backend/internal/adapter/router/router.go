@@ -21,14 +21,21 @@ type Router struct {
 
 func NewRouter(
 	token port.TokenInterface,
+	tokenVersion port.TokenVersionService,
+	authz port.AuthorizationService,
+	apiKeys port.APIKeyService,
+	streamTickets port.StreamTicketService,
 	authHandler *http.AuthHandler,
 	userHandler *http.UserHandler,
 	attendanceHandler *http.AttendanceHandler,
 	leaveHandler *http.LeaveHandler,
 	scheduleHandler *http.ScheduleHandler,
+	rotationHandler *http.RotationHandler,
 	monitoringHandler *http.MonitoringHandler,
 	notificationHandler *http.NotificationHandler,
 	departmentHandler *http.DepartmentHandler,
+	permissionHandler *http.PermissionHandler,
+	apiKeyHandler *http.APIKeyHandler,
 ) (*Router, error) {
 
 	// Set Gin mode
@@ -39,6 +46,12 @@ func NewRouter(
 	}
 
 	router := gin.New()
+
+	// client IPs are rate limited and audited, so forwarded headers are only believed from known proxies
+	if err := router.SetTrustedProxies(config.HTTPTrustedProxies()); err != nil {
+		return nil, err
+	}
+
 	router.Use(middleware.CORSMiddleware())
 	router.Use(gin.Recovery())
 	router.Use(gin.Logger())
@@ -55,6 +68,18 @@ func NewRouter(
 	util.Metrics(router)
 
 	router.GET("/docs/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	router.GET("/.well-known/jwks.json", http.NewJWKSHandler(token).GetJWKS)
+
+	authMiddleware := middleware.AuthMiddleware(token, tokenVersion, apiKeys)
+	// require lets a user through when their role holds the action in some scope, the services
+	// check that the scope covers the records involved
+	require := func(action domain.PermissionAction) gin.HandlerFunc {
+		return middleware.RequirePermission(authz, action)
+	}
+	// requireOrKey also lets API keys through, only for routes whose handlers never act as the user
+	requireOrKey := func(action domain.PermissionAction) gin.HandlerFunc {
+		return middleware.RequirePermissionOrAPIKey(authz, action)
+	}
 
 	api := router.Group("/api")
 	v1 := api.Group("/v1")
@@ -64,82 +89,171 @@ func NewRouter(
 		{
 			auth.POST("/register", userHandler.Register)
 			auth.POST("/login", authHandler.Login)
+			auth.POST("/login/mfa", authHandler.LoginMFA)
+			auth.POST("/login/mfa/enroll", authHandler.EnrollMFAChallenge)
+			auth.GET("/oidc/login", authHandler.StartOIDCLogin)
+			auth.POST("/oidc/callback", authHandler.LoginOIDC)
 			auth.POST("/refresh-token", authHandler.RefreshToken)
+			auth.POST("/logout", authHandler.Logout)
+			auth.POST("/forgot-password", authHandler.ForgotPassword)
+			auth.POST("/reset-password", authHandler.ResetPassword)
+			auth.POST("/verify-email", authHandler.VerifyEmail)
+			auth.POST("/resend-verification", authHandler.ResendVerification)
+			auth.POST("/logout-all", authMiddleware, authHandler.LogoutAll)
+			auth.GET("/mfa", authMiddleware, authHandler.GetMFAStatus)
+			auth.POST("/mfa/enroll", authMiddleware, authHandler.EnrollMFA)
+			auth.POST("/mfa/confirm", authMiddleware, authHandler.ConfirmMFA)
+			auth.POST("/mfa/disable", authMiddleware, authHandler.DisableMFA)
 		}
 
-		user := v1.Group("/user").Use(middleware.AuthMiddleware(token))
+		user := v1.Group("/user").Use(authMiddleware)
 		{
 			user.GET("/profile", userHandler.GetProfile)
 			user.PUT("/profile", userHandler.UpdateProfile)
 		}
 
-		admin := v1.Group("/admin").Use(middleware.AuthMiddleware(token), middleware.VerifyRole(domain.Admin, domain.HR))
+		admin := v1.Group("/admin/users").Use(authMiddleware, require(domain.ActionUsersManage))
 		{
-			admin.GET("/users", userHandler.ListUser)
-			admin.GET("/users/:id", userHandler.GetUserByID)
-			admin.POST("/users", userHandler.CreateUser)
-			admin.DELETE("/users/:id", userHandler.DeleteUserByID)
-			admin.PUT("/users/:id", userHandler.UpdateUserByID)
+			admin.GET("", userHandler.ListUser)
+			admin.GET("/:id", userHandler.GetUserByID)
+			admin.POST("", userHandler.CreateUser)
+			admin.DELETE("/:id", userHandler.DeleteUserByID)
+			admin.PUT("/:id", userHandler.UpdateUserByID)
+			admin.POST("/:id/unlock", authHandler.UnlockUser)
 		}
 
-		notification := v1.Group("/notification").Use(middleware.AuthMiddleware(token))
+		permission := v1.Group("/admin/permissions").Use(authMiddleware, require(domain.ActionPermissionsManage))
+		{
+			permission.GET("", permissionHandler.ListRolePermissions)
+			permission.PUT("/:role", permissionHandler.UpdateRolePermissions)
+		}
+
+		apiKey := v1.Group("/admin/api-keys").Use(authMiddleware, require(domain.ActionAPIKeysManage))
+		{
+			apiKey.GET("", apiKeyHandler.ListAPIKeys)
+			apiKey.POST("", apiKeyHandler.CreateAPIKey)
+			apiKey.GET("/:id", apiKeyHandler.GetAPIKey)
+			apiKey.DELETE("/:id", apiKeyHandler.RevokeAPIKey)
+			apiKey.GET("/:id/audit-logs", apiKeyHandler.ListAPIKeyAuditLogs)
+		}
+
+		v1.GET("/notification/stream", middleware.StreamTicketMiddleware(streamTickets, authMiddleware), notificationHandler.StreamNotifications)
+
+		notification := v1.Group("/notification").Use(authMiddleware)
 		{
 			notification.GET("", notificationHandler.ListNotifications)
+			notification.POST("/stream/ticket", notificationHandler.CreateStreamTicket)
+			notification.GET("/unread-count", notificationHandler.CountUnreadNotifications)
+			notification.POST("/read", notificationHandler.MarkNotificationsRead)
+			notification.POST("/read-all", notificationHandler.MarkAllNotificationsRead)
+			notification.POST("/bulk-delete", notificationHandler.DeleteNotifications)
+			notification.GET("/preferences", notificationHandler.ListNotificationPreferences)
+			notification.PUT("/preferences", notificationHandler.UpdateNotificationPreference)
 			notification.GET("/:id", notificationHandler.GetNotificationByID)
 			notification.PUT("/:id", notificationHandler.UpdateNotificationStatus)
 			notification.DELETE("/:id", notificationHandler.DeleteNotification)
-			notification.POST("", notificationHandler.CreateNotification)
+			notification.POST("", require(domain.ActionNotificationSend), notificationHandler.CreateNotification)
 		}
 
 		attendance := v1.Group("/attendance")
 		{
-			att := attendance.Use(middleware.AuthMiddleware(token))
-			att.GET("", attendanceHandler.ListAttendance)
-			att.POST("", attendanceHandler.CreateAttendance)
-			att.GET("/:id", attendanceHandler.GetAttendance)
-			att.PUT("/:id", attendanceHandler.UpdateAttendance)
-			att.DELETE("/:id", attendanceHandler.DeleteAttendance)
-			att.GET("/status", attendanceHandler.GetUsersAttendanceStatus)
+			att := attendance.Use(authMiddleware)
+			att.GET("", requireOrKey(domain.ActionAttendanceRead), attendanceHandler.ListAttendance)
+			att.POST("", require(domain.ActionAttendanceRecord), attendanceHandler.CreateAttendance)
+			att.GET("/:id", requireOrKey(domain.ActionAttendanceRead), attendanceHandler.GetAttendance)
+			att.PUT("/:id", requireOrKey(domain.ActionAttendanceWrite), attendanceHandler.UpdateAttendance)
+			att.DELETE("/:id", requireOrKey(domain.ActionAttendanceWrite), attendanceHandler.DeleteAttendance)
+			att.GET("/status", requireOrKey(domain.ActionAttendanceRead), attendanceHandler.GetUsersAttendanceStatus)
 		}
 
 		leave := v1.Group("/leave")
 		{
-			leaveUser := leave.Group("").Use(middleware.AuthMiddleware(token))
-			leaveUser.GET("", leaveHandler.ListLeaves)
-			leaveUser.POST("", leaveHandler.CreateLeave)
-			leaveUser.GET("/:id", leaveHandler.GetLeave)
-			leaveUser.PUT("/:id", leaveHandler.UpdateLeave)
-			leaveUser.DELETE("/:id", leaveHandler.DeleteLeave)
-
-			leaveAdmin := leave.Group("/admin").Use(middleware.AuthMiddleware(token), middleware.VerifyRole(domain.Admin, domain.HR))
-			leaveAdmin.GET("/balance", leaveHandler.GetLeaveBalance)
-			leaveAdmin.POST("/approve/:id", leaveHandler.ApproveLeave)
-			leaveAdmin.POST("/reject/:id", leaveHandler.RejectLeave)
+			leaveUser := leave.Group("").Use(authMiddleware)
+			leaveUser.GET("", requireOrKey(domain.ActionLeaveRead), leaveHandler.ListLeaves)
+			leaveUser.GET("/calendar", require(domain.ActionLeaveRead), leaveHandler.GetLeaveCalendar)
+			leaveUser.POST("", require(domain.ActionLeaveWrite), leaveHandler.CreateLeave)
+			leaveUser.GET("/:id", requireOrKey(domain.ActionLeaveRead), leaveHandler.GetLeave)
+			leaveUser.PUT("/:id", require(domain.ActionLeaveWrite), leaveHandler.UpdateLeave)
+			leaveUser.DELETE("/:id", require(domain.ActionLeaveWrite), leaveHandler.DeleteLeave)
+			leaveUser.POST("/:id/attachments", require(domain.ActionLeaveWrite), leaveHandler.UploadLeaveAttachments)
+
+			leaveAdmin := leave.Group("/admin").Use(authMiddleware)
+			leaveAdmin.GET("/balance", require(domain.ActionLeaveApprove), leaveHandler.GetLeaveBalance)
+			leaveAdmin.POST("/approve/:id", require(domain.ActionLeaveApprove), leaveHandler.ApproveLeave)
+			leaveAdmin.POST("/reject/:id", require(domain.ActionLeaveApprove), leaveHandler.RejectLeave)
+			leaveAdmin.GET("/attachments/:id", requireOrKey(domain.ActionLeaveApprove), leaveHandler.ListLeaveAttachments)
+			leaveAdmin.GET("/coverage/:id", requireOrKey(domain.ActionLeaveApprove), leaveHandler.GetLeaveCoverage)
 		}
 
-		department := v1.Group("/department")
+		department := v1.Group("/department").Use(authMiddleware, requireOrKey(domain.ActionDepartmentRead))
 		{
 			department.GET("", departmentHandler.ListDepartments)
 		}
 
-		schedule := v1.Group("/schedule").Use(middleware.AuthMiddleware(token))
+		schedule := v1.Group("/schedule").Use(authMiddleware)
+		{
+			scheduleRead := require(domain.ActionScheduleRead)
+			scheduleReadOrKey := requireOrKey(domain.ActionScheduleRead)
+			scheduleWriteOrKey := requireOrKey(domain.ActionScheduleWrite)
+			schedule.GET("", scheduleReadOrKey, scheduleHandler.ListSchedules)
+			schedule.POST("", scheduleReadOrKey, scheduleWriteOrKey, scheduleHandler.CreateSchedule)
+			schedule.GET("/:id", scheduleReadOrKey, scheduleHandler.GetSchedule)
+			schedule.PUT("/:id", scheduleReadOrKey, scheduleWriteOrKey, scheduleHandler.UpdateSchedule)
+			schedule.DELETE("/:id", scheduleReadOrKey, scheduleWriteOrKey, scheduleHandler.DeleteSchedule)
+			schedule.GET("/rotation", scheduleRead, scheduleHandler.GetWorkRotation)
+			schedule.GET("/calendar", scheduleRead, scheduleHandler.GetWorkCalendar)
+			schedule.POST("/feed", scheduleRead, scheduleHandler.CreateCalendarFeed)
+			schedule.DELETE("/feed", scheduleRead, scheduleHandler.RevokeCalendarFeed)
+			schedule.POST("/swap", scheduleRead, scheduleHandler.RequestScheduleSwap)
+			schedule.GET("/swap", scheduleRead, scheduleHandler.ListScheduleSwaps)
+			schedule.POST("/swap/:id/respond", scheduleRead, scheduleHandler.RespondScheduleSwap)
+			schedule.POST("/swap/:id/cancel", scheduleRead, scheduleHandler.CancelScheduleSwap)
+			schedule.GET("/open-shifts", scheduleRead, scheduleHandler.ListAvailableOpenShifts)
+			schedule.POST("/open-shifts/:id/claim", scheduleRead, scheduleHandler.ClaimOpenShift)
+		}
+
+		// calendar clients cannot send an Authorization header, the feed token authenticates them
+		v1.GET("/calendar/:token", scheduleHandler.GetCalendarFeed)
+
+		scheduleAdmin := v1.Group("/schedule/admin").Use(authMiddleware)
+		{
+			scheduleManage := require(domain.ActionScheduleManage)
+			scheduleManageOrKey := requireOrKey(domain.ActionScheduleManage)
+			scheduleAdmin.GET("/swap", scheduleManageOrKey, scheduleHandler.ListPendingScheduleSwaps)
+			scheduleAdmin.POST("/swap/:id/approve", scheduleManage, scheduleHandler.ApproveScheduleSwap)
+			scheduleAdmin.POST("/swap/:id/reject", scheduleManage, scheduleHandler.RejectScheduleSwap)
+			scheduleAdmin.GET("/templates", scheduleManageOrKey, scheduleHandler.ListShiftTemplates)
+			scheduleAdmin.POST("/templates", scheduleManageOrKey, scheduleHandler.CreateShiftTemplate)
+			scheduleAdmin.DELETE("/templates/:id", scheduleManageOrKey, scheduleHandler.DeleteShiftTemplate)
+			scheduleAdmin.POST("/bulk", scheduleManageOrKey, scheduleHandler.GenerateSchedules)
+			scheduleAdmin.GET("/rules", scheduleManageOrKey, scheduleHandler.ListScheduleRules)
+			scheduleAdmin.POST("/rules", scheduleManageOrKey, scheduleHandler.CreateScheduleRule)
+			scheduleAdmin.DELETE("/rules/:id", scheduleManageOrKey, scheduleHandler.DeleteScheduleRule)
+			scheduleAdmin.GET("/open-shifts", scheduleManageOrKey, scheduleHandler.ListOpenShifts)
+			scheduleAdmin.POST("/open-shifts", scheduleManage, scheduleHandler.PostOpenShift)
+			scheduleAdmin.GET("/open-shifts/:id/claims", scheduleManageOrKey, scheduleHandler.ListOpenShiftClaims)
+			scheduleAdmin.POST("/open-shifts/:id/cancel", scheduleManage, scheduleHandler.CancelOpenShift)
+			scheduleAdmin.POST("/open-shifts/claims/:id/confirm", scheduleManage, scheduleHandler.ConfirmOpenShiftClaim)
+		}
+
+		rotation := v1.Group("/rotation").Use(authMiddleware, requireOrKey(domain.ActionRotationManage))
 		{
-			schedule.GET("", scheduleHandler.ListSchedules)
-			schedule.POST("", scheduleHandler.CreateSchedule)
-			schedule.GET("/:id", scheduleHandler.GetSchedule)
-			schedule.PUT("/:id", scheduleHandler.UpdateSchedule)
-			schedule.DELETE("/:id", scheduleHandler.DeleteSchedule)
-			schedule.GET("/rotation", scheduleHandler.GetWorkRotation)
-			schedule.GET("/calendar", scheduleHandler.GetWorkCalendar)
-			schedule.POST("/swap", scheduleHandler.RequestScheduleSwap)
+			rotation.GET("/patterns", rotationHandler.ListRotationPatterns)
+			rotation.POST("/patterns", rotationHandler.CreateRotationPattern)
+			rotation.GET("/patterns/:id", rotationHandler.GetRotationPattern)
+			rotation.DELETE("/patterns/:id", rotationHandler.DeleteRotationPattern)
+			rotation.POST("/assignments", rotationHandler.AssignRotation)
+			rotation.GET("/assignments/user/:userId", rotationHandler.ListRotationAssignments)
+			rotation.DELETE("/assignments/:id", rotationHandler.DeleteRotationAssignment)
 		}
 
-		monitoring := v1.Group("/monitoring").Use(middleware.AuthMiddleware(token), middleware.VerifyRole(domain.HR, domain.HR, domain.Admin))
+		monitoring := v1.Group("/monitoring").Use(authMiddleware, requireOrKey(domain.ActionMonitoringRead))
 		{
 			monitoring.GET("/reports", monitoringHandler.GetReports)
 			monitoring.GET("/summary", monitoringHandler.GetSummary)
 			monitoring.GET("/dashboard", monitoringHandler.GetDashboardAnalytics)
 			monitoring.GET("/attendance-report", monitoringHandler.GenerateAttendanceReport)
+			monitoring.GET("/anomalies", monitoringHandler.DetectAnomalies)
 			monitoring.GET("/export", monitoringHandler.ExportData)
 		}
 	}
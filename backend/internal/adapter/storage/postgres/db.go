@@ -3,6 +3,7 @@ package postgres
 import (
 	"context"
 	"embed"
+	"errors"
 	"fmt"
 
 	"github.com/Masterminds/squirrel"
@@ -82,9 +83,19 @@ func (db *DB) Migrate() error {
 	return nil
 }
 
-// ErrorCode returns the error code of the given error
+// PostgreSQL error codes checked by the repositories
+const (
+	ForeignKeyViolationCode = "23503"
+	UniqueViolationCode     = "23505"
+)
+
+// ErrorCode returns the error code of the given error, or an empty string if it is not a PostgreSQL error
 func (db *DB) ErrorCode(err error) string {
-	pgErr := err.(*pgconn.PgError)
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return ""
+	}
+
 	return pgErr.Code
 }
 
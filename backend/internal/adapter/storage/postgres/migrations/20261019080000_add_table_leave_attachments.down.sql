@@ -0,0 +1 @@
+DROP TABLE IF EXISTS leave_attachments;
\ No newline at end of file
@@ -0,0 +1,14 @@
+CREATE TABLE leave_attachments (
+    id UUID PRIMARY KEY,
+    leave_request_id UUID NOT NULL,
+    file_name VARCHAR(255) NOT NULL,
+    object_key TEXT NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    size BIGINT NOT NULL,
+    uploaded_by UUID,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (leave_request_id) REFERENCES leave_requests (id) ON DELETE CASCADE,
+    FOREIGN KEY (uploaded_by) REFERENCES users (id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_leave_attachments_leave_request_id ON leave_attachments (leave_request_id);
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS schedule_swap_requests;
\ No newline at end of file
@@ -0,0 +1,35 @@
+CREATE TABLE schedule_swap_requests (
+    id UUID PRIMARY KEY,
+    requestor_id UUID NOT NULL,
+    target_user_id UUID NOT NULL,
+    proposed_schedule_id UUID NOT NULL,
+    target_schedule_id UUID NOT NULL,
+    reason TEXT,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (
+        status IN (
+            'pending',
+            'accepted',
+            'declined',
+            'approved',
+            'rejected',
+            'cancelled'
+        )
+    ),
+    responded_at TIMESTAMPTZ,
+    reviewed_by UUID,
+    reviewed_at TIMESTAMPTZ,
+    note TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (requestor_id) REFERENCES users (id) ON DELETE CASCADE,
+    FOREIGN KEY (target_user_id) REFERENCES users (id) ON DELETE CASCADE,
+    FOREIGN KEY (proposed_schedule_id) REFERENCES schedules (id) ON DELETE CASCADE,
+    FOREIGN KEY (target_schedule_id) REFERENCES schedules (id) ON DELETE CASCADE,
+    FOREIGN KEY (reviewed_by) REFERENCES users (id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_schedule_swap_requests_requestor_id ON schedule_swap_requests (requestor_id);
+
+CREATE INDEX idx_schedule_swap_requests_target_user_id ON schedule_swap_requests (target_user_id);
+
+CREATE INDEX idx_schedule_swap_requests_status ON schedule_swap_requests (status);
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS rotation_patterns;
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS rotation_assignments;
\ No newline at end of file
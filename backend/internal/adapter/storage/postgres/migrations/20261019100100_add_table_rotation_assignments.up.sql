@@ -0,0 +1,16 @@
+CREATE TABLE rotation_assignments (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL,
+    rotation_pattern_id UUID NOT NULL,
+    start_date DATE NOT NULL,
+    end_date DATE,
+    work_location_id UUID,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK (end_date IS NULL OR end_date >= start_date),
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    FOREIGN KEY (rotation_pattern_id) REFERENCES rotation_patterns (id) ON DELETE RESTRICT,
+    FOREIGN KEY (work_location_id) REFERENCES work_locations (id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_rotation_assignments_user_id ON rotation_assignments (user_id);
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS shift_templates;
\ No newline at end of file
@@ -0,0 +1,19 @@
+CREATE TABLE shift_templates (
+    id UUID PRIMARY KEY,
+    name VARCHAR(100) NOT NULL UNIQUE,
+    shift_start TIME NOT NULL,
+    shift_end TIME NOT NULL,
+    break_start TIME,
+    break_end TIME,
+    work_location_id UUID,
+    schedule_type VARCHAR(20) NOT NULL DEFAULT 'regular' CHECK (
+        schedule_type IN (
+            'regular',
+            'shift',
+            'flexible'
+        )
+    ),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (work_location_id) REFERENCES work_locations (id) ON DELETE SET NULL
+);
\ No newline at end of file
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_attendances_user_id_business_date;
+
+ALTER TABLE attendances DROP COLUMN IF EXISTS schedule_id, DROP COLUMN IF EXISTS business_date;
\ No newline at end of file
@@ -0,0 +1,10 @@
+ALTER TABLE attendances
+ADD COLUMN business_date DATE,
+ADD COLUMN schedule_id UUID,
+ADD FOREIGN KEY (schedule_id) REFERENCES schedules (id) ON DELETE SET NULL;
+
+UPDATE attendances SET business_date = DATE(time);
+
+ALTER TABLE attendances ALTER COLUMN business_date SET NOT NULL;
+
+CREATE INDEX idx_attendances_user_id_business_date ON attendances (user_id, business_date);
\ No newline at end of file
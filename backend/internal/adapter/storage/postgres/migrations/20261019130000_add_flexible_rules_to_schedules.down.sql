@@ -0,0 +1,16 @@
+ALTER TABLE attendances DROP CONSTRAINT IF EXISTS attendances_status_check;
+
+UPDATE attendances SET status = 'present' WHERE status IN ('core_hours_violation', 'short_day');
+
+ALTER TABLE attendances ADD CONSTRAINT attendances_status_check CHECK (
+    status IN (
+        'present',
+        'absent',
+        'late',
+        'leave'
+    )
+);
+
+ALTER TABLE shift_templates DROP COLUMN IF EXISTS min_duration_minutes, DROP COLUMN IF EXISTS core_end, DROP COLUMN IF EXISTS core_start;
+
+ALTER TABLE schedules DROP COLUMN IF EXISTS min_duration_minutes, DROP COLUMN IF EXISTS core_end, DROP COLUMN IF EXISTS core_start;
\ No newline at end of file
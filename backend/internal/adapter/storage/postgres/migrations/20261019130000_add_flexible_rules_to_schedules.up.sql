@@ -0,0 +1,22 @@
+ALTER TABLE schedules
+ADD COLUMN core_start TIME,
+ADD COLUMN core_end TIME,
+ADD COLUMN min_duration_minutes INTEGER CHECK (min_duration_minutes > 0);
+
+ALTER TABLE shift_templates
+ADD COLUMN core_start TIME,
+ADD COLUMN core_end TIME,
+ADD COLUMN min_duration_minutes INTEGER CHECK (min_duration_minutes > 0);
+
+ALTER TABLE attendances DROP CONSTRAINT IF EXISTS attendances_status_check;
+
+ALTER TABLE attendances ADD CONSTRAINT attendances_status_check CHECK (
+    status IN (
+        'present',
+        'absent',
+        'late',
+        'leave',
+        'core_hours_violation',
+        'short_day'
+    )
+);
\ No newline at end of file
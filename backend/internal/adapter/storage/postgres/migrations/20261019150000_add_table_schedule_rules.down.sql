@@ -0,0 +1 @@
+DROP TABLE IF EXISTS schedule_rules;
\ No newline at end of file
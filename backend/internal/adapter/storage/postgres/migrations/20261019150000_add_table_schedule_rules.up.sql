@@ -0,0 +1,12 @@
+CREATE TABLE schedule_rules (
+    id UUID PRIMARY KEY,
+    department_id UUID UNIQUE,
+    country VARCHAR(100) UNIQUE,
+    min_rest_hours INTEGER NOT NULL DEFAULT 0 CHECK (min_rest_hours >= 0),
+    max_weekly_hours INTEGER NOT NULL DEFAULT 0 CHECK (max_weekly_hours >= 0),
+    max_consecutive_days INTEGER NOT NULL DEFAULT 0 CHECK (max_consecutive_days >= 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK ((department_id IS NULL) <> (country IS NULL)),
+    FOREIGN KEY (department_id) REFERENCES departments (id) ON DELETE CASCADE
+);
\ No newline at end of file
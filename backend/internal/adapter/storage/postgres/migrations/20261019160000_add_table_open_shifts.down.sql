@@ -0,0 +1 @@
+DROP TABLE IF EXISTS open_shifts;
\ No newline at end of file
@@ -0,0 +1,31 @@
+CREATE TABLE open_shifts (
+    id UUID PRIMARY KEY,
+    schedule_id UUID NOT NULL,
+    department_id UUID NOT NULL,
+    work_location_id UUID,
+    status VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (
+        status IN (
+            'open',
+            'filled',
+            'cancelled'
+        )
+    ),
+    note TEXT,
+    posted_by UUID,
+    filled_by UUID,
+    reviewed_by UUID,
+    reviewed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (schedule_id) REFERENCES schedules (id) ON DELETE CASCADE,
+    FOREIGN KEY (department_id) REFERENCES departments (id) ON DELETE CASCADE,
+    FOREIGN KEY (work_location_id) REFERENCES work_locations (id) ON DELETE SET NULL,
+    FOREIGN KEY (posted_by) REFERENCES users (id) ON DELETE SET NULL,
+    FOREIGN KEY (filled_by) REFERENCES users (id) ON DELETE SET NULL,
+    FOREIGN KEY (reviewed_by) REFERENCES users (id) ON DELETE SET NULL
+);
+
+-- a schedule can only be on offer once at a time
+CREATE UNIQUE INDEX idx_open_shifts_schedule_id_open ON open_shifts (schedule_id) WHERE status = 'open';
+
+CREATE INDEX idx_open_shifts_department_id_status ON open_shifts (department_id, status);
\ No newline at end of file
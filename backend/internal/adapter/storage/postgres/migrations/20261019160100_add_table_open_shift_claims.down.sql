@@ -0,0 +1 @@
+DROP TABLE IF EXISTS open_shift_claims;
\ No newline at end of file
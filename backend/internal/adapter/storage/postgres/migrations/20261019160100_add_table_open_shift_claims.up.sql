@@ -0,0 +1,19 @@
+CREATE TABLE open_shift_claims (
+    id UUID PRIMARY KEY,
+    open_shift_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (
+        status IN (
+            'pending',
+            'confirmed',
+            'rejected'
+        )
+    ),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (open_shift_id, user_id),
+    FOREIGN KEY (open_shift_id) REFERENCES open_shifts (id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_open_shift_claims_user_id ON open_shift_claims (user_id);
\ No newline at end of file
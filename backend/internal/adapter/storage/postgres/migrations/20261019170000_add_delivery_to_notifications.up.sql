@@ -0,0 +1,15 @@
+ALTER TABLE notifications
+ADD COLUMN status VARCHAR(10) NOT NULL DEFAULT 'delivered' CHECK (
+    status IN ('pending', 'delivered', 'failed')
+),
+ADD COLUMN attempts INTEGER NOT NULL DEFAULT 0,
+ADD COLUMN last_error TEXT,
+ADD COLUMN next_attempt_at TIMESTAMP,
+ADD COLUMN delivered_at TIMESTAMP;
+
+-- notifications created before the dispatcher existed were shown right away
+UPDATE notifications SET delivered_at = send_at;
+
+ALTER TABLE notifications ALTER COLUMN status SET DEFAULT 'pending';
+
+CREATE INDEX idx_notifications_pending ON notifications (COALESCE(next_attempt_at, send_at)) WHERE status = 'pending';
\ No newline at end of file
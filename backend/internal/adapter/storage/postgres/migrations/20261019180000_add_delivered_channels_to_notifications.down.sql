@@ -0,0 +1 @@
+ALTER TABLE notifications DROP COLUMN IF EXISTS delivered_channels;
\ No newline at end of file
@@ -0,0 +1,5 @@
+ALTER TABLE notifications
+ADD COLUMN delivered_channels VARCHAR(10)[] NOT NULL DEFAULT '{}';
+
+-- delivered notifications so far were only shown in the app
+UPDATE notifications SET delivered_channels = '{in_app}' WHERE status = 'delivered';
\ No newline at end of file
@@ -0,0 +1,12 @@
+CREATE TABLE notification_preferences (
+    user_id UUID NOT NULL,
+    type VARCHAR(10) CHECK (
+        type IN ('reminder', 'warning', 'info')
+    ) NOT NULL,
+    channels VARCHAR(10)[] NOT NULL,
+    webhook_url TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, type),
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
\ No newline at end of file
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_notifications_user_listed;
+
+DROP INDEX IF EXISTS idx_notifications_user_unread;
+
+ALTER TABLE notifications
+ALTER COLUMN is_read DROP NOT NULL;
\ No newline at end of file
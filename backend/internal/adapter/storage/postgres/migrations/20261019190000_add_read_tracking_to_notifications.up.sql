@@ -0,0 +1,8 @@
+UPDATE notifications SET is_read = false WHERE is_read IS NULL;
+
+ALTER TABLE notifications
+ALTER COLUMN is_read SET NOT NULL;
+
+CREATE INDEX idx_notifications_user_unread ON notifications (user_id) WHERE NOT is_read;
+
+CREATE INDEX idx_notifications_user_listed ON notifications (user_id, COALESCE(delivered_at, send_at) DESC, id DESC);
\ No newline at end of file
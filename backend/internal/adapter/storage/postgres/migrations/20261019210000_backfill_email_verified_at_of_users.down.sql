@@ -0,0 +1,2 @@
+-- the verification dates set by the up migration cannot be told apart from real ones and are kept
+SELECT 1;
\ No newline at end of file
@@ -0,0 +1,4 @@
+-- accounts created before email verification existed are treated as verified
+UPDATE users
+SET email_verified_at = created_at
+WHERE email_verified_at IS NULL;
\ No newline at end of file
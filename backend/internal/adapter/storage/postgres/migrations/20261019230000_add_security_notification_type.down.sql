@@ -0,0 +1,12 @@
+DELETE FROM notifications WHERE type = 'security';
+DELETE FROM notification_preferences WHERE type = 'security';
+
+ALTER TABLE notifications DROP CONSTRAINT notifications_type_check;
+ALTER TABLE notifications ADD CONSTRAINT notifications_type_check CHECK (
+    type IN ('reminder', 'warning', 'info')
+);
+
+ALTER TABLE notification_preferences DROP CONSTRAINT notification_preferences_type_check;
+ALTER TABLE notification_preferences ADD CONSTRAINT notification_preferences_type_check CHECK (
+    type IN ('reminder', 'warning', 'info')
+);
\ No newline at end of file
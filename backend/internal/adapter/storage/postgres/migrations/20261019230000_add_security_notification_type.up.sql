@@ -0,0 +1,9 @@
+ALTER TABLE notifications DROP CONSTRAINT notifications_type_check;
+ALTER TABLE notifications ADD CONSTRAINT notifications_type_check CHECK (
+    type IN ('reminder', 'warning', 'info', 'security')
+);
+
+ALTER TABLE notification_preferences DROP CONSTRAINT notification_preferences_type_check;
+ALTER TABLE notification_preferences ADD CONSTRAINT notification_preferences_type_check CHECK (
+    type IN ('reminder', 'warning', 'info', 'security')
+);
\ No newline at end of file
@@ -0,0 +1,63 @@
+CREATE TABLE role_permissions (
+    role VARCHAR(20) NOT NULL CHECK (
+        role IN (
+            'admin',
+            'hr',
+            'manager',
+            'employee'
+        )
+    ),
+    permission VARCHAR(100) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (role, permission)
+);
+
+INSERT INTO
+    role_permissions (role, permission)
+VALUES ('admin', 'users:manage:any'),
+    ('admin', 'permissions:manage:any'),
+    ('admin', 'attendance:record:own'),
+    ('admin', 'attendance:read:any'),
+    ('admin', 'attendance:write:any'),
+    ('admin', 'leave:read:any'),
+    ('admin', 'leave:write:any'),
+    ('admin', 'leave:approve:any'),
+    ('admin', 'schedule:read:any'),
+    ('admin', 'schedule:write:any'),
+    ('admin', 'schedule:manage:any'),
+    ('admin', 'rotation:manage:any'),
+    ('admin', 'monitoring:read:any'),
+    ('admin', 'department:read:any'),
+    ('admin', 'notification:send:any'),
+    ('hr', 'users:manage:any'),
+    ('hr', 'attendance:record:own'),
+    ('hr', 'attendance:read:any'),
+    ('hr', 'attendance:write:any'),
+    ('hr', 'leave:read:any'),
+    ('hr', 'leave:write:own'),
+    ('hr', 'leave:approve:any'),
+    ('hr', 'schedule:read:any'),
+    ('hr', 'schedule:write:any'),
+    ('hr', 'schedule:manage:any'),
+    ('hr', 'rotation:manage:any'),
+    ('hr', 'monitoring:read:any'),
+    ('hr', 'department:read:any'),
+    ('hr', 'notification:send:any'),
+    ('manager', 'attendance:record:own'),
+    ('manager', 'attendance:read:team'),
+    ('manager', 'attendance:write:team'),
+    ('manager', 'leave:read:team'),
+    ('manager', 'leave:write:own'),
+    ('manager', 'leave:approve:team'),
+    ('manager', 'schedule:read:team'),
+    ('manager', 'schedule:write:team'),
+    ('manager', 'schedule:manage:any'),
+    ('manager', 'rotation:manage:any'),
+    ('manager', 'department:read:any'),
+    ('manager', 'notification:send:any'),
+    ('employee', 'attendance:record:own'),
+    ('employee', 'attendance:read:own'),
+    ('employee', 'leave:read:own'),
+    ('employee', 'leave:write:own'),
+    ('employee', 'schedule:read:own'),
+    ('employee', 'department:read:any');
\ No newline at end of file
@@ -0,0 +1,5 @@
+DELETE FROM role_permissions WHERE permission = 'api_keys:manage:any';
+
+DROP TABLE IF EXISTS api_key_audit_logs;
+
+DROP TABLE IF EXISTS api_keys;
\ No newline at end of file
@@ -0,0 +1,30 @@
+CREATE TABLE api_keys (
+    id UUID PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    prefix VARCHAR(20) NOT NULL,
+    key_hash VARCHAR(64) NOT NULL UNIQUE,
+    permissions VARCHAR(100)[] NOT NULL DEFAULT '{}',
+    created_by UUID,
+    expires_at TIMESTAMPTZ NOT NULL,
+    last_used_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (created_by) REFERENCES users (id) ON DELETE SET NULL
+);
+
+CREATE TABLE api_key_audit_logs (
+    id UUID PRIMARY KEY,
+    api_key_id UUID NOT NULL,
+    method VARCHAR(10) NOT NULL,
+    path TEXT NOT NULL,
+    status_code INT NOT NULL,
+    client_ip VARCHAR(45) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (api_key_id) REFERENCES api_keys (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_api_key_audit_logs_key_created ON api_key_audit_logs (api_key_id, created_at DESC);
+
+INSERT INTO
+    role_permissions (role, permission)
+VALUES ('admin', 'api_keys:manage:any');
\ No newline at end of file
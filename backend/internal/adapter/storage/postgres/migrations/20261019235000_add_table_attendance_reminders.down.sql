@@ -0,0 +1 @@
+DROP TABLE IF EXISTS attendance_reminders;
\ No newline at end of file
@@ -0,0 +1,9 @@
+CREATE TABLE attendance_reminders (
+    id UUID PRIMARY KEY,
+    schedule_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    type VARCHAR(20) NOT NULL,
+    sent_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (schedule_id) REFERENCES schedules (id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
\ No newline at end of file
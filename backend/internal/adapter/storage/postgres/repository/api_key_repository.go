@@ -0,0 +1,234 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/aldotp/employee-attendance-system/internal/adapter/storage/postgres"
+	"github.com/aldotp/employee-attendance-system/internal/core/domain"
+	"github.com/aldotp/employee-attendance-system/pkg/consts"
+	"github.com/jackc/pgx/v5"
+)
+
+type APIKeyRepository struct {
+	db *postgres.DB
+}
+
+func NewAPIKeyRepository(db *postgres.DB) *APIKeyRepository {
+	return &APIKeyRepository{
+		db: db,
+	}
+}
+
+const apiKeyColumns = "id, name, prefix, key_hash, permissions, COALESCE(created_by::text, ''), expires_at, last_used_at, revoked_at, created_at"
+
+func (ar *APIKeyRepository) CreateAPIKey(ctx context.Context, key *domain.APIKey) error {
+	query := ar.db.QueryBuilder.Insert("api_keys").
+		Columns("id", "name", "prefix", "key_hash", "permissions", "created_by", "expires_at", "created_at").
+		Values(key.ID, key.Name, key.Prefix, key.KeyHash, permissionNames(key.Permissions), nullString(key.CreatedBy), key.ExpiresAt, key.CreatedAt)
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = ar.db.Exec(ctx, sql, args...)
+	return err
+}
+
+func (ar *APIKeyRepository) GetAPIKeyByID(ctx context.Context, id string) (*domain.APIKey, error) {
+	return ar.getAPIKey(ctx, sq.Eq{"id": id})
+}
+
+func (ar *APIKeyRepository) GetAPIKeyByHash(ctx context.Context, keyHash string) (*domain.APIKey, error) {
+	return ar.getAPIKey(ctx, sq.Eq{"key_hash": keyHash})
+}
+
+func (ar *APIKeyRepository) getAPIKey(ctx context.Context, where sq.Eq) (*domain.APIKey, error) {
+	query := ar.db.QueryBuilder.Select(apiKeyColumns).
+		From("api_keys").
+		Where(where)
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := scanAPIKey(ar.db.QueryRow(ctx, sql, args...))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, consts.ErrDataNotFound
+		}
+		return nil, err
+	}
+
+	return key, nil
+}
+
+func (ar *APIKeyRepository) ListAPIKeys(ctx context.Context) ([]domain.APIKey, error) {
+	query := ar.db.QueryBuilder.Select(apiKeyColumns).
+		From("api_keys").
+		OrderBy("created_at DESC")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := ar.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []domain.APIKey{}
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, *key)
+	}
+
+	return keys, rows.Err()
+}
+
+// RevokeAPIKey revokes a key that is not revoked yet
+func (ar *APIKeyRepository) RevokeAPIKey(ctx context.Context, id string, revokedAt time.Time) error {
+	query := ar.db.QueryBuilder.Update("api_keys").
+		Set("revoked_at", revokedAt).
+		Where(sq.Eq{"id": id, "revoked_at": nil})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	tag, err := ar.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return consts.ErrDataNotFound
+	}
+
+	return nil
+}
+
+func (ar *APIKeyRepository) RecordAPIKeyUsage(ctx context.Context, log *domain.APIKeyAuditLog) (err error) {
+	tx, err := ar.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+
+	insertQuery := ar.db.QueryBuilder.Insert("api_key_audit_logs").
+		Columns("id", "api_key_id", "method", "path", "status_code", "client_ip", "created_at").
+		Values(log.ID, log.APIKeyID, log.Method, log.Path, log.StatusCode, log.ClientIP, log.CreatedAt)
+
+	sql, args, err := insertQuery.ToSql()
+	if err != nil {
+		return err
+	}
+
+	if _, err = tx.Exec(ctx, sql, args...); err != nil {
+		return err
+	}
+
+	updateQuery := ar.db.QueryBuilder.Update("api_keys").
+		Set("last_used_at", log.CreatedAt).
+		Where(sq.Eq{"id": log.APIKeyID})
+
+	sql, args, err = updateQuery.ToSql()
+	if err != nil {
+		return err
+	}
+
+	if _, err = tx.Exec(ctx, sql, args...); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (ar *APIKeyRepository) ListAPIKeyAuditLogs(ctx context.Context, apiKeyID string, page, limit uint64) ([]domain.APIKeyAuditLog, error) {
+	query := ar.db.QueryBuilder.Select("id", "api_key_id", "method", "path", "status_code", "client_ip", "created_at").
+		From("api_key_audit_logs").
+		Where(sq.Eq{"api_key_id": apiKeyID}).
+		OrderBy("created_at DESC").
+		Limit(limit).
+		Offset((page - 1) * limit)
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := ar.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	logs := []domain.APIKeyAuditLog{}
+	for rows.Next() {
+		var log domain.APIKeyAuditLog
+		err := rows.Scan(
+			&log.ID,
+			&log.APIKeyID,
+			&log.Method,
+			&log.Path,
+			&log.StatusCode,
+			&log.ClientIP,
+			&log.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		logs = append(logs, log)
+	}
+
+	return logs, rows.Err()
+}
+
+func scanAPIKey(row pgx.Row) (*domain.APIKey, error) {
+	var key domain.APIKey
+	var permissions []string
+	err := row.Scan(
+		&key.ID,
+		&key.Name,
+		&key.Prefix,
+		&key.KeyHash,
+		&permissions,
+		&key.CreatedBy,
+		&key.ExpiresAt,
+		&key.LastUsedAt,
+		&key.RevokedAt,
+		&key.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	key.Permissions = make([]domain.Permission, 0, len(permissions))
+	for _, permission := range permissions {
+		key.Permissions = append(key.Permissions, domain.Permission(permission))
+	}
+
+	return &key, nil
+}
+
+func permissionNames(permissions []domain.Permission) []string {
+	names := make([]string, 0, len(permissions))
+	for _, permission := range permissions {
+		names = append(names, string(permission))
+	}
+
+	return names
+}
@@ -26,39 +26,22 @@ func NewAttendanceRepository(db *postgres.DB) *AttendanceRepository {
 func (ar *AttendanceRepository) CreateAttendance(ctx context.Context, attendance *domain.Attendance) (*domain.Attendance, error) {
 	query := ar.db.QueryBuilder.Insert("attendances").
 		Columns(
-			"id", "user_id", "time", "type", "status", "notes",
-			"latitude", "longitude", "selfie_url", "created_at", "updated_at",
+			"id", "user_id", "schedule_id", "time", "business_date", "type", "status", "notes",
+			"latitude", "longitude", "selfie_url", "hours_worked", "created_at", "updated_at",
 		).
 		Values(
-			attendance.ID, attendance.UserID, attendance.Time, attendance.Type, attendance.Status, attendance.Notes,
-			attendance.Latitude, attendance.Longitude, attendance.SelfieURL, attendance.CreatedAt, attendance.UpdatedAt,
+			attendance.ID, attendance.UserID, nullString(attendance.ScheduleID), attendance.Time, attendance.BusinessDate,
+			attendance.Type, attendance.Status, attendance.Notes, attendance.Latitude, attendance.Longitude,
+			attendance.SelfieURL, attendance.HoursWorked, attendance.CreatedAt, attendance.UpdatedAt,
 		).
-		Suffix("RETURNING *")
+		Suffix("RETURNING " + attendanceColumns)
 
 	sql, args, err := query.ToSql()
 	if err != nil {
 		return nil, err
 	}
 
-	err = ar.db.QueryRow(ctx, sql, args...).Scan(
-		&attendance.ID,
-		&attendance.UserID,
-		&attendance.Time,
-		&attendance.Type,
-		&attendance.Status,
-		&attendance.Notes,
-		&attendance.Latitude,
-		&attendance.Longitude,
-		&attendance.SelfieURL,
-		&attendance.CreatedAt,
-		&attendance.UpdatedAt,
-	)
-
-	if err != nil {
-		return nil, err
-	}
-
-	return attendance, nil
+	return scanAttendance(ar.db.QueryRow(ctx, sql, args...))
 }
 
 func (ar *AttendanceRepository) GetAttendanceHistory(ctx context.Context, userID string, startDate, endDate string) ([]domain.Attendance, error) {
@@ -74,13 +57,13 @@ func (ar *AttendanceRepository) GetAttendanceHistory(ctx context.Context, userID
 		return nil, fmt.Errorf("invalid end date format: %v", err)
 	}
 
-	query := ar.db.QueryBuilder.Select("a.id, a.user_id, a.time, a.type, a.status, a.notes, a.latitude, a.longitude, a.selfie_url, a.created_at, a.updated_at").
-		From("attendances a").
-		Join("employees e ON e.user_id = a.user_id").
+	// filter on the business date so the events of a night shift stay with the day it started
+	query := ar.db.QueryBuilder.Select(attendanceColumns).
+		From("attendances").
 		Where(sq.And{
-			sq.Eq{"a.user_id": userID},
-			sq.GtOrEq{"a.time": startTime},
-			sq.LtOrEq{"a.time": endTime},
+			sq.Eq{"user_id": userID},
+			sq.GtOrEq{"business_date": startTime},
+			sq.LtOrEq{"business_date": endTime},
 		}).
 		OrderBy("time DESC")
 
@@ -96,24 +79,75 @@ func (ar *AttendanceRepository) GetAttendanceHistory(ctx context.Context, userID
 	defer rows.Close()
 
 	for rows.Next() {
-		var attendance domain.Attendance
-		err := rows.Scan(
-			&attendance.ID,
-			&attendance.UserID,
-			&attendance.Time,
-			&attendance.Type,
-			&attendance.Status,
-			&attendance.Notes,
-			&attendance.Latitude,
-			&attendance.Longitude,
-			&attendance.SelfieURL,
-			&attendance.CreatedAt,
-			&attendance.UpdatedAt,
-		)
+		attendance, err := scanAttendance(rows)
 		if err != nil {
 			return attendances, err
 		}
-		attendances = append(attendances, attendance)
+		attendances = append(attendances, *attendance)
+	}
+
+	return attendances, nil
+}
+
+// ListAttendancesByBusinessDate retrieves the attendance events of every employee on a business date
+func (ar *AttendanceRepository) ListAttendancesByBusinessDate(ctx context.Context, date time.Time) ([]domain.Attendance, error) {
+	var attendances []domain.Attendance
+
+	query := ar.db.QueryBuilder.Select(attendanceColumns).
+		From("attendances").
+		Where(sq.Eq{"business_date": date}).
+		OrderBy("time ASC")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := ar.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		attendance, err := scanAttendance(rows)
+		if err != nil {
+			return nil, err
+		}
+		attendances = append(attendances, *attendance)
+	}
+
+	return attendances, nil
+}
+
+// ListAttendancesByUsers retrieves the attendance events of the given users between two business dates
+func (ar *AttendanceRepository) ListAttendancesByUsers(ctx context.Context, userIDs []string, startDate, endDate time.Time) ([]domain.Attendance, error) {
+	var attendances []domain.Attendance
+
+	query := ar.db.QueryBuilder.Select(attendanceColumns).
+		From("attendances").
+		Where(sq.Expr("user_id = ANY(?::uuid[])", userIDs)).
+		Where(sq.GtOrEq{"business_date": startDate}).
+		Where(sq.LtOrEq{"business_date": endDate}).
+		OrderBy("time ASC")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := ar.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		attendance, err := scanAttendance(rows)
+		if err != nil {
+			return nil, err
+		}
+		attendances = append(attendances, *attendance)
 	}
 
 	return attendances, nil
@@ -129,39 +163,23 @@ func (ar *AttendanceRepository) UpdateAttendance(ctx context.Context, attendance
 		Set("latitude", sq.Expr("COALESCE(?, latitude)", attendance.Latitude)).
 		Set("longitude", sq.Expr("COALESCE(?, longitude)", attendance.Longitude)).
 		Set("selfie_url", sq.Expr("COALESCE(?, selfie_url)", attendance.SelfieURL)).
+		Set("schedule_id", nullString(attendance.ScheduleID)).
+		Set("business_date", attendance.BusinessDate).
+		Set("hours_worked", attendance.HoursWorked).
 		Set("updated_at", attendance.UpdatedAt).
 		Where(sq.Eq{"id": attendance.ID}).
-		Suffix("RETURNING *")
+		Suffix("RETURNING " + attendanceColumns)
 
 	sql, args, err := query.ToSql()
 	if err != nil {
 		return nil, err
 	}
 
-	err = ar.db.QueryRow(ctx, sql, args...).Scan(
-		&attendance.ID,
-		&attendance.UserID,
-		&attendance.Time,
-		&attendance.Type,
-		&attendance.Status,
-		&attendance.Notes,
-		&attendance.Latitude,
-		&attendance.Longitude,
-		&attendance.SelfieURL,
-		&attendance.CreatedAt,
-		&attendance.UpdatedAt,
-	)
-	if err != nil {
-		return nil, err
-	}
-
-	return attendance, nil
+	return scanAttendance(ar.db.QueryRow(ctx, sql, args...))
 }
 
 func (ar *AttendanceRepository) GetAttendanceByID(ctx context.Context, id string) (*domain.Attendance, error) {
-	var attendance domain.Attendance
-
-	query := ar.db.QueryBuilder.Select("*").
+	query := ar.db.QueryBuilder.Select(attendanceColumns).
 		From("attendances").
 		Where(sq.Eq{"id": id}).
 		Limit(1)
@@ -171,19 +189,7 @@ func (ar *AttendanceRepository) GetAttendanceByID(ctx context.Context, id string
 		return nil, err
 	}
 
-	err = ar.db.QueryRow(ctx, sql, args...).Scan(
-		&attendance.ID,
-		&attendance.UserID,
-		&attendance.Time,
-		&attendance.Type,
-		&attendance.Status,
-		&attendance.Notes,
-		&attendance.Latitude,
-		&attendance.Longitude,
-		&attendance.SelfieURL,
-		&attendance.CreatedAt,
-		&attendance.UpdatedAt,
-	)
+	attendance, err := scanAttendance(ar.db.QueryRow(ctx, sql, args...))
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, consts.ErrDataNotFound
@@ -191,7 +197,7 @@ func (ar *AttendanceRepository) GetAttendanceByID(ctx context.Context, id string
 		return nil, err
 	}
 
-	return &attendance, nil
+	return attendance, nil
 }
 
 func (ar *AttendanceRepository) DeleteAttendance(ctx context.Context, id string) error {
@@ -207,7 +213,7 @@ func (ar *AttendanceRepository) DeleteAttendance(ctx context.Context, id string)
 	return err
 }
 
-func (ar *AttendanceRepository) ListAttendances(ctx context.Context, page, limit uint64, date string, attendanceType string) ([]domain.GetAttendanceResponse, error) {
+func (ar *AttendanceRepository) ListAttendances(ctx context.Context, page, limit uint64, date string, attendanceType string, filter domain.OwnershipFilter) ([]domain.GetAttendanceResponse, error) {
 	var data []domain.GetAttendanceResponse
 
 	if limit == 0 {
@@ -222,6 +228,7 @@ func (ar *AttendanceRepository) ListAttendances(ctx context.Context, page, limit
 		"COALESCE(u.email, '') AS email",
 		"COALESCE(d.name, '') AS department_name",
 		"a.time",
+		"a.business_date",
 		"a.latitude",
 		"a.longitude",
 		"a.selfie_url",
@@ -240,13 +247,15 @@ func (ar *AttendanceRepository) ListAttendances(ctx context.Context, page, limit
 		Offset((page - 1) * limit)
 
 	if date != "" {
-		query = query.Where(sq.Eq{"DATE(a.time)": date})
+		query = query.Where(sq.Eq{"a.business_date": date})
 	}
 
 	if attendanceType != "" {
 		query = query.Where(sq.Eq{"a.type": attendanceType})
 	}
 
+	query = whereOwnership(query, filter, "a.user_id")
+
 	sql, args, err := query.ToSql()
 	if err != nil {
 		return nil, err
@@ -265,6 +274,7 @@ func (ar *AttendanceRepository) ListAttendances(ctx context.Context, page, limit
 			&attendance.Email,
 			&attendance.Department,
 			&attendance.Time,
+			&attendance.BusinessDate,
 			&attendance.Latitude,
 			&attendance.Longitude,
 			&attendance.SelfieURL,
@@ -292,7 +302,7 @@ func (r *AttendanceRepository) GetUsersAttendanceStatus(ctx context.Context, dat
 		LEFT JOIN employees e ON u.id = e.user_id
         LEFT JOIN attendances a 
             ON u.id = a.user_id 
-            AND DATE(a.time) = $1
+            AND a.business_date = $1
     `
 
 	rows, err := r.db.Query(ctx, query, date)
@@ -317,3 +327,99 @@ func (r *AttendanceRepository) GetUsersAttendanceStatus(ctx context.Context, dat
 
 	return statusMap, nil
 }
+
+// GetOpenCheckIn retrieves the latest check-in of a user made between since and before that
+// has no check-out after it yet, not counting the check-out checkOutID being placed
+func (ar *AttendanceRepository) GetOpenCheckIn(ctx context.Context, userID, checkOutID string, since, before time.Time) (*domain.Attendance, error) {
+	query := ar.db.QueryBuilder.Select(attendanceColumns).
+		From("attendances a").
+		Where(sq.And{
+			sq.Eq{"a.user_id": userID},
+			sq.Eq{"a.type": domain.AttendanceCheckIn},
+			sq.GtOrEq{"a.time": since},
+			sq.LtOrEq{"a.time": before},
+			sq.Expr("NOT EXISTS (SELECT 1 FROM attendances o WHERE o.user_id = a.user_id AND o.type = ? AND o.time > a.time AND o.time <= ? AND o.id <> ?)",
+				domain.AttendanceCheckOut, before, checkOutID),
+		}).
+		OrderBy("a.time DESC").
+		Limit(1)
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	attendance, err := scanAttendance(ar.db.QueryRow(ctx, sql, args...))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, consts.ErrDataNotFound
+		}
+		return nil, err
+	}
+
+	return attendance, nil
+}
+
+// MarkReminderSent records that the reminder was sent, reporting false when it already was
+func (ar *AttendanceRepository) MarkReminderSent(ctx context.Context, id, scheduleID, userID string, reminderType domain.NotificationType, sentAt time.Time) (bool, error) {
+	query := ar.db.QueryBuilder.Insert("attendance_reminders").
+		Columns("id", "schedule_id", "user_id", "type", "sent_at").
+		Values(id, scheduleID, userID, reminderType, sentAt).
+		Suffix("ON CONFLICT (id) DO NOTHING")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return false, err
+	}
+
+	tag, err := ar.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+// UnmarkReminderSent forgets a reminder whose notification could not be created, so the next
+// run tries again
+func (ar *AttendanceRepository) UnmarkReminderSent(ctx context.Context, id string) error {
+	query := ar.db.QueryBuilder.Delete("attendance_reminders").
+		Where(sq.Eq{"id": id})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = ar.db.Exec(ctx, sql, args...)
+	return err
+}
+
+const attendanceColumns = "id, user_id, COALESCE(schedule_id::text, ''), time, business_date, type, status, " +
+	"COALESCE(notes, ''), COALESCE(latitude, 0), COALESCE(longitude, 0), COALESCE(selfie_url, ''), " +
+	"COALESCE(hours_worked, 0)::float8, created_at, updated_at"
+
+func scanAttendance(row pgx.Row) (*domain.Attendance, error) {
+	var attendance domain.Attendance
+	err := row.Scan(
+		&attendance.ID,
+		&attendance.UserID,
+		&attendance.ScheduleID,
+		&attendance.Time,
+		&attendance.BusinessDate,
+		&attendance.Type,
+		&attendance.Status,
+		&attendance.Notes,
+		&attendance.Latitude,
+		&attendance.Longitude,
+		&attendance.SelfieURL,
+		&attendance.HoursWorked,
+		&attendance.CreatedAt,
+		&attendance.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &attendance, nil
+}
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/aldotp/employee-attendance-system/internal/adapter/storage/postgres"
+	"github.com/aldotp/employee-attendance-system/internal/core/domain"
+	"github.com/aldotp/employee-attendance-system/pkg/consts"
+	"github.com/jackc/pgx/v5"
+)
+
+type CalendarFeedRepository struct {
+	db *postgres.DB
+}
+
+func NewCalendarFeedRepository(db *postgres.DB) *CalendarFeedRepository {
+	return &CalendarFeedRepository{
+		db: db,
+	}
+}
+
+// SaveCalendarFeedToken stores the feed token of a user, replacing any previous one so an
+// employee has at most one valid feed URL
+func (cr *CalendarFeedRepository) SaveCalendarFeedToken(ctx context.Context, token *domain.CalendarFeedToken) error {
+	query := cr.db.QueryBuilder.Insert("calendar_feed_tokens").
+		Columns("id", "user_id", "token_hash", "created_at").
+		Values(token.ID, token.UserID, token.TokenHash, token.CreatedAt).
+		Suffix("ON CONFLICT (user_id) DO UPDATE SET id = EXCLUDED.id, token_hash = EXCLUDED.token_hash, " +
+			"created_at = EXCLUDED.created_at, last_accessed_at = NULL")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = cr.db.Exec(ctx, sql, args...)
+	return err
+}
+
+func (cr *CalendarFeedRepository) GetCalendarFeedToken(ctx context.Context, tokenHash string) (*domain.CalendarFeedToken, error) {
+	var token domain.CalendarFeedToken
+
+	query := cr.db.QueryBuilder.Select("id", "user_id", "token_hash", "last_accessed_at", "created_at").
+		From("calendar_feed_tokens").
+		Where(sq.Eq{"token_hash": tokenHash})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	err = cr.db.QueryRow(ctx, sql, args...).Scan(
+		&token.ID,
+		&token.UserID,
+		&token.TokenHash,
+		&token.LastAccessedAt,
+		&token.CreatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, consts.ErrDataNotFound
+		}
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+func (cr *CalendarFeedRepository) TouchCalendarFeedToken(ctx context.Context, id string, accessedAt time.Time) error {
+	query := cr.db.QueryBuilder.Update("calendar_feed_tokens").
+		Set("last_accessed_at", accessedAt).
+		Where(sq.Eq{"id": id})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = cr.db.Exec(ctx, sql, args...)
+	return err
+}
+
+func (cr *CalendarFeedRepository) DeleteCalendarFeedToken(ctx context.Context, userID string) error {
+	query := cr.db.QueryBuilder.Delete("calendar_feed_tokens").
+		Where(sq.Eq{"user_id": userID})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	tag, err := cr.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return consts.ErrDataNotFound
+	}
+
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/aldotp/employee-attendance-system/internal/adapter/storage/postgres"
 	"github.com/aldotp/employee-attendance-system/internal/core/domain"
+	"github.com/aldotp/employee-attendance-system/pkg/consts"
 	"github.com/jackc/pgx/v5"
 )
 
@@ -150,6 +151,9 @@ func (r *DepartmentRepository) GetDepartmentByName(ctx context.Context, name str
 	var dept domain.Department
 	err = r.db.QueryRow(ctx, sql, args...).Scan(&dept.ID, &dept.Name, &dept.Location, &dept.Timezone, &dept.WFA_Policy)
 	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, consts.ErrDataNotFound
+		}
 		return nil, err
 	}
 	return &dept, nil
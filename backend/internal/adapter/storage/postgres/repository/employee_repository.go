@@ -277,3 +277,155 @@ func (w *nullStringWrapper) Scan(value interface{}) error {
 	*w.str = s
 	return nil
 }
+
+func (er *EmployeeRepository) GetEmployeeByUserID(ctx context.Context, userID string) (*domain.Employee, error) {
+	var employee domain.Employee
+
+	query := er.db.QueryBuilder.Select(
+		"id", "user_id", "department_id", "name", "COALESCE(location, '')", "timezone", "COALESCE(photo_url, '')", "status", "join_date", "reporting_to", "created_at", "updated_at",
+	).
+		From("employees").
+		Where(sq.Eq{"user_id": userID}).
+		Limit(1)
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	err = er.db.QueryRow(ctx, sql, args...).Scan(
+		&employee.ID,
+		&employee.UserID,
+		&nullStringWrapper{&employee.DepartmentID},
+		&employee.Name,
+		&employee.Location,
+		&employee.Timezone,
+		&employee.PhotoURL,
+		&employee.Status,
+		&nullTimeWrapper{&employee.JoinDate},
+		&nullStringWrapper{&employee.ReportingTo},
+		&employee.CreatedAt,
+		&employee.UpdatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, consts.ErrDataNotFound
+		}
+		return nil, err
+	}
+
+	return &employee, nil
+}
+
+// ListEmployeesByUserIDs retrieves the employees of the given users, users without one are left out
+func (er *EmployeeRepository) ListEmployeesByUserIDs(ctx context.Context, userIDs []string) ([]domain.Employee, error) {
+	var employees []domain.Employee
+
+	query := er.db.QueryBuilder.Select(
+		"id", "user_id", "department_id", "name", "COALESCE(location, '')", "timezone", "COALESCE(photo_url, '')", "status", "join_date", "reporting_to", "created_at", "updated_at",
+	).
+		From("employees").
+		Where(sq.Expr("user_id = ANY(?::uuid[])", userIDs))
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := er.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var employee domain.Employee
+		err := rows.Scan(
+			&employee.ID,
+			&employee.UserID,
+			&nullStringWrapper{&employee.DepartmentID},
+			&employee.Name,
+			&employee.Location,
+			&employee.Timezone,
+			&employee.PhotoURL,
+			&employee.Status,
+			&nullTimeWrapper{&employee.JoinDate},
+			&nullStringWrapper{&employee.ReportingTo},
+			&employee.CreatedAt,
+			&employee.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		employees = append(employees, employee)
+	}
+
+	return employees, nil
+}
+
+func (er *EmployeeRepository) CountActiveEmployeesByDepartment(ctx context.Context, departmentID string) (int, error) {
+	query := er.db.QueryBuilder.Select("COUNT(id)").
+		From("employees").
+		Where(sq.Eq{"department_id": departmentID, "status": domain.StatusActive})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	var count int
+	err = er.db.QueryRow(ctx, sql, args...).Scan(&count)
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+type nullTimeWrapper struct {
+	t *time.Time
+}
+
+func (w *nullTimeWrapper) Scan(value interface{}) error {
+	if value == nil {
+		*w.t = time.Time{}
+		return nil
+	}
+	t, ok := value.(time.Time)
+	if !ok {
+		return fmt.Errorf("expected time, got %T", value)
+	}
+	*w.t = t
+	return nil
+}
+
+// ListActiveUserIDsByDepartment retrieves the user ids of the active employees of a department
+func (er *EmployeeRepository) ListActiveUserIDsByDepartment(ctx context.Context, departmentID string) ([]string, error) {
+	var userIDs []string
+
+	query := er.db.QueryBuilder.Select("user_id::text").
+		From("employees").
+		Where(sq.Eq{"department_id": departmentID, "status": domain.StatusActive}).
+		Where(sq.NotEq{"user_id": nil})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := er.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var userID string
+		if err := rows.Scan(&userID); err != nil {
+			return nil, err
+		}
+		userIDs = append(userIDs, userID)
+	}
+
+	return userIDs, nil
+}
@@ -2,6 +2,9 @@ package repository
 
 import (
 	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/aldotp/employee-attendance-system/internal/core/domain"
 )
 
 // nullString converts a string to sql.NullString for empty string check
@@ -53,3 +56,17 @@ func nullFloat64(value float64) sql.NullFloat64 {
 		Valid:   true,
 	}
 }
+
+// whereOwnership narrows a query to the records the filter allows, userColumn holding the user
+// each record belongs to
+func whereOwnership(query sq.SelectBuilder, filter domain.OwnershipFilter, userColumn string) sq.SelectBuilder {
+	if filter.UserID != "" {
+		query = query.Where(sq.Eq{userColumn: filter.UserID})
+	}
+
+	if filter.DepartmentID != "" {
+		query = query.Where(userColumn+" IN (SELECT user_id FROM employees WHERE department_id = ?)", filter.DepartmentID)
+	}
+
+	return query
+}
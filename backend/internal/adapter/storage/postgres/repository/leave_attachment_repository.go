@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/aldotp/employee-attendance-system/internal/adapter/storage/postgres"
+	"github.com/aldotp/employee-attendance-system/internal/core/domain"
+)
+
+type LeaveAttachmentRepository struct {
+	db *postgres.DB
+}
+
+func NewLeaveAttachmentRepository(db *postgres.DB) *LeaveAttachmentRepository {
+	return &LeaveAttachmentRepository{
+		db,
+	}
+}
+
+func (lar *LeaveAttachmentRepository) CreateLeaveAttachment(ctx context.Context, attachment *domain.LeaveAttachment) (*domain.LeaveAttachment, error) {
+	query := lar.db.QueryBuilder.Insert("leave_attachments").
+		Columns("id", "leave_request_id", "file_name", "object_key", "content_type", "size", "uploaded_by", "created_at").
+		Values(attachment.ID, attachment.LeaveRequestID, attachment.FileName, attachment.ObjectKey, attachment.ContentType, attachment.Size, nullString(attachment.UploadedBy), attachment.CreatedAt).
+		Suffix("RETURNING id, leave_request_id, file_name, object_key, content_type, size, COALESCE(uploaded_by::text, ''), created_at")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	err = lar.db.QueryRow(ctx, sql, args...).Scan(
+		&attachment.ID,
+		&attachment.LeaveRequestID,
+		&attachment.FileName,
+		&attachment.ObjectKey,
+		&attachment.ContentType,
+		&attachment.Size,
+		&attachment.UploadedBy,
+		&attachment.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return attachment, nil
+}
+
+func (lar *LeaveAttachmentRepository) ListLeaveAttachments(ctx context.Context, leaveRequestID string) ([]domain.LeaveAttachment, error) {
+	var attachments []domain.LeaveAttachment
+
+	query := lar.db.QueryBuilder.Select(
+		"id", "leave_request_id", "file_name", "object_key", "content_type", "size", "COALESCE(uploaded_by::text, '')", "created_at",
+	).
+		From("leave_attachments").
+		Where(sq.Eq{"leave_request_id": leaveRequestID}).
+		OrderBy("created_at ASC")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := lar.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var attachment domain.LeaveAttachment
+		err := rows.Scan(
+			&attachment.ID,
+			&attachment.LeaveRequestID,
+			&attachment.FileName,
+			&attachment.ObjectKey,
+			&attachment.ContentType,
+			&attachment.Size,
+			&attachment.UploadedBy,
+			&attachment.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, attachment)
+	}
+
+	return attachments, nil
+}
+
+func (lar *LeaveAttachmentRepository) DeleteLeaveAttachment(ctx context.Context, id string) error {
+	query := lar.db.QueryBuilder.Delete("leave_attachments").
+		Where(sq.Eq{"id": id})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = lar.db.Exec(ctx, sql, args...)
+	return err
+}
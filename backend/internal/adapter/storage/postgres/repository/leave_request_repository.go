@@ -123,7 +123,7 @@ func (lr *LeaveRequestRepository) GetLeaveRequestByID(ctx context.Context, id st
 	return &request, nil
 }
 
-func (lr *LeaveRequestRepository) ListLeaveRequests(ctx context.Context, skip, limit uint64) ([]domain.LeaveRequest, error) {
+func (lr *LeaveRequestRepository) ListLeaveRequests(ctx context.Context, skip, limit uint64, filter domain.OwnershipFilter) ([]domain.LeaveRequest, error) {
 	var request domain.LeaveRequest
 	var requests []domain.LeaveRequest
 
@@ -141,6 +141,8 @@ func (lr *LeaveRequestRepository) ListLeaveRequests(ctx context.Context, skip, l
 		Limit(limit).
 		Offset((skip - 1) * limit)
 
+	query = whereOwnership(query, filter, "user_id")
+
 	sql, args, err := query.ToSql()
 	if err != nil {
 		return nil, err
@@ -230,3 +232,103 @@ func (lr *LeaveRequestRepository) DeleteLeaveRequest(ctx context.Context, id str
 	_, err = lr.db.Exec(ctx, sql, args...)
 	return err
 }
+
+// ListDepartmentLeaves returns the leave requests of a department's employees that overlap the given period
+func (lr *LeaveRequestRepository) ListDepartmentLeaves(ctx context.Context, departmentID string, startDate, endDate time.Time, statuses []domain.LeaveStatus) ([]domain.DepartmentLeave, error) {
+	var leaves []domain.DepartmentLeave
+
+	query := lr.db.QueryBuilder.Select(
+		"l.id", "l.user_id", "l.start_date", "l.end_date", "l.type", "COALESCE(l.reason, '')", "l.status", "e.name",
+	).
+		From("leave_requests l").
+		Join("employees e ON e.user_id = l.user_id").
+		Where(sq.And{
+			sq.Eq{"e.department_id": departmentID},
+			sq.LtOrEq{"l.start_date": endDate},
+			sq.GtOrEq{"l.end_date": startDate},
+		}).
+		OrderBy("l.start_date ASC")
+
+	if len(statuses) > 0 {
+		query = query.Where(sq.Eq{"l.status": statuses})
+	}
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := lr.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var leave domain.DepartmentLeave
+		err := rows.Scan(
+			&leave.ID,
+			&leave.UserID,
+			&leave.StartDate,
+			&leave.EndDate,
+			&leave.Type,
+			&leave.Reason,
+			&leave.Status,
+			&leave.EmployeeName,
+		)
+		if err != nil {
+			return nil, err
+		}
+		leaves = append(leaves, leave)
+	}
+
+	return leaves, nil
+}
+
+// ListUserLeaves retrieves the leaves of the given users overlapping the period between startDate and endDate
+func (lr *LeaveRequestRepository) ListUserLeaves(ctx context.Context, userIDs []string, startDate, endDate time.Time, statuses []domain.LeaveStatus) ([]domain.LeaveRequest, error) {
+	var leaves []domain.LeaveRequest
+
+	// a single array parameter keeps the query valid for any number of users
+	query := lr.db.QueryBuilder.Select(
+		"id", "user_id", "start_date", "end_date", "type", "COALESCE(reason, '')", "status",
+	).
+		From("leave_requests").
+		Where(sq.Expr("user_id = ANY(?::uuid[])", userIDs)).
+		Where(sq.LtOrEq{"start_date": endDate}).
+		Where(sq.GtOrEq{"end_date": startDate})
+
+	if len(statuses) > 0 {
+		query = query.Where(sq.Eq{"status": statuses})
+	}
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := lr.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var leave domain.LeaveRequest
+		err := rows.Scan(
+			&leave.ID,
+			&leave.UserID,
+			&leave.StartDate,
+			&leave.EndDate,
+			&leave.Type,
+			&leave.Reason,
+			&leave.Status,
+		)
+		if err != nil {
+			return nil, err
+		}
+		leaves = append(leaves, leave)
+	}
+
+	return leaves, nil
+}
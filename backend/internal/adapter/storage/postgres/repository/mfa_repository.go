@@ -0,0 +1,219 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/aldotp/employee-attendance-system/internal/adapter/storage/postgres"
+	"github.com/aldotp/employee-attendance-system/internal/core/domain"
+	"github.com/aldotp/employee-attendance-system/pkg/consts"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type MFARepository struct {
+	db *postgres.DB
+}
+
+func NewMFARepository(db *postgres.DB) *MFARepository {
+	return &MFARepository{
+		db: db,
+	}
+}
+
+func (mr *MFARepository) GetUserMFA(ctx context.Context, userID string) (*domain.UserMFA, error) {
+	var mfa domain.UserMFA
+
+	query := mr.db.QueryBuilder.Select("user_id", "secret", "enabled_at", "last_used_step", "created_at", "updated_at").
+		From("user_mfa").
+		Where(sq.Eq{"user_id": userID})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	err = mr.db.QueryRow(ctx, sql, args...).Scan(
+		&mfa.UserID,
+		&mfa.Secret,
+		&mfa.EnabledAt,
+		&mfa.LastUsedStep,
+		&mfa.CreatedAt,
+		&mfa.UpdatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, consts.ErrDataNotFound
+		}
+		return nil, err
+	}
+
+	return &mfa, nil
+}
+
+// SaveUserMFA stores a new enrollment of the user, replacing one that was never confirmed.
+// Enabled authenticators are left alone and reported as consts.ErrConflictingData.
+func (mr *MFARepository) SaveUserMFA(ctx context.Context, mfa *domain.UserMFA) error {
+	query := mr.db.QueryBuilder.Insert("user_mfa").
+		Columns("user_id", "secret", "created_at", "updated_at").
+		Values(mfa.UserID, mfa.Secret, mfa.CreatedAt, mfa.UpdatedAt).
+		Suffix("ON CONFLICT (user_id) DO UPDATE SET secret = EXCLUDED.secret, last_used_step = 0, " +
+			"created_at = EXCLUDED.created_at, updated_at = EXCLUDED.updated_at WHERE user_mfa.enabled_at IS NULL")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	tag, err := mr.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return consts.ErrConflictingData
+	}
+
+	return nil
+}
+
+// EnableUserMFA enables a confirmed enrollment and replaces the recovery codes of the user in a
+// single transaction
+func (mr *MFARepository) EnableUserMFA(ctx context.Context, userID string, step int64, enabledAt time.Time, recoveryCodeHashes []string) (err error) {
+	tx, err := mr.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+
+	query := mr.db.QueryBuilder.Update("user_mfa").
+		Set("enabled_at", enabledAt).
+		Set("last_used_step", step).
+		Set("updated_at", enabledAt).
+		Where(sq.Eq{"user_id": userID, "enabled_at": nil})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	tag, err := tx.Exec(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return consts.ErrConflictingData
+	}
+
+	deleteQuery := mr.db.QueryBuilder.Delete("user_mfa_recovery_codes").
+		Where(sq.Eq{"user_id": userID})
+
+	sql, args, err = deleteQuery.ToSql()
+	if err != nil {
+		return err
+	}
+
+	if _, err = tx.Exec(ctx, sql, args...); err != nil {
+		return err
+	}
+
+	if len(recoveryCodeHashes) > 0 {
+		insertQuery := mr.db.QueryBuilder.Insert("user_mfa_recovery_codes").
+			Columns("id", "user_id", "code_hash", "created_at")
+		for _, hash := range recoveryCodeHashes {
+			insertQuery = insertQuery.Values(uuid.New().String(), userID, hash, enabledAt)
+		}
+
+		sql, args, err = insertQuery.ToSql()
+		if err != nil {
+			return err
+		}
+
+		if _, err = tx.Exec(ctx, sql, args...); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// UseMFAStep records the time step of an accepted code. It reports false when that step or a
+// later one was already used, which makes a replayed code fail.
+func (mr *MFARepository) UseMFAStep(ctx context.Context, userID string, step int64) (bool, error) {
+	query := mr.db.QueryBuilder.Update("user_mfa").
+		Set("last_used_step", step).
+		Set("updated_at", time.Now()).
+		Where(sq.Eq{"user_id": userID}).
+		Where(sq.Lt{"last_used_step": step})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return false, err
+	}
+
+	tag, err := mr.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+// UseRecoveryCode marks an unused recovery code of the user used, consts.ErrDataNotFound when
+// there is none with the hash
+func (mr *MFARepository) UseRecoveryCode(ctx context.Context, userID, codeHash string, usedAt time.Time) error {
+	query := mr.db.QueryBuilder.Update("user_mfa_recovery_codes").
+		Set("used_at", usedAt).
+		Where(sq.Eq{"user_id": userID, "code_hash": codeHash, "used_at": nil})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	tag, err := mr.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return consts.ErrDataNotFound
+	}
+
+	return nil
+}
+
+// DeleteUserMFA removes the authenticator and the recovery codes of the user
+func (mr *MFARepository) DeleteUserMFA(ctx context.Context, userID string) (err error) {
+	tx, err := mr.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+
+	for _, table := range []string{"user_mfa_recovery_codes", "user_mfa"} {
+		query := mr.db.QueryBuilder.Delete(table).
+			Where(sq.Eq{"user_id": userID})
+
+		sql, args, err := query.ToSql()
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(ctx, sql, args...); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
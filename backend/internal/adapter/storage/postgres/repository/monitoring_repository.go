@@ -80,7 +80,7 @@ func (mr *MonitoringRepository) GetSummary(ctx context.Context, date string) (*d
 
 	query = mr.db.QueryBuilder.Select("COUNT(DISTINCT id)").From("attendances").Where("type = ?", "check_in")
 	if date != "" {
-		query = query.Where("DATE(time) = ?", date)
+		query = query.Where("business_date = ?", date)
 	}
 	sql, args, err = query.ToSql()
 	if err != nil {
@@ -93,7 +93,7 @@ func (mr *MonitoringRepository) GetSummary(ctx context.Context, date string) (*d
 
 	query = mr.db.QueryBuilder.Select("COUNT(DISTINCT id)").From("attendances").Where("type = ?", "check_out")
 	if date != "" {
-		query = query.Where("DATE(time) = ?", date)
+		query = query.Where("business_date = ?", date)
 	}
 	sql, args, err = query.ToSql()
 	if err != nil {
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/aldotp/employee-attendance-system/internal/adapter/storage/postgres"
@@ -20,10 +21,20 @@ func NewNotificationRepository(db *postgres.DB) *NotificationRepository {
 	}
 }
 
+const notificationColumns = "id, user_id, type, message, send_at, status, attempts, COALESCE(last_error, ''), next_attempt_at, delivered_at, is_read, read_at, delivered_channels, created_at"
+
+// inAppNotifications restricts a query to the notifications of the user shown in the app
+func inAppNotifications(userID string) sq.Sqlizer {
+	return sq.And{
+		sq.Eq{"user_id": userID},
+		sq.Expr("? = ANY(delivered_channels)", domain.NotificationChannelInApp),
+	}
+}
+
 func (nr *NotificationRepository) CreateNotification(ctx context.Context, notif *domain.Notification) (string, error) {
 	query := nr.db.QueryBuilder.Insert("notifications").
-		Columns("id", "user_id", "type", "message", "send_at", "created_at").
-		Values(notif.ID, notif.UserID, notif.Type, notif.Message, notif.SendAt, notif.CreatedAt).
+		Columns("id", "user_id", "type", "message", "send_at", "status", "next_attempt_at", "created_at").
+		Values(notif.ID, notif.UserID, notif.Type, notif.Message, notif.SendAt, notif.Status, notif.NextAttemptAt, notif.CreatedAt).
 		Suffix("RETURNING id")
 
 	sql, args, err := query.ToSql()
@@ -34,17 +45,20 @@ func (nr *NotificationRepository) CreateNotification(ctx context.Context, notif
 	var id string
 	err = nr.db.QueryRow(ctx, sql, args...).Scan(&id)
 	if err != nil {
+		if errCode := nr.db.ErrorCode(err); errCode == postgres.UniqueViolationCode {
+			return "", consts.ErrConflictingData
+		}
 		return "", err
 	}
 
 	return id, nil
 }
 
-func (nr *NotificationRepository) GetNotificationByID(ctx context.Context, id string) (*domain.Notification, error) {
-	var notif domain.Notification
-
-	query := nr.db.QueryBuilder.Select("*").
+// GetNotificationByID retrieves a notification of the user shown in the app
+func (nr *NotificationRepository) GetNotificationByID(ctx context.Context, userID string, id string) (*domain.Notification, error) {
+	query := nr.db.QueryBuilder.Select(notificationColumns).
 		From("notifications").
+		Where(inAppNotifications(userID)).
 		Where(sq.Eq{"id": id}).
 		Limit(1)
 
@@ -53,14 +67,7 @@ func (nr *NotificationRepository) GetNotificationByID(ctx context.Context, id st
 		return nil, err
 	}
 
-	err = nr.db.QueryRow(ctx, sql, args...).Scan(
-		&notif.ID,
-		&notif.UserID,
-		&notif.Type,
-		&notif.Message,
-		&notif.SendAt,
-		&notif.CreatedAt,
-	)
+	notif, err := scanNotification(nr.db.QueryRow(ctx, sql, args...))
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, consts.ErrDataNotFound
@@ -68,26 +75,37 @@ func (nr *NotificationRepository) GetNotificationByID(ctx context.Context, id st
 		return nil, err
 	}
 
-	return &notif, nil
+	return notif, nil
 }
 
-func (nr *NotificationRepository) ListNotifications(ctx context.Context, userID string, skip, limit uint64) ([]domain.Notification, error) {
-	var notif domain.Notification
+// ListNotifications lists the notifications already delivered in the app to the user, newest
+// first. The list continues after filter.After when it is set, or from filter.Offset otherwise.
+func (nr *NotificationRepository) ListNotifications(ctx context.Context, filter domain.NotificationFilter) ([]domain.Notification, error) {
 	var notifs []domain.Notification
 
-	if limit == 0 {
-		limit = 10
-	}
-	if skip == 0 {
-		skip = 1
+	if filter.Limit == 0 {
+		filter.Limit = 10
 	}
 
-	query := nr.db.QueryBuilder.Select("*").
+	query := nr.db.QueryBuilder.Select(notificationColumns).
 		From("notifications").
-		Where(sq.Eq{"user_id": userID}).
-		OrderBy("created_at DESC").
-		Limit(limit).
-		Offset((skip - 1) * limit)
+		Where(inAppNotifications(filter.UserID)).
+		OrderBy("COALESCE(delivered_at, send_at) DESC", "id DESC").
+		Limit(filter.Limit)
+
+	if filter.Type != "" {
+		query = query.Where(sq.Eq{"type": filter.Type})
+	}
+
+	if filter.IsRead != nil {
+		query = query.Where(sq.Eq{"is_read": *filter.IsRead})
+	}
+
+	if filter.After != nil {
+		query = query.Where(sq.Expr("(COALESCE(delivered_at, send_at), id) < (?, ?::uuid)", filter.After.ListedAt, filter.After.ID))
+	} else {
+		query = query.Offset(filter.Offset)
+	}
 
 	sql, args, err := query.ToSql()
 	if err != nil {
@@ -101,40 +119,169 @@ func (nr *NotificationRepository) ListNotifications(ctx context.Context, userID
 	defer rows.Close()
 
 	for rows.Next() {
-		err := rows.Scan(
-			&notif.ID,
-			&notif.UserID,
-			&notif.Type,
-			&notif.Message,
-			&notif.SendAt,
-			&notif.CreatedAt,
-		)
+		notif, err := scanNotification(rows)
 		if err != nil {
 			return nil, err
 		}
-		notifs = append(notifs, notif)
+		notifs = append(notifs, *notif)
 	}
 
 	return notifs, nil
 }
 
-func (nr *NotificationRepository) DeleteNotification(ctx context.Context, notificationID string) error {
+// CountUnreadNotifications counts the unread notifications of the user shown in the app, of one
+// type or of every type when notificationType is empty
+func (nr *NotificationRepository) CountUnreadNotifications(ctx context.Context, userID string, notificationType domain.NotificationType) (int, error) {
+	query := nr.db.QueryBuilder.Select("COUNT(*)").
+		From("notifications").
+		Where(inAppNotifications(userID)).
+		Where(sq.Eq{"is_read": false})
+
+	if notificationType != "" {
+		query = query.Where(sq.Eq{"type": notificationType})
+	}
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	var count int
+	if err := nr.db.QueryRow(ctx, sql, args...).Scan(&count); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// SetNotificationsRead marks notifications of the user read or unread, keeping the time they were
+// first read. It returns how many of them belong to the user.
+func (nr *NotificationRepository) SetNotificationsRead(ctx context.Context, userID string, ids []string, read bool) (int64, error) {
+	query := nr.db.QueryBuilder.Update("notifications").
+		Set("is_read", read).
+		Where(inAppNotifications(userID)).
+		Where(sq.Expr("id = ANY(?::uuid[])", ids))
+
+	if read {
+		query = query.Set("read_at", sq.Expr("COALESCE(read_at, ?)", time.Now()))
+	} else {
+		query = query.Set("read_at", nil)
+	}
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	tag, err := nr.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+// MarkAllNotificationsRead marks every unread notification of the user read, of one type or of
+// every type when notificationType is empty. It returns how many were marked.
+func (nr *NotificationRepository) MarkAllNotificationsRead(ctx context.Context, userID string, notificationType domain.NotificationType) (int64, error) {
+	query := nr.db.QueryBuilder.Update("notifications").
+		Set("is_read", true).
+		Set("read_at", time.Now()).
+		Where(inAppNotifications(userID)).
+		Where(sq.Eq{"is_read": false})
+
+	if notificationType != "" {
+		query = query.Where(sq.Eq{"type": notificationType})
+	}
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	tag, err := nr.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+// DeleteNotifications deletes notifications of the user, returning how many of them belong to the user
+func (nr *NotificationRepository) DeleteNotifications(ctx context.Context, userID string, ids []string) (int64, error) {
 	query := nr.db.QueryBuilder.Delete("notifications").
-		Where(sq.Eq{"id": notificationID})
+		Where(inAppNotifications(userID)).
+		Where(sq.Expr("id = ANY(?::uuid[])", ids))
 
 	sql, args, err := query.ToSql()
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	_, err = nr.db.Exec(ctx, sql, args...)
-	return err
+	tag, err := nr.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+// ClaimDueNotifications picks up to limit pending notifications whose time has come and hides
+// them from other dispatchers until leaseUntil, so a crashed run is retried once the lease ends
+func (nr *NotificationRepository) ClaimDueNotifications(ctx context.Context, now time.Time, leaseUntil time.Time, limit uint64) ([]domain.Notification, error) {
+	var notifs []domain.Notification
+
+	// built with ? placeholders, numbered together with the outer statement
+	due := sq.Select("id").
+		From("notifications").
+		Where(sq.Eq{"status": domain.NotificationPending}).
+		Where(sq.LtOrEq{"COALESCE(next_attempt_at, send_at)": now}).
+		OrderBy("send_at ASC").
+		Limit(limit).
+		Suffix("FOR UPDATE SKIP LOCKED")
+
+	dueSQL, dueArgs, err := due.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	query := nr.db.QueryBuilder.Update("notifications").
+		Set("next_attempt_at", leaseUntil).
+		Where(sq.Expr("id IN ("+dueSQL+")", dueArgs...)).
+		Suffix("RETURNING " + notificationColumns)
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := nr.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		notif, err := scanNotification(rows)
+		if err != nil {
+			return nil, err
+		}
+		notifs = append(notifs, *notif)
+	}
+
+	return notifs, nil
 }
 
-func (nr *NotificationRepository) UpdateNotificationStatus(ctx context.Context, notificationID string, status string) error {
+// UpdateNotificationDelivery stores the outcome of a delivery attempt
+func (nr *NotificationRepository) UpdateNotificationDelivery(ctx context.Context, notif *domain.Notification) error {
 	query := nr.db.QueryBuilder.Update("notifications").
-		Set("status", status).
-		Where(sq.Eq{"id": notificationID})
+		Set("status", notif.Status).
+		Set("attempts", notif.Attempts).
+		Set("last_error", nullString(notif.LastError)).
+		Set("next_attempt_at", notif.NextAttemptAt).
+		Set("delivered_at", notif.DeliveredAt).
+		Set("delivered_channels", channelNames(notif.DeliveredChannels)).
+		Where(sq.Eq{"id": notif.ID})
 
 	sql, args, err := query.ToSql()
 	if err != nil {
@@ -144,3 +291,144 @@ func (nr *NotificationRepository) UpdateNotificationStatus(ctx context.Context,
 	_, err = nr.db.Exec(ctx, sql, args...)
 	return err
 }
+
+func scanNotification(row pgx.Row) (*domain.Notification, error) {
+	var notif domain.Notification
+	var deliveredChannels []string
+	err := row.Scan(
+		&notif.ID,
+		&notif.UserID,
+		&notif.Type,
+		&notif.Message,
+		&notif.SendAt,
+		&notif.Status,
+		&notif.Attempts,
+		&notif.LastError,
+		&notif.NextAttemptAt,
+		&notif.DeliveredAt,
+		&notif.IsRead,
+		&notif.ReadAt,
+		&deliveredChannels,
+		&notif.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	notif.DeliveredChannels = channelTypes(deliveredChannels)
+
+	return &notif, nil
+}
+
+const notificationPreferenceColumns = "user_id, type, channels, COALESCE(webhook_url, ''), updated_at"
+
+func (nr *NotificationRepository) ListNotificationPreferences(ctx context.Context, userID string) ([]domain.NotificationPreference, error) {
+	var prefs []domain.NotificationPreference
+
+	query := nr.db.QueryBuilder.Select(notificationPreferenceColumns).
+		From("notification_preferences").
+		Where(sq.Eq{"user_id": userID})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := nr.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		pref, err := scanNotificationPreference(rows)
+		if err != nil {
+			return nil, err
+		}
+		prefs = append(prefs, *pref)
+	}
+
+	return prefs, nil
+}
+
+func (nr *NotificationRepository) GetNotificationPreference(ctx context.Context, userID string, notificationType domain.NotificationType) (*domain.NotificationPreference, error) {
+	query := nr.db.QueryBuilder.Select(notificationPreferenceColumns).
+		From("notification_preferences").
+		Where(sq.Eq{"user_id": userID, "type": notificationType})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	pref, err := scanNotificationPreference(nr.db.QueryRow(ctx, sql, args...))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, consts.ErrDataNotFound
+		}
+		return nil, err
+	}
+
+	return pref, nil
+}
+
+// SaveNotificationPreference creates or replaces the user's preference for the notification type
+func (nr *NotificationRepository) SaveNotificationPreference(ctx context.Context, pref *domain.NotificationPreference) (*domain.NotificationPreference, error) {
+	query := nr.db.QueryBuilder.Insert("notification_preferences").
+		Columns("user_id", "type", "channels", "webhook_url", "created_at", "updated_at").
+		Values(pref.UserID, pref.Type, channelNames(pref.Channels), nullString(pref.WebhookURL), pref.UpdatedAt, pref.UpdatedAt).
+		Suffix("ON CONFLICT (user_id, type) DO UPDATE SET channels = EXCLUDED.channels, webhook_url = EXCLUDED.webhook_url, updated_at = EXCLUDED.updated_at").
+		Suffix("RETURNING " + notificationPreferenceColumns)
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	pref, err = scanNotificationPreference(nr.db.QueryRow(ctx, sql, args...))
+	if err != nil {
+		if errCode := nr.db.ErrorCode(err); errCode == postgres.ForeignKeyViolationCode {
+			return nil, consts.ErrDataNotFound
+		}
+		return nil, err
+	}
+
+	return pref, nil
+}
+
+func scanNotificationPreference(row pgx.Row) (*domain.NotificationPreference, error) {
+	var pref domain.NotificationPreference
+	var channels []string
+	err := row.Scan(
+		&pref.UserID,
+		&pref.Type,
+		&channels,
+		&pref.WebhookURL,
+		&pref.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	pref.Channels = channelTypes(channels)
+
+	return &pref, nil
+}
+
+func channelNames(channels []domain.NotificationChannelType) []string {
+	names := make([]string, 0, len(channels))
+	for _, channel := range channels {
+		names = append(names, string(channel))
+	}
+
+	return names
+}
+
+func channelTypes(names []string) []domain.NotificationChannelType {
+	channels := make([]domain.NotificationChannelType, 0, len(names))
+	for _, name := range names {
+		channels = append(channels, domain.NotificationChannelType(name))
+	}
+
+	return channels
+}
@@ -0,0 +1,370 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/aldotp/employee-attendance-system/internal/adapter/storage/postgres"
+	"github.com/aldotp/employee-attendance-system/internal/core/domain"
+	"github.com/aldotp/employee-attendance-system/pkg/consts"
+	"github.com/jackc/pgx/v5"
+)
+
+type OpenShiftRepository struct {
+	db *postgres.DB
+}
+
+func NewOpenShiftRepository(db *postgres.DB) *OpenShiftRepository {
+	return &OpenShiftRepository{
+		db: db,
+	}
+}
+
+// openShiftColumns selects an open shift together with the date and times of its schedule,
+// from open_shifts os joined with schedules s
+const openShiftColumns = "os.id, os.schedule_id, os.department_id, COALESCE(os.work_location_id::text, ''), s.date, " +
+	"TO_CHAR(s.shift_start, 'HH24:MI'), TO_CHAR(s.shift_end, 'HH24:MI'), os.status, COALESCE(os.note, ''), " +
+	"COALESCE(os.posted_by::text, ''), COALESCE(os.filled_by::text, ''), COALESCE(os.reviewed_by::text, ''), os.reviewed_at, " +
+	"os.created_at, os.updated_at"
+
+const openShiftClaimColumns = "id, open_shift_id, user_id, status, created_at, updated_at"
+
+func (or *OpenShiftRepository) CreateOpenShift(ctx context.Context, openShift *domain.OpenShift) (*domain.OpenShift, error) {
+	query := or.db.QueryBuilder.Insert("open_shifts").
+		Columns("id", "schedule_id", "department_id", "work_location_id", "status", "note", "posted_by", "created_at", "updated_at").
+		Values(openShift.ID, openShift.ScheduleID, openShift.DepartmentID, nullString(openShift.WorkLocationID), openShift.Status,
+			openShift.Note, nullString(openShift.PostedBy), openShift.CreatedAt, openShift.UpdatedAt)
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := or.db.Exec(ctx, sql, args...); err != nil {
+		switch or.db.ErrorCode(err) {
+		case postgres.UniqueViolationCode:
+			return nil, consts.ErrConflictingData
+		case postgres.ForeignKeyViolationCode:
+			return nil, consts.ErrDataNotFound
+		}
+		return nil, err
+	}
+
+	return or.GetOpenShift(ctx, openShift.ID)
+}
+
+func (or *OpenShiftRepository) GetOpenShift(ctx context.Context, id string) (*domain.OpenShift, error) {
+	query := or.db.QueryBuilder.Select(openShiftColumns).
+		From("open_shifts os").
+		Join("schedules s ON s.id = os.schedule_id").
+		Where(sq.Eq{"os.id": id})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	openShift, err := scanOpenShift(or.db.QueryRow(ctx, sql, args...))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, consts.ErrDataNotFound
+		}
+		return nil, err
+	}
+
+	return openShift, nil
+}
+
+// ListOpenShifts lists the open shifts of a department, or of every department when departmentID
+// is empty. An empty status lists any status.
+func (or *OpenShiftRepository) ListOpenShifts(ctx context.Context, departmentID string, status domain.OpenShiftStatus) ([]domain.OpenShift, error) {
+	var openShifts []domain.OpenShift
+
+	query := or.db.QueryBuilder.Select(openShiftColumns).
+		From("open_shifts os").
+		Join("schedules s ON s.id = os.schedule_id").
+		OrderBy("s.date ASC", "s.shift_start ASC")
+
+	if departmentID != "" {
+		query = query.Where(sq.Eq{"os.department_id": departmentID})
+	}
+
+	if status != "" {
+		query = query.Where(sq.Eq{"os.status": status})
+	}
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := or.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		openShift, err := scanOpenShift(rows)
+		if err != nil {
+			return nil, err
+		}
+		openShifts = append(openShifts, *openShift)
+	}
+
+	return openShifts, nil
+}
+
+// CancelOpenShift withdraws an open shift and rejects its pending claims. It returns
+// consts.ErrOpenShiftClosed when the shift is no longer open.
+func (or *OpenShiftRepository) CancelOpenShift(ctx context.Context, id string, reviewerID string) (err error) {
+	tx, err := or.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+
+	now := time.Now()
+	update := or.db.QueryBuilder.Update("open_shifts").
+		Set("status", domain.OpenShiftCancelled).
+		Set("reviewed_by", reviewerID).
+		Set("reviewed_at", now).
+		Set("updated_at", now).
+		Where(sq.Eq{"id": id, "status": domain.OpenShiftOpen})
+
+	sql, args, err := update.ToSql()
+	if err != nil {
+		return err
+	}
+
+	tag, err := tx.Exec(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return consts.ErrOpenShiftClosed
+	}
+
+	update = or.db.QueryBuilder.Update("open_shift_claims").
+		Set("status", domain.ClaimRejected).
+		Set("updated_at", now).
+		Where(sq.Eq{"open_shift_id": id, "status": domain.ClaimPending})
+
+	sql, args, err = update.ToSql()
+	if err != nil {
+		return err
+	}
+
+	if _, err = tx.Exec(ctx, sql, args...); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (or *OpenShiftRepository) CreateOpenShiftClaim(ctx context.Context, claim *domain.OpenShiftClaim) (*domain.OpenShiftClaim, error) {
+	query := or.db.QueryBuilder.Insert("open_shift_claims").
+		Columns("id", "open_shift_id", "user_id", "status", "created_at", "updated_at").
+		Values(claim.ID, claim.OpenShiftID, claim.UserID, claim.Status, claim.CreatedAt, claim.UpdatedAt).
+		Suffix("RETURNING " + openShiftClaimColumns)
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	claim, err = scanOpenShiftClaim(or.db.QueryRow(ctx, sql, args...))
+	if err != nil {
+		if errCode := or.db.ErrorCode(err); errCode == postgres.UniqueViolationCode {
+			return nil, consts.ErrConflictingData
+		}
+		return nil, err
+	}
+
+	return claim, nil
+}
+
+func (or *OpenShiftRepository) GetOpenShiftClaim(ctx context.Context, id string) (*domain.OpenShiftClaim, error) {
+	query := or.db.QueryBuilder.Select(openShiftClaimColumns).
+		From("open_shift_claims").
+		Where(sq.Eq{"id": id})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	claim, err := scanOpenShiftClaim(or.db.QueryRow(ctx, sql, args...))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, consts.ErrDataNotFound
+		}
+		return nil, err
+	}
+
+	return claim, nil
+}
+
+func (or *OpenShiftRepository) ListOpenShiftClaims(ctx context.Context, openShiftID string) ([]domain.OpenShiftClaim, error) {
+	var claims []domain.OpenShiftClaim
+
+	query := or.db.QueryBuilder.Select(openShiftClaimColumns).
+		From("open_shift_claims").
+		Where(sq.Eq{"open_shift_id": openShiftID}).
+		OrderBy("created_at ASC")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := or.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		claim, err := scanOpenShiftClaim(rows)
+		if err != nil {
+			return nil, err
+		}
+		claims = append(claims, *claim)
+	}
+
+	return claims, nil
+}
+
+// ConfirmOpenShiftClaim assigns the schedule of an open shift to the claimant in a single
+// transaction, marking the shift filled and rejecting the other pending claims. The claim and the
+// open shift are locked so two managers cannot confirm different claims of the same shift.
+func (or *OpenShiftRepository) ConfirmOpenShiftClaim(ctx context.Context, claimID string, reviewerID string) (err error) {
+	tx, err := or.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+
+	query := or.db.QueryBuilder.Select(openShiftClaimColumns).
+		From("open_shift_claims").
+		Where(sq.Eq{"id": claimID}).
+		Suffix("FOR UPDATE")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	claim, err := scanOpenShiftClaim(tx.QueryRow(ctx, sql, args...))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return consts.ErrDataNotFound
+		}
+		return err
+	}
+
+	var scheduleID string
+	var status domain.OpenShiftStatus
+	query = or.db.QueryBuilder.Select("schedule_id", "status").
+		From("open_shifts").
+		Where(sq.Eq{"id": claim.OpenShiftID}).
+		Suffix("FOR UPDATE")
+
+	sql, args, err = query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	if err = tx.QueryRow(ctx, sql, args...).Scan(&scheduleID, &status); err != nil {
+		return err
+	}
+
+	if status != domain.OpenShiftOpen || claim.Status != domain.ClaimPending {
+		return consts.ErrOpenShiftClosed
+	}
+
+	now := time.Now()
+	updates := []sq.UpdateBuilder{
+		or.db.QueryBuilder.Update("schedules").
+			Set("user_id", claim.UserID).
+			Set("updated_at", now).
+			Where(sq.Eq{"id": scheduleID}),
+		or.db.QueryBuilder.Update("open_shifts").
+			Set("status", domain.OpenShiftFilled).
+			Set("filled_by", claim.UserID).
+			Set("reviewed_by", reviewerID).
+			Set("reviewed_at", now).
+			Set("updated_at", now).
+			Where(sq.Eq{"id": claim.OpenShiftID}),
+		or.db.QueryBuilder.Update("open_shift_claims").
+			Set("status", sq.Expr("CASE WHEN id = ? THEN ? ELSE ? END", claim.ID, domain.ClaimConfirmed, domain.ClaimRejected)).
+			Set("updated_at", now).
+			Where(sq.Eq{"open_shift_id": claim.OpenShiftID, "status": domain.ClaimPending}),
+	}
+
+	for _, update := range updates {
+		sql, args, err = update.ToSql()
+		if err != nil {
+			return err
+		}
+
+		if _, err = tx.Exec(ctx, sql, args...); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+func scanOpenShift(row pgx.Row) (*domain.OpenShift, error) {
+	var openShift domain.OpenShift
+	err := row.Scan(
+		&openShift.ID,
+		&openShift.ScheduleID,
+		&openShift.DepartmentID,
+		&openShift.WorkLocationID,
+		&openShift.Date,
+		&openShift.ShiftStart,
+		&openShift.ShiftEnd,
+		&openShift.Status,
+		&openShift.Note,
+		&openShift.PostedBy,
+		&openShift.FilledBy,
+		&openShift.ReviewedBy,
+		&openShift.ReviewedAt,
+		&openShift.CreatedAt,
+		&openShift.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &openShift, nil
+}
+
+func scanOpenShiftClaim(row pgx.Row) (*domain.OpenShiftClaim, error) {
+	var claim domain.OpenShiftClaim
+	err := row.Scan(
+		&claim.ID,
+		&claim.OpenShiftID,
+		&claim.UserID,
+		&claim.Status,
+		&claim.CreatedAt,
+		&claim.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &claim, nil
+}
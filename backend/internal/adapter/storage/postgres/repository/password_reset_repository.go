@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/aldotp/employee-attendance-system/internal/adapter/storage/postgres"
+	"github.com/aldotp/employee-attendance-system/internal/core/domain"
+	"github.com/aldotp/employee-attendance-system/pkg/consts"
+	"github.com/jackc/pgx/v5"
+)
+
+type PasswordResetRepository struct {
+	db *postgres.DB
+}
+
+func NewPasswordResetRepository(db *postgres.DB) *PasswordResetRepository {
+	return &PasswordResetRepository{
+		db: db,
+	}
+}
+
+func (pr *PasswordResetRepository) CreatePasswordResetToken(ctx context.Context, token *domain.PasswordResetToken) error {
+	query := pr.db.QueryBuilder.Insert("password_reset_tokens").
+		Columns("id", "user_id", "token_hash", "expires_at", "created_at").
+		Values(token.ID, token.UserID, token.TokenHash, token.ExpiresAt, token.CreatedAt)
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = pr.db.Exec(ctx, sql, args...)
+	return err
+}
+
+// UsePasswordResetToken marks the token used and returns it, in one statement so a token can only
+// be used once even by concurrent requests. Unknown, used and expired tokens are all reported as
+// consts.ErrDataNotFound.
+func (pr *PasswordResetRepository) UsePasswordResetToken(ctx context.Context, tokenHash string, usedAt time.Time) (*domain.PasswordResetToken, error) {
+	var token domain.PasswordResetToken
+
+	query := pr.db.QueryBuilder.Update("password_reset_tokens").
+		Set("used_at", usedAt).
+		Where(sq.Eq{"token_hash": tokenHash, "used_at": nil}).
+		Where(sq.Gt{"expires_at": usedAt}).
+		Suffix("RETURNING id, user_id, token_hash, expires_at, used_at, created_at")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	err = pr.db.QueryRow(ctx, sql, args...).Scan(
+		&token.ID,
+		&token.UserID,
+		&token.TokenHash,
+		&token.ExpiresAt,
+		&token.UsedAt,
+		&token.CreatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, consts.ErrDataNotFound
+		}
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// DeletePasswordResetTokens deletes every reset token of the user, used or not
+func (pr *PasswordResetRepository) DeletePasswordResetTokens(ctx context.Context, userID string) error {
+	query := pr.db.QueryBuilder.Delete("password_reset_tokens").
+		Where(sq.Eq{"user_id": userID})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = pr.db.Exec(ctx, sql, args...)
+	return err
+}
@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/aldotp/employee-attendance-system/internal/adapter/storage/postgres"
+	"github.com/aldotp/employee-attendance-system/internal/core/domain"
+	"github.com/jackc/pgx/v5"
+)
+
+type PermissionRepository struct {
+	db *postgres.DB
+}
+
+func NewPermissionRepository(db *postgres.DB) *PermissionRepository {
+	return &PermissionRepository{
+		db: db,
+	}
+}
+
+// ListRolePermissions returns the permissions of every role that has any
+func (pr *PermissionRepository) ListRolePermissions(ctx context.Context) ([]domain.RolePermissions, error) {
+	query := pr.db.QueryBuilder.Select("role", "permission").
+		From("role_permissions").
+		OrderBy("role", "permission")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := pr.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var roles []domain.RolePermissions
+	for rows.Next() {
+		var role domain.UserRole
+		var permission domain.Permission
+		if err := rows.Scan(&role, &permission); err != nil {
+			return nil, err
+		}
+
+		if len(roles) == 0 || roles[len(roles)-1].Role != role {
+			roles = append(roles, domain.RolePermissions{Role: role})
+		}
+		roles[len(roles)-1].Permissions = append(roles[len(roles)-1].Permissions, permission)
+	}
+
+	return roles, rows.Err()
+}
+
+func (pr *PermissionRepository) ListPermissionsByRole(ctx context.Context, role domain.UserRole) ([]domain.Permission, error) {
+	query := pr.db.QueryBuilder.Select("permission").
+		From("role_permissions").
+		Where(sq.Eq{"role": role}).
+		OrderBy("permission")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := pr.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	permissions := []domain.Permission{}
+	for rows.Next() {
+		var permission domain.Permission
+		if err := rows.Scan(&permission); err != nil {
+			return nil, err
+		}
+		permissions = append(permissions, permission)
+	}
+
+	return permissions, rows.Err()
+}
+
+// ReplaceRolePermissions grants the role exactly the given permissions in a single transaction
+func (pr *PermissionRepository) ReplaceRolePermissions(ctx context.Context, role domain.UserRole, permissions []domain.Permission) (err error) {
+	tx, err := pr.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+
+	deleteQuery := pr.db.QueryBuilder.Delete("role_permissions").
+		Where(sq.Eq{"role": role})
+
+	sql, args, err := deleteQuery.ToSql()
+	if err != nil {
+		return err
+	}
+
+	if _, err = tx.Exec(ctx, sql, args...); err != nil {
+		return err
+	}
+
+	if len(permissions) > 0 {
+		now := time.Now()
+		insertQuery := pr.db.QueryBuilder.Insert("role_permissions").
+			Columns("role", "permission", "created_at")
+		for _, permission := range permissions {
+			insertQuery = insertQuery.Values(role, permission, now)
+		}
+
+		sql, args, err = insertQuery.ToSql()
+		if err != nil {
+			return err
+		}
+
+		if _, err = tx.Exec(ctx, sql, args...); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,243 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/aldotp/employee-attendance-system/internal/adapter/storage/postgres"
+	"github.com/aldotp/employee-attendance-system/internal/core/domain"
+	"github.com/aldotp/employee-attendance-system/pkg/consts"
+	"github.com/jackc/pgx/v5"
+)
+
+type RotationRepository struct {
+	db *postgres.DB
+}
+
+func NewRotationRepository(db *postgres.DB) *RotationRepository {
+	return &RotationRepository{
+		db: db,
+	}
+}
+
+func (rr *RotationRepository) CreateRotationPattern(ctx context.Context, pattern *domain.RotationPattern) (*domain.RotationPattern, error) {
+	query := rr.db.QueryBuilder.Insert("rotation_patterns").
+		Columns("id", "name", "description", "days", "created_at", "updated_at").
+		Values(pattern.ID, pattern.Name, pattern.Description, pattern.Days, pattern.CreatedAt, pattern.UpdatedAt).
+		Suffix("RETURNING id, name, COALESCE(description, ''), days, created_at, updated_at")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	err = rr.db.QueryRow(ctx, sql, args...).Scan(
+		&pattern.ID,
+		&pattern.Name,
+		&pattern.Description,
+		&pattern.Days,
+		&pattern.CreatedAt,
+		&pattern.UpdatedAt,
+	)
+	if err != nil {
+		if errCode := rr.db.ErrorCode(err); errCode == postgres.UniqueViolationCode {
+			return nil, consts.ErrConflictingData
+		}
+		return nil, err
+	}
+
+	return pattern, nil
+}
+
+func (rr *RotationRepository) ListRotationPatterns(ctx context.Context) ([]domain.RotationPattern, error) {
+	var patterns []domain.RotationPattern
+
+	query := rr.db.QueryBuilder.Select("id", "name", "COALESCE(description, '')", "days", "created_at", "updated_at").
+		From("rotation_patterns").
+		OrderBy("name ASC")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := rr.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var pattern domain.RotationPattern
+		err := rows.Scan(
+			&pattern.ID,
+			&pattern.Name,
+			&pattern.Description,
+			&pattern.Days,
+			&pattern.CreatedAt,
+			&pattern.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		patterns = append(patterns, pattern)
+	}
+
+	return patterns, nil
+}
+
+func (rr *RotationRepository) GetRotationPattern(ctx context.Context, id string) (*domain.RotationPattern, error) {
+	var pattern domain.RotationPattern
+
+	query := rr.db.QueryBuilder.Select("id", "name", "COALESCE(description, '')", "days", "created_at", "updated_at").
+		From("rotation_patterns").
+		Where(sq.Eq{"id": id})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	err = rr.db.QueryRow(ctx, sql, args...).Scan(
+		&pattern.ID,
+		&pattern.Name,
+		&pattern.Description,
+		&pattern.Days,
+		&pattern.CreatedAt,
+		&pattern.UpdatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, consts.ErrDataNotFound
+		}
+		return nil, err
+	}
+
+	return &pattern, nil
+}
+
+func (rr *RotationRepository) DeleteRotationPattern(ctx context.Context, id string) error {
+	query := rr.db.QueryBuilder.Delete("rotation_patterns").
+		Where(sq.Eq{"id": id})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = rr.db.Exec(ctx, sql, args...)
+	if err != nil {
+		// patterns still assigned to employees are protected by the foreign key
+		if errCode := rr.db.ErrorCode(err); errCode == postgres.ForeignKeyViolationCode {
+			return consts.ErrConflictingData
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (rr *RotationRepository) CreateRotationAssignment(ctx context.Context, assignment *domain.RotationAssignment) (*domain.RotationAssignment, error) {
+	query := rr.db.QueryBuilder.Insert("rotation_assignments").
+		Columns("id", "user_id", "rotation_pattern_id", "start_date", "end_date", "work_location_id", "created_at", "updated_at").
+		Values(assignment.ID, assignment.UserID, assignment.RotationPatternID, assignment.StartDate, assignment.EndDate,
+			nullString(assignment.WorkLocationID), assignment.CreatedAt, assignment.UpdatedAt).
+		Suffix("RETURNING id, user_id, rotation_pattern_id, start_date, end_date, COALESCE(work_location_id::text, ''), created_at, updated_at")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	err = rr.db.QueryRow(ctx, sql, args...).Scan(
+		&assignment.ID,
+		&assignment.UserID,
+		&assignment.RotationPatternID,
+		&assignment.StartDate,
+		&assignment.EndDate,
+		&assignment.WorkLocationID,
+		&assignment.CreatedAt,
+		&assignment.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return assignment, nil
+}
+
+// ListRotationAssignments retrieves the assignments of a user, along with their pattern, that
+// overlap the period between startDate and endDate
+func (rr *RotationRepository) ListRotationAssignments(ctx context.Context, userID string, startDate, endDate time.Time) ([]domain.RotationAssignment, error) {
+	var assignments []domain.RotationAssignment
+
+	query := rr.db.QueryBuilder.Select(
+		"a.id", "a.user_id", "a.rotation_pattern_id", "a.start_date", "a.end_date",
+		"COALESCE(a.work_location_id::text, '')", "a.created_at", "a.updated_at",
+		"p.id", "p.name", "COALESCE(p.description, '')", "p.days", "p.created_at", "p.updated_at",
+	).
+		From("rotation_assignments a").
+		Join("rotation_patterns p ON p.id = a.rotation_pattern_id").
+		Where(sq.And{
+			sq.Eq{"a.user_id": userID},
+			sq.LtOrEq{"a.start_date": endDate},
+			sq.Or{
+				sq.Eq{"a.end_date": nil},
+				sq.GtOrEq{"a.end_date": startDate},
+			},
+		}).
+		OrderBy("a.start_date ASC")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := rr.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var assignment domain.RotationAssignment
+		var pattern domain.RotationPattern
+		err := rows.Scan(
+			&assignment.ID,
+			&assignment.UserID,
+			&assignment.RotationPatternID,
+			&assignment.StartDate,
+			&assignment.EndDate,
+			&assignment.WorkLocationID,
+			&assignment.CreatedAt,
+			&assignment.UpdatedAt,
+			&pattern.ID,
+			&pattern.Name,
+			&pattern.Description,
+			&pattern.Days,
+			&pattern.CreatedAt,
+			&pattern.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		assignment.Pattern = &pattern
+		assignments = append(assignments, assignment)
+	}
+
+	return assignments, nil
+}
+
+func (rr *RotationRepository) DeleteRotationAssignment(ctx context.Context, id string) error {
+	query := rr.db.QueryBuilder.Delete("rotation_assignments").
+		Where(sq.Eq{"id": id})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = rr.db.Exec(ctx, sql, args...)
+	return err
+}
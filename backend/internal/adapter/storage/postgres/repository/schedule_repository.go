@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	sq "github.com/Masterminds/squirrel"
@@ -22,25 +23,15 @@ func NewScheduleRepository(db *postgres.DB) *ScheduleRepository {
 	}
 }
 
-func (sr *ScheduleRepository) ListSchedules(ctx context.Context) ([]domain.Schedule, error) {
+func (sr *ScheduleRepository) ListSchedules(ctx context.Context, filter domain.OwnershipFilter) ([]domain.Schedule, error) {
 	var schedules []domain.Schedule
 
-	query := sr.db.QueryBuilder.Select(
-		"id",
-		"user_id",
-		"date",
-		"shift_start",
-		"shift_end",
-		"break_start",
-		"break_end",
-		"work_location_id",
-		"schedule_type",
-		"created_at",
-		"updated_at",
-	).
+	query := sr.db.QueryBuilder.Select(scheduleColumns...).
 		From("schedules").
 		OrderBy("created_at DESC")
 
+	query = whereOwnership(query, filter, "user_id")
+
 	sql, args, err := query.ToSql()
 	if err != nil {
 		return nil, err
@@ -54,19 +45,7 @@ func (sr *ScheduleRepository) ListSchedules(ctx context.Context) ([]domain.Sched
 
 	for rows.Next() {
 		var schedule domain.Schedule
-		err := rows.Scan(
-			&schedule.ID,
-			&schedule.UserID,
-			&schedule.Date,
-			&schedule.ShiftStart,
-			&schedule.ShiftEnd,
-			&schedule.BreakStart,
-			&schedule.BreakEnd,
-			&schedule.WorkLocationID,
-			&schedule.ScheduleType,
-			&schedule.CreatedAt,
-			&schedule.UpdatedAt,
-		)
+		err := scanSchedule(rows, &schedule)
 		if err != nil {
 			return schedules, err
 		}
@@ -91,35 +70,24 @@ func (sr *ScheduleRepository) CreateSchedule(ctx context.Context, schedule *doma
 	query := sr.db.QueryBuilder.Insert("schedules").
 		Columns(
 			"id", "user_id", "date", "shift_start", "shift_end",
-			"break_start", "break_end", "work_location_id", "schedule_type",
-			"created_at", "updated_at",
+			"break_start", "break_end", "core_start", "core_end", "min_duration_minutes",
+			"work_location_id", "schedule_type", "created_at", "updated_at",
 		).
 		Values(
 			schedule.ID, schedule.UserID, schedule.Date, schedule.ShiftStart,
-			schedule.ShiftEnd, schedule.BreakStart, schedule.BreakEnd,
-			schedule.WorkLocationID, schedule.ScheduleType,
+			schedule.ShiftEnd, nullString(schedule.BreakStart), nullString(schedule.BreakEnd),
+			nullString(schedule.CoreStart), nullString(schedule.CoreEnd), nullInt64(int64(schedule.MinDurationMinutes)),
+			nullString(schedule.WorkLocationID), schedule.ScheduleType,
 			schedule.CreatedAt, schedule.UpdatedAt,
 		).
-		Suffix("RETURNING *")
+		Suffix("RETURNING " + strings.Join(scheduleColumns, ", "))
 
 	sql, args, err := query.ToSql()
 	if err != nil {
 		return nil, err
 	}
 
-	err = sr.db.QueryRow(ctx, sql, args...).Scan(
-		&schedule.ID,
-		&schedule.UserID,
-		&schedule.Date,
-		&schedule.ShiftStart,
-		&schedule.ShiftEnd,
-		&schedule.BreakStart,
-		&schedule.BreakEnd,
-		&schedule.WorkLocationID,
-		&schedule.ScheduleType,
-		&schedule.CreatedAt,
-		&schedule.UpdatedAt,
-	)
+	err = scanSchedule(sr.db.QueryRow(ctx, sql, args...), schedule)
 	if err != nil {
 		return nil, err
 	}
@@ -130,11 +98,7 @@ func (sr *ScheduleRepository) CreateSchedule(ctx context.Context, schedule *doma
 func (sr *ScheduleRepository) GetSchedule(ctx context.Context, id string) (*domain.Schedule, error) {
 	var schedule domain.Schedule
 
-	query := sr.db.QueryBuilder.Select(
-		"id", "user_id", "date", "shift_start", "shift_end",
-		"break_start", "break_end", "work_location_id", "schedule_type",
-		"created_at", "updated_at",
-	).
+	query := sr.db.QueryBuilder.Select(scheduleColumns...).
 		From("schedules").
 		Where(sq.Eq{"id": id}).
 		Limit(1)
@@ -144,19 +108,7 @@ func (sr *ScheduleRepository) GetSchedule(ctx context.Context, id string) (*doma
 		return nil, err
 	}
 
-	err = sr.db.QueryRow(ctx, sql, args...).Scan(
-		&schedule.ID,
-		&schedule.UserID,
-		&schedule.Date,
-		&schedule.ShiftStart,
-		&schedule.ShiftEnd,
-		&schedule.BreakStart,
-		&schedule.BreakEnd,
-		&schedule.WorkLocationID,
-		&schedule.ScheduleType,
-		&schedule.CreatedAt,
-		&schedule.UpdatedAt,
-	)
+	err = scanSchedule(sr.db.QueryRow(ctx, sql, args...), &schedule)
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, consts.ErrDataNotFound
@@ -175,32 +127,23 @@ func (sr *ScheduleRepository) UpdateSchedule(ctx context.Context, id string, sch
 		Set("date", sq.Expr("COALESCE(?, date)", schedule.Date)).
 		Set("shift_start", sq.Expr("COALESCE(?, shift_start)", schedule.ShiftStart)).
 		Set("shift_end", sq.Expr("COALESCE(?, shift_end)", schedule.ShiftEnd)).
-		Set("break_start", sq.Expr("COALESCE(?, break_start)", schedule.BreakStart)).
-		Set("break_end", sq.Expr("COALESCE(?, break_end)", schedule.BreakEnd)).
-		Set("work_location_id", sq.Expr("COALESCE(?, work_location_id)", schedule.WorkLocationID)).
+		Set("break_start", sq.Expr("COALESCE(?, break_start)", nullString(schedule.BreakStart))).
+		Set("break_end", sq.Expr("COALESCE(?, break_end)", nullString(schedule.BreakEnd))).
+		Set("work_location_id", sq.Expr("COALESCE(?, work_location_id)", nullString(schedule.WorkLocationID))).
+		Set("core_start", sq.Expr("COALESCE(?, core_start)", nullString(schedule.CoreStart))).
+		Set("core_end", sq.Expr("COALESCE(?, core_end)", nullString(schedule.CoreEnd))).
+		Set("min_duration_minutes", sq.Expr("COALESCE(?, min_duration_minutes)", nullInt64(int64(schedule.MinDurationMinutes)))).
 		Set("schedule_type", sq.Expr("COALESCE(?, schedule_type)", schedule.ScheduleType)).
 		Set("updated_at", schedule.UpdatedAt).
 		Where(sq.Eq{"id": id}).
-		Suffix("RETURNING *")
+		Suffix("RETURNING " + strings.Join(scheduleColumns, ", "))
 
 	sql, args, err := query.ToSql()
 	if err != nil {
 		return nil, err
 	}
 
-	err = sr.db.QueryRow(ctx, sql, args...).Scan(
-		&schedule.ID,
-		&schedule.UserID,
-		&schedule.Date,
-		&schedule.ShiftStart,
-		&schedule.ShiftEnd,
-		&schedule.BreakStart,
-		&schedule.BreakEnd,
-		&schedule.WorkLocationID,
-		&schedule.ScheduleType,
-		&schedule.CreatedAt,
-		&schedule.UpdatedAt,
-	)
+	err = scanSchedule(sr.db.QueryRow(ctx, sql, args...), schedule)
 	if err != nil {
 		return nil, err
 	}
@@ -221,18 +164,272 @@ func (sr *ScheduleRepository) DeleteSchedule(ctx context.Context, id string) err
 	return err
 }
 
-func (sr *ScheduleRepository) RequestScheduleSwap(ctx context.Context, requestorID string, targetScheduleID string, proposedScheduleID string) error {
+func (sr *ScheduleRepository) RequestScheduleSwap(ctx context.Context, swap *domain.ScheduleSwapRequest) (*domain.ScheduleSwapRequest, error) {
 	query := sr.db.QueryBuilder.Insert("schedule_swap_requests").
-		Columns("requestor_id", "target_schedule_id", "proposed_schedule_id", "status", "created_at").
-		Values(requestorID, targetScheduleID, proposedScheduleID, "pending", time.Now())
+		Columns("id", "requestor_id", "target_user_id", "proposed_schedule_id", "target_schedule_id", "reason", "status", "created_at", "updated_at").
+		Values(swap.ID, swap.RequestorID, swap.TargetUserID, swap.ProposedScheduleID, swap.TargetScheduleID, swap.Reason, swap.Status, swap.CreatedAt, swap.UpdatedAt).
+		Suffix("RETURNING " + scheduleSwapColumns)
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	return scanScheduleSwap(sr.db.QueryRow(ctx, sql, args...))
+}
+
+func (sr *ScheduleRepository) GetScheduleSwapRequest(ctx context.Context, id string) (*domain.ScheduleSwapRequest, error) {
+	query := sr.db.QueryBuilder.Select(scheduleSwapColumns).
+		From("schedule_swap_requests").
+		Where(sq.Eq{"id": id})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	swap, err := scanScheduleSwap(sr.db.QueryRow(ctx, sql, args...))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, consts.ErrDataNotFound
+		}
+		return nil, err
+	}
+
+	return swap, nil
+}
+
+// ListScheduleSwapRequests lists the swap requests a user is part of, either as requestor or
+// as target. An empty userID lists the requests of everyone and an empty status any status.
+func (sr *ScheduleRepository) ListScheduleSwapRequests(ctx context.Context, userID string, status domain.SwapStatus) ([]domain.ScheduleSwapRequest, error) {
+	var swaps []domain.ScheduleSwapRequest
+
+	query := sr.db.QueryBuilder.Select(scheduleSwapColumns).
+		From("schedule_swap_requests").
+		OrderBy("created_at DESC")
+
+	if userID != "" {
+		query = query.Where(sq.Or{
+			sq.Eq{"requestor_id": userID},
+			sq.Eq{"target_user_id": userID},
+		})
+	}
+
+	if status != "" {
+		query = query.Where(sq.Eq{"status": status})
+	}
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := sr.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		swap, err := scanScheduleSwap(rows)
+		if err != nil {
+			return nil, err
+		}
+		swaps = append(swaps, *swap)
+	}
+
+	return swaps, nil
+}
+
+// CountOpenScheduleSwaps counts the pending or accepted swap requests involving any of the schedules
+func (sr *ScheduleRepository) CountOpenScheduleSwaps(ctx context.Context, scheduleIDs ...string) (int, error) {
+	var count int
+
+	query := sr.db.QueryBuilder.Select("COUNT(*)").
+		From("schedule_swap_requests").
+		Where(sq.Eq{"status": []domain.SwapStatus{domain.SwapPending, domain.SwapAccepted}}).
+		Where(sq.Or{
+			sq.Eq{"proposed_schedule_id": scheduleIDs},
+			sq.Eq{"target_schedule_id": scheduleIDs},
+		})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	err = sr.db.QueryRow(ctx, sql, args...).Scan(&count)
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// UpdateScheduleSwapStatus moves a swap request out of status `from`. It returns
+// consts.ErrScheduleSwapClosed when the request is no longer in that status.
+func (sr *ScheduleRepository) UpdateScheduleSwapStatus(ctx context.Context, swap *domain.ScheduleSwapRequest, from domain.SwapStatus) error {
+	query := sr.db.QueryBuilder.Update("schedule_swap_requests").
+		Set("status", swap.Status).
+		Set("responded_at", swap.RespondedAt).
+		Set("reviewed_by", nullString(swap.ReviewedBy)).
+		Set("reviewed_at", swap.ReviewedAt).
+		Set("note", swap.Note).
+		Set("updated_at", time.Now()).
+		Where(sq.Eq{"id": swap.ID, "status": from})
 
 	sql, args, err := query.ToSql()
 	if err != nil {
 		return err
 	}
 
-	_, err = sr.db.Exec(ctx, sql, args...)
-	return err
+	tag, err := sr.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return consts.ErrScheduleSwapClosed
+	}
+
+	return nil
+}
+
+// ApproveScheduleSwap approves an accepted swap request and exchanges the owners of both
+// schedules in a single transaction. The swap and schedule rows are locked so a concurrent
+// decision or schedule edit cannot interleave with the exchange.
+func (sr *ScheduleRepository) ApproveScheduleSwap(ctx context.Context, id string, reviewerID string, note string) (err error) {
+	tx, err := sr.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+
+	query := sr.db.QueryBuilder.Select(scheduleSwapColumns).
+		From("schedule_swap_requests").
+		Where(sq.Eq{"id": id}).
+		Suffix("FOR UPDATE")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	swap, err := scanScheduleSwap(tx.QueryRow(ctx, sql, args...))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return consts.ErrDataNotFound
+		}
+		return err
+	}
+
+	if swap.Status != domain.SwapAccepted {
+		return consts.ErrScheduleSwapClosed
+	}
+
+	owners := map[string]string{}
+	query = sr.db.QueryBuilder.Select("id", "user_id").
+		From("schedules").
+		Where(sq.Eq{"id": []string{swap.ProposedScheduleID, swap.TargetScheduleID}}).
+		Suffix("FOR UPDATE")
+
+	sql, args, err = query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	rows, err := tx.Query(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+
+	for rows.Next() {
+		var scheduleID, userID string
+		if err = rows.Scan(&scheduleID, &userID); err != nil {
+			rows.Close()
+			return err
+		}
+		owners[scheduleID] = userID
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	// a schedule that was reassigned or deleted since the request was made invalidates it
+	if owners[swap.ProposedScheduleID] != swap.RequestorID || owners[swap.TargetScheduleID] != swap.TargetUserID {
+		return consts.ErrScheduleSwapConflict
+	}
+
+	now := time.Now()
+	for scheduleID, userID := range map[string]string{
+		swap.ProposedScheduleID: swap.TargetUserID,
+		swap.TargetScheduleID:   swap.RequestorID,
+	} {
+		update := sr.db.QueryBuilder.Update("schedules").
+			Set("user_id", userID).
+			Set("updated_at", now).
+			Where(sq.Eq{"id": scheduleID})
+
+		sql, args, err = update.ToSql()
+		if err != nil {
+			return err
+		}
+
+		if _, err = tx.Exec(ctx, sql, args...); err != nil {
+			return err
+		}
+	}
+
+	update := sr.db.QueryBuilder.Update("schedule_swap_requests").
+		Set("status", domain.SwapApproved).
+		Set("reviewed_by", reviewerID).
+		Set("reviewed_at", now).
+		Set("note", note).
+		Set("updated_at", now).
+		Where(sq.Eq{"id": id})
+
+	sql, args, err = update.ToSql()
+	if err != nil {
+		return err
+	}
+
+	if _, err = tx.Exec(ctx, sql, args...); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+const scheduleSwapColumns = "id, requestor_id, target_user_id, proposed_schedule_id, target_schedule_id, COALESCE(reason, ''), status, " +
+	"responded_at, COALESCE(reviewed_by::text, ''), reviewed_at, COALESCE(note, ''), created_at, updated_at"
+
+func scanScheduleSwap(row pgx.Row) (*domain.ScheduleSwapRequest, error) {
+	var swap domain.ScheduleSwapRequest
+	err := row.Scan(
+		&swap.ID,
+		&swap.RequestorID,
+		&swap.TargetUserID,
+		&swap.ProposedScheduleID,
+		&swap.TargetScheduleID,
+		&swap.Reason,
+		&swap.Status,
+		&swap.RespondedAt,
+		&swap.ReviewedBy,
+		&swap.ReviewedAt,
+		&swap.Note,
+		&swap.CreatedAt,
+		&swap.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &swap, nil
 }
 
 // GetWorkCalendar retrieves all schedules for an employee in a specific month and year
@@ -240,16 +437,16 @@ func (sr *ScheduleRepository) GetWorkCalendar(ctx context.Context, userID string
 	var schedules []domain.Schedule
 
 	startDate := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
-	endDate := startDate.AddDate(0, 1, 0).Add(-time.Second)
+	endDate := startDate.AddDate(0, 1, -1)
 
-	query := sr.db.QueryBuilder.Select("*").
+	query := sr.db.QueryBuilder.Select(scheduleColumns...).
 		From("schedules").
 		Where(sq.And{
 			sq.Eq{"user_id": userID},
-			sq.GtOrEq{"start_time": startDate},
-			sq.LtOrEq{"end_time": endDate},
+			sq.GtOrEq{"date": startDate},
+			sq.LtOrEq{"date": endDate},
 		}).
-		OrderBy("start_time ASC")
+		OrderBy("date ASC", "shift_start ASC")
 
 	sql, args, err := query.ToSql()
 	if err != nil {
@@ -264,19 +461,7 @@ func (sr *ScheduleRepository) GetWorkCalendar(ctx context.Context, userID string
 
 	for rows.Next() {
 		var schedule domain.Schedule
-		err := rows.Scan(
-			&schedule.ID,
-			&schedule.UserID,
-			&schedule.Date,
-			&schedule.ShiftStart,
-			&schedule.ShiftEnd,
-			&schedule.BreakStart,
-			&schedule.BreakEnd,
-			&schedule.WorkLocationID,
-			&schedule.ScheduleType,
-			&schedule.CreatedAt,
-			&schedule.UpdatedAt,
-		)
+		err := scanSchedule(rows, &schedule)
 		if err != nil {
 			return schedules, err
 		}
@@ -286,17 +471,19 @@ func (sr *ScheduleRepository) GetWorkCalendar(ctx context.Context, userID string
 	return schedules, nil
 }
 
-// GetWorkRotation retrieves the current work rotation schedule for an employee
-func (sr *ScheduleRepository) GetWorkRotation(ctx context.Context, employeeID string) (*domain.Schedule, error) {
+// GetWorkRotation retrieves the next upcoming schedule of an employee, starting today
+func (sr *ScheduleRepository) GetWorkRotation(ctx context.Context, userID string) (*domain.Schedule, error) {
 	var schedule domain.Schedule
 
-	query := sr.db.QueryBuilder.Select("*").
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+
+	query := sr.db.QueryBuilder.Select(scheduleColumns...).
 		From("schedules").
 		Where(sq.And{
-			sq.Eq{"user_id": employeeID},
-			sq.GtOrEq{"end_time": time.Now()},
+			sq.Eq{"user_id": userID},
+			sq.GtOrEq{"date": today},
 		}).
-		OrderBy("start_time ASC").
+		OrderBy("date ASC", "shift_start ASC").
 		Limit(1)
 
 	sql, args, err := query.ToSql()
@@ -304,7 +491,31 @@ func (sr *ScheduleRepository) GetWorkRotation(ctx context.Context, employeeID st
 		return nil, err
 	}
 
-	err = sr.db.QueryRow(ctx, sql, args...).Scan(
+	err = scanSchedule(sr.db.QueryRow(ctx, sql, args...), &schedule)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, consts.ErrDataNotFound
+		}
+		return nil, err
+	}
+
+	return &schedule, nil
+}
+
+// scheduleColumns selects the time and optional columns of schedules as text so they
+// scan into the string fields of domain.Schedule
+var scheduleColumns = []string{
+	"id", "user_id", "date",
+	"TO_CHAR(shift_start, 'HH24:MI')", "TO_CHAR(shift_end, 'HH24:MI')",
+	"COALESCE(TO_CHAR(break_start, 'HH24:MI'), '')", "COALESCE(TO_CHAR(break_end, 'HH24:MI'), '')",
+	"COALESCE(TO_CHAR(core_start, 'HH24:MI'), '')", "COALESCE(TO_CHAR(core_end, 'HH24:MI'), '')",
+	"COALESCE(min_duration_minutes, 0)",
+	"COALESCE(work_location_id::text, '')", "schedule_type",
+	"created_at", "updated_at",
+}
+
+func scanSchedule(row pgx.Row, schedule *domain.Schedule) error {
+	return row.Scan(
 		&schedule.ID,
 		&schedule.UserID,
 		&schedule.Date,
@@ -312,17 +523,228 @@ func (sr *ScheduleRepository) GetWorkRotation(ctx context.Context, employeeID st
 		&schedule.ShiftEnd,
 		&schedule.BreakStart,
 		&schedule.BreakEnd,
+		&schedule.CoreStart,
+		&schedule.CoreEnd,
+		&schedule.MinDurationMinutes,
 		&schedule.WorkLocationID,
 		&schedule.ScheduleType,
 		&schedule.CreatedAt,
 		&schedule.UpdatedAt,
 	)
+}
+
+// ListDepartmentSchedules retrieves the schedules of a department's employees between two dates
+func (sr *ScheduleRepository) ListDepartmentSchedules(ctx context.Context, departmentID string, startDate, endDate time.Time) ([]domain.Schedule, error) {
+	var schedules []domain.Schedule
+
+	query := sr.db.QueryBuilder.Select(scheduleColumns...).
+		From("schedules").
+		Where(sq.Expr("user_id IN (SELECT user_id FROM employees WHERE department_id = ?)", departmentID)).
+		Where(sq.GtOrEq{"date": startDate}).
+		Where(sq.LtOrEq{"date": endDate}).
+		OrderBy("date ASC")
+
+	sql, args, err := query.ToSql()
 	if err != nil {
-		if err == pgx.ErrNoRows {
-			return nil, consts.ErrDataNotFound
+		return nil, err
+	}
+
+	rows, err := sr.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var schedule domain.Schedule
+		err := scanSchedule(rows, &schedule)
+		if err != nil {
+			return schedules, err
 		}
+		schedules = append(schedules, schedule)
+	}
+
+	return schedules, nil
+}
+
+// ListSchedulesByUsers retrieves the schedules of the given users between two dates
+func (sr *ScheduleRepository) ListSchedulesByUsers(ctx context.Context, userIDs []string, startDate, endDate time.Time) ([]domain.Schedule, error) {
+	var schedules []domain.Schedule
+
+	query := sr.db.QueryBuilder.Select(scheduleColumns...).
+		From("schedules").
+		Where(sq.Expr("user_id = ANY(?::uuid[])", userIDs)).
+		Where(sq.GtOrEq{"date": startDate}).
+		Where(sq.LtOrEq{"date": endDate}).
+		OrderBy("date ASC")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
 		return nil, err
 	}
 
-	return &schedule, nil
+	rows, err := sr.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var schedule domain.Schedule
+		err := scanSchedule(rows, &schedule)
+		if err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, schedule)
+	}
+
+	return schedules, nil
+}
+
+// ListSchedulesByDate retrieves the schedules of every employee on a business date
+func (sr *ScheduleRepository) ListSchedulesByDate(ctx context.Context, date time.Time) ([]domain.Schedule, error) {
+	var schedules []domain.Schedule
+
+	query := sr.db.QueryBuilder.Select(scheduleColumns...).
+		From("schedules").
+		Where(sq.Eq{"date": date}).
+		OrderBy("user_id ASC", "shift_start ASC")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := sr.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var schedule domain.Schedule
+		err := scanSchedule(rows, &schedule)
+		if err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, schedule)
+	}
+
+	return schedules, nil
+}
+
+// ListSchedulesStartingOrEnding retrieves the schedules whose shift starts after startAfter up to
+// startUntil, or ends after endAfter up to endUntil. Shift times are taken in the employee's
+// timezone, UTC when it is unknown, and employees no longer active are left out.
+func (sr *ScheduleRepository) ListSchedulesStartingOrEnding(ctx context.Context, startAfter, startUntil, endAfter, endUntil time.Time) ([]domain.Schedule, error) {
+	var schedules []domain.Schedule
+
+	// schedules are dated in the employee's timezone, which can be a day away from UTC, and
+	// overnight shifts end the day after their date
+	from, until := startAfter, startUntil
+	if endAfter.Before(from) {
+		from = endAfter
+	}
+	if endUntil.After(until) {
+		until = endUntil
+	}
+	from = time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, -2)
+	until = time.Date(until.Year(), until.Month(), until.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, 1)
+
+	// timezones PostgreSQL does not know fall back to UTC instead of failing the whole query
+	located := sr.db.QueryBuilder.Select("schedules.*", "COALESCE(tz.name, 'UTC') AS shift_timezone").
+		From("schedules").
+		LeftJoin("employees ON employees.user_id = schedules.user_id").
+		LeftJoin("pg_timezone_names tz ON tz.name = employees.timezone").
+		Where(sq.GtOrEq{"schedules.date": from}).
+		Where(sq.LtOrEq{"schedules.date": until}).
+		Where(sq.Or{sq.Eq{"employees.status": nil}, sq.Eq{"employees.status": domain.StatusActive}})
+
+	startAt := "((date + shift_start) AT TIME ZONE shift_timezone)"
+	endAt := "((date + shift_end + CASE WHEN shift_end <= shift_start THEN INTERVAL '1 day' ELSE INTERVAL '0' END) AT TIME ZONE shift_timezone)"
+
+	query := sr.db.QueryBuilder.Select(scheduleColumns...).
+		FromSelect(located, "schedules").
+		Where(sq.Or{
+			sq.Expr("("+startAt+" > ? AND "+startAt+" <= ?)", startAfter, startUntil),
+			sq.Expr("("+endAt+" > ? AND "+endAt+" <= ?)", endAfter, endUntil),
+		}).
+		OrderBy("date ASC", "user_id ASC")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := sr.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var schedule domain.Schedule
+		err := scanSchedule(rows, &schedule)
+		if err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, schedule)
+	}
+
+	return schedules, nil
+}
+
+// bulkInsertBatchSize keeps each INSERT well below the 65535 parameters PostgreSQL accepts
+const bulkInsertBatchSize = 1000
+
+// BulkCreateSchedules inserts the schedules in batches within a single transaction, so either
+// all of them are created or none is
+func (sr *ScheduleRepository) BulkCreateSchedules(ctx context.Context, schedules []domain.Schedule) (created int, err error) {
+	tx, err := sr.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+
+	for start := 0; start < len(schedules); start += bulkInsertBatchSize {
+		end := min(start+bulkInsertBatchSize, len(schedules))
+
+		query := sr.db.QueryBuilder.Insert("schedules").
+			Columns(
+				"id", "user_id", "date", "shift_start", "shift_end",
+				"break_start", "break_end", "core_start", "core_end", "min_duration_minutes",
+				"work_location_id", "schedule_type", "created_at", "updated_at",
+			)
+
+		for _, schedule := range schedules[start:end] {
+			query = query.Values(
+				schedule.ID, schedule.UserID, schedule.Date, schedule.ShiftStart,
+				schedule.ShiftEnd, nullString(schedule.BreakStart), nullString(schedule.BreakEnd),
+				nullString(schedule.CoreStart), nullString(schedule.CoreEnd), nullInt64(int64(schedule.MinDurationMinutes)),
+				nullString(schedule.WorkLocationID), schedule.ScheduleType,
+				schedule.CreatedAt, schedule.UpdatedAt,
+			)
+		}
+
+		sql, args, err := query.ToSql()
+		if err != nil {
+			return 0, err
+		}
+
+		tag, err := tx.Exec(ctx, sql, args...)
+		if err != nil {
+			return 0, err
+		}
+		created += int(tag.RowsAffected())
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+
+	return created, nil
 }
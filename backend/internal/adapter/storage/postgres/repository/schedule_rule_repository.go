@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/aldotp/employee-attendance-system/internal/adapter/storage/postgres"
+	"github.com/aldotp/employee-attendance-system/internal/core/domain"
+	"github.com/aldotp/employee-attendance-system/pkg/consts"
+	"github.com/jackc/pgx/v5"
+)
+
+type ScheduleRuleRepository struct {
+	db *postgres.DB
+}
+
+func NewScheduleRuleRepository(db *postgres.DB) *ScheduleRuleRepository {
+	return &ScheduleRuleRepository{
+		db: db,
+	}
+}
+
+const scheduleRuleColumns = "id, COALESCE(department_id::text, ''), COALESCE(country, ''), min_rest_hours, max_weekly_hours, " +
+	"max_consecutive_days, created_at, updated_at"
+
+func (rr *ScheduleRuleRepository) CreateScheduleRule(ctx context.Context, rule *domain.ScheduleRule) (*domain.ScheduleRule, error) {
+	query := rr.db.QueryBuilder.Insert("schedule_rules").
+		Columns("id", "department_id", "country", "min_rest_hours", "max_weekly_hours", "max_consecutive_days", "created_at", "updated_at").
+		Values(rule.ID, nullString(rule.DepartmentID), nullString(rule.Country), rule.MinRestHours, rule.MaxWeeklyHours,
+			rule.MaxConsecutiveDays, rule.CreatedAt, rule.UpdatedAt).
+		Suffix("RETURNING " + scheduleRuleColumns)
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rule, err = scanScheduleRule(rr.db.QueryRow(ctx, sql, args...))
+	if err != nil {
+		switch rr.db.ErrorCode(err) {
+		case postgres.UniqueViolationCode:
+			return nil, consts.ErrConflictingData
+		case postgres.ForeignKeyViolationCode:
+			return nil, consts.ErrDataNotFound
+		}
+		return nil, err
+	}
+
+	return rule, nil
+}
+
+func (rr *ScheduleRuleRepository) ListScheduleRules(ctx context.Context) ([]domain.ScheduleRule, error) {
+	var rules []domain.ScheduleRule
+
+	query := rr.db.QueryBuilder.Select(scheduleRuleColumns).
+		From("schedule_rules").
+		OrderBy("created_at ASC")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := rr.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		rule, err := scanScheduleRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, *rule)
+	}
+
+	return rules, nil
+}
+
+// FindScheduleRule returns the rule of the department if there is one, otherwise the rule of
+// the country the work location is in
+func (rr *ScheduleRuleRepository) FindScheduleRule(ctx context.Context, departmentID string, workLocationID string) (*domain.ScheduleRule, error) {
+	query := rr.db.QueryBuilder.Select(scheduleRuleColumns).
+		From("schedule_rules").
+		Where(sq.Or{
+			sq.Expr("department_id = ?", nullString(departmentID)),
+			sq.Expr("LOWER(country) = (SELECT LOWER(country) FROM work_locations WHERE id = ?)", nullString(workLocationID)),
+		}).
+		OrderBy("department_id NULLS LAST").
+		Limit(1)
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rule, err := scanScheduleRule(rr.db.QueryRow(ctx, sql, args...))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, consts.ErrDataNotFound
+		}
+		return nil, err
+	}
+
+	return rule, nil
+}
+
+func (rr *ScheduleRuleRepository) DeleteScheduleRule(ctx context.Context, id string) error {
+	query := rr.db.QueryBuilder.Delete("schedule_rules").
+		Where(sq.Eq{"id": id})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = rr.db.Exec(ctx, sql, args...)
+	return err
+}
+
+func scanScheduleRule(row pgx.Row) (*domain.ScheduleRule, error) {
+	var rule domain.ScheduleRule
+	err := row.Scan(
+		&rule.ID,
+		&rule.DepartmentID,
+		&rule.Country,
+		&rule.MinRestHours,
+		&rule.MaxWeeklyHours,
+		&rule.MaxConsecutiveDays,
+		&rule.CreatedAt,
+		&rule.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rule, nil
+}
@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"context"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/aldotp/employee-attendance-system/internal/adapter/storage/postgres"
+	"github.com/aldotp/employee-attendance-system/internal/core/domain"
+	"github.com/aldotp/employee-attendance-system/pkg/consts"
+	"github.com/jackc/pgx/v5"
+)
+
+type ShiftTemplateRepository struct {
+	db *postgres.DB
+}
+
+func NewShiftTemplateRepository(db *postgres.DB) *ShiftTemplateRepository {
+	return &ShiftTemplateRepository{
+		db: db,
+	}
+}
+
+const shiftTemplateColumns = "id, name, TO_CHAR(shift_start, 'HH24:MI'), TO_CHAR(shift_end, 'HH24:MI'), " +
+	"COALESCE(TO_CHAR(break_start, 'HH24:MI'), ''), COALESCE(TO_CHAR(break_end, 'HH24:MI'), ''), " +
+	"COALESCE(TO_CHAR(core_start, 'HH24:MI'), ''), COALESCE(TO_CHAR(core_end, 'HH24:MI'), ''), COALESCE(min_duration_minutes, 0), " +
+	"COALESCE(work_location_id::text, ''), schedule_type, created_at, updated_at"
+
+func (tr *ShiftTemplateRepository) CreateShiftTemplate(ctx context.Context, template *domain.ShiftTemplate) (*domain.ShiftTemplate, error) {
+	query := tr.db.QueryBuilder.Insert("shift_templates").
+		Columns("id", "name", "shift_start", "shift_end", "break_start", "break_end", "core_start", "core_end", "min_duration_minutes",
+			"work_location_id", "schedule_type", "created_at", "updated_at").
+		Values(template.ID, template.Name, template.ShiftStart, template.ShiftEnd, nullString(template.BreakStart), nullString(template.BreakEnd),
+			nullString(template.CoreStart), nullString(template.CoreEnd), nullInt64(int64(template.MinDurationMinutes)),
+			nullString(template.WorkLocationID), template.ScheduleType, template.CreatedAt, template.UpdatedAt).
+		Suffix("RETURNING " + shiftTemplateColumns)
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	template, err = scanShiftTemplate(tr.db.QueryRow(ctx, sql, args...))
+	if err != nil {
+		if errCode := tr.db.ErrorCode(err); errCode == postgres.UniqueViolationCode {
+			return nil, consts.ErrConflictingData
+		}
+		return nil, err
+	}
+
+	return template, nil
+}
+
+func (tr *ShiftTemplateRepository) ListShiftTemplates(ctx context.Context) ([]domain.ShiftTemplate, error) {
+	var templates []domain.ShiftTemplate
+
+	query := tr.db.QueryBuilder.Select(shiftTemplateColumns).
+		From("shift_templates").
+		OrderBy("name ASC")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tr.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		template, err := scanShiftTemplate(rows)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, *template)
+	}
+
+	return templates, nil
+}
+
+func (tr *ShiftTemplateRepository) GetShiftTemplate(ctx context.Context, id string) (*domain.ShiftTemplate, error) {
+	query := tr.db.QueryBuilder.Select(shiftTemplateColumns).
+		From("shift_templates").
+		Where(sq.Eq{"id": id})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	template, err := scanShiftTemplate(tr.db.QueryRow(ctx, sql, args...))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, consts.ErrDataNotFound
+		}
+		return nil, err
+	}
+
+	return template, nil
+}
+
+func (tr *ShiftTemplateRepository) DeleteShiftTemplate(ctx context.Context, id string) error {
+	query := tr.db.QueryBuilder.Delete("shift_templates").
+		Where(sq.Eq{"id": id})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = tr.db.Exec(ctx, sql, args...)
+	return err
+}
+
+func scanShiftTemplate(row pgx.Row) (*domain.ShiftTemplate, error) {
+	var template domain.ShiftTemplate
+	err := row.Scan(
+		&template.ID,
+		&template.Name,
+		&template.ShiftStart,
+		&template.ShiftEnd,
+		&template.BreakStart,
+		&template.BreakEnd,
+		&template.CoreStart,
+		&template.CoreEnd,
+		&template.MinDurationMinutes,
+		&template.WorkLocationID,
+		&template.ScheduleType,
+		&template.CreatedAt,
+		&template.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &template, nil
+}
@@ -187,3 +187,33 @@ func (wlr *WorkLocationRepository) UpdateWorkLocation(ctx context.Context, locat
 
 	return location, nil
 }
+
+// ListWorkLocationNames maps the given work location IDs to their names
+func (wlr *WorkLocationRepository) ListWorkLocationNames(ctx context.Context, ids []string) (map[string]string, error) {
+	names := make(map[string]string, len(ids))
+
+	query := wlr.db.QueryBuilder.Select("id", "name").
+		From("work_locations").
+		Where(sq.Expr("id = ANY(?::uuid[])", ids))
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := wlr.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id, name string
+		if err := rows.Scan(&id, &name); err != nil {
+			return nil, err
+		}
+		names[id] = name
+	}
+
+	return names, nil
+}
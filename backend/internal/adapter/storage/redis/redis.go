@@ -7,6 +7,7 @@ import (
 
 	"github.com/aldotp/employee-attendance-system/internal/adapter/config"
 	"github.com/aldotp/employee-attendance-system/internal/core/port"
+	"github.com/aldotp/employee-attendance-system/pkg/consts"
 	"github.com/redis/go-redis/v9"
 )
 
@@ -35,9 +36,12 @@ func (r *Redis) Set(ctx context.Context, key string, value []byte, ttl time.Dura
 	return r.client.Set(ctx, key, value, ttl).Err()
 }
 
-// Get retrieves the value from the redis database
+// Get retrieves the value from the redis database, consts.ErrDataNotFound when the key is missing
 func (r *Redis) Get(ctx context.Context, key string) ([]byte, error) {
 	res, err := r.client.Get(ctx, key).Result()
+	if err == redis.Nil {
+		return nil, consts.ErrDataNotFound
+	}
 	bytes := []byte(res)
 	return bytes, err
 }
@@ -74,6 +78,48 @@ func (r *Redis) DeleteByPrefix(ctx context.Context, prefix string) error {
 	return nil
 }
 
+// Increment atomically adds one to the counter at key and returns the new count. The ttl is set
+// when the counter is created, so the counter resets once it expires.
+func (r *Redis) Increment(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	count, err := r.client.Incr(ctx, key).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	if count == 1 && ttl > 0 {
+		if err := r.client.Expire(ctx, key, ttl).Err(); err != nil {
+			return 0, err
+		}
+	}
+
+	return count, nil
+}
+
+// compareAndSwapScript replaces the value of KEYS[1] with ARGV[2] only while it still holds
+// ARGV[1], expiring it after ARGV[3] milliseconds unless that is zero
+var compareAndSwapScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) ~= ARGV[1] then
+	return 0
+end
+if tonumber(ARGV[3]) > 0 then
+	redis.call("SET", KEYS[1], ARGV[2], "PX", ARGV[3])
+else
+	redis.call("SET", KEYS[1], ARGV[2])
+end
+return 1
+`)
+
+// CompareAndSwap atomically stores the value at key if the key still holds old, and reports
+// whether it did. A missing key never matches.
+func (r *Redis) CompareAndSwap(ctx context.Context, key string, old, value []byte, ttl time.Duration) (bool, error) {
+	swapped, err := compareAndSwapScript.Run(ctx, r.client, []string{key}, old, value, ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, err
+	}
+
+	return swapped == 1, nil
+}
+
 // Close closes the connection to the redis database
 func (r *Redis) Close() error {
 	return r.client.Close()
@@ -0,0 +1,55 @@
+package domain
+
+import "time"
+
+// APIKey lets another system, such as payroll, call the API without a user. Only the SHA-256
+// hash of the key is stored, the key itself is shown once when it is created.
+type APIKey struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// Prefix is the start of the key, enough to tell keys apart without revealing them
+	Prefix      string       `json:"prefix"`
+	KeyHash     string       `json:"-"`
+	Permissions []Permission `json:"permissions"`
+	CreatedBy   string       `json:"created_by"`
+	ExpiresAt   time.Time    `json:"expires_at"`
+	LastUsedAt  *time.Time   `json:"last_used_at"`
+	RevokedAt   *time.Time   `json:"revoked_at"`
+	CreatedAt   time.Time    `json:"created_at"`
+}
+
+// IsActive reports whether the key is neither revoked nor expired at the given time
+func (k APIKey) IsActive(now time.Time) bool {
+	return k.RevokedAt == nil && now.Before(k.ExpiresAt)
+}
+
+// APIKeyGrantable reports whether API keys may hold the action. Keys cannot manage permissions or
+// other keys, which would let a leaked key widen its own access.
+func (a PermissionAction) APIKeyGrantable() bool {
+	return a != ActionPermissionsManage && a != ActionAPIKeysManage
+}
+
+// NewAPIKey is a newly created key together with the secret to hand over to the integration
+type NewAPIKey struct {
+	APIKey
+	Key string `json:"key"`
+}
+
+// APIKeyAuditLog is one request made with an API key
+type APIKeyAuditLog struct {
+	ID         string    `json:"id"`
+	APIKeyID   string    `json:"api_key_id"`
+	Method     string    `json:"method"`
+	Path       string    `json:"path"`
+	StatusCode int       `json:"status_code"`
+	ClientIP   string    `json:"client_ip"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// APIKeyPolicy controls how long API keys live
+type APIKeyPolicy struct {
+	// DefaultTTL is the lifetime of keys created without an expiry
+	DefaultTTL time.Duration
+	// MaxTTL bounds the expiry that can be requested
+	MaxTTL time.Duration
+}
@@ -8,35 +8,64 @@ const (
 	AttendanceStatusPresent AttendanceStatus = "present"
 	AttendanceStatusLate    AttendanceStatus = "late"
 	AttendanceStatusAbsent  AttendanceStatus = "absent"
+	// AttendanceStatusCoreViolation marks a flexible schedule day with absence during core hours
+	AttendanceStatusCoreViolation AttendanceStatus = "core_hours_violation"
+	// AttendanceStatusShortDay marks a flexible schedule day below the minimum duration
+	AttendanceStatusShortDay AttendanceStatus = "short_day"
 )
 
+const (
+	AttendanceCheckIn  = "check_in"
+	AttendanceCheckOut = "check_out"
+)
+
+// Attendance is a check-in or check-out event. BusinessDate is the date of the shift the
+// event belongs to, so the check-out of a night shift is counted on the day the shift started.
 type Attendance struct {
-	ID        string           `json:"id"`
-	UserID    string           `json:"user_id"`
-	Time      time.Time        `json:"time"`
-	Latitude  float64          `json:"latitude"`
-	Longitude float64          `json:"longitude"`
-	SelfieURL string           `json:"selfie_url"`
-	Type      string           `json:"type"`
-	Notes     string           `json:"notes"`
-	Status    AttendanceStatus `json:"status"`
-	CreatedAt time.Time        `json:"created_at"`
-	UpdatedAt time.Time        `json:"updated_at"`
+	ID           string           `json:"id"`
+	UserID       string           `json:"user_id"`
+	ScheduleID   string           `json:"schedule_id,omitempty"`
+	Time         time.Time        `json:"time"`
+	BusinessDate time.Time        `json:"business_date"`
+	Latitude     float64          `json:"latitude"`
+	Longitude    float64          `json:"longitude"`
+	SelfieURL    string           `json:"selfie_url"`
+	Type         string           `json:"type"`
+	Notes        string           `json:"notes"`
+	Status       AttendanceStatus `json:"status"`
+	HoursWorked  float64          `json:"hours_worked"`
+	CreatedAt    time.Time        `json:"created_at"`
+	UpdatedAt    time.Time        `json:"updated_at"`
+}
+
+// AttendancePolicy controls how check-ins are matched to shifts and when they count as late
+type AttendancePolicy struct {
+	// EarlyCheckIn is how long before its start a shift accepts check-ins
+	EarlyCheckIn time.Duration
+	// LateGrace is how long after the start of the shift a check-in is still on time
+	LateGrace time.Duration
+	// CheckInReminder is how long before the start of the shift an employee who has not checked
+	// in is reminded, zero disables the reminder
+	CheckInReminder time.Duration
+	// MissedCheckOutAfter is how long after the end of the shift an employee who has not checked
+	// out is warned, zero disables the warning
+	MissedCheckOutAfter time.Duration
 }
 
 type GetAttendanceResponse struct {
-	Name       string           `json:"name"`
-	Email      string           `json:"email"`
-	Department string           `json:"department"`
-	Time       time.Time        `json:"time"`
-	Latitude   float64          `json:"latitude"`
-	Longitude  float64          `json:"longitude"`
-	SelfieURL  string           `json:"selfie_url"`
-	Type       string           `json:"type"`
-	Notes      string           `json:"notes"`
-	Status     AttendanceStatus `json:"status"`
-	CreatedAt  time.Time        `json:"created_at"`
-	UpdatedAt  time.Time        `json:"updated_at"`
+	Name         string           `json:"name"`
+	Email        string           `json:"email"`
+	Department   string           `json:"department"`
+	Time         time.Time        `json:"time"`
+	BusinessDate time.Time        `json:"business_date"`
+	Latitude     float64          `json:"latitude"`
+	Longitude    float64          `json:"longitude"`
+	SelfieURL    string           `json:"selfie_url"`
+	Type         string           `json:"type"`
+	Notes        string           `json:"notes"`
+	Status       AttendanceStatus `json:"status"`
+	CreatedAt    time.Time        `json:"created_at"`
+	UpdatedAt    time.Time        `json:"updated_at"`
 }
 
 type ListAttendanceRequest struct {
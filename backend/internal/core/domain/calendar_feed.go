@@ -0,0 +1,29 @@
+package domain
+
+import "time"
+
+// CalendarFeedToken grants read access to the ICS feed of one employee. Only the SHA-256
+// hash of the token is stored, the token itself is shown once when it is created.
+type CalendarFeedToken struct {
+	ID             string     `json:"id"`
+	UserID         string     `json:"user_id"`
+	TokenHash      string     `json:"-"`
+	LastAccessedAt *time.Time `json:"last_accessed_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// CalendarFeed is a newly created feed token together with the subscription URL embedding it
+type CalendarFeed struct {
+	Token     string    `json:"token"`
+	URL       string    `json:"url"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CalendarFeedPolicy controls the subscription URL and the period rendered in the feed
+type CalendarFeedPolicy struct {
+	// BaseURL is the public address of the API the subscription URL is built on
+	BaseURL string
+	// PastDays and FutureDays bound the rendered period around today
+	PastDays   int
+	FutureDays int
+}
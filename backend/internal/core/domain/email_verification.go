@@ -0,0 +1,20 @@
+package domain
+
+import "time"
+
+// EmailVerificationPolicy controls the verification link and how often it may be resent
+type EmailVerificationPolicy struct {
+	// URL is the page of the client application the token is appended to as the token query parameter
+	URL           string
+	EmailTemplate string
+	AppName       string
+	// TokenTTL is how long a verification link stays valid
+	TokenTTL time.Duration
+	// EmailLimit and IPLimit are the resend requests allowed per email address and per client IP
+	// within RateWindow, 0 disables a limit
+	EmailLimit int
+	IPLimit    int
+	RateWindow time.Duration
+	// Disabled verifies new addresses right away without an email, for deployments without SMTP
+	Disabled bool
+}
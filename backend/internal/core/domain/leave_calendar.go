@@ -0,0 +1,45 @@
+package domain
+
+import "time"
+
+// DepartmentLeave is a leave request joined with the name of the employee taking it
+type DepartmentLeave struct {
+	LeaveRequest
+	EmployeeName string `json:"employee_name"`
+}
+
+type LeaveCalendarEntry struct {
+	LeaveID string      `json:"leave_id"`
+	UserID  string      `json:"user_id"`
+	Name    string      `json:"name"`
+	Type    LeaveType   `json:"type"`
+	Status  LeaveStatus `json:"status"`
+}
+
+type LeaveCalendarDay struct {
+	Date     string               `json:"date"`
+	Absences []LeaveCalendarEntry `json:"absences"`
+}
+
+type LeaveCalendar struct {
+	DepartmentID string             `json:"department_id"`
+	StartDate    time.Time          `json:"start_date"`
+	EndDate      time.Time          `json:"end_date"`
+	Days         []LeaveCalendarDay `json:"days"`
+}
+
+// LeaveCoverage describes how many of the people expected at work on a given day are still available
+type LeaveCoverage struct {
+	Date         string  `json:"date"`
+	Expected     int     `json:"expected"`
+	Absent       int     `json:"absent"`
+	Available    int     `json:"available"`
+	Coverage     float64 `json:"coverage"`
+	BelowMinimum bool    `json:"below_minimum"`
+}
+
+// LeaveCoveragePolicy sets the minimum share of a department that must remain available
+type LeaveCoveragePolicy struct {
+	MinCoverage   float64
+	BlockApproval bool
+}
@@ -36,3 +36,51 @@ type LeaveRequest struct {
 	CreatedAt  time.Time   `json:"created_at"`
 	UpdatedAt  time.Time   `json:"updated_at"`
 }
+
+type LeaveAttachment struct {
+	ID             string    `json:"id"`
+	LeaveRequestID string    `json:"leave_request_id"`
+	FileName       string    `json:"file_name"`
+	ObjectKey      string    `json:"-"`
+	ContentType    string    `json:"content_type"`
+	Size           int64     `json:"size"`
+	UploadedBy     string    `json:"uploaded_by"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// LeaveAttachmentPolicy describes which leave requests need supporting documents
+// and which files are accepted as such.
+type LeaveAttachmentPolicy struct {
+	// RequiredAfterDays maps a leave type to the number of days a request may last
+	// before an attachment becomes mandatory. A value of 0 means always required.
+	RequiredAfterDays   map[LeaveType]int
+	MaxSize             int64
+	AllowedContentTypes []string
+	DownloadURLExpiry   time.Duration
+}
+
+// Days returns the inclusive number of calendar days covered by the leave request.
+func (l *LeaveRequest) Days() int {
+	return int(l.EndDate.Sub(l.StartDate).Hours()/24) + 1
+}
+
+// RequiresAttachment reports whether the policy demands a supporting document for the leave request.
+func (p LeaveAttachmentPolicy) RequiresAttachment(leave *LeaveRequest) bool {
+	days, ok := p.RequiredAfterDays[leave.Type]
+	if !ok {
+		return false
+	}
+
+	return leave.Days() > days
+}
+
+// AllowsContentType reports whether files of the given content type may be attached.
+func (p LeaveAttachmentPolicy) AllowsContentType(contentType string) bool {
+	for _, allowed := range p.AllowedContentTypes {
+		if allowed == contentType {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,33 @@
+package domain
+
+import "time"
+
+// LoginProtectionPolicy slows down and then locks out repeated failed logins. Failures are counted
+// per email address, whether or not an account has it, so lockouts reveal nothing about accounts.
+type LoginProtectionPolicy struct {
+	// AccountLimit failed logins of one email address within Window lock it for LockoutDuration
+	AccountLimit int
+	// IPLimit failed logins from one client IP within Window lock that IP out for LockoutDuration
+	IPLimit         int
+	Window          time.Duration
+	LockoutDuration time.Duration
+	// DelayAfter failed logins of an email address, each further attempt has to wait BaseDelay,
+	// doubled after every failure up to MaxDelay
+	DelayAfter int
+	BaseDelay  time.Duration
+	MaxDelay   time.Duration
+}
+
+// LoginDelay is how long the next login of an email address has to wait after its failures
+func (p LoginProtectionPolicy) LoginDelay(failures int) time.Duration {
+	if p.DelayAfter <= 0 || failures <= p.DelayAfter || p.BaseDelay <= 0 {
+		return 0
+	}
+
+	delay := p.BaseDelay
+	for i := p.DelayAfter + 1; i < failures && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+
+	return min(delay, p.MaxDelay)
+}
@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"slices"
+	"time"
+)
+
+// UserMFA is the TOTP authenticator of a user. The secret is stored encrypted, and the
+// authenticator only guards logins once EnabledAt is set by confirming a first code.
+type UserMFA struct {
+	UserID    string     `json:"user_id"`
+	Secret    string     `json:"-"`
+	EnabledAt *time.Time `json:"enabled_at"`
+	// LastUsedStep is the TOTP time step of the last accepted code, older or equal steps are
+	// refused so an intercepted code cannot be replayed
+	LastUsedStep int64     `json:"-"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+func (m *UserMFA) Enabled() bool {
+	return m != nil && m.EnabledAt != nil
+}
+
+// MFAEnrollment is a new TOTP secret together with the URI authenticator apps import, shown once
+// while enrolling
+type MFAEnrollment struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// MFAPolicy controls who must use a second factor and how logins are challenged
+type MFAPolicy struct {
+	// RequiredRoles must enroll an authenticator before they can log in
+	RequiredRoles []UserRole
+	// Issuer names the account in authenticator apps
+	Issuer string
+	// EncryptionKey encrypts the stored TOTP secrets
+	EncryptionKey string
+	// ChallengeTTL is how long the second step of a login may take, with at most MaxAttempts codes tried
+	ChallengeTTL time.Duration
+	MaxAttempts  int
+	// RecoveryCodes is the number of single-use codes issued when enrolling
+	RecoveryCodes int
+}
+
+// Required tells whether users with the role must use a second factor
+func (p MFAPolicy) Required(role UserRole) bool {
+	return slices.Contains(p.RequiredRoles, role)
+}
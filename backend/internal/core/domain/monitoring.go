@@ -42,12 +42,23 @@ type AttendanceReport struct {
 	DailyStatus map[string]AttendanceStatus `json:"daily_status"`
 }
 
+const (
+	AnomalyNotPresent         = "not_present"
+	AnomalyLate               = "late"
+	AnomalyLeftEarly          = "left_early"
+	AnomalyCoreHoursViolation = "core_hours_violation"
+	AnomalyShortDay           = "short_day"
+)
+
 type Anomaly struct {
-	ID          string    `json:"id"`
+	ID          string    `json:"id,omitempty"`
+	UserID      string    `json:"user_id"`
+	ScheduleID  string    `json:"schedule_id,omitempty"`
+	Date        string    `json:"date"`
 	Type        string    `json:"type"`
 	Description string    `json:"description"`
 	DetectedAt  time.Time `json:"detected_at"`
-	Status      string    `json:"status"`
+	Status      string    `json:"status,omitempty"`
 }
 
 type ExportRequest struct {
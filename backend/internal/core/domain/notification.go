@@ -1,6 +1,9 @@
 package domain
 
 import (
+	"encoding/base64"
+	"errors"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -12,15 +15,144 @@ const (
 	NotificationTypeReminder NotificationType = "reminder"
 	NotificationTypeWarning  NotificationType = "warning"
 	NotificationTypeInfo     NotificationType = "info"
+	// NotificationTypeSecurity tells users about events on their account, such as a lockout
+	NotificationTypeSecurity NotificationType = "security"
+)
+
+var NotificationTypes = []NotificationType{NotificationTypeReminder, NotificationTypeWarning, NotificationTypeInfo, NotificationTypeSecurity}
+
+type NotificationChannelType string
+
+const (
+	// NotificationChannelInApp lists the notification in the application
+	NotificationChannelInApp NotificationChannelType = "in_app"
+	// NotificationChannelEmail sends the notification to the user's email address
+	NotificationChannelEmail NotificationChannelType = "email"
+	// NotificationChannelWebhook posts the notification to a URL chosen by the user, such as a chat integration
+	NotificationChannelWebhook NotificationChannelType = "webhook"
+)
+
+type NotificationStatus string
+
+const (
+	// NotificationPending waits for the dispatcher to deliver it once SendAt is reached
+	NotificationPending NotificationStatus = "pending"
+	// NotificationDelivered is visible to its recipient
+	NotificationDelivered NotificationStatus = "delivered"
+	// NotificationFailed could not be delivered within the allowed attempts
+	NotificationFailed NotificationStatus = "failed"
 )
 
 type Notification struct {
-	ID        string           `json:"id"`
-	UserID    string           `json:"user_id"`
-	Type      NotificationType `json:"type"`
-	Message   string           `json:"message"`
-	SendAt    time.Time        `json:"send_at"`
-	CreatedAt time.Time        `json:"created_at"`
+	ID            string             `json:"id"`
+	UserID        string             `json:"user_id"`
+	Type          NotificationType   `json:"type"`
+	Message       string             `json:"message"`
+	SendAt        time.Time          `json:"send_at"`
+	Status        NotificationStatus `json:"status"`
+	Attempts      int                `json:"-"`
+	LastError     string             `json:"-"`
+	NextAttemptAt *time.Time         `json:"-"`
+	DeliveredAt   *time.Time         `json:"delivered_at"`
+	IsRead        bool               `json:"is_read"`
+	ReadAt        *time.Time         `json:"read_at"`
+	// DeliveredChannels are the channels that already received the notification, skipped on retries
+	DeliveredChannels []NotificationChannelType `json:"-"`
+	CreatedAt         time.Time                 `json:"created_at"`
+}
+
+// ListedAt is when the notification appeared in the app, which the list is ordered by. Partially
+// delivered notifications are listed at their send time.
+func (n *Notification) ListedAt() time.Time {
+	if n.DeliveredAt != nil {
+		return *n.DeliveredAt
+	}
+
+	return n.SendAt
+}
+
+// NotificationFilter selects the in-app notifications of a user. Type and IsRead are optional,
+// and After continues a list after the notification the cursor points at, in place of Offset.
+type NotificationFilter struct {
+	UserID string
+	Type   NotificationType
+	IsRead *bool
+	After  *NotificationCursor
+	Offset uint64
+	Limit  uint64
+}
+
+// NotificationCursor points at a notification in the list, which is ordered by ListedAt and ID
+type NotificationCursor struct {
+	ListedAt time.Time
+	ID       string
+}
+
+// NewNotificationCursor points at the notification, for the next page to start after it
+func NewNotificationCursor(notif Notification) NotificationCursor {
+	return NotificationCursor{ListedAt: notif.ListedAt(), ID: notif.ID}
+}
+
+// String encodes the cursor into the opaque value handed to clients
+func (c NotificationCursor) String() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(c.ListedAt.UTC().Format(time.RFC3339Nano) + "|" + c.ID))
+}
+
+// ParseNotificationCursor decodes a cursor produced by NotificationCursor.String
+func ParseNotificationCursor(value string) (NotificationCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return NotificationCursor{}, err
+	}
+
+	listedAt, id, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return NotificationCursor{}, errors.New("malformed cursor")
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, listedAt)
+	if err != nil {
+		return NotificationCursor{}, err
+	}
+
+	if _, err := uuid.Parse(id); err != nil {
+		return NotificationCursor{}, err
+	}
+
+	return NotificationCursor{ListedAt: t, ID: id}, nil
+}
+
+// IsDeliveredTo reports whether the notification already went out through the channel
+func (n *Notification) IsDeliveredTo(channel NotificationChannelType) bool {
+	for _, delivered := range n.DeliveredChannels {
+		if delivered == channel {
+			return true
+		}
+	}
+
+	return false
+}
+
+// NotificationTopic is the pub/sub topic the in-app notifications of a user are streamed on
+func NotificationTopic(userID string) string {
+	return "notifications:" + userID
+}
+
+// NotificationPreference is the set of channels a user receives one type of notification through
+type NotificationPreference struct {
+	UserID     string                    `json:"user_id"`
+	Type       NotificationType          `json:"type"`
+	Channels   []NotificationChannelType `json:"channels"`
+	WebhookURL string                    `json:"webhook_url,omitempty"`
+	UpdatedAt  *time.Time                `json:"updated_at"`
+}
+
+// NotificationRecipient is where a notification is delivered to
+type NotificationRecipient struct {
+	UserID     string
+	Name       string
+	Email      string
+	WebhookURL string
 }
 
 func NewNotification(userID string, notificationType NotificationType, message string, sendAt time.Time) *Notification {
@@ -30,6 +162,61 @@ func NewNotification(userID string, notificationType NotificationType, message s
 		Type:      notificationType,
 		Message:   message,
 		SendAt:    sendAt,
+		Status:    NotificationPending,
 		CreatedAt: time.Now(),
 	}
 }
+
+// NotificationPolicy controls when the dispatcher delivers notifications and how it retries them
+type NotificationPolicy struct {
+	// QuietStart and QuietEnd bound, as HH:MM in the recipient's timezone, the hours during which
+	// nothing is delivered. The window may cross midnight, equal values disable it.
+	QuietStart string
+	QuietEnd   string
+	// MaxAttempts is how many failed deliveries mark a notification as failed
+	MaxAttempts int
+	// RetryBackoff is the delay before the first retry, doubled on every further attempt
+	RetryBackoff time.Duration
+	// BatchSize is how many due notifications one dispatch run delivers at most
+	BatchSize uint64
+	// DefaultChannels are used for the notification types a user has no preference for
+	DefaultChannels []NotificationChannelType
+}
+
+// QuietUntil reports whether t falls within the quiet hours in loc, and if so when they end
+func (p NotificationPolicy) QuietUntil(t time.Time, loc *time.Location) (time.Time, bool) {
+	if p.QuietStart == "" || p.QuietEnd == "" {
+		return time.Time{}, false
+	}
+
+	start, err := ParseClock(p.QuietStart)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	end, err := ParseClock(p.QuietEnd)
+	if err != nil || start == end {
+		return time.Time{}, false
+	}
+
+	local := t.In(loc)
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+	clock := local.Sub(midnight)
+
+	switch {
+	case start < end && clock >= start && clock < end:
+		return midnight.Add(end), true
+	case start > end && clock >= start:
+		// the quiet hours run past midnight and end tomorrow
+		return midnight.AddDate(0, 0, 1).Add(end), true
+	case start > end && clock < end:
+		return midnight.Add(end), true
+	}
+
+	return time.Time{}, false
+}
+
+// RetryDelay is how long to wait before the next attempt after the given number of failed ones
+func (p NotificationPolicy) RetryDelay(attempts int) time.Duration {
+	return p.RetryBackoff << min(max(attempts-1, 0), 10)
+}
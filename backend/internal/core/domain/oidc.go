@@ -0,0 +1,39 @@
+package domain
+
+import "time"
+
+// OIDCIdentity is the user an identity provider vouched for in a verified ID token
+type OIDCIdentity struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	// Department is read from the claim named by the provider configuration, empty when it has none
+	Department string
+}
+
+// OIDCAuthorization is where the client sends the user to sign in at the identity provider. The
+// client keeps the state and checks the provider returns the same one before finishing the login.
+type OIDCAuthorization struct {
+	URL   string `json:"authorization_url"`
+	State string `json:"state"`
+}
+
+// OIDCLoginState is kept between sending the user to the identity provider and their return, the
+// PKCE verifier never leaves the server
+type OIDCLoginState struct {
+	Verifier string `json:"verifier"`
+	Nonce    string `json:"nonce"`
+}
+
+// OIDCPolicy controls how users signing in through the identity provider are matched to accounts
+type OIDCPolicy struct {
+	// AutoProvision creates a user and an employee record for emails without an account
+	AutoProvision bool
+	// DefaultDepartment is where provisioned employees go when the ID token names no known department
+	DefaultDepartment string
+	// RequireVerifiedEmail refuses ID tokens whose email_verified claim is not true
+	RequireVerifiedEmail bool
+	// StateTTL is how long the user may take to sign in at the identity provider
+	StateTTL time.Duration
+}
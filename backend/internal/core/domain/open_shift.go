@@ -0,0 +1,49 @@
+package domain
+
+import "time"
+
+type OpenShiftStatus string
+
+const (
+	OpenShiftOpen      OpenShiftStatus = "open"
+	OpenShiftFilled    OpenShiftStatus = "filled"
+	OpenShiftCancelled OpenShiftStatus = "cancelled"
+)
+
+// OpenShift is a schedule offered to the employees of a department, for example because its
+// owner called in sick. Date, ShiftStart and ShiftEnd are copied from the schedule for listing.
+type OpenShift struct {
+	ID             string          `json:"id"`
+	ScheduleID     string          `json:"schedule_id"`
+	DepartmentID   string          `json:"department_id"`
+	WorkLocationID string          `json:"work_location_id,omitempty"`
+	Date           time.Time       `json:"date"`
+	ShiftStart     string          `json:"shift_start"`
+	ShiftEnd       string          `json:"shift_end"`
+	Status         OpenShiftStatus `json:"status"`
+	Note           string          `json:"note"`
+	PostedBy       string          `json:"posted_by"`
+	FilledBy       string          `json:"filled_by,omitempty"`
+	ReviewedBy     string          `json:"reviewed_by,omitempty"`
+	ReviewedAt     *time.Time      `json:"reviewed_at"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+}
+
+type OpenShiftClaimStatus string
+
+const (
+	ClaimPending   OpenShiftClaimStatus = "pending"
+	ClaimConfirmed OpenShiftClaimStatus = "confirmed"
+	ClaimRejected  OpenShiftClaimStatus = "rejected"
+)
+
+// OpenShiftClaim is an employee's request to take over an open shift, waiting for a manager
+type OpenShiftClaim struct {
+	ID          string               `json:"id"`
+	OpenShiftID string               `json:"open_shift_id"`
+	UserID      string               `json:"user_id"`
+	Status      OpenShiftClaimStatus `json:"status"`
+	CreatedAt   time.Time            `json:"created_at"`
+	UpdatedAt   time.Time            `json:"updated_at"`
+}
@@ -0,0 +1,29 @@
+package domain
+
+import "time"
+
+// PasswordResetToken lets the owner of an email address choose a new password once. Only the
+// SHA-256 hash of the token is stored, the token itself is only sent by email.
+type PasswordResetToken struct {
+	ID        string     `json:"id"`
+	UserID    string     `json:"user_id"`
+	TokenHash string     `json:"-"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// PasswordResetPolicy controls the reset link and how often resets may be requested
+type PasswordResetPolicy struct {
+	// URL is the page of the client application the token is appended to as the token query parameter
+	URL           string
+	EmailTemplate string
+	AppName       string
+	// TokenTTL is how long a reset token stays valid
+	TokenTTL time.Duration
+	// EmailLimit and IPLimit are the requests allowed per email address and per client IP
+	// within RateWindow, 0 disables a limit
+	EmailLimit int
+	IPLimit    int
+	RateWindow time.Duration
+}
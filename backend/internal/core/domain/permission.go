@@ -0,0 +1,162 @@
+package domain
+
+import "strings"
+
+// PermissionScope is whose records a permission covers. Each scope includes the narrower ones.
+type PermissionScope string
+
+const (
+	// ScopeOwn covers the user's own records
+	ScopeOwn PermissionScope = "own"
+	// ScopeTeam covers the records of the user's department
+	ScopeTeam PermissionScope = "team"
+	// ScopeAny covers everybody's records
+	ScopeAny PermissionScope = "any"
+)
+
+var scopeRank = map[PermissionScope]int{
+	ScopeOwn:  1,
+	ScopeTeam: 2,
+	ScopeAny:  3,
+}
+
+// Includes reports whether the scope covers the other one
+func (s PermissionScope) Includes(other PermissionScope) bool {
+	return scopeRank[s] > 0 && scopeRank[s] >= scopeRank[other]
+}
+
+// PermissionAction is something done to a resource, such as attendance:write
+type PermissionAction string
+
+const (
+	ActionUsersManage       PermissionAction = "users:manage"
+	ActionPermissionsManage PermissionAction = "permissions:manage"
+	ActionAPIKeysManage     PermissionAction = "api_keys:manage"
+	ActionAttendanceRecord  PermissionAction = "attendance:record"
+	ActionAttendanceRead    PermissionAction = "attendance:read"
+	ActionAttendanceWrite   PermissionAction = "attendance:write"
+	ActionLeaveRead         PermissionAction = "leave:read"
+	ActionLeaveWrite        PermissionAction = "leave:write"
+	ActionLeaveApprove      PermissionAction = "leave:approve"
+	ActionScheduleRead      PermissionAction = "schedule:read"
+	ActionScheduleWrite     PermissionAction = "schedule:write"
+	ActionScheduleManage    PermissionAction = "schedule:manage"
+	ActionRotationManage    PermissionAction = "rotation:manage"
+	ActionMonitoringRead    PermissionAction = "monitoring:read"
+	ActionDepartmentRead    PermissionAction = "department:read"
+	ActionNotificationSend  PermissionAction = "notification:send"
+)
+
+// In is the permission to do the action within the scope
+func (a PermissionAction) In(scope PermissionScope) Permission {
+	return Permission(string(a) + ":" + string(scope))
+}
+
+// Permission is a named grant such as attendance:write:any, an action and the scope it holds in
+type Permission string
+
+// Action is the action the permission grants
+func (p Permission) Action() PermissionAction {
+	action, _, _ := p.split()
+	return action
+}
+
+// Scope is whose records the permission covers
+func (p Permission) Scope() PermissionScope {
+	_, scope, _ := p.split()
+	return scope
+}
+
+func (p Permission) split() (PermissionAction, PermissionScope, bool) {
+	i := strings.LastIndex(string(p), ":")
+	if i < 0 {
+		return "", "", false
+	}
+
+	return PermissionAction(p[:i]), PermissionScope(p[i+1:]), true
+}
+
+// Permissions are all the permissions roles can be granted. Actions that have no owner, such as
+// managing users, only exist in the any scope.
+var Permissions = []Permission{
+	ActionUsersManage.In(ScopeAny),
+	ActionPermissionsManage.In(ScopeAny),
+	ActionAPIKeysManage.In(ScopeAny),
+	ActionAttendanceRecord.In(ScopeOwn),
+	ActionAttendanceRead.In(ScopeOwn),
+	ActionAttendanceRead.In(ScopeTeam),
+	ActionAttendanceRead.In(ScopeAny),
+	ActionAttendanceWrite.In(ScopeOwn),
+	ActionAttendanceWrite.In(ScopeTeam),
+	ActionAttendanceWrite.In(ScopeAny),
+	ActionLeaveRead.In(ScopeOwn),
+	ActionLeaveRead.In(ScopeTeam),
+	ActionLeaveRead.In(ScopeAny),
+	ActionLeaveWrite.In(ScopeOwn),
+	ActionLeaveWrite.In(ScopeAny),
+	ActionLeaveApprove.In(ScopeTeam),
+	ActionLeaveApprove.In(ScopeAny),
+	ActionScheduleRead.In(ScopeOwn),
+	ActionScheduleRead.In(ScopeTeam),
+	ActionScheduleRead.In(ScopeAny),
+	ActionScheduleWrite.In(ScopeTeam),
+	ActionScheduleWrite.In(ScopeAny),
+	ActionScheduleManage.In(ScopeAny),
+	ActionRotationManage.In(ScopeAny),
+	ActionMonitoringRead.In(ScopeAny),
+	ActionDepartmentRead.In(ScopeAny),
+	ActionNotificationSend.In(ScopeAny),
+}
+
+// IsValid reports whether the permission is one roles can be granted
+func (p Permission) IsValid() bool {
+	for _, permission := range Permissions {
+		if permission == p {
+			return true
+		}
+	}
+
+	return false
+}
+
+// PermissionSet is the permissions a role holds
+type PermissionSet map[Permission]struct{}
+
+func NewPermissionSet(permissions []Permission) PermissionSet {
+	set := make(PermissionSet, len(permissions))
+	for _, permission := range permissions {
+		set[permission] = struct{}{}
+	}
+
+	return set
+}
+
+// Scope is the widest scope in which the set grants the action, empty when it does not
+func (s PermissionSet) Scope(action PermissionAction) PermissionScope {
+	var widest PermissionScope
+	for permission := range s {
+		if permission.Action() == action && scopeRank[permission.Scope()] > scopeRank[widest] {
+			widest = permission.Scope()
+		}
+	}
+
+	return widest
+}
+
+// Has reports whether the set grants the permission, directly or through a wider scope
+func (s PermissionSet) Has(permission Permission) bool {
+	return s.Scope(permission.Action()).Includes(permission.Scope())
+}
+
+// RolePermissions is the permissions granted to a role
+type RolePermissions struct {
+	Role        UserRole     `json:"role"`
+	Permissions []Permission `json:"permissions"`
+}
+
+// OwnershipFilter narrows a listing to the records a user may see: those of UserID or of the
+// employees of DepartmentID. Both empty means every record.
+type OwnershipFilter struct {
+	UserID       string
+	DepartmentID string
+}
@@ -0,0 +1,87 @@
+package domain
+
+import "time"
+
+// RotationDay is a single day of a rotation cycle, either a working day with its shift or a day off
+type RotationDay struct {
+	Work       bool   `json:"work"`
+	ShiftStart string `json:"shift_start,omitempty"`
+	ShiftEnd   string `json:"shift_end,omitempty"`
+	BreakStart string `json:"break_start,omitempty"`
+	BreakEnd   string `json:"break_end,omitempty"`
+}
+
+// RotationPattern is a named cycle of days repeated from the start date of each assignment,
+// e.g. a 4-on-3-off pattern has four working days followed by three days off
+type RotationPattern struct {
+	ID          string        `json:"id"`
+	Name        string        `json:"name"`
+	Description string        `json:"description"`
+	Days        []RotationDay `json:"days"`
+	CreatedAt   time.Time     `json:"created_at"`
+	UpdatedAt   time.Time     `json:"updated_at"`
+}
+
+type RotationAssignment struct {
+	ID                string           `json:"id"`
+	UserID            string           `json:"user_id"`
+	RotationPatternID string           `json:"rotation_pattern_id"`
+	StartDate         time.Time        `json:"start_date"`
+	EndDate           *time.Time       `json:"end_date"`
+	WorkLocationID    string           `json:"work_location_id,omitempty"`
+	Pattern           *RotationPattern `json:"pattern,omitempty"`
+	CreatedAt         time.Time        `json:"created_at"`
+	UpdatedAt         time.Time        `json:"updated_at"`
+}
+
+// Covers reports whether date falls within the assignment period
+func (a *RotationAssignment) Covers(date time.Time) bool {
+	if date.Before(a.StartDate) {
+		return false
+	}
+
+	return a.EndDate == nil || !date.After(*a.EndDate)
+}
+
+// Project returns the schedule the rotation yields on date. The second value is false
+// when the assignment does not cover date or date is a day off in the cycle.
+func (a *RotationAssignment) Project(date time.Time) (Schedule, bool) {
+	if a.Pattern == nil || len(a.Pattern.Days) == 0 || !a.Covers(date) {
+		return Schedule{}, false
+	}
+
+	offset := int(date.Sub(a.StartDate).Hours() / 24)
+	day := a.Pattern.Days[offset%len(a.Pattern.Days)]
+	if !day.Work {
+		return Schedule{}, false
+	}
+
+	return Schedule{
+		UserID:         a.UserID,
+		Date:           date,
+		ShiftStart:     day.ShiftStart,
+		ShiftEnd:       day.ShiftEnd,
+		BreakStart:     day.BreakStart,
+		BreakEnd:       day.BreakEnd,
+		WorkLocationID: a.WorkLocationID,
+		ScheduleType:   ScheduleTypeShift,
+	}, true
+}
+
+const (
+	CalendarSourceSchedule = "schedule"
+	CalendarSourceRotation = "rotation"
+)
+
+// WorkCalendarDay is a working day of an employee, taken from a materialized schedule or
+// projected from a rotation assignment when no schedule exists for that date
+type WorkCalendarDay struct {
+	Date     string   `json:"date"`
+	Source   string   `json:"source"`
+	Schedule Schedule `json:"schedule"`
+}
+
+type WorkRotation struct {
+	Assignment *RotationAssignment `json:"assignment"`
+	NextShift  *WorkCalendarDay    `json:"next_shift"`
+}
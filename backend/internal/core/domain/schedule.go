@@ -1,22 +1,267 @@
 package domain
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	ScheduleTypeRegular = "regular"
+	ScheduleTypeShift   = "shift"
+	// ScheduleTypeFlexible lets the employee choose when to work within the shift, as long as
+	// they are present during the core hours and work at least the minimum duration
+	ScheduleTypeFlexible = "flexible"
+)
 
 type Schedule struct {
-	ID             string    `json:"id"`
-	UserID         string    `json:"user_id"`
-	Date           time.Time `json:"date"`
-	ShiftStart     string    `json:"shift_start"`
-	ShiftEnd       string    `json:"shift_end"`
-	BreakStart     string    `json:"break_start,omitempty"`
-	BreakEnd       string    `json:"break_end,omitempty"`
-	WorkLocationID string    `json:"work_location_id,omitempty"`
-	ScheduleType   string    `json:"schedule_type"`
-	CreatedAt      time.Time `json:"created_at"`
-	UpdatedAt      time.Time `json:"updated_at"`
+	ID                 string    `json:"id"`
+	UserID             string    `json:"user_id"`
+	Date               time.Time `json:"date"`
+	ShiftStart         string    `json:"shift_start"`
+	ShiftEnd           string    `json:"shift_end"`
+	BreakStart         string    `json:"break_start,omitempty"`
+	BreakEnd           string    `json:"break_end,omitempty"`
+	CoreStart          string    `json:"core_start,omitempty"`
+	CoreEnd            string    `json:"core_end,omitempty"`
+	MinDurationMinutes int       `json:"min_duration_minutes,omitempty"`
+	WorkLocationID     string    `json:"work_location_id,omitempty"`
+	ScheduleType       string    `json:"schedule_type"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
+
+// IsFlexible reports whether the schedule is checked against core hours and a minimum
+// duration instead of its start and end
+func (s *Schedule) IsFlexible() bool {
+	return s.ScheduleType == ScheduleTypeFlexible
+}
+
+// EndsNextDay reports whether the shift runs past midnight, which is the case when it
+// ends at or before the time it starts, e.g. 22:00 to 06:00
+func (s *Schedule) EndsNextDay() bool {
+	return ShiftEndsNextDay(s.ShiftStart, s.ShiftEnd)
+}
+
+// Window returns when the shift starts and ends in loc. The shift belongs to its business
+// date, Date, even when it ends on the following calendar day.
+func (s *Schedule) Window(loc *time.Location) (time.Time, time.Time, error) {
+	start, err := ParseClock(s.ShiftStart)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	end, err := ParseClock(s.ShiftEnd)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	day := time.Date(s.Date.Year(), s.Date.Month(), s.Date.Day(), 0, 0, 0, 0, loc)
+	if end <= start {
+		end += 24 * time.Hour
+	}
+
+	return day.Add(start), day.Add(end), nil
+}
+
+// CoreWindow returns when the core hours start and end in loc, and false when the schedule has
+// none. Core hours before the shift start fall on the next day of an overnight shift.
+func (s *Schedule) CoreWindow(loc *time.Location) (time.Time, time.Time, bool, error) {
+	if s.CoreStart == "" || s.CoreEnd == "" {
+		return time.Time{}, time.Time{}, false, nil
+	}
+
+	start, err := ParseClock(s.ShiftStart)
+	if err != nil {
+		return time.Time{}, time.Time{}, false, err
+	}
+
+	coreStart, err := ParseClock(s.CoreStart)
+	if err != nil {
+		return time.Time{}, time.Time{}, false, err
+	}
+
+	coreEnd, err := ParseClock(s.CoreEnd)
+	if err != nil {
+		return time.Time{}, time.Time{}, false, err
+	}
+
+	if coreStart < start {
+		coreStart += 24 * time.Hour
+	}
+	if coreEnd < start {
+		coreEnd += 24 * time.Hour
+	}
+
+	day := time.Date(s.Date.Year(), s.Date.Month(), s.Date.Day(), 0, 0, 0, 0, loc)
+	return day.Add(coreStart), day.Add(coreEnd), true, nil
+}
+
+// CheckInStatus returns the status of a check-in at the given time. It is late when it comes
+// after the start of the shift plus grace, or for a flexible schedule, a core hours violation
+// when it comes after the start of the core hours plus grace.
+func (s *Schedule) CheckInStatus(at time.Time, loc *time.Location, grace time.Duration) (AttendanceStatus, error) {
+	if s.IsFlexible() {
+		coreStart, _, ok, err := s.CoreWindow(loc)
+		if err != nil {
+			return "", err
+		}
+
+		if ok && at.After(coreStart.Add(grace)) {
+			return AttendanceStatusCoreViolation, nil
+		}
+		return AttendanceStatusPresent, nil
+	}
+
+	start, _, err := s.Window(loc)
+	if err != nil {
+		return "", err
+	}
+
+	if at.After(start.Add(grace)) {
+		return AttendanceStatusLate, nil
+	}
+	return AttendanceStatusPresent, nil
+}
+
+// CheckOutStatus returns the status of a check-out closing a check-in. Only flexible schedules
+// judge the check-out: leaving before the end of the core hours is a core hours violation and
+// leaving before the minimum duration has been worked makes a short day.
+func (s *Schedule) CheckOutStatus(checkIn, checkOut time.Time, loc *time.Location) (AttendanceStatus, error) {
+	if !s.IsFlexible() {
+		return AttendanceStatusPresent, nil
+	}
+
+	_, coreEnd, ok, err := s.CoreWindow(loc)
+	if err != nil {
+		return "", err
+	}
+
+	if ok && checkOut.Before(coreEnd) {
+		return AttendanceStatusCoreViolation, nil
+	}
+
+	if s.MinDurationMinutes > 0 && checkOut.Sub(checkIn) < time.Duration(s.MinDurationMinutes)*time.Minute {
+		return AttendanceStatusShortDay, nil
+	}
+
+	return AttendanceStatusPresent, nil
+}
+
+// ShiftEndsNextDay reports whether a shift from shiftStart to shiftEnd crosses midnight
+func ShiftEndsNextDay(shiftStart, shiftEnd string) bool {
+	start, err := ParseClock(shiftStart)
+	if err != nil {
+		return false
+	}
+
+	end, err := ParseClock(shiftEnd)
+	if err != nil {
+		return false
+	}
+
+	return end <= start
+}
+
+// ParseClock parses a HH:MM or HH:MM:SS time of day into the duration since midnight
+func ParseClock(value string) (time.Duration, error) {
+	for _, layout := range []string{"15:04", "15:04:05"} {
+		if clock, err := time.Parse(layout, value); err == nil {
+			return time.Duration(clock.Hour())*time.Hour + time.Duration(clock.Minute())*time.Minute + time.Duration(clock.Second())*time.Second, nil
+		}
+	}
+
+	return 0, fmt.Errorf("invalid time of day %q, expected HH:MM", value)
 }
 
+type SwapStatus string
+
+const (
+	// SwapPending waits for the colleague owning the target schedule to respond
+	SwapPending SwapStatus = "pending"
+	// SwapAccepted has been accepted by the colleague and waits for a manager
+	SwapAccepted  SwapStatus = "accepted"
+	SwapDeclined  SwapStatus = "declined"
+	SwapApproved  SwapStatus = "approved"
+	SwapRejected  SwapStatus = "rejected"
+	SwapCancelled SwapStatus = "cancelled"
+)
+
+// ScheduleSwapRequest is a request from RequestorID to trade ProposedScheduleID,
+// one of their own shifts, for TargetScheduleID owned by TargetUserID
 type ScheduleSwapRequest struct {
-	ScheduleID1 string `json:"schedule_id_1"`
-	ScheduleID2 string `json:"schedule_id_2"`
+	ID                 string     `json:"id"`
+	RequestorID        string     `json:"requestor_id"`
+	TargetUserID       string     `json:"target_user_id"`
+	ProposedScheduleID string     `json:"proposed_schedule_id"`
+	TargetScheduleID   string     `json:"target_schedule_id"`
+	Reason             string     `json:"reason"`
+	Status             SwapStatus `json:"status"`
+	RespondedAt        *time.Time `json:"responded_at"`
+	ReviewedBy         string     `json:"reviewed_by"`
+	ReviewedAt         *time.Time `json:"reviewed_at"`
+	Note               string     `json:"note"`
+	CreatedAt          time.Time  `json:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+}
+
+// IsOpen reports whether the swap request can still change state
+func (s *ScheduleSwapRequest) IsOpen() bool {
+	return s.Status == SwapPending || s.Status == SwapAccepted
+}
+
+// ShiftTemplate is a reusable shift definition from which schedules are generated in bulk
+type ShiftTemplate struct {
+	ID                 string    `json:"id"`
+	Name               string    `json:"name"`
+	ShiftStart         string    `json:"shift_start"`
+	ShiftEnd           string    `json:"shift_end"`
+	BreakStart         string    `json:"break_start,omitempty"`
+	BreakEnd           string    `json:"break_end,omitempty"`
+	CoreStart          string    `json:"core_start,omitempty"`
+	CoreEnd            string    `json:"core_end,omitempty"`
+	MinDurationMinutes int       `json:"min_duration_minutes,omitempty"`
+	WorkLocationID     string    `json:"work_location_id,omitempty"`
+	ScheduleType       string    `json:"schedule_type"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
+
+// Schedule returns the schedule the template yields for userID on date
+func (t *ShiftTemplate) Schedule(userID string, date time.Time) Schedule {
+	return Schedule{
+		UserID:             userID,
+		Date:               date,
+		ShiftStart:         t.ShiftStart,
+		ShiftEnd:           t.ShiftEnd,
+		BreakStart:         t.BreakStart,
+		BreakEnd:           t.BreakEnd,
+		CoreStart:          t.CoreStart,
+		CoreEnd:            t.CoreEnd,
+		MinDurationMinutes: t.MinDurationMinutes,
+		WorkLocationID:     t.WorkLocationID,
+		ScheduleType:       t.ScheduleType,
+	}
+}
+
+const (
+	ConflictExistingSchedule = "schedule"
+	ConflictApprovedLeave    = "leave"
+	ConflictScheduleRules    = "rules"
+)
+
+// ScheduleConflict is a day skipped by bulk generation because the employee is already
+// scheduled, on approved leave, or the shift would break the scheduling rules
+type ScheduleConflict struct {
+	UserID     string              `json:"user_id"`
+	Date       string              `json:"date"`
+	Reason     string              `json:"reason"`
+	Violations []ScheduleViolation `json:"violations,omitempty"`
+}
+
+type BulkScheduleResult struct {
+	DryRun    bool               `json:"dry_run"`
+	Employees int                `json:"employees"`
+	Generated int                `json:"generated"`
+	Conflicts []ScheduleConflict `json:"conflicts"`
+	Preview   []Schedule         `json:"preview,omitempty"`
 }
@@ -0,0 +1,32 @@
+package domain
+
+import "time"
+
+// ScheduleRule holds the labor limits schedules are validated against. A rule applies either to
+// a department or to a country, the country of a schedule being the one of its work location.
+// A limit of zero disables it.
+type ScheduleRule struct {
+	ID                 string    `json:"id"`
+	DepartmentID       string    `json:"department_id,omitempty"`
+	Country            string    `json:"country,omitempty"`
+	MinRestHours       int       `json:"min_rest_hours"`
+	MaxWeeklyHours     int       `json:"max_weekly_hours"`
+	MaxConsecutiveDays int       `json:"max_consecutive_days"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
+
+const (
+	RuleNoOverlap          = "no_overlap"
+	RuleMinRest            = "min_rest"
+	RuleMaxWeeklyHours     = "max_weekly_hours"
+	RuleMaxConsecutiveDays = "max_consecutive_days"
+)
+
+// ScheduleViolation describes how a schedule breaks one of the scheduling rules
+type ScheduleViolation struct {
+	Rule                  string `json:"rule"`
+	Date                  string `json:"date"`
+	ConflictingScheduleID string `json:"conflicting_schedule_id,omitempty"`
+	Message               string `json:"message"`
+}
@@ -0,0 +1,11 @@
+package domain
+
+import "time"
+
+// StreamTicket opens the notification stream once, for clients such as the browser's EventSource
+// that cannot send the access token in a header. It stands in for the token in the stream URL,
+// where access logs, proxies and the browser history keep it.
+type StreamTicket struct {
+	Ticket    string    `json:"ticket"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
@@ -1,11 +1,34 @@
 package domain
 
+import "time"
+
 type TokenPayload struct {
 	Email  string   `json:"email"`
 	UserID string   `json:"user_id"`
 	Role   UserRole `json:"role"`
+	// Version is the user's token version when the token was issued, see TokenVersionService
+	Version int64 `json:"version"`
+	// APIKeyID is set instead of UserID when the caller authenticated with an API key, which is
+	// authorized by its own Permissions rather than by a role
+	APIKeyID    string       `json:"api_key_id,omitempty"`
+	Permissions []Permission `json:"permissions,omitempty"`
 }
 
+// RefreshTokenPayload identifies the session a refresh token belongs to and which of the
+// session's tokens it is
 type RefreshTokenPayload struct {
-	UserID string `json:"user_id"`
+	UserID    string    `json:"user_id"`
+	SessionID string    `json:"session_id"`
+	TokenID   string    `json:"token_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// RefreshSession is a login on one device. Every refresh rotates its token and only the latest
+// one, CurrentTokenID, is accepted, so presenting an older token reveals that it was copied.
+type RefreshSession struct {
+	ID             string    `json:"id"`
+	UserID         string    `json:"user_id"`
+	CurrentTokenID string    `json:"current_token_id"`
+	CreatedAt      time.Time `json:"created_at"`
+	RotatedAt      time.Time `json:"rotated_at"`
 }
@@ -0,0 +1,32 @@
+package port
+
+import (
+	"context"
+	"time"
+
+	"github.com/aldotp/employee-attendance-system/internal/core/domain"
+)
+
+type APIKeyRepository interface {
+	CreateAPIKey(ctx context.Context, key *domain.APIKey) error
+	GetAPIKeyByID(ctx context.Context, id string) (*domain.APIKey, error)
+	GetAPIKeyByHash(ctx context.Context, keyHash string) (*domain.APIKey, error)
+	ListAPIKeys(ctx context.Context) ([]domain.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id string, revokedAt time.Time) error
+	// RecordAPIKeyUsage stores the audit log entry and marks the key as last used at its time
+	RecordAPIKeyUsage(ctx context.Context, log *domain.APIKeyAuditLog) error
+	ListAPIKeyAuditLogs(ctx context.Context, apiKeyID string, page, limit uint64) ([]domain.APIKeyAuditLog, error)
+}
+
+type APIKeyService interface {
+	CreateAPIKey(ctx context.Context, actor *domain.TokenPayload, name string, permissions []domain.Permission, expiresAt *time.Time) (*domain.NewAPIKey, error)
+	ListAPIKeys(ctx context.Context) ([]domain.APIKey, error)
+	GetAPIKey(ctx context.Context, id string) (*domain.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id string) error
+	ListAPIKeyAuditLogs(ctx context.Context, id string, page, limit uint64) ([]domain.APIKeyAuditLog, error)
+
+	// Authenticate resolves an API key to the payload requests made with it run as
+	Authenticate(ctx context.Context, key string) (*domain.TokenPayload, error)
+	// RecordUsage audit logs a request made with an API key
+	RecordUsage(ctx context.Context, log *domain.APIKeyAuditLog) error
+}
@@ -2,6 +2,7 @@ package port
 
 import (
 	"context"
+	"time"
 
 	"github.com/aldotp/employee-attendance-system/internal/adapter/dto"
 	"github.com/aldotp/employee-attendance-system/internal/core/domain"
@@ -10,11 +11,16 @@ import (
 type AttendanceRepository interface {
 	CreateAttendance(ctx context.Context, attendance *domain.Attendance) (*domain.Attendance, error)
 	GetAttendanceByID(ctx context.Context, id string) (*domain.Attendance, error)
-	ListAttendances(ctx context.Context, page, limit uint64, date string, attendanceType string) ([]domain.GetAttendanceResponse, error)
+	ListAttendances(ctx context.Context, page, limit uint64, date string, attendanceType string, filter domain.OwnershipFilter) ([]domain.GetAttendanceResponse, error)
 	UpdateAttendance(ctx context.Context, attendance *domain.Attendance) (*domain.Attendance, error)
 	DeleteAttendance(ctx context.Context, id string) error
 	GetAttendanceHistory(ctx context.Context, employeeID string, startDate, endDate string) ([]domain.Attendance, error)
+	ListAttendancesByBusinessDate(ctx context.Context, date time.Time) ([]domain.Attendance, error)
+	ListAttendancesByUsers(ctx context.Context, userIDs []string, startDate, endDate time.Time) ([]domain.Attendance, error)
 	GetUsersAttendanceStatus(ctx context.Context, date string) (map[string]bool, error)
+	GetOpenCheckIn(ctx context.Context, userID, checkOutID string, since, before time.Time) (*domain.Attendance, error)
+	MarkReminderSent(ctx context.Context, id, scheduleID, userID string, reminderType domain.NotificationType, sentAt time.Time) (bool, error)
+	UnmarkReminderSent(ctx context.Context, id string) error
 }
 
 type AttendanceService interface {
@@ -24,9 +30,11 @@ type AttendanceService interface {
 	ValidateRadius(ctx context.Context, userID string, lat, lng float64) (bool, error)
 	RecordAttendance(ctx context.Context, req dto.AttendanceRequest, userID string) error
 	SendAttendanceNotification(ctx context.Context, userID string) error
+	SendAttendanceReminders(ctx context.Context) (int, error)
 
-	ListAttendances(context.Context, domain.ListAttendanceRequest) ([]domain.GetAttendanceResponse, error)
-	GetAttendanceByID(ctx context.Context, id string) (*domain.Attendance, error)
-	UpdateAttendance(ctx context.Context, id string, req dto.AttendanceRequest) (*domain.Attendance, error)
-	DeleteAttendance(ctx context.Context, id string) error
+	ListAttendances(ctx context.Context, actor *domain.TokenPayload, req domain.ListAttendanceRequest) ([]domain.GetAttendanceResponse, error)
+	GetAttendanceByID(ctx context.Context, actor *domain.TokenPayload, id string) (*domain.Attendance, error)
+	UpdateAttendance(ctx context.Context, actor *domain.TokenPayload, id string, req dto.AttendanceRequest) (*domain.Attendance, error)
+	DeleteAttendance(ctx context.Context, actor *domain.TokenPayload, id string) error
+	GetUsersAttendanceStatus(ctx context.Context, actor *domain.TokenPayload, date string) (map[string]bool, error)
 }
@@ -2,6 +2,7 @@ package port
 
 import (
 	"context"
+	"time"
 
 	"github.com/aldotp/employee-attendance-system/internal/adapter/dto"
 	"github.com/aldotp/employee-attendance-system/internal/core/domain"
@@ -10,14 +11,35 @@ import (
 //go:generate mockgen -source=auth.go -destination=mock/auth.go -package=mock
 
 type TokenInterface interface {
-	GenerateAccessToken(user *domain.User) (string, error)
-	GenerateRefreshToken(user *domain.User) (string, error)
+	GenerateAccessToken(user *domain.User, version int64) (string, error)
+	GenerateRefreshToken(user *domain.User, sessionID, tokenID string) (string, time.Time, error)
 	VerifyAccessToken(encodedToken string) (*domain.TokenPayload, error)
-	VerifyRefreshToken(ctx context.Context, refreshToken string) (*domain.RefreshTokenPayload, bool, error)
-	GenerateActivationToken(userID uint64) (string, error)
+	VerifyRefreshToken(ctx context.Context, refreshToken string) (*domain.RefreshTokenPayload, error)
+	GenerateActivationToken(user *domain.User) (string, error)
+	VerifyActivationToken(token string) (*domain.TokenPayload, error)
+	JWKS() []byte
 }
 
 type AuthService interface {
-	Login(ctx context.Context, email, password string) (dto.LoginResponse, error)
-	RefreshToken(ctx context.Context, refreshToken string) (string, error)
+	Login(ctx context.Context, email, password, clientIP string) (dto.LoginResponse, error)
+	LoginMFA(ctx context.Context, mfaToken, code string) (dto.LoginResponse, error)
+	EnrollMFAChallenge(ctx context.Context, mfaToken string) (*domain.MFAEnrollment, error)
+	StartOIDCLogin(ctx context.Context) (*domain.OIDCAuthorization, error)
+	LoginOIDC(ctx context.Context, state, code string) (dto.LoginResponse, error)
+	RefreshToken(ctx context.Context, refreshToken string) (dto.LoginResponse, error)
+	Logout(ctx context.Context, refreshToken string) error
+	LogoutAll(ctx context.Context, userID string) error
+}
+
+// TokenVersionService revokes access tokens before they expire by versioning them per user
+type TokenVersionService interface {
+	TokenVersion(ctx context.Context, userID string) (int64, error)
+	RevokeAccessTokens(ctx context.Context, userID string) error
+	RevokeAllTokens(ctx context.Context, userID string) error
+}
+
+type EmailVerificationService interface {
+	SendVerificationEmail(ctx context.Context, user *domain.User) error
+	VerifyEmail(ctx context.Context, token string) error
+	ResendVerificationEmail(ctx context.Context, email, clientIP string) error
 }
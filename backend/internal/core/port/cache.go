@@ -10,5 +10,7 @@ type CacheInterface interface {
 	Get(ctx context.Context, key string) ([]byte, error)
 	Delete(ctx context.Context, key string) error
 	DeleteByPrefix(ctx context.Context, prefix string) error
+	Increment(ctx context.Context, key string, ttl time.Duration) (int64, error)
+	CompareAndSwap(ctx context.Context, key string, old, value []byte, ttl time.Duration) (bool, error)
 	Close() error
 }
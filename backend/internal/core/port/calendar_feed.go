@@ -0,0 +1,21 @@
+package port
+
+import (
+	"context"
+	"time"
+
+	"github.com/aldotp/employee-attendance-system/internal/core/domain"
+)
+
+type CalendarFeedRepository interface {
+	SaveCalendarFeedToken(ctx context.Context, token *domain.CalendarFeedToken) error
+	GetCalendarFeedToken(ctx context.Context, tokenHash string) (*domain.CalendarFeedToken, error)
+	TouchCalendarFeedToken(ctx context.Context, id string, accessedAt time.Time) error
+	DeleteCalendarFeedToken(ctx context.Context, userID string) error
+}
+
+type CalendarFeedService interface {
+	CreateCalendarFeed(ctx context.Context, userID string) (*domain.CalendarFeed, error)
+	RevokeCalendarFeed(ctx context.Context, userID string) error
+	RenderCalendarFeed(ctx context.Context, token string) ([]byte, error)
+}
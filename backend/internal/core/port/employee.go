@@ -15,4 +15,8 @@ type EmployeeRepository interface {
 	DeleteEmployee(ctx context.Context, id string) error
 	FindOneByFilters(ctx context.Context, filter map[string]interface{}) (*domain.Employee, error)
 	CreateEmployeeTx(ctx context.Context, tx pgx.Tx, employee *domain.Employee) (*domain.Employee, error)
+	GetEmployeeByUserID(ctx context.Context, userID string) (*domain.Employee, error)
+	ListEmployeesByUserIDs(ctx context.Context, userIDs []string) ([]domain.Employee, error)
+	CountActiveEmployeesByDepartment(ctx context.Context, departmentID string) (int, error)
+	ListActiveUserIDsByDepartment(ctx context.Context, departmentID string) ([]string, error)
 }
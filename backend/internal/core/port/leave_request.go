@@ -2,6 +2,8 @@ package port
 
 import (
 	"context"
+	"mime/multipart"
+	"time"
 
 	"github.com/aldotp/employee-attendance-system/internal/adapter/dto"
 	"github.com/aldotp/employee-attendance-system/internal/core/domain"
@@ -10,11 +12,19 @@ import (
 type LeaveRequestRepository interface {
 	CreateLeaveRequest(ctx context.Context, request *domain.LeaveRequest) (*domain.LeaveRequest, error)
 	GetLeaveRequestByID(ctx context.Context, id string) (*domain.LeaveRequest, error)
-	ListLeaveRequests(ctx context.Context, skip, limit uint64) ([]domain.LeaveRequest, error)
+	ListLeaveRequests(ctx context.Context, skip, limit uint64, filter domain.OwnershipFilter) ([]domain.LeaveRequest, error)
 	UpdateLeaveRequest(ctx context.Context, request *domain.LeaveRequest) (*domain.LeaveRequest, error)
 	DeleteLeaveRequest(ctx context.Context, id string) error
 	ApproveLeaveRequest(ctx context.Context, id string, reviewedBy string) error
 	RejectLeaveRequest(ctx context.Context, id string, reviewedBy string, note string) error
+	ListDepartmentLeaves(ctx context.Context, departmentID string, startDate, endDate time.Time, statuses []domain.LeaveStatus) ([]domain.DepartmentLeave, error)
+	ListUserLeaves(ctx context.Context, userIDs []string, startDate, endDate time.Time, statuses []domain.LeaveStatus) ([]domain.LeaveRequest, error)
+}
+
+type LeaveAttachmentRepository interface {
+	CreateLeaveAttachment(ctx context.Context, attachment *domain.LeaveAttachment) (*domain.LeaveAttachment, error)
+	ListLeaveAttachments(ctx context.Context, leaveRequestID string) ([]domain.LeaveAttachment, error)
+	DeleteLeaveAttachment(ctx context.Context, id string) error
 }
 
 type LeaveService interface {
@@ -25,13 +35,17 @@ type LeaveService interface {
 	UpdateLeaveStatus(ctx context.Context, leaveID string, status string) error
 	UpdateAttendanceForLeave(ctx context.Context, leaveID string) error
 	SendLeaveNotification(ctx context.Context, userID string, leaveType string, status string) error
-	ApproveLeave(ctx context.Context, leaveID string) error
+	ApproveLeave(ctx context.Context, leaveID string) ([]domain.LeaveCoverage, error)
 	RejectLeave(ctx context.Context, leaveID string, reason string) error
 	GetLeaveBalance(ctx context.Context, leaveType string) (float64, error)
+	AddLeaveAttachments(ctx context.Context, leaveID string, userID string, files []*multipart.FileHeader) ([]domain.LeaveAttachment, error)
+	ListLeaveAttachments(ctx context.Context, actor *domain.TokenPayload, leaveID string) ([]dto.LeaveAttachmentResponse, error)
+	GetDepartmentLeaveCalendar(ctx context.Context, actor *domain.TokenPayload, req dto.LeaveCalendarRequest) (*domain.LeaveCalendar, error)
+	CheckLeaveCoverage(ctx context.Context, actor *domain.TokenPayload, leaveID string) ([]domain.LeaveCoverage, error)
 
-	ListLeaves(ctx context.Context) ([]domain.LeaveRequest, error)
+	ListLeaves(ctx context.Context, actor *domain.TokenPayload) ([]domain.LeaveRequest, error)
 	CreateLeave(ctx context.Context, req dto.LeaveRequest) (*domain.LeaveRequest, error)
-	GetLeaveByID(ctx context.Context, id string) (*domain.LeaveRequest, error)
-	UpdateLeave(ctx context.Context, id string, req dto.LeaveRequest) (*domain.LeaveRequest, error)
-	DeleteLeave(ctx context.Context, id string) error
+	GetLeaveByID(ctx context.Context, actor *domain.TokenPayload, id string) (*domain.LeaveRequest, error)
+	UpdateLeave(ctx context.Context, actor *domain.TokenPayload, id string, req dto.LeaveRequest) (*domain.LeaveRequest, error)
+	DeleteLeave(ctx context.Context, actor *domain.TokenPayload, id string) error
 }
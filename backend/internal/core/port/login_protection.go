@@ -0,0 +1,11 @@
+package port
+
+import "context"
+
+// LoginProtectionService tracks failed logins to slow down and lock out password guessing
+type LoginProtectionService interface {
+	CheckLogin(ctx context.Context, email, clientIP string) error
+	RecordLoginFailure(ctx context.Context, email, clientIP string) error
+	RecordLoginSuccess(ctx context.Context, email string) error
+	UnlockAccount(ctx context.Context, userID string) error
+}
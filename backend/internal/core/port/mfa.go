@@ -0,0 +1,28 @@
+package port
+
+import (
+	"context"
+	"time"
+
+	"github.com/aldotp/employee-attendance-system/internal/core/domain"
+)
+
+type MFARepository interface {
+	GetUserMFA(ctx context.Context, userID string) (*domain.UserMFA, error)
+	SaveUserMFA(ctx context.Context, mfa *domain.UserMFA) error
+	EnableUserMFA(ctx context.Context, userID string, step int64, enabledAt time.Time, recoveryCodeHashes []string) error
+	UseMFAStep(ctx context.Context, userID string, step int64) (bool, error)
+	UseRecoveryCode(ctx context.Context, userID, codeHash string, usedAt time.Time) error
+	DeleteUserMFA(ctx context.Context, userID string) error
+}
+
+type MFAService interface {
+	MFAStatus(ctx context.Context, userID string, role domain.UserRole) (enabled bool, required bool, err error)
+	EnrollMFA(ctx context.Context, userID string) (*domain.MFAEnrollment, error)
+	ConfirmMFA(ctx context.Context, userID, code string) ([]string, error)
+	DisableMFA(ctx context.Context, userID, code string) error
+	VerifyMFACode(ctx context.Context, userID, code string) ([]string, error)
+	CreateMFAChallenge(ctx context.Context, userID string) (string, error)
+	ResolveMFAChallenge(ctx context.Context, token string) (string, error)
+	DeleteMFAChallenge(ctx context.Context, token string) error
+}
@@ -11,7 +11,7 @@ type MonitoringService interface {
 	GetSummary(ctx context.Context, date string) (*domain.MonitoringSummary, error)
 	GetDashboardAnalytics(context.Context, string) (*domain.DashboardAnalytics, error)
 	GenerateAttendanceReport(context.Context) ([]domain.AttendanceReport, error)
-	DetectAnomalies(ctx context.Context) ([]domain.Anomaly, error)
+	DetectAnomalies(ctx context.Context, date string) ([]domain.Anomaly, error)
 	ExportData(ctx context.Context, req domain.ExportRequest) (*domain.ExportResponse, error)
 }
 
@@ -2,22 +2,45 @@ package port
 
 import (
 	"context"
+	"time"
 
+	"github.com/aldotp/employee-attendance-system/internal/adapter/dto"
 	"github.com/aldotp/employee-attendance-system/internal/core/domain"
 )
 
 type NotificationRepository interface {
 	CreateNotification(ctx context.Context, notif *domain.Notification) (string, error)
-	GetNotificationByID(ctx context.Context, id string) (*domain.Notification, error)
-	ListNotifications(ctx context.Context, employeeID string, skip, limit uint64) ([]domain.Notification, error)
-	UpdateNotificationStatus(ctx context.Context, notificationID string, status string) error
-	DeleteNotification(ctx context.Context, notificationID string) error
+	GetNotificationByID(ctx context.Context, userID string, id string) (*domain.Notification, error)
+	ListNotifications(ctx context.Context, filter domain.NotificationFilter) ([]domain.Notification, error)
+	CountUnreadNotifications(ctx context.Context, userID string, notificationType domain.NotificationType) (int, error)
+	SetNotificationsRead(ctx context.Context, userID string, ids []string, read bool) (int64, error)
+	MarkAllNotificationsRead(ctx context.Context, userID string, notificationType domain.NotificationType) (int64, error)
+	DeleteNotifications(ctx context.Context, userID string, ids []string) (int64, error)
+	ClaimDueNotifications(ctx context.Context, now time.Time, leaseUntil time.Time, limit uint64) ([]domain.Notification, error)
+	UpdateNotificationDelivery(ctx context.Context, notif *domain.Notification) error
+	ListNotificationPreferences(ctx context.Context, userID string) ([]domain.NotificationPreference, error)
+	GetNotificationPreference(ctx context.Context, userID string, notificationType domain.NotificationType) (*domain.NotificationPreference, error)
+	SaveNotificationPreference(ctx context.Context, pref *domain.NotificationPreference) (*domain.NotificationPreference, error)
 }
 
 type NotificationService interface {
 	CreateNotification(ctx context.Context, notif *domain.Notification) (string, error)
-	GetNotificationByID(ctx context.Context, id string) (*domain.Notification, error)
-	ListNotifications(ctx context.Context, employeeID string, skip, limit uint64) ([]domain.Notification, error)
-	UpdateNotificationStatus(ctx context.Context, notificationID string, status string) error
-	DeleteNotification(ctx context.Context, notificationID string) error
+	GetNotificationByID(ctx context.Context, userID string, id string) (*domain.Notification, error)
+	ListNotifications(ctx context.Context, userID string, req dto.ListNotificationRequest) (*dto.ListNotificationResponse, error)
+	CountUnreadNotifications(ctx context.Context, userID string, notificationType string) (int, error)
+	MarkNotificationRead(ctx context.Context, userID string, id string, read bool) (*domain.Notification, error)
+	MarkNotificationsRead(ctx context.Context, userID string, ids []string) (int64, error)
+	MarkAllNotificationsRead(ctx context.Context, userID string, notificationType string) (int64, error)
+	DeleteNotification(ctx context.Context, userID string, id string) error
+	DeleteNotifications(ctx context.Context, userID string, ids []string) (int64, error)
+	DispatchDueNotifications(ctx context.Context) (int, error)
+	SubscribeNotifications(ctx context.Context, userID string) (<-chan domain.Notification, error)
+	ListNotificationPreferences(ctx context.Context, userID string) ([]domain.NotificationPreference, error)
+	UpdateNotificationPreference(ctx context.Context, userID string, req dto.NotificationPreferenceRequest) (*domain.NotificationPreference, error)
+}
+
+// NotificationChannel delivers notifications through one medium, such as email
+type NotificationChannel interface {
+	Type() domain.NotificationChannelType
+	Send(ctx context.Context, notif domain.Notification, recipient domain.NotificationRecipient) error
 }
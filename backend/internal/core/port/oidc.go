@@ -0,0 +1,14 @@
+package port
+
+import (
+	"context"
+
+	"github.com/aldotp/employee-attendance-system/internal/core/domain"
+)
+
+// OIDCProvider signs users in at an OpenID Connect identity provider with the authorization code
+// flow and PKCE
+type OIDCProvider interface {
+	AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error)
+	Exchange(ctx context.Context, code, verifier, nonce string) (*domain.OIDCIdentity, error)
+}
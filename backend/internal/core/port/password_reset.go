@@ -0,0 +1,19 @@
+package port
+
+import (
+	"context"
+	"time"
+
+	"github.com/aldotp/employee-attendance-system/internal/core/domain"
+)
+
+type PasswordResetRepository interface {
+	CreatePasswordResetToken(ctx context.Context, token *domain.PasswordResetToken) error
+	UsePasswordResetToken(ctx context.Context, tokenHash string, usedAt time.Time) (*domain.PasswordResetToken, error)
+	DeletePasswordResetTokens(ctx context.Context, userID string) error
+}
+
+type PasswordResetService interface {
+	ForgotPassword(ctx context.Context, email, clientIP string) error
+	ResetPassword(ctx context.Context, token, password, clientIP string) error
+}
@@ -0,0 +1,27 @@
+package port
+
+import (
+	"context"
+
+	"github.com/aldotp/employee-attendance-system/internal/core/domain"
+)
+
+type PermissionRepository interface {
+	ListRolePermissions(ctx context.Context) ([]domain.RolePermissions, error)
+	ListPermissionsByRole(ctx context.Context, role domain.UserRole) ([]domain.Permission, error)
+	ReplaceRolePermissions(ctx context.Context, role domain.UserRole, permissions []domain.Permission) error
+}
+
+// AuthorizationService decides what users may do from the permissions granted to their role
+type AuthorizationService interface {
+	// Scope is the widest scope in which the actor may perform the action, empty when it may not
+	Scope(ctx context.Context, actor *domain.TokenPayload, action domain.PermissionAction) (domain.PermissionScope, error)
+	// Authorize returns consts.ErrForbidden unless the actor may perform the action on a record
+	// of the owner
+	Authorize(ctx context.Context, actor *domain.TokenPayload, action domain.PermissionAction, ownerID string) error
+	// OwnershipFilter narrows a listing to the records the actor may perform the action on
+	OwnershipFilter(ctx context.Context, actor *domain.TokenPayload, action domain.PermissionAction) (domain.OwnershipFilter, error)
+
+	ListRolePermissions(ctx context.Context) ([]domain.RolePermissions, error)
+	UpdateRolePermissions(ctx context.Context, role domain.UserRole, permissions []domain.Permission) (*domain.RolePermissions, error)
+}
@@ -0,0 +1,13 @@
+package port
+
+import "context"
+
+// PubSubInterface broadcasts messages to every subscriber of a topic. Delivery is best effort:
+// subscribers only receive the messages published while they are subscribed.
+type PubSubInterface interface {
+	Publish(ctx context.Context, topic string, message []byte) error
+	// Subscribe returns the messages published to the topic until ctx is done, when the channel
+	// is closed
+	Subscribe(ctx context.Context, topic string) (<-chan []byte, error)
+	Close() error
+}
@@ -0,0 +1,31 @@
+package port
+
+import (
+	"context"
+	"time"
+
+	"github.com/aldotp/employee-attendance-system/internal/adapter/dto"
+	"github.com/aldotp/employee-attendance-system/internal/core/domain"
+)
+
+type RotationRepository interface {
+	CreateRotationPattern(ctx context.Context, pattern *domain.RotationPattern) (*domain.RotationPattern, error)
+	ListRotationPatterns(ctx context.Context) ([]domain.RotationPattern, error)
+	GetRotationPattern(ctx context.Context, id string) (*domain.RotationPattern, error)
+	DeleteRotationPattern(ctx context.Context, id string) error
+
+	CreateRotationAssignment(ctx context.Context, assignment *domain.RotationAssignment) (*domain.RotationAssignment, error)
+	ListRotationAssignments(ctx context.Context, userID string, startDate, endDate time.Time) ([]domain.RotationAssignment, error)
+	DeleteRotationAssignment(ctx context.Context, id string) error
+}
+
+type RotationService interface {
+	CreateRotationPattern(ctx context.Context, req dto.RotationPatternRequest) (*domain.RotationPattern, error)
+	ListRotationPatterns(ctx context.Context) ([]domain.RotationPattern, error)
+	GetRotationPattern(ctx context.Context, id string) (*domain.RotationPattern, error)
+	DeleteRotationPattern(ctx context.Context, id string) error
+
+	AssignRotation(ctx context.Context, req dto.RotationAssignmentRequest) (*domain.RotationAssignment, error)
+	ListRotationAssignments(ctx context.Context, userID string) ([]domain.RotationAssignment, error)
+	DeleteRotationAssignment(ctx context.Context, id string) error
+}
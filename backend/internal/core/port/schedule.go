@@ -2,29 +2,88 @@ package port
 
 import (
 	"context"
+	"time"
 
+	"github.com/aldotp/employee-attendance-system/internal/adapter/dto"
 	"github.com/aldotp/employee-attendance-system/internal/core/domain"
 )
 
 type ScheduleRepository interface {
-	ListSchedules(ctx context.Context) ([]domain.Schedule, error)
+	ListSchedules(ctx context.Context, filter domain.OwnershipFilter) ([]domain.Schedule, error)
 	CreateSchedule(ctx context.Context, schedule *domain.Schedule) (*domain.Schedule, error)
 	GetSchedule(ctx context.Context, id string) (*domain.Schedule, error)
 	UpdateSchedule(ctx context.Context, id string, schedule *domain.Schedule) (*domain.Schedule, error)
 	DeleteSchedule(ctx context.Context, id string) error
-	RequestScheduleSwap(ctx context.Context, requestorID string, targetScheduleID string, proposedScheduleID string) error
+	RequestScheduleSwap(ctx context.Context, swap *domain.ScheduleSwapRequest) (*domain.ScheduleSwapRequest, error)
+	GetScheduleSwapRequest(ctx context.Context, id string) (*domain.ScheduleSwapRequest, error)
+	ListScheduleSwapRequests(ctx context.Context, userID string, status domain.SwapStatus) ([]domain.ScheduleSwapRequest, error)
+	CountOpenScheduleSwaps(ctx context.Context, scheduleIDs ...string) (int, error)
+	UpdateScheduleSwapStatus(ctx context.Context, swap *domain.ScheduleSwapRequest, from domain.SwapStatus) error
+	ApproveScheduleSwap(ctx context.Context, id string, reviewerID string, note string) error
 	GetWorkCalendar(ctx context.Context, employeeID string, month int, year int) ([]domain.Schedule, error)
 	GetWorkRotation(ctx context.Context, employeeID string) (*domain.Schedule, error)
+	ListDepartmentSchedules(ctx context.Context, departmentID string, startDate, endDate time.Time) ([]domain.Schedule, error)
+	ListSchedulesByUsers(ctx context.Context, userIDs []string, startDate, endDate time.Time) ([]domain.Schedule, error)
+	ListSchedulesByDate(ctx context.Context, date time.Time) ([]domain.Schedule, error)
+	ListSchedulesStartingOrEnding(ctx context.Context, startAfter, startUntil, endAfter, endUntil time.Time) ([]domain.Schedule, error)
+	BulkCreateSchedules(ctx context.Context, schedules []domain.Schedule) (int, error)
+}
+
+type ShiftTemplateRepository interface {
+	CreateShiftTemplate(ctx context.Context, template *domain.ShiftTemplate) (*domain.ShiftTemplate, error)
+	ListShiftTemplates(ctx context.Context) ([]domain.ShiftTemplate, error)
+	GetShiftTemplate(ctx context.Context, id string) (*domain.ShiftTemplate, error)
+	DeleteShiftTemplate(ctx context.Context, id string) error
+}
+
+type ScheduleRuleRepository interface {
+	CreateScheduleRule(ctx context.Context, rule *domain.ScheduleRule) (*domain.ScheduleRule, error)
+	ListScheduleRules(ctx context.Context) ([]domain.ScheduleRule, error)
+	FindScheduleRule(ctx context.Context, departmentID string, workLocationID string) (*domain.ScheduleRule, error)
+	DeleteScheduleRule(ctx context.Context, id string) error
+}
+
+type OpenShiftRepository interface {
+	CreateOpenShift(ctx context.Context, openShift *domain.OpenShift) (*domain.OpenShift, error)
+	GetOpenShift(ctx context.Context, id string) (*domain.OpenShift, error)
+	ListOpenShifts(ctx context.Context, departmentID string, status domain.OpenShiftStatus) ([]domain.OpenShift, error)
+	CancelOpenShift(ctx context.Context, id string, reviewerID string) error
+	CreateOpenShiftClaim(ctx context.Context, claim *domain.OpenShiftClaim) (*domain.OpenShiftClaim, error)
+	GetOpenShiftClaim(ctx context.Context, id string) (*domain.OpenShiftClaim, error)
+	ListOpenShiftClaims(ctx context.Context, openShiftID string) ([]domain.OpenShiftClaim, error)
+	ConfirmOpenShiftClaim(ctx context.Context, claimID string, reviewerID string) error
 }
 
 type ScheduleService interface {
-	GetWorkRotation(ctx context.Context, employeeID string) (*domain.Schedule, error)
-	GetWorkCalendar(ctx context.Context, employeeID string, month int, year int) ([]domain.Schedule, error)
-	RequestScheduleSwap(ctx context.Context, requestorID string, targetScheduleID string, proposedScheduleID string) error
+	GetWorkRotation(ctx context.Context, userID string) (*domain.WorkRotation, error)
+	GetWorkCalendar(ctx context.Context, userID string, month int, year int) ([]domain.WorkCalendarDay, error)
+	RequestScheduleSwap(ctx context.Context, requestorID string, req dto.ScheduleSwapRequest) (*domain.ScheduleSwapRequest, error)
+	ListScheduleSwapRequests(ctx context.Context, userID string, status domain.SwapStatus) ([]domain.ScheduleSwapRequest, error)
+	RespondScheduleSwap(ctx context.Context, swapID string, userID string, accept bool) (*domain.ScheduleSwapRequest, error)
+	CancelScheduleSwap(ctx context.Context, swapID string, userID string) (*domain.ScheduleSwapRequest, error)
+	ApproveScheduleSwap(ctx context.Context, swapID string, reviewerID string, note string) (*domain.ScheduleSwapRequest, []domain.ScheduleViolation, error)
+	RejectScheduleSwap(ctx context.Context, swapID string, reviewerID string, note string) (*domain.ScheduleSwapRequest, error)
 
-	ListSchedules(ctx context.Context) ([]domain.Schedule, error)
-	CreateSchedule(ctx context.Context, schedule *domain.Schedule) (*domain.Schedule, error)
-	GetSchedule(ctx context.Context, id string) (*domain.Schedule, error)
-	UpdateSchedule(ctx context.Context, id string, schedule *domain.Schedule) (*domain.Schedule, error)
-	DeleteSchedule(ctx context.Context, id string) error
+	ListSchedules(ctx context.Context, actor *domain.TokenPayload) ([]domain.Schedule, error)
+	CreateSchedule(ctx context.Context, actor *domain.TokenPayload, schedule *domain.Schedule) (*domain.Schedule, []domain.ScheduleViolation, error)
+	GetSchedule(ctx context.Context, actor *domain.TokenPayload, id string) (*domain.Schedule, error)
+	UpdateSchedule(ctx context.Context, actor *domain.TokenPayload, id string, schedule *domain.Schedule) (*domain.Schedule, []domain.ScheduleViolation, error)
+	DeleteSchedule(ctx context.Context, actor *domain.TokenPayload, id string) error
+
+	CreateScheduleRule(ctx context.Context, req dto.ScheduleRuleRequest) (*domain.ScheduleRule, error)
+	ListScheduleRules(ctx context.Context) ([]domain.ScheduleRule, error)
+	DeleteScheduleRule(ctx context.Context, id string) error
+
+	PostOpenShift(ctx context.Context, postedBy string, req dto.OpenShiftRequest) (*domain.OpenShift, error)
+	ListOpenShifts(ctx context.Context, departmentID string, status domain.OpenShiftStatus) ([]domain.OpenShift, error)
+	ListAvailableOpenShifts(ctx context.Context, userID string) ([]domain.OpenShift, error)
+	ClaimOpenShift(ctx context.Context, openShiftID string, userID string) (*domain.OpenShiftClaim, []domain.ScheduleViolation, error)
+	ListOpenShiftClaims(ctx context.Context, openShiftID string) ([]domain.OpenShiftClaim, error)
+	ConfirmOpenShiftClaim(ctx context.Context, claimID string, reviewerID string) (*domain.OpenShift, []domain.ScheduleViolation, error)
+	CancelOpenShift(ctx context.Context, openShiftID string, reviewerID string) (*domain.OpenShift, error)
+
+	CreateShiftTemplate(ctx context.Context, req dto.ShiftTemplateRequest) (*domain.ShiftTemplate, error)
+	ListShiftTemplates(ctx context.Context) ([]domain.ShiftTemplate, error)
+	DeleteShiftTemplate(ctx context.Context, id string) error
+	GenerateSchedules(ctx context.Context, req dto.BulkScheduleRequest) (*domain.BulkScheduleResult, error)
 }
@@ -0,0 +1,12 @@
+package port
+
+import (
+	"context"
+
+	"github.com/aldotp/employee-attendance-system/internal/core/domain"
+)
+
+type StreamTicketService interface {
+	CreateStreamTicket(ctx context.Context, payload *domain.TokenPayload) (*domain.StreamTicket, error)
+	RedeemStreamTicket(ctx context.Context, ticket string) (*domain.TokenPayload, error)
+}
@@ -12,4 +12,5 @@ type WorkLocationRepository interface {
 	ListWorkLocations(ctx context.Context, skip, limit uint64) ([]domain.WorkLocation, error)
 	UpdateWorkLocation(ctx context.Context, location *domain.WorkLocation) (*domain.WorkLocation, error)
 	DeleteWorkLocation(ctx context.Context, id string) error
+	ListWorkLocationNames(ctx context.Context, ids []string) (map[string]string, error)
 }
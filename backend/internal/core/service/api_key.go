@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"slices"
+	"time"
+
+	"github.com/aldotp/employee-attendance-system/internal/core/domain"
+	"github.com/aldotp/employee-attendance-system/internal/core/port"
+	"github.com/aldotp/employee-attendance-system/pkg/consts"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// apiKeyPrefix marks the secrets issued as API keys so they are easy to recognise in leaks
+	apiKeyPrefix = "eas_"
+	// apiKeyShownLength is how much of a key is kept in clear to tell keys apart
+	apiKeyShownLength = len(apiKeyPrefix) + 8
+)
+
+type APIKeyService struct {
+	repo   port.APIKeyRepository
+	authz  port.AuthorizationService
+	policy domain.APIKeyPolicy
+	log    *zap.Logger
+}
+
+func NewAPIKeyService(repo port.APIKeyRepository, authz port.AuthorizationService, policy domain.APIKeyPolicy, log *zap.Logger) *APIKeyService {
+	return &APIKeyService{
+		repo:   repo,
+		authz:  authz,
+		policy: policy,
+		log:    log,
+	}
+}
+
+// CreateAPIKey issues a key holding the given permissions, which must be in the any scope since a
+// key owns no records, and which the actor must hold themselves. The key is only returned here.
+func (s *APIKeyService) CreateAPIKey(ctx context.Context, actor *domain.TokenPayload, name string, permissions []domain.Permission, expiresAt *time.Time) (*domain.NewAPIKey, error) {
+	unique := make([]domain.Permission, 0, len(permissions))
+	for _, permission := range permissions {
+		if !permission.IsValid() {
+			return nil, consts.ErrUnknownPermission
+		}
+		if permission.Scope() != domain.ScopeAny || !permission.Action().APIKeyGrantable() {
+			return nil, consts.ErrAPIKeyPermission
+		}
+
+		scope, err := s.authz.Scope(ctx, actor, permission.Action())
+		if err != nil {
+			return nil, err
+		}
+		if !scope.Includes(permission.Scope()) {
+			return nil, consts.ErrAPIKeyPermission
+		}
+
+		if !slices.Contains(unique, permission) {
+			unique = append(unique, permission)
+		}
+	}
+	slices.Sort(unique)
+
+	now := time.Now()
+	expiry := now.Add(s.policy.DefaultTTL)
+	if expiresAt != nil {
+		expiry = *expiresAt
+	}
+	if !expiry.After(now) || (s.policy.MaxTTL > 0 && expiry.Sub(now) > s.policy.MaxTTL) {
+		return nil, consts.ErrInvalidAPIKeyExpiry
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	key := apiKeyPrefix + hex.EncodeToString(secret)
+
+	apiKey := domain.APIKey{
+		ID:          uuid.New().String(),
+		Name:        name,
+		Prefix:      key[:apiKeyShownLength],
+		KeyHash:     hashToken(key),
+		Permissions: unique,
+		CreatedBy:   actor.UserID,
+		ExpiresAt:   expiry,
+		CreatedAt:   now,
+	}
+
+	if err := s.repo.CreateAPIKey(ctx, &apiKey); err != nil {
+		s.log.Error("failed to create api key", zap.Error(err))
+		return nil, consts.ErrInternal
+	}
+
+	s.log.Info("api key created", zap.String("api_key_id", apiKey.ID), zap.String("created_by", actor.UserID))
+
+	return &domain.NewAPIKey{APIKey: apiKey, Key: key}, nil
+}
+
+func (s *APIKeyService) ListAPIKeys(ctx context.Context) ([]domain.APIKey, error) {
+	keys, err := s.repo.ListAPIKeys(ctx)
+	if err != nil {
+		s.log.Error("failed to list api keys", zap.Error(err))
+		return nil, consts.ErrInternal
+	}
+
+	return keys, nil
+}
+
+func (s *APIKeyService) GetAPIKey(ctx context.Context, id string) (*domain.APIKey, error) {
+	key, err := s.repo.GetAPIKeyByID(ctx, id)
+	if err != nil {
+		if err == consts.ErrDataNotFound {
+			return nil, err
+		}
+		s.log.Error("failed to get api key", zap.Error(err))
+		return nil, consts.ErrInternal
+	}
+
+	return key, nil
+}
+
+// RevokeAPIKey stops the key from working right away, its audit log is kept
+func (s *APIKeyService) RevokeAPIKey(ctx context.Context, id string) error {
+	if err := s.repo.RevokeAPIKey(ctx, id, time.Now()); err != nil {
+		if err == consts.ErrDataNotFound {
+			return err
+		}
+		s.log.Error("failed to revoke api key", zap.Error(err))
+		return consts.ErrInternal
+	}
+
+	s.log.Info("api key revoked", zap.String("api_key_id", id))
+
+	return nil
+}
+
+func (s *APIKeyService) ListAPIKeyAuditLogs(ctx context.Context, id string, page, limit uint64) ([]domain.APIKeyAuditLog, error) {
+	if _, err := s.GetAPIKey(ctx, id); err != nil {
+		return nil, err
+	}
+
+	if page == 0 {
+		page = 1
+	}
+	if limit == 0 {
+		limit = 50
+	}
+
+	logs, err := s.repo.ListAPIKeyAuditLogs(ctx, id, page, limit)
+	if err != nil {
+		s.log.Error("failed to list api key audit logs", zap.Error(err))
+		return nil, consts.ErrInternal
+	}
+
+	return logs, nil
+}
+
+// Authenticate returns consts.ErrInvalidAPIKey for keys that are unknown, revoked or expired
+func (s *APIKeyService) Authenticate(ctx context.Context, key string) (*domain.TokenPayload, error) {
+	apiKey, err := s.repo.GetAPIKeyByHash(ctx, hashToken(key))
+	if err != nil {
+		if err == consts.ErrDataNotFound {
+			return nil, consts.ErrInvalidAPIKey
+		}
+		s.log.Error("failed to get api key by hash", zap.Error(err))
+		return nil, consts.ErrInternal
+	}
+
+	if !apiKey.IsActive(time.Now()) {
+		return nil, consts.ErrInvalidAPIKey
+	}
+
+	// grants no longer allowed to keys are dropped from the keys created before
+	permissions := slices.DeleteFunc(slices.Clone(apiKey.Permissions), func(permission domain.Permission) bool {
+		return !permission.Action().APIKeyGrantable()
+	})
+
+	return &domain.TokenPayload{
+		APIKeyID:    apiKey.ID,
+		Permissions: permissions,
+	}, nil
+}
+
+func (s *APIKeyService) RecordUsage(ctx context.Context, log *domain.APIKeyAuditLog) error {
+	log.ID = uuid.New().String()
+	if log.CreatedAt.IsZero() {
+		log.CreatedAt = time.Now()
+	}
+
+	if err := s.repo.RecordAPIKeyUsage(ctx, log); err != nil {
+		s.log.Error("failed to record api key usage", zap.String("api_key_id", log.APIKeyID), zap.Error(err))
+		return consts.ErrInternal
+	}
+
+	return nil
+}
@@ -2,23 +2,35 @@ package service
 
 import (
 	"context"
-	"errors"
 	"time"
 
 	"github.com/aldotp/employee-attendance-system/internal/adapter/dto"
 	"github.com/aldotp/employee-attendance-system/internal/core/domain"
 	"github.com/aldotp/employee-attendance-system/internal/core/port"
+	"github.com/aldotp/employee-attendance-system/pkg/consts"
+	"github.com/aldotp/employee-attendance-system/pkg/util"
 	"github.com/google/uuid"
 )
 
 type AttendanceService struct {
-	repo port.AttendanceRepository
-	// add other dependencies as needed (e.g., notification, logger)
+	repo            port.AttendanceRepository
+	scheduleRepo    port.ScheduleRepository
+	employeeRepo    port.EmployeeRepository
+	leaveRepo       port.LeaveRequestRepository
+	notificationSvc port.NotificationService
+	authz           port.AuthorizationService
+	policy          domain.AttendancePolicy
 }
 
-func NewAttendanceService(repo port.AttendanceRepository) *AttendanceService {
+func NewAttendanceService(repo port.AttendanceRepository, scheduleRepo port.ScheduleRepository, employeeRepo port.EmployeeRepository, leaveRepo port.LeaveRequestRepository, notificationService port.NotificationService, authz port.AuthorizationService, policy domain.AttendancePolicy) *AttendanceService {
 	return &AttendanceService{
-		repo: repo,
+		repo:            repo,
+		scheduleRepo:    scheduleRepo,
+		employeeRepo:    employeeRepo,
+		leaveRepo:       leaveRepo,
+		notificationSvc: notificationService,
+		authz:           authz,
+		policy:          policy,
 	}
 }
 
@@ -26,12 +38,12 @@ func NewAttendanceService(repo port.AttendanceRepository) *AttendanceService {
 func (s *AttendanceService) OpenAttendance(ctx context.Context, req dto.AttendanceRequest, userID string) (dto.AttendanceResponse, error) {
 	var typeAttendance string
 
-	if req.TypeAttendance == "check_in" {
-		typeAttendance = "check_in"
-	} else if req.TypeAttendance == "check_out" {
-		typeAttendance = "check_out"
+	if req.TypeAttendance == domain.AttendanceCheckIn {
+		typeAttendance = domain.AttendanceCheckIn
+	} else if req.TypeAttendance == domain.AttendanceCheckOut {
+		typeAttendance = domain.AttendanceCheckOut
 	} else {
-		return dto.AttendanceResponse{}, errors.New("invalid attendance status")
+		return dto.AttendanceResponse{}, consts.InvalidInput("invalid attendance status")
 	}
 
 	attendance := &domain.Attendance{
@@ -48,6 +60,10 @@ func (s *AttendanceService) OpenAttendance(ctx context.Context, req dto.Attendan
 		UpdatedAt: time.Now(),
 	}
 
+	if err := s.assignShift(ctx, attendance); err != nil {
+		return dto.AttendanceResponse{}, err
+	}
+
 	created, err := s.repo.CreateAttendance(ctx, attendance)
 	if err != nil {
 		return dto.AttendanceResponse{}, err
@@ -57,10 +73,117 @@ func (s *AttendanceService) OpenAttendance(ctx context.Context, req dto.Attendan
 		AttendanceID: created.ID,
 		UserID:       created.UserID,
 		Time:         created.Time,
+		BusinessDate: created.BusinessDate.Format("2006-01-02"),
 		Status:       string(created.Status),
+		HoursWorked:  created.HoursWorked,
 	}, nil
 }
 
+// maxShiftLength bounds how far back a check-out looks for the check-in it closes
+const maxShiftLength = 24 * time.Hour
+
+// assignShift attaches an attendance event to the shift it belongs to. A check-in is matched
+// to the scheduled shift whose window contains it and is late when it comes after the start of
+// the shift plus the grace period. A check-out closes the open check-in, taking over its shift
+// and business date and recording the hours worked. Flexible schedules replace the late rule
+// with their core hours and minimum duration, see domain.Schedule. Events outside any shift
+// fall back to the calendar date in the employee's timezone.
+func (s *AttendanceService) assignShift(ctx context.Context, attendance *domain.Attendance) error {
+	if attendance.Time.IsZero() {
+		attendance.Time = time.Now()
+	}
+
+	loc := employeeLocation(ctx, s.employeeRepo, attendance.UserID)
+	local := attendance.Time.In(loc)
+	attendance.BusinessDate = time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+
+	if attendance.Type == domain.AttendanceCheckOut {
+		checkIn, err := s.repo.GetOpenCheckIn(ctx, attendance.UserID, attendance.ID, attendance.Time.Add(-maxShiftLength), attendance.Time)
+		if err != nil {
+			if err == consts.ErrDataNotFound {
+				return nil
+			}
+			return err
+		}
+
+		attendance.ScheduleID = checkIn.ScheduleID
+		attendance.BusinessDate = checkIn.BusinessDate
+		attendance.HoursWorked = util.RoundFloat(attendance.Time.Sub(checkIn.Time).Hours(), 2)
+
+		if checkIn.ScheduleID == "" {
+			return nil
+		}
+
+		schedule, err := s.scheduleRepo.GetSchedule(ctx, checkIn.ScheduleID)
+		if err != nil {
+			if err == consts.ErrDataNotFound {
+				return nil
+			}
+			return err
+		}
+
+		status, err := schedule.CheckOutStatus(checkIn.Time, attendance.Time, loc)
+		if err != nil {
+			return err
+		}
+		attendance.Status = status
+		return nil
+	}
+
+	// yesterday's schedules are included for night shifts still running after midnight
+	schedules, err := s.scheduleRepo.ListSchedulesByUsers(ctx, []string{attendance.UserID}, attendance.BusinessDate.AddDate(0, 0, -1), attendance.BusinessDate.AddDate(0, 0, 1))
+	if err != nil {
+		return err
+	}
+
+	for _, schedule := range schedules {
+		start, end, err := schedule.Window(loc)
+		if err != nil {
+			continue
+		}
+
+		if attendance.Time.Before(start.Add(-s.policy.EarlyCheckIn)) || attendance.Time.After(end) {
+			continue
+		}
+
+		status, err := schedule.CheckInStatus(attendance.Time, loc, s.policy.LateGrace)
+		if err != nil {
+			return err
+		}
+
+		attendance.ScheduleID = schedule.ID
+		attendance.BusinessDate = schedule.Date
+		attendance.Status = status
+		return nil
+	}
+
+	return nil
+}
+
+// employeeLocation returns the timezone of an employee, UTC when it is unknown
+func employeeLocation(ctx context.Context, employeeRepo port.EmployeeRepository, userID string) *time.Location {
+	employee, err := employeeRepo.GetEmployeeByUserID(ctx, userID)
+	if err != nil {
+		return time.UTC
+	}
+
+	return timezoneLocation(employee.Timezone)
+}
+
+// timezoneLocation loads an IANA timezone, UTC when it is empty or unknown
+func timezoneLocation(name string) *time.Location {
+	if name == "" {
+		return time.UTC
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return time.UTC
+	}
+
+	return loc
+}
+
 // ValidateSchedule checks if the user is scheduled for a given scheduleID
 func (s *AttendanceService) ValidateSchedule(ctx context.Context, userID, scheduleID string) (bool, error) {
 	// Example logic: always return true (replace with real validation)
@@ -82,6 +205,7 @@ func (s *AttendanceService) ValidateRadius(ctx context.Context, userID string, l
 // RecordAttendance records an attendance event
 func (s *AttendanceService) RecordAttendance(ctx context.Context, req dto.AttendanceRequest, userID string) error {
 	attendance := &domain.Attendance{
+		ID:        uuid.New().String(),
 		UserID:    userID,
 		Type:      req.TypeAttendance,
 		Time:      req.Time,
@@ -92,26 +216,31 @@ func (s *AttendanceService) RecordAttendance(ctx context.Context, req dto.Attend
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
+
+	if err := s.assignShift(ctx, attendance); err != nil {
+		return err
+	}
+
 	_, err := s.repo.CreateAttendance(ctx, attendance)
 	return err
 }
 
-// SendAttendanceNotification sends a notification to the user
-func (s *AttendanceService) SendAttendanceNotification(ctx context.Context, userID string) error {
-	// Example logic: not implemented
-	return errors.New("SendAttendanceNotification not implemented")
-}
+// ListAttendances lists the attendance events the actor may read
+func (s *AttendanceService) ListAttendances(ctx context.Context, actor *domain.TokenPayload, req domain.ListAttendanceRequest) ([]domain.GetAttendanceResponse, error) {
+	filter, err := s.authz.OwnershipFilter(ctx, actor, domain.ActionAttendanceRead)
+	if err != nil {
+		return nil, err
+	}
 
-func (s *AttendanceService) ListAttendances(ctx context.Context, req domain.ListAttendanceRequest) ([]domain.GetAttendanceResponse, error) {
-	return s.repo.ListAttendances(ctx, req.Page, req.Limit, req.Date, req.Type)
+	return s.repo.ListAttendances(ctx, req.Page, req.Limit, req.Date, req.Type, filter)
 }
 
-func (s *AttendanceService) GetAttendanceByID(ctx context.Context, id string) (*domain.Attendance, error) {
-	return s.repo.GetAttendanceByID(ctx, id)
+func (s *AttendanceService) GetAttendanceByID(ctx context.Context, actor *domain.TokenPayload, id string) (*domain.Attendance, error) {
+	return s.authorizedAttendance(ctx, actor, domain.ActionAttendanceRead, id)
 }
 
-func (s *AttendanceService) UpdateAttendance(ctx context.Context, id string, req dto.AttendanceRequest) (*domain.Attendance, error) {
-	attendance, err := s.repo.GetAttendanceByID(ctx, id)
+func (s *AttendanceService) UpdateAttendance(ctx context.Context, actor *domain.TokenPayload, id string, req dto.AttendanceRequest) (*domain.Attendance, error) {
+	attendance, err := s.authorizedAttendance(ctx, actor, domain.ActionAttendanceWrite, id)
 	if err != nil {
 		return nil, err
 	}
@@ -123,6 +252,15 @@ func (s *AttendanceService) UpdateAttendance(ctx context.Context, id string, req
 	if req.TypeAttendance != "" {
 		attendance.Type = req.TypeAttendance
 	}
+	// a moved event may belong to another shift and business day
+	if req.Time != (time.Time{}) || req.TypeAttendance != "" {
+		attendance.ScheduleID = ""
+		attendance.Status = domain.AttendanceStatusPresent
+		attendance.HoursWorked = 0
+		if err := s.assignShift(ctx, attendance); err != nil {
+			return nil, err
+		}
+	}
 	if req.Status != "" {
 		attendance.Status = domain.AttendanceStatus(req.Status)
 	}
@@ -143,14 +281,38 @@ func (s *AttendanceService) UpdateAttendance(ctx context.Context, id string, req
 	return s.repo.UpdateAttendance(ctx, attendance)
 }
 
-func (s *AttendanceService) DeleteAttendance(ctx context.Context, id string) error {
+func (s *AttendanceService) DeleteAttendance(ctx context.Context, actor *domain.TokenPayload, id string) error {
+	if _, err := s.authorizedAttendance(ctx, actor, domain.ActionAttendanceWrite, id); err != nil {
+		return err
+	}
+
 	return s.repo.DeleteAttendance(ctx, id)
 }
 
+// authorizedAttendance loads an attendance event the actor may perform the action on
+func (s *AttendanceService) authorizedAttendance(ctx context.Context, actor *domain.TokenPayload, action domain.PermissionAction, id string) (*domain.Attendance, error) {
+	attendance, err := s.repo.GetAttendanceByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.authz.Authorize(ctx, actor, action, attendance.UserID); err != nil {
+		return nil, err
+	}
+
+	return attendance, nil
+}
+
 func (s *AttendanceService) GetAttendanceHistory(ctx context.Context, employeeID, startDate, endDate string) ([]domain.Attendance, error) {
 	return s.repo.GetAttendanceHistory(ctx, employeeID, startDate, endDate)
 }
 
-func (s *AttendanceService) GetUsersAttendanceStatus(ctx context.Context, date string) (map[string]bool, error) {
+// GetUsersAttendanceStatus reports who attended on the date, it covers everybody so it needs
+// attendance:read:any
+func (s *AttendanceService) GetUsersAttendanceStatus(ctx context.Context, actor *domain.TokenPayload, date string) (map[string]bool, error) {
+	if err := s.authz.Authorize(ctx, actor, domain.ActionAttendanceRead, ""); err != nil {
+		return nil, err
+	}
+
 	return s.repo.GetUsersAttendanceStatus(ctx, date)
 }
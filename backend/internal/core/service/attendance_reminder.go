@@ -0,0 +1,202 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aldotp/employee-attendance-system/internal/core/domain"
+	"github.com/aldotp/employee-attendance-system/pkg/consts"
+	"github.com/google/uuid"
+)
+
+// attendanceReminderNamespace derives the IDs of attendance reminders from the schedule they are
+// about. Sent reminders are recorded under that ID apart from their notification, so later runs
+// skip them even after a restart or once the employee deleted the notification.
+var attendanceReminderNamespace = uuid.NewSHA1(uuid.NameSpaceURL, []byte("employee-attendance-system/attendance-reminder"))
+
+// SendAttendanceReminders reminds the employees whose shift starts within the check-in reminder
+// lead time and who have not checked in yet, and warns those whose shift ended longer than the
+// missed check-out delay ago without a check-out. Shift times are taken in each employee's
+// timezone. Employees on approved leave or no longer active are skipped. It returns the number
+// of notifications enqueued.
+func (s *AttendanceService) SendAttendanceReminders(ctx context.Context) (int, error) {
+	now := time.Now()
+
+	// only the shifts starting within the reminder lead time, or that ended long enough ago to
+	// miss their check-out, empty ranges for the notifications turned off
+	startUntil := now
+	if s.policy.CheckInReminder > 0 {
+		startUntil = now.Add(s.policy.CheckInReminder)
+	}
+	endAfter, endUntil := now, now
+	if s.policy.MissedCheckOutAfter > 0 {
+		endAfter, endUntil = now.Add(-maxShiftLength), now.Add(-s.policy.MissedCheckOutAfter)
+	}
+
+	schedules, err := s.scheduleRepo.ListSchedulesStartingOrEnding(ctx, now, startUntil, endAfter, endUntil)
+	if err != nil {
+		return 0, err
+	}
+
+	return s.sendAttendanceReminders(ctx, schedules, now)
+}
+
+// SendAttendanceNotification sends the check-in reminder or missed check-out warning due to the
+// user right now, if any
+func (s *AttendanceService) SendAttendanceNotification(ctx context.Context, userID string) error {
+	now := time.Now()
+	utc := now.UTC()
+	today := time.Date(utc.Year(), utc.Month(), utc.Day(), 0, 0, 0, 0, time.UTC)
+
+	schedules, err := s.scheduleRepo.ListSchedulesByUsers(ctx, []string{userID}, today.AddDate(0, 0, -2), today.AddDate(0, 0, 1))
+	if err != nil {
+		return err
+	}
+
+	_, err = s.sendAttendanceReminders(ctx, schedules, now)
+	return err
+}
+
+func (s *AttendanceService) sendAttendanceReminders(ctx context.Context, schedules []domain.Schedule, now time.Time) (int, error) {
+	if len(schedules) == 0 {
+		return 0, nil
+	}
+
+	userIDs := []string{}
+	seenUsers := map[string]bool{}
+	dates := []time.Time{}
+	seenDates := map[time.Time]bool{}
+	for _, schedule := range schedules {
+		if !seenUsers[schedule.UserID] {
+			seenUsers[schedule.UserID] = true
+			userIDs = append(userIDs, schedule.UserID)
+		}
+		if !seenDates[schedule.Date] {
+			seenDates[schedule.Date] = true
+			dates = append(dates, schedule.Date)
+		}
+	}
+
+	startDate, endDate := dates[0], dates[0]
+	for _, date := range dates {
+		if date.Before(startDate) {
+			startDate = date
+		}
+		if date.After(endDate) {
+			endDate = date
+		}
+	}
+
+	attendances, err := s.repo.ListAttendancesByUsers(ctx, userIDs, startDate, endDate)
+	if err != nil {
+		return 0, err
+	}
+
+	// events are matched to their schedule when recorded, those outside any shift only carry the
+	// business date
+	checkedIn := map[string]bool{}
+	checkedOut := map[string]bool{}
+	for _, attendance := range attendances {
+		key := attendance.ScheduleID
+		if key == "" {
+			key = attendance.UserID + "/" + attendance.BusinessDate.Format("2006-01-02")
+		}
+
+		switch attendance.Type {
+		case domain.AttendanceCheckIn:
+			checkedIn[key] = true
+		case domain.AttendanceCheckOut:
+			checkedOut[key] = true
+		}
+	}
+
+	leaves, err := s.leaveRepo.ListUserLeaves(ctx, userIDs, startDate, endDate, []domain.LeaveStatus{domain.Approved})
+	if err != nil {
+		return 0, err
+	}
+
+	onLeave := func(schedule domain.Schedule) bool {
+		for _, leave := range leaves {
+			if leave.UserID == schedule.UserID && !schedule.Date.Before(leave.StartDate) && !schedule.Date.After(leave.EndDate) {
+				return true
+			}
+		}
+		return false
+	}
+
+	employeeList, err := s.employeeRepo.ListEmployeesByUserIDs(ctx, userIDs)
+	if err != nil {
+		return 0, err
+	}
+
+	// schedules of users without an employee are still honoured, in UTC like employeeLocation does
+	employees := make(map[string]*domain.Employee, len(employeeList))
+	for i := range employeeList {
+		employees[employeeList[i].UserID] = &employeeList[i]
+	}
+
+	sent := 0
+	for _, schedule := range schedules {
+		loc := time.UTC
+		if e := employees[schedule.UserID]; e != nil {
+			if e.Status != domain.StatusActive {
+				continue
+			}
+			loc = timezoneLocation(e.Timezone)
+		}
+
+		start, end, err := schedule.Window(loc)
+		if err != nil {
+			continue
+		}
+
+		dayKey := schedule.UserID + "/" + schedule.Date.Format("2006-01-02")
+		hasCheckIn := checkedIn[schedule.ID] || checkedIn[dayKey]
+		hasCheckOut := checkedOut[schedule.ID] || checkedOut[dayKey]
+
+		var notificationType domain.NotificationType
+		var message string
+		switch {
+		case s.policy.CheckInReminder > 0 && !hasCheckIn &&
+			!now.Before(start.Add(-s.policy.CheckInReminder)) && now.Before(start):
+			notificationType = domain.NotificationTypeReminder
+			message = fmt.Sprintf("Your shift starts at %s, remember to check in.", start.In(loc).Format("15:04"))
+		case s.policy.MissedCheckOutAfter > 0 && hasCheckIn && !hasCheckOut &&
+			!now.Before(end.Add(s.policy.MissedCheckOutAfter)) && now.Before(end.Add(maxShiftLength)):
+			notificationType = domain.NotificationTypeWarning
+			message = fmt.Sprintf("Your shift on %s ended at %s but you have not checked out yet.", schedule.Date.Format("2006-01-02"), end.In(loc).Format("15:04"))
+		default:
+			continue
+		}
+
+		if onLeave(schedule) {
+			continue
+		}
+
+		reminderID := uuid.NewSHA1(attendanceReminderNamespace, []byte(string(notificationType)+"/"+schedule.ID+"/"+schedule.UserID)).String()
+		marked, err := s.repo.MarkReminderSent(ctx, reminderID, schedule.ID, schedule.UserID, notificationType, now)
+		if err != nil {
+			return sent, err
+		}
+		if !marked {
+			continue
+		}
+
+		notif := domain.NewNotification(schedule.UserID, notificationType, message, now)
+		notif.ID = reminderID
+
+		if _, err := s.notificationSvc.CreateNotification(ctx, notif); err != nil {
+			if err != consts.ErrConflictingData {
+				fmt.Printf("failed to send attendance reminder: %v", err)
+				if err := s.repo.UnmarkReminderSent(ctx, reminderID); err != nil {
+					fmt.Printf("failed to release attendance reminder: %v", err)
+				}
+			}
+			continue
+		}
+		sent++
+	}
+
+	return sent, nil
+}
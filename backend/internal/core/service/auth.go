@@ -2,55 +2,180 @@ package service
 
 import (
 	"context"
+	"time"
 
 	"github.com/aldotp/employee-attendance-system/internal/adapter/dto"
+	"github.com/aldotp/employee-attendance-system/internal/core/domain"
 	"github.com/aldotp/employee-attendance-system/internal/core/port"
 	"github.com/aldotp/employee-attendance-system/pkg/consts"
 	"github.com/aldotp/employee-attendance-system/pkg/util"
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
 type AuthService struct {
-	repo         port.UserRepository
-	employeeRepo port.EmployeeRepository
-	ts           port.TokenInterface
-	log          *zap.Logger
+	repo            port.UserRepository
+	employeeRepo    port.EmployeeRepository
+	departmentRepo  port.DepartmentRepository
+	ts              port.TokenInterface
+	cache           port.CacheInterface
+	tokenVersion    port.TokenVersionService
+	mfa             port.MFAService
+	oidc            port.OIDCProvider
+	oidcPolicy      domain.OIDCPolicy
+	loginProtection port.LoginProtectionService
+	log             *zap.Logger
 }
 
-func NewAuthService(repo port.UserRepository, employeeRepo port.EmployeeRepository, ts port.TokenInterface, log *zap.Logger) *AuthService {
+// NewAuthService creates the auth service, oidc is nil when single sign-on is not configured
+func NewAuthService(repo port.UserRepository, employeeRepo port.EmployeeRepository, departmentRepo port.DepartmentRepository, ts port.TokenInterface, cache port.CacheInterface, tokenVersion port.TokenVersionService, mfa port.MFAService, oidc port.OIDCProvider, oidcPolicy domain.OIDCPolicy, loginProtection port.LoginProtectionService, log *zap.Logger) *AuthService {
 	return &AuthService{
 		repo,
 		employeeRepo,
+		departmentRepo,
 		ts,
+		cache,
+		tokenVersion,
+		mfa,
+		oidc,
+		oidcPolicy,
+		loginProtection,
 		log,
 	}
 }
 
-func (as *AuthService) Login(ctx context.Context, email, password string) (dto.LoginResponse, error) {
+func (as *AuthService) Login(ctx context.Context, email, password, clientIP string) (dto.LoginResponse, error) {
+	// locked out email addresses are refused the same way whether or not an account has them
+	if err := as.loginProtection.CheckLogin(ctx, email, clientIP); err != nil {
+		return dto.LoginResponse{}, err
+	}
+
 	user, err := as.repo.GetUserByEmail(ctx, email)
 	if err != nil {
 		as.log.Error("failed to get user by email", zap.Error(err))
 		if err == consts.ErrDataNotFound {
-			return dto.LoginResponse{}, consts.ErrInvalidCredentials
+			return dto.LoginResponse{}, as.loginFailed(ctx, email, clientIP)
 		}
 		return dto.LoginResponse{}, consts.ErrInternal
 	}
 
 	if err = util.ComparePassword(password, user.Password); err != nil {
 		as.log.Error("password comparison failed", zap.Error(err))
-		return dto.LoginResponse{}, consts.ErrInvalidCredentials
+		return dto.LoginResponse{}, as.loginFailed(ctx, email, clientIP)
+	}
+
+	if err := as.loginProtection.RecordLoginSuccess(ctx, email); err != nil {
+		return dto.LoginResponse{}, err
+	}
+
+	if user.EmailVerifiedAt == nil {
+		return dto.LoginResponse{}, consts.ErrEmailNotVerified
+	}
+
+	return as.completeLogin(ctx, user)
+}
+
+// loginFailed records a failed login, returning the lockout it caused or invalid credentials
+func (as *AuthService) loginFailed(ctx context.Context, email, clientIP string) error {
+	if err := as.loginProtection.RecordLoginFailure(ctx, email, clientIP); err != nil {
+		return err
+	}
+
+	return consts.ErrInvalidCredentials
+}
+
+// completeLogin starts the session of a user who proved who they are, unless they must pass a
+// second factor first
+func (as *AuthService) completeLogin(ctx context.Context, user *domain.User) (dto.LoginResponse, error) {
+	if err := as.checkActive(ctx, user.ID); err != nil {
+		return dto.LoginResponse{}, err
+	}
+
+	// users with an authenticator, or whose role requires one, finish logging in with LoginMFA
+	enabled, required, err := as.mfa.MFAStatus(ctx, user.ID, user.Role)
+	if err != nil {
+		return dto.LoginResponse{}, err
+	}
+
+	if enabled || required {
+		mfaToken, err := as.mfa.CreateMFAChallenge(ctx, user.ID)
+		if err != nil {
+			return dto.LoginResponse{}, err
+		}
+
+		return dto.LoginResponse{
+			MFARequired:           true,
+			MFAEnrollmentRequired: !enabled,
+			MFAToken:              mfaToken,
+		}, nil
+	}
+
+	return as.startSession(ctx, user)
+}
+
+// LoginMFA finishes a login challenged for a second factor, with a code from the user's
+// authenticator or a recovery code. Users enrolling while logging in confirm their authenticator
+// with it and receive their recovery codes.
+func (as *AuthService) LoginMFA(ctx context.Context, mfaToken, code string) (dto.LoginResponse, error) {
+	userID, err := as.mfa.ResolveMFAChallenge(ctx, mfaToken)
+	if err != nil {
+		return dto.LoginResponse{}, err
+	}
+
+	user, err := as.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		as.log.Error("failed to get user by ID", zap.Error(err))
+		if err == consts.ErrDataNotFound {
+			return dto.LoginResponse{}, consts.ErrInvalidMFAToken
+		}
+		return dto.LoginResponse{}, consts.ErrInternal
 	}
 
-	accessToken, err := as.ts.GenerateAccessToken(user)
+	recoveryCodes, err := as.mfa.VerifyMFACode(ctx, user.ID, code)
+	if err != nil {
+		return dto.LoginResponse{}, err
+	}
+
+	if err := as.mfa.DeleteMFAChallenge(ctx, mfaToken); err != nil {
+		return dto.LoginResponse{}, err
+	}
+
+	response, err := as.startSession(ctx, user)
+	if err != nil {
+		return dto.LoginResponse{}, err
+	}
+	response.RecoveryCodes = recoveryCodes
+
+	return response, nil
+}
+
+// EnrollMFAChallenge starts enrolling an authenticator for a user whose role requires one, in the
+// middle of their first login
+func (as *AuthService) EnrollMFAChallenge(ctx context.Context, mfaToken string) (*domain.MFAEnrollment, error) {
+	userID, err := as.mfa.ResolveMFAChallenge(ctx, mfaToken)
+	if err != nil {
+		return nil, err
+	}
+
+	return as.mfa.EnrollMFA(ctx, userID)
+}
+
+// startSession issues the access token and the refresh token of a new session
+func (as *AuthService) startSession(ctx context.Context, user *domain.User) (dto.LoginResponse, error) {
+	accessToken, err := as.generateAccessToken(ctx, user)
 	if err != nil {
 		as.log.Error("failed to generate access token", zap.Error(err))
 		return dto.LoginResponse{}, consts.ErrTokenCreation
 	}
 
-	refreshToken, err := as.ts.GenerateRefreshToken(user)
+	now := time.Now()
+	refreshToken, err := as.issueRefreshToken(ctx, user, &domain.RefreshSession{
+		ID:        uuid.New().String(),
+		UserID:    user.ID,
+		CreatedAt: now,
+	}, nil)
 	if err != nil {
-		as.log.Error("failed to generate refresh token", zap.Error(err))
-		return dto.LoginResponse{}, consts.ErrTokenCreation
+		return dto.LoginResponse{}, err
 	}
 
 	return dto.LoginResponse{
@@ -59,27 +184,192 @@ func (as *AuthService) Login(ctx context.Context, email, password string) (dto.L
 	}, nil
 }
 
-func (as *AuthService) RefreshToken(ctx context.Context, refreshToken string) (string, error) {
-	payload, _, err := as.ts.VerifyRefreshToken(ctx, refreshToken)
+// RefreshToken exchanges the current refresh token of a session for a new access token and a new
+// refresh token. Presenting a refresh token the session has already rotated away from revokes the
+// whole session, since either the legitimate client or whoever copied the token is replaying it.
+func (as *AuthService) RefreshToken(ctx context.Context, refreshToken string) (dto.LoginResponse, error) {
+	payload, err := as.ts.VerifyRefreshToken(ctx, refreshToken)
 	if err != nil {
 		as.log.Error("failed to verify refresh token", zap.Error(err))
-		return "", consts.ErrInvalidSignature
+		return dto.LoginResponse{}, consts.ErrInvalidRefreshToken
+	}
+
+	session, stored, err := as.refreshSession(ctx, payload.UserID, payload.SessionID)
+	if err != nil {
+		return dto.LoginResponse{}, err
+	}
+
+	if session.CurrentTokenID != payload.TokenID {
+		return dto.LoginResponse{}, as.revokeReusedSession(ctx, payload.UserID, payload.SessionID)
 	}
 
 	user, err := as.repo.GetUserByID(ctx, payload.UserID)
 	if err != nil {
 		as.log.Error("failed to get user by ID", zap.Error(err))
 		if err == consts.ErrDataNotFound {
-			return "", consts.ErrInvalidToken
+			return dto.LoginResponse{}, consts.ErrInvalidRefreshToken
 		}
-		return "", consts.ErrInternal
+		return dto.LoginResponse{}, consts.ErrInternal
 	}
 
-	accessToken, err := as.ts.GenerateAccessToken(user)
+	if err = as.checkActive(ctx, user.ID); err != nil {
+		return dto.LoginResponse{}, err
+	}
+
+	// sessions started before the role required a second factor must log in again
+	enabled, required, err := as.mfa.MFAStatus(ctx, user.ID, user.Role)
+	if err != nil {
+		return dto.LoginResponse{}, err
+	}
+	if required && !enabled {
+		return dto.LoginResponse{}, consts.ErrMFARequired
+	}
+
+	accessToken, err := as.generateAccessToken(ctx, user)
 	if err != nil {
 		as.log.Error("failed to generate access token", zap.Error(err))
+		return dto.LoginResponse{}, consts.ErrTokenCreation
+	}
+
+	newRefreshToken, err := as.issueRefreshToken(ctx, user, session, stored)
+	if err != nil {
+		return dto.LoginResponse{}, err
+	}
+
+	return dto.LoginResponse{
+		AccessToken:  accessToken,
+		RefreshToken: newRefreshToken,
+	}, nil
+}
+
+// Logout revokes the session of the refresh token, on this device only
+func (as *AuthService) Logout(ctx context.Context, refreshToken string) error {
+	payload, err := as.ts.VerifyRefreshToken(ctx, refreshToken)
+	if err != nil {
+		as.log.Error("failed to verify refresh token", zap.Error(err))
+		return consts.ErrInvalidRefreshToken
+	}
+
+	if err := as.cache.Delete(ctx, refreshSessionKey(payload.UserID, payload.SessionID)); err != nil {
+		as.log.Error("failed to revoke refresh session", zap.Error(err))
+		return consts.ErrInternal
+	}
+
+	return nil
+}
+
+// LogoutAll revokes every session and access token of the user, logging them out of all their
+// devices
+func (as *AuthService) LogoutAll(ctx context.Context, userID string) error {
+	if err := as.tokenVersion.RevokeAllTokens(ctx, userID); err != nil {
+		as.log.Error("failed to revoke refresh sessions", zap.Error(err))
+		return consts.ErrInternal
+	}
+
+	return nil
+}
+
+// checkActive refuses users whose employee record is no longer active. Users without an employee
+// record, such as admins created from the admin API, may sign in.
+func (as *AuthService) checkActive(ctx context.Context, userID string) error {
+	employee, err := as.employeeRepo.GetEmployeeByUserID(ctx, userID)
+	if err != nil {
+		if err == consts.ErrDataNotFound {
+			return nil
+		}
+		as.log.Error("failed to get employee by user ID", zap.Error(err))
+		return consts.ErrInternal
+	}
+
+	if employee.Status != domain.StatusActive {
+		return consts.ErrAccountInactive
+	}
+
+	return nil
+}
+
+// generateAccessToken issues an access token carrying the user's current token version
+func (as *AuthService) generateAccessToken(ctx context.Context, user *domain.User) (string, error) {
+	version, err := as.tokenVersion.TokenVersion(ctx, user.ID)
+	if err != nil {
+		return "", err
+	}
+
+	return as.ts.GenerateAccessToken(user, version)
+}
+
+// issueRefreshToken rotates the session to a new refresh token and stores it until that token
+// expires. An existing session is only rotated while it is still stored as previous, so two
+// refreshes racing with the same token cannot both succeed.
+func (as *AuthService) issueRefreshToken(ctx context.Context, user *domain.User, session *domain.RefreshSession, previous []byte) (string, error) {
+	session.CurrentTokenID = uuid.New().String()
+	session.RotatedAt = time.Now()
+
+	refreshToken, expiresAt, err := as.ts.GenerateRefreshToken(user, session.ID, session.CurrentTokenID)
+	if err != nil {
+		as.log.Error("failed to generate refresh token", zap.Error(err))
 		return "", consts.ErrTokenCreation
 	}
 
-	return accessToken, nil
+	sessionSerialized, err := util.Serialize(session)
+	if err != nil {
+		return "", consts.ErrInternal
+	}
+
+	key := refreshSessionKey(user.ID, session.ID)
+	if previous == nil {
+		if err := as.cache.Set(ctx, key, sessionSerialized, time.Until(expiresAt)); err != nil {
+			as.log.Error("failed to store refresh session", zap.Error(err))
+			return "", consts.ErrInternal
+		}
+		return refreshToken, nil
+	}
+
+	// another refresh with the same token rotated the session first, which is a reuse as well
+	swapped, err := as.cache.CompareAndSwap(ctx, key, previous, sessionSerialized, time.Until(expiresAt))
+	if err != nil {
+		as.log.Error("failed to rotate refresh session", zap.Error(err))
+		return "", consts.ErrInternal
+	}
+	if !swapped {
+		return "", as.revokeReusedSession(ctx, user.ID, session.ID)
+	}
+
+	return refreshToken, nil
+}
+
+// revokeReusedSession ends a session whose refresh token was presented again after rotation
+func (as *AuthService) revokeReusedSession(ctx context.Context, userID, sessionID string) error {
+	as.log.Warn("refresh token reused, revoking session", zap.String("user_id", userID), zap.String("session_id", sessionID))
+	if err := as.cache.Delete(ctx, refreshSessionKey(userID, sessionID)); err != nil {
+		as.log.Error("failed to revoke refresh session", zap.Error(err))
+		return consts.ErrInternal
+	}
+
+	return consts.ErrInvalidRefreshToken
+}
+
+// refreshSession loads a session that has not been revoked or expired, along with its stored
+// form that rotating it must still find in place
+func (as *AuthService) refreshSession(ctx context.Context, userID, sessionID string) (*domain.RefreshSession, []byte, error) {
+	cached, err := as.cache.Get(ctx, refreshSessionKey(userID, sessionID))
+	if err != nil {
+		if err == consts.ErrDataNotFound {
+			return nil, nil, consts.ErrInvalidRefreshToken
+		}
+		as.log.Error("failed to get refresh session", zap.Error(err))
+		return nil, nil, consts.ErrInternal
+	}
+
+	var session domain.RefreshSession
+	if err := util.Deserialize(cached, &session); err != nil {
+		return nil, nil, consts.ErrInternal
+	}
+
+	return &session, cached, nil
+}
+
+// refreshSessionKey keys the sessions by user, so all of a user's sessions share a prefix
+func refreshSessionKey(userID, sessionID string) string {
+	return util.GenerateCacheKey("refresh_session", userID+":"+sessionID)
 }
@@ -0,0 +1,230 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/aldotp/employee-attendance-system/internal/core/domain"
+	"github.com/aldotp/employee-attendance-system/internal/core/port"
+	"github.com/aldotp/employee-attendance-system/pkg/consts"
+	"github.com/aldotp/employee-attendance-system/pkg/util"
+	"go.uber.org/zap"
+)
+
+// rolePermissionsTTL bounds how long a role keeps permissions changed outside UpdateRolePermissions
+const rolePermissionsTTL = 10 * time.Minute
+
+type AuthorizationService struct {
+	repo         port.PermissionRepository
+	employeeRepo port.EmployeeRepository
+	cache        port.CacheInterface
+	log          *zap.Logger
+}
+
+func NewAuthorizationService(repo port.PermissionRepository, employeeRepo port.EmployeeRepository, cache port.CacheInterface, log *zap.Logger) *AuthorizationService {
+	return &AuthorizationService{
+		repo:         repo,
+		employeeRepo: employeeRepo,
+		cache:        cache,
+		log:          log,
+	}
+}
+
+// Scope is the widest scope in which the actor may perform the action, empty when it may not.
+// Users hold the permissions of their role, API keys the permissions they were created with.
+func (s *AuthorizationService) Scope(ctx context.Context, actor *domain.TokenPayload, action domain.PermissionAction) (domain.PermissionScope, error) {
+	if actor == nil {
+		return "", consts.ErrUnauthorized
+	}
+
+	if actor.APIKeyID != "" {
+		return domain.NewPermissionSet(actor.Permissions).Scope(action), nil
+	}
+
+	permissions, err := s.rolePermissions(ctx, actor.Role)
+	if err != nil {
+		return "", err
+	}
+
+	return permissions.Scope(action), nil
+}
+
+// Authorize returns consts.ErrForbidden unless the actor may perform the action on a record of
+// the owner. The team scope covers the owners working in the actor's department.
+func (s *AuthorizationService) Authorize(ctx context.Context, actor *domain.TokenPayload, action domain.PermissionAction, ownerID string) error {
+	scope, err := s.Scope(ctx, actor, action)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case scope == domain.ScopeAny:
+		return nil
+	case scope.Includes(domain.ScopeOwn) && ownerID != "" && ownerID == actor.UserID:
+		return nil
+	case scope == domain.ScopeTeam:
+		sameTeam, err := s.sameDepartment(ctx, actor.UserID, ownerID)
+		if err != nil {
+			return err
+		}
+		if sameTeam {
+			return nil
+		}
+	}
+
+	return consts.ErrForbidden
+}
+
+// OwnershipFilter narrows a listing to the records the actor may perform the action on
+func (s *AuthorizationService) OwnershipFilter(ctx context.Context, actor *domain.TokenPayload, action domain.PermissionAction) (domain.OwnershipFilter, error) {
+	scope, err := s.Scope(ctx, actor, action)
+	if err != nil {
+		return domain.OwnershipFilter{}, err
+	}
+
+	switch scope {
+	case domain.ScopeAny:
+		return domain.OwnershipFilter{}, nil
+	case domain.ScopeTeam:
+		departmentID, err := s.departmentOf(ctx, actor.UserID)
+		if err != nil {
+			return domain.OwnershipFilter{}, err
+		}
+		// without a department the team is the actor alone
+		if departmentID != "" {
+			return domain.OwnershipFilter{DepartmentID: departmentID}, nil
+		}
+		return domain.OwnershipFilter{UserID: actor.UserID}, nil
+	case domain.ScopeOwn:
+		return domain.OwnershipFilter{UserID: actor.UserID}, nil
+	default:
+		return domain.OwnershipFilter{}, consts.ErrForbidden
+	}
+}
+
+// ListRolePermissions returns the permissions of every role, including roles without any
+func (s *AuthorizationService) ListRolePermissions(ctx context.Context) ([]domain.RolePermissions, error) {
+	granted, err := s.repo.ListRolePermissions(ctx)
+	if err != nil {
+		s.log.Error("failed to list role permissions", zap.Error(err))
+		return nil, consts.ErrInternal
+	}
+
+	roles := make([]domain.RolePermissions, 0, len(domain.ExistRoleMap))
+	for _, role := range []domain.UserRole{domain.Admin, domain.HR, domain.Manager, domain.Employees} {
+		rolePermissions := domain.RolePermissions{Role: role, Permissions: []domain.Permission{}}
+		for _, g := range granted {
+			if g.Role == role {
+				rolePermissions.Permissions = g.Permissions
+			}
+		}
+		roles = append(roles, rolePermissions)
+	}
+
+	return roles, nil
+}
+
+// UpdateRolePermissions grants the role exactly the given permissions. The admin role cannot give
+// up managing permissions, otherwise nobody could grant them back.
+func (s *AuthorizationService) UpdateRolePermissions(ctx context.Context, role domain.UserRole, permissions []domain.Permission) (*domain.RolePermissions, error) {
+	if _, ok := domain.ExistRoleMap[role]; !ok {
+		return nil, consts.ErrUnknownRole
+	}
+
+	unique := make([]domain.Permission, 0, len(permissions))
+	for _, permission := range permissions {
+		if !permission.IsValid() {
+			return nil, consts.ErrUnknownPermission
+		}
+		if !slices.Contains(unique, permission) {
+			unique = append(unique, permission)
+		}
+	}
+	slices.Sort(unique)
+
+	if role == domain.Admin && !domain.NewPermissionSet(unique).Has(domain.ActionPermissionsManage.In(domain.ScopeAny)) {
+		return nil, consts.ErrPermissionLockout
+	}
+
+	if err := s.repo.ReplaceRolePermissions(ctx, role, unique); err != nil {
+		s.log.Error("failed to replace role permissions", zap.Error(err))
+		return nil, consts.ErrInternal
+	}
+
+	if err := s.cache.Delete(ctx, rolePermissionsKey(role)); err != nil {
+		s.log.Error("failed to delete cached role permissions", zap.Error(err))
+		return nil, consts.ErrInternal
+	}
+
+	s.log.Info("role permissions updated", zap.String("role", string(role)), zap.Int("permissions", len(unique)))
+
+	return &domain.RolePermissions{Role: role, Permissions: unique}, nil
+}
+
+// rolePermissions loads the permissions of the role, from the cache when it has them
+func (s *AuthorizationService) rolePermissions(ctx context.Context, role domain.UserRole) (domain.PermissionSet, error) {
+	key := rolePermissionsKey(role)
+
+	var permissions []domain.Permission
+	cached, err := s.cache.Get(ctx, key)
+	if err == nil {
+		if err := util.Deserialize(cached, &permissions); err == nil {
+			return domain.NewPermissionSet(permissions), nil
+		}
+	} else if err != consts.ErrDataNotFound {
+		s.log.Error("failed to get cached role permissions", zap.Error(err))
+	}
+
+	permissions, err = s.repo.ListPermissionsByRole(ctx, role)
+	if err != nil {
+		s.log.Error("failed to list role permissions", zap.Error(err))
+		return nil, consts.ErrInternal
+	}
+
+	serialized, err := util.Serialize(permissions)
+	if err == nil {
+		if err := s.cache.Set(ctx, key, serialized, rolePermissionsTTL); err != nil {
+			s.log.Error("failed to cache role permissions", zap.Error(err))
+		}
+	}
+
+	return domain.NewPermissionSet(permissions), nil
+}
+
+// sameDepartment reports whether both users are employees of the same department
+func (s *AuthorizationService) sameDepartment(ctx context.Context, userID, otherUserID string) (bool, error) {
+	if otherUserID == "" {
+		return false, nil
+	}
+
+	departmentID, err := s.departmentOf(ctx, userID)
+	if err != nil || departmentID == "" {
+		return false, err
+	}
+
+	otherDepartmentID, err := s.departmentOf(ctx, otherUserID)
+	if err != nil {
+		return false, err
+	}
+
+	return departmentID == otherDepartmentID, nil
+}
+
+// departmentOf is the department of the user's employee record, empty when they have none
+func (s *AuthorizationService) departmentOf(ctx context.Context, userID string) (string, error) {
+	employee, err := s.employeeRepo.GetEmployeeByUserID(ctx, userID)
+	if err != nil {
+		if err == consts.ErrDataNotFound {
+			return "", nil
+		}
+		s.log.Error("failed to get employee by user ID", zap.Error(err))
+		return "", consts.ErrInternal
+	}
+
+	return employee.DepartmentID, nil
+}
+
+func rolePermissionsKey(role domain.UserRole) string {
+	return util.GenerateCacheKey("role_permissions", string(role))
+}
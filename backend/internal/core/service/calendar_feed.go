@@ -0,0 +1,224 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aldotp/employee-attendance-system/internal/core/domain"
+	"github.com/aldotp/employee-attendance-system/internal/core/port"
+	"github.com/google/uuid"
+)
+
+type CalendarFeedService struct {
+	repo             port.CalendarFeedRepository
+	scheduleRepo     port.ScheduleRepository
+	leaveRepo        port.LeaveRequestRepository
+	employeeRepo     port.EmployeeRepository
+	workLocationRepo port.WorkLocationRepository
+	policy           domain.CalendarFeedPolicy
+}
+
+func NewCalendarFeedService(repo port.CalendarFeedRepository, scheduleRepo port.ScheduleRepository, leaveRepo port.LeaveRequestRepository, employeeRepo port.EmployeeRepository, workLocationRepo port.WorkLocationRepository, policy domain.CalendarFeedPolicy) *CalendarFeedService {
+	return &CalendarFeedService{
+		repo:             repo,
+		scheduleRepo:     scheduleRepo,
+		leaveRepo:        leaveRepo,
+		employeeRepo:     employeeRepo,
+		workLocationRepo: workLocationRepo,
+		policy:           policy,
+	}
+}
+
+// CreateCalendarFeed issues a new feed token for the user. Any previous token stops working,
+// so calling it again rotates a leaked subscription URL.
+func (s *CalendarFeedService) CreateCalendarFeed(ctx context.Context, userID string) (*domain.CalendarFeed, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	token := hex.EncodeToString(secret)
+
+	now := time.Now()
+	err := s.repo.SaveCalendarFeedToken(ctx, &domain.CalendarFeedToken{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		TokenHash: hashToken(token),
+		CreatedAt: now,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.CalendarFeed{
+		Token:     token,
+		URL:       fmt.Sprintf("%s/api/v1/calendar/%s.ics", strings.TrimRight(s.policy.BaseURL, "/"), token),
+		CreatedAt: now,
+	}, nil
+}
+
+func (s *CalendarFeedService) RevokeCalendarFeed(ctx context.Context, userID string) error {
+	return s.repo.DeleteCalendarFeedToken(ctx, userID)
+}
+
+// RenderCalendarFeed renders the schedules and approved leave of the token's owner as an
+// iCalendar document. Shifts are computed in the employee's timezone and written in UTC, which
+// every calendar client resolves without needing a VTIMEZONE definition.
+func (s *CalendarFeedService) RenderCalendarFeed(ctx context.Context, token string) ([]byte, error) {
+	feedToken, err := s.repo.GetCalendarFeedToken(ctx, hashToken(token))
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	go s.repo.TouchCalendarFeedToken(context.WithoutCancel(ctx), feedToken.ID, now)
+
+	loc := employeeLocation(ctx, s.employeeRepo, feedToken.UserID)
+	local := now.In(loc)
+	today := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+	startDate := today.AddDate(0, 0, -s.policy.PastDays)
+	endDate := today.AddDate(0, 0, s.policy.FutureDays)
+
+	schedules, err := s.scheduleRepo.ListSchedulesByUsers(ctx, []string{feedToken.UserID}, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	leaves, err := s.leaveRepo.ListUserLeaves(ctx, []string{feedToken.UserID}, startDate, endDate, []domain.LeaveStatus{domain.Approved})
+	if err != nil {
+		return nil, err
+	}
+
+	var locationIDs []string
+	for _, schedule := range schedules {
+		if schedule.WorkLocationID != "" {
+			locationIDs = append(locationIDs, schedule.WorkLocationID)
+		}
+	}
+
+	locations := map[string]string{}
+	if len(locationIDs) > 0 {
+		locations, err = s.workLocationRepo.ListWorkLocationNames(ctx, locationIDs)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	stamp := now.UTC().Format(icsTimeLayout)
+
+	var ics icsWriter
+	ics.line("BEGIN", "VCALENDAR")
+	ics.line("VERSION", "2.0")
+	ics.line("PRODID", "-//Employee Attendance System//Work Schedule//EN")
+	ics.line("CALSCALE", "GREGORIAN")
+	ics.line("METHOD", "PUBLISH")
+	ics.line("X-WR-CALNAME", "Work schedule")
+	ics.line("X-WR-TIMEZONE", loc.String())
+
+	for _, schedule := range schedules {
+		start, end, err := schedule.Window(loc)
+		if err != nil {
+			continue
+		}
+
+		ics.line("BEGIN", "VEVENT")
+		ics.line("UID", schedule.ID+"@schedule")
+		ics.line("DTSTAMP", stamp)
+		ics.line("LAST-MODIFIED", schedule.UpdatedAt.UTC().Format(icsTimeLayout))
+		ics.line("DTSTART", start.UTC().Format(icsTimeLayout))
+		ics.line("DTEND", end.UTC().Format(icsTimeLayout))
+		ics.line("SUMMARY", icsText(scheduleSummary(schedule)))
+		if name, ok := locations[schedule.WorkLocationID]; ok {
+			ics.line("LOCATION", icsText(name))
+		}
+		if description := scheduleDescription(schedule); description != "" {
+			ics.line("DESCRIPTION", icsText(description))
+		}
+		ics.line("END", "VEVENT")
+	}
+
+	for _, leave := range leaves {
+		ics.line("BEGIN", "VEVENT")
+		ics.line("UID", leave.ID+"@leave")
+		ics.line("DTSTAMP", stamp)
+		ics.line("DTSTART;VALUE=DATE", leave.StartDate.Format(icsDateLayout))
+		// the end of an all-day event is exclusive
+		ics.line("DTEND;VALUE=DATE", leave.EndDate.AddDate(0, 0, 1).Format(icsDateLayout))
+		ics.line("SUMMARY", icsText(fmt.Sprintf("Leave (%s)", leave.Type)))
+		ics.line("TRANSP", "OPAQUE")
+		ics.line("X-MICROSOFT-CDO-BUSYSTATUS", "OOF")
+		ics.line("END", "VEVENT")
+	}
+
+	ics.line("END", "VCALENDAR")
+
+	return ics.Bytes(), nil
+}
+
+// hashToken is the SHA-256 digest secret tokens are stored and looked up by
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func scheduleSummary(schedule domain.Schedule) string {
+	if schedule.IsFlexible() {
+		return fmt.Sprintf("Flexible shift %s-%s", schedule.ShiftStart, schedule.ShiftEnd)
+	}
+
+	return fmt.Sprintf("Shift %s-%s", schedule.ShiftStart, schedule.ShiftEnd)
+}
+
+func scheduleDescription(schedule domain.Schedule) string {
+	var details []string
+	if schedule.BreakStart != "" && schedule.BreakEnd != "" {
+		details = append(details, fmt.Sprintf("Break %s-%s", schedule.BreakStart, schedule.BreakEnd))
+	}
+	if schedule.CoreStart != "" && schedule.CoreEnd != "" {
+		details = append(details, fmt.Sprintf("Core hours %s-%s", schedule.CoreStart, schedule.CoreEnd))
+	}
+	if schedule.MinDurationMinutes > 0 {
+		details = append(details, fmt.Sprintf("Minimum %d minutes", schedule.MinDurationMinutes))
+	}
+
+	return strings.Join(details, "\n")
+}
+
+const (
+	icsTimeLayout = "20060102T150405Z"
+	icsDateLayout = "20060102"
+	// icsLineLength is the maximum length of a content line in octets before it must be folded
+	icsLineLength = 75
+)
+
+// icsWriter writes iCalendar content lines, terminated by CRLF and folded at 75 octets
+type icsWriter struct {
+	bytes.Buffer
+}
+
+func (w *icsWriter) line(name, value string) {
+	line := name + ":" + value
+	limit := icsLineLength
+	for len(line) > limit {
+		// never split a multi-byte UTF-8 sequence
+		cut := limit
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		w.WriteString(line[:cut] + "\r\n ")
+		line = line[cut:]
+		// continuation lines start with a space that counts towards their length
+		limit = icsLineLength - 1
+	}
+	w.WriteString(line + "\r\n")
+}
+
+// icsText escapes a TEXT property value
+func icsText(value string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(value)
+}
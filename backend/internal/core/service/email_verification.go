@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aldotp/employee-attendance-system/internal/core/domain"
+	"github.com/aldotp/employee-attendance-system/internal/core/port"
+	"github.com/aldotp/employee-attendance-system/pkg/consts"
+	"github.com/aldotp/employee-attendance-system/pkg/email"
+	"github.com/aldotp/employee-attendance-system/pkg/util"
+	"go.uber.org/zap"
+)
+
+type EmailVerificationService struct {
+	userRepo port.UserRepository
+	ts       port.TokenInterface
+	cache    port.CacheInterface
+	sender   *email.Email
+	policy   domain.EmailVerificationPolicy
+	log      *zap.Logger
+}
+
+func NewEmailVerificationService(userRepo port.UserRepository, ts port.TokenInterface, cache port.CacheInterface, sender *email.Email, policy domain.EmailVerificationPolicy, log *zap.Logger) *EmailVerificationService {
+	return &EmailVerificationService{
+		userRepo: userRepo,
+		ts:       ts,
+		cache:    cache,
+		sender:   sender,
+		policy:   policy,
+		log:      log,
+	}
+}
+
+type emailVerificationPayload struct {
+	AppName   string
+	Subject   string
+	VerifyURL string
+	ExpiresIn string
+}
+
+// SendVerificationEmail emails the user a link verifying their address. When verification is
+// disabled the address is verified right away instead.
+func (s *EmailVerificationService) SendVerificationEmail(ctx context.Context, user *domain.User) error {
+	if s.policy.Disabled {
+		return s.markVerified(ctx, user.ID)
+	}
+
+	if s.sender == nil {
+		return consts.ErrEmailNotConfigured
+	}
+
+	token, err := s.ts.GenerateActivationToken(user)
+	if err != nil {
+		return err
+	}
+
+	verifyURL, err := tokenLink(s.policy.URL, token)
+	if err != nil {
+		return err
+	}
+
+	subject := fmt.Sprintf("[%s] Verify your email address", s.policy.AppName)
+	return s.sender.SendEmail(emailVerificationPayload{
+		AppName:   s.policy.AppName,
+		Subject:   subject,
+		VerifyURL: verifyURL,
+		ExpiresIn: strconv.Itoa(int(s.policy.TokenTTL.Minutes())),
+	}, s.policy.EmailTemplate, []string{user.Email}, subject)
+}
+
+// VerifyEmail verifies the address a verification token was sent to. Verifying an address twice
+// succeeds, while tokens sent to an address the user no longer has are refused.
+func (s *EmailVerificationService) VerifyEmail(ctx context.Context, token string) error {
+	payload, err := s.ts.VerifyActivationToken(token)
+	if err != nil {
+		return consts.ErrInvalidActivationToken
+	}
+
+	user, err := s.userRepo.GetUserByID(ctx, payload.UserID)
+	if err != nil {
+		if err == consts.ErrDataNotFound {
+			return consts.ErrInvalidActivationToken
+		}
+		s.log.Error("failed to get user by ID", zap.Error(err))
+		return consts.ErrInternal
+	}
+
+	if !strings.EqualFold(user.Email, payload.Email) {
+		return consts.ErrInvalidActivationToken
+	}
+
+	if user.EmailVerifiedAt != nil {
+		return nil
+	}
+
+	if err := s.markVerified(ctx, user.ID); err != nil {
+		s.log.Error("failed to verify email", zap.Error(err))
+		return consts.ErrInternal
+	}
+
+	return nil
+}
+
+// ResendVerificationEmail sends a new verification link when the address belongs to a user who
+// has not verified it yet. Like a password reset request, the outcome does not reveal whether the
+// address is registered, only the rate limits are reported.
+func (s *EmailVerificationService) ResendVerificationEmail(ctx context.Context, address, clientIP string) error {
+	address = strings.TrimSpace(address)
+
+	if err := s.checkRateLimit(ctx, "ip:"+clientIP, s.policy.IPLimit); err != nil {
+		return err
+	}
+	if err := s.checkRateLimit(ctx, "email:"+strings.ToLower(address), s.policy.EmailLimit); err != nil {
+		return err
+	}
+
+	go func(ctx context.Context) {
+		user, err := s.userRepo.GetUserByEmail(ctx, address)
+		if err != nil {
+			if err != consts.ErrDataNotFound {
+				s.log.Error("failed to get user by email", zap.Error(err))
+			}
+			return
+		}
+
+		if user.EmailVerifiedAt != nil {
+			return
+		}
+
+		if err := s.SendVerificationEmail(ctx, user); err != nil {
+			s.log.Error("failed to send verification email", zap.Error(err))
+		}
+	}(context.WithoutCancel(ctx))
+
+	return nil
+}
+
+func (s *EmailVerificationService) markVerified(ctx context.Context, userID string) error {
+	now := time.Now()
+	if _, err := s.userRepo.UpdateUser(ctx, &domain.User{ID: userID, EmailVerifiedAt: &now}); err != nil {
+		return err
+	}
+
+	// the cached profile still shows the address unverified
+	return s.cache.Delete(ctx, util.GenerateCacheKey("user", userID))
+}
+
+func (s *EmailVerificationService) checkRateLimit(ctx context.Context, key string, limit int) error {
+	allowed, err := allowRequest(ctx, s.cache, util.GenerateCacheKey("email_verification", key), limit, s.policy.RateWindow)
+	if err != nil {
+		s.log.Error("failed to check email verification rate limit", zap.Error(err))
+		return consts.ErrInternal
+	}
+	if !allowed {
+		return consts.ErrTooManyRequests
+	}
+
+	return nil
+}
@@ -3,25 +3,44 @@ package service
 import (
 	"context"
 	"fmt"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/aldotp/employee-attendance-system/internal/adapter/dto"
 	"github.com/aldotp/employee-attendance-system/internal/core/domain"
 	"github.com/aldotp/employee-attendance-system/internal/core/port"
 	"github.com/aldotp/employee-attendance-system/pkg/consts"
+	"github.com/aldotp/employee-attendance-system/pkg/minio"
 	"github.com/aldotp/employee-attendance-system/pkg/util"
 	"github.com/google/uuid"
 )
 
 type LeaveService struct {
-	repo            port.LeaveRequestRepository
-	notificationSvc port.NotificationService
+	repo             port.LeaveRequestRepository
+	attachmentRepo   port.LeaveAttachmentRepository
+	employeeRepo     port.EmployeeRepository
+	scheduleRepo     port.ScheduleRepository
+	notificationSvc  port.NotificationService
+	authz            port.AuthorizationService
+	storage          minio.StorageInterface
+	attachmentPolicy domain.LeaveAttachmentPolicy
+	coveragePolicy   domain.LeaveCoveragePolicy
 }
 
-func NewLeaveService(repo port.LeaveRequestRepository, notificationService port.NotificationService) *LeaveService {
+func NewLeaveService(repo port.LeaveRequestRepository, attachmentRepo port.LeaveAttachmentRepository, employeeRepo port.EmployeeRepository, scheduleRepo port.ScheduleRepository, notificationService port.NotificationService, authz port.AuthorizationService, storage minio.StorageInterface, attachmentPolicy domain.LeaveAttachmentPolicy, coveragePolicy domain.LeaveCoveragePolicy) *LeaveService {
 	return &LeaveService{
-		repo:            repo,
-		notificationSvc: notificationService,
+		repo:             repo,
+		attachmentRepo:   attachmentRepo,
+		employeeRepo:     employeeRepo,
+		scheduleRepo:     scheduleRepo,
+		notificationSvc:  notificationService,
+		authz:            authz,
+		storage:          storage,
+		attachmentPolicy: attachmentPolicy,
+		coveragePolicy:   coveragePolicy,
 	}
 }
 
@@ -29,16 +48,16 @@ func (s *LeaveService) SubmitLeaveRequest(ctx context.Context, req dto.LeaveRequ
 
 	startDate, err := time.Parse("2006-01-02", req.StartDate)
 	if err != nil {
-		return dto.LeaveResponse{}, fmt.Errorf("invalid start date format")
+		return dto.LeaveResponse{}, consts.InvalidInput("invalid start date format")
 	}
 
 	endDate, err := time.Parse("2006-01-02", req.EndDate)
 	if err != nil {
-		return dto.LeaveResponse{}, fmt.Errorf("invalid end date format")
+		return dto.LeaveResponse{}, consts.InvalidInput("invalid end date format")
 	}
 
 	if startDate.After(endDate) {
-		return dto.LeaveResponse{}, fmt.Errorf("start date must be before end date")
+		return dto.LeaveResponse{}, consts.InvalidInput("start date must be before end date")
 	}
 
 	leave := &domain.LeaveRequest{
@@ -52,26 +71,47 @@ func (s *LeaveService) SubmitLeaveRequest(ctx context.Context, req dto.LeaveRequ
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
+
+	if s.attachmentPolicy.RequiresAttachment(leave) && len(req.Attachments) == 0 {
+		return dto.LeaveResponse{}, consts.ErrLeaveAttachmentRequired
+	}
+
+	contentTypes, err := s.validateAttachments(req.Attachments)
+	if err != nil {
+		return dto.LeaveResponse{}, err
+	}
+
 	created, err := s.repo.CreateLeaveRequest(ctx, leave)
 	if err != nil {
 		return dto.LeaveResponse{}, err
 	}
 
-	go s.notificationSvc.CreateNotification(ctx, &domain.Notification{
-		ID:        uuid.New().String(),
-		UserID:    leave.UserID,
-		Type:      domain.NotificationTypeInfo,
-		Message:   fmt.Sprintf("Your leave request for %s has been submitted.", leave.Type),
-		CreatedAt: time.Now(),
-	})
+	attachments, err := s.storeAttachments(ctx, created.ID, req.UserID, req.Attachments, contentTypes)
+	if err != nil {
+		if delErr := s.repo.DeleteLeaveRequest(ctx, created.ID); delErr != nil {
+			fmt.Printf("failed to roll back leave request %s: %v", created.ID, delErr)
+		}
+		return dto.LeaveResponse{}, err
+	}
+
+	coverage, err := s.computeCoverage(ctx, created)
+	if err != nil {
+		fmt.Printf("failed to compute coverage for leave request %s: %v", created.ID, err)
+	}
+
+	if _, err := s.notificationSvc.CreateNotification(ctx, domain.NewNotification(leave.UserID, domain.NotificationTypeInfo, fmt.Sprintf("Your leave request for %s has been submitted.", leave.Type), time.Now())); err != nil {
+		fmt.Printf("failed to send notification: %v", err)
+	}
 
 	return dto.LeaveResponse{
-		LeaveID:   created.ID,
-		Type:      string(created.Type),
-		StartDate: created.StartDate,
-		EndDate:   created.EndDate,
-		Status:    string(created.Status),
-		Reason:    created.Reason,
+		LeaveID:          created.ID,
+		Type:             string(created.Type),
+		StartDate:        created.StartDate,
+		EndDate:          created.EndDate,
+		Status:           string(created.Status),
+		Reason:           created.Reason,
+		Attachments:      attachments,
+		CoverageWarnings: belowMinimumCoverage(coverage),
 	}, nil
 }
 
@@ -105,13 +145,9 @@ func (s *LeaveService) ReviewLeaveSubmission(ctx context.Context, leaveID, appro
 		}
 
 	}
-	go s.notificationSvc.CreateNotification(ctx, &domain.Notification{
-		ID:        uuid.New().String(),
-		UserID:    leave.UserID,
-		Type:      domain.NotificationTypeInfo,
-		Message:   fmt.Sprintf("Your leave request for %s has been %s.", leave.Type, leave.Status),
-		CreatedAt: time.Now(),
-	})
+	if err := s.SendLeaveNotification(ctx, leave.UserID, string(leave.Type), string(leave.Status)); err != nil {
+		fmt.Printf("failed to send notification: %v", err)
+	}
 
 	return nil
 
@@ -134,37 +170,35 @@ func (s *LeaveService) UpdateAttendanceForLeave(ctx context.Context, leaveID str
 }
 
 func (s *LeaveService) SendLeaveNotification(ctx context.Context, userID string, leaveType string, status string) error {
-	go s.notificationSvc.CreateNotification(ctx, &domain.Notification{
-		ID:        uuid.New().String(),
-		UserID:    userID,
-		Type:      domain.NotificationTypeInfo,
-		SendAt:    time.Now(),
-		Message:   fmt.Sprintf("Your leave request for %s has been %s.", leaveType, status),
-		CreatedAt: time.Now(),
-	})
-
-	return nil
+	_, err := s.notificationSvc.CreateNotification(ctx, domain.NewNotification(userID, domain.NotificationTypeInfo, fmt.Sprintf("Your leave request for %s has been %s.", leaveType, status), time.Now()))
+	return err
 }
 
 // CRUD untuk handler
-func (s *LeaveService) ListLeaves(ctx context.Context) ([]domain.LeaveRequest, error) {
-	return s.repo.ListLeaveRequests(ctx, 0, 100)
+// ListLeaves lists the leave requests the actor may read
+func (s *LeaveService) ListLeaves(ctx context.Context, actor *domain.TokenPayload) ([]domain.LeaveRequest, error) {
+	filter, err := s.authz.OwnershipFilter(ctx, actor, domain.ActionLeaveRead)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.ListLeaveRequests(ctx, 0, 100, filter)
 }
 
 func (s *LeaveService) CreateLeave(ctx context.Context, req dto.LeaveRequest) (*domain.LeaveRequest, error) {
 
 	startDate, err := time.Parse("2006-01-02", req.StartDate)
 	if err != nil {
-		return nil, fmt.Errorf("invalid start date format")
+		return nil, consts.InvalidInput("invalid start date format")
 	}
 
 	endDate, err := time.Parse("2006-01-02", req.EndDate)
 	if err != nil {
-		return nil, fmt.Errorf("invalid end date format")
+		return nil, consts.InvalidInput("invalid end date format")
 	}
 
 	if startDate.After(endDate) {
-		return nil, fmt.Errorf("start date must be before end date")
+		return nil, consts.InvalidInput("start date must be before end date")
 	}
 
 	leave := &domain.LeaveRequest{
@@ -181,15 +215,21 @@ func (s *LeaveService) CreateLeave(ctx context.Context, req dto.LeaveRequest) (*
 	return s.repo.CreateLeaveRequest(ctx, leave)
 }
 
-func (s *LeaveService) GetLeaveByID(ctx context.Context, id string) (*domain.LeaveRequest, error) {
-	return s.repo.GetLeaveRequestByID(ctx, id)
+func (s *LeaveService) GetLeaveByID(ctx context.Context, actor *domain.TokenPayload, id string) (*domain.LeaveRequest, error) {
+	return s.authorizedLeave(ctx, actor, domain.ActionLeaveRead, id)
 }
 
-func (s *LeaveService) UpdateLeave(ctx context.Context, id string, req dto.LeaveRequest) (*domain.LeaveRequest, error) {
-	leave, err := s.repo.GetLeaveRequestByID(ctx, id)
+func (s *LeaveService) UpdateLeave(ctx context.Context, actor *domain.TokenPayload, id string, req dto.LeaveRequest) (*domain.LeaveRequest, error) {
+	leave, err := s.authorizedLeave(ctx, actor, domain.ActionLeaveWrite, id)
 	if err != nil {
 		return nil, err
 	}
+
+	// reviewed requests keep the dates and type they were approved or rejected for
+	if leave.Status != "pending" {
+		return nil, consts.InvalidInput("leave request is not in pending status")
+	}
+
 	if req.Type != "" {
 		leave.Type = domain.LeaveType(req.Type)
 	}
@@ -197,7 +237,7 @@ func (s *LeaveService) UpdateLeave(ctx context.Context, id string, req dto.Leave
 	if req.StartDate != "" {
 		startDate, err := time.Parse("2006-01-02", req.StartDate)
 		if err != nil {
-			return nil, fmt.Errorf("invalid start date format")
+			return nil, consts.InvalidInput("invalid start date format")
 		}
 
 		leave.StartDate = startDate
@@ -206,7 +246,7 @@ func (s *LeaveService) UpdateLeave(ctx context.Context, id string, req dto.Leave
 	if req.EndDate != "" {
 		endDate, err := time.Parse("2006-01-02", req.EndDate)
 		if err != nil {
-			return nil, fmt.Errorf("invalid end date format")
+			return nil, consts.InvalidInput("invalid end date format")
 		}
 		leave.EndDate = endDate
 	}
@@ -218,34 +258,89 @@ func (s *LeaveService) UpdateLeave(ctx context.Context, id string, req dto.Leave
 	return s.repo.UpdateLeaveRequest(ctx, leave)
 }
 
-func (s *LeaveService) DeleteLeave(ctx context.Context, id string) error {
+func (s *LeaveService) DeleteLeave(ctx context.Context, actor *domain.TokenPayload, id string) error {
+	if _, err := s.authorizedLeave(ctx, actor, domain.ActionLeaveWrite, id); err != nil {
+		return err
+	}
+
 	return s.repo.DeleteLeaveRequest(ctx, id)
 }
-func (s *LeaveService) ApproveLeave(ctx context.Context, leaveID string) error {
+
+// authorizedLeave loads a leave request the actor may perform the action on
+func (s *LeaveService) authorizedLeave(ctx context.Context, actor *domain.TokenPayload, action domain.PermissionAction, id string) (*domain.LeaveRequest, error) {
+	leave, err := s.repo.GetLeaveRequestByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.authz.Authorize(ctx, actor, action, leave.UserID); err != nil {
+		return nil, err
+	}
+
+	return leave, nil
+}
+
+// authorizeReview checks that the actor may approve the leave request, which is never their own
+func (s *LeaveService) authorizeReview(ctx context.Context, actor *domain.TokenPayload, leave *domain.LeaveRequest) error {
+	if actor != nil && leave.UserID == actor.UserID {
+		return consts.ErrForbidden
+	}
+
+	return s.authz.Authorize(ctx, actor, domain.ActionLeaveApprove, leave.UserID)
+}
+
+// ApproveLeave approves a pending leave request and returns the days on which the
+// department drops below its minimum coverage as a result
+func (s *LeaveService) ApproveLeave(ctx context.Context, leaveID string) ([]domain.LeaveCoverage, error) {
 	userSession := util.GetAuthPayload(ctx, consts.AuthorizationKey)
 	if userSession == nil {
-		return fmt.Errorf("user session not found")
+		return nil, fmt.Errorf("user session not found")
 	}
 
 	leave, err := s.repo.GetLeaveRequestByID(ctx, leaveID)
 	if err != nil {
-		return fmt.Errorf("failed to get leave request: %w", err)
+		return nil, fmt.Errorf("failed to get leave request: %w", err)
+	}
+
+	if err := s.authorizeReview(ctx, userSession, leave); err != nil {
+		return nil, err
 	}
 
 	if leave.Status != "pending" {
-		return fmt.Errorf("leave request is not in pending status")
+		return nil, consts.InvalidInput("leave request is not in pending status")
+	}
+
+	if s.attachmentPolicy.RequiresAttachment(leave) {
+		attachments, err := s.attachmentRepo.ListLeaveAttachments(ctx, leaveID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get leave attachments: %w", err)
+		}
+
+		if len(attachments) == 0 {
+			return nil, consts.ErrLeaveAttachmentRequired
+		}
+	}
+
+	coverage, err := s.computeCoverage(ctx, leave)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute coverage: %w", err)
+	}
+
+	warnings := belowMinimumCoverage(coverage)
+	if len(warnings) > 0 && s.coveragePolicy.BlockApproval {
+		return warnings, consts.ErrInsufficientCoverage
 	}
 
 	err = s.repo.ApproveLeaveRequest(ctx, leaveID, userSession.UserID)
 	if err != nil {
-		return fmt.Errorf("failed to approve leave request: %w", err)
+		return nil, fmt.Errorf("failed to approve leave request: %w", err)
 	}
 
 	if err := s.SendLeaveNotification(ctx, leave.UserID, string(leave.Type), "approve"); err != nil {
 		fmt.Printf("failed to send notification: %v", err)
 	}
 
-	return nil
+	return warnings, nil
 }
 
 func (s *LeaveService) RejectLeave(ctx context.Context, leaveID string, reason string) error {
@@ -259,8 +354,12 @@ func (s *LeaveService) RejectLeave(ctx context.Context, leaveID string, reason s
 		return fmt.Errorf("failed to get leave request: %w", err)
 	}
 
+	if err := s.authorizeReview(ctx, userSession, leave); err != nil {
+		return err
+	}
+
 	if leave.Status != "pending" {
-		return fmt.Errorf("leave request is not in pending status")
+		return consts.InvalidInput("leave request is not in pending status")
 	}
 
 	err = s.repo.RejectLeaveRequest(ctx, leaveID, userSession.UserID, reason)
@@ -281,7 +380,7 @@ func (s *LeaveService) GetLeaveBalance(ctx context.Context, leaveType string) (f
 		return 0, fmt.Errorf("user session not found")
 	}
 
-	leaves, err := s.repo.ListLeaveRequests(ctx, 0, 100)
+	leaves, err := s.repo.ListLeaveRequests(ctx, 0, 100, domain.OwnershipFilter{UserID: userSession.UserID})
 	if err != nil {
 		return 0, fmt.Errorf("failed to get leave requests: %w", err)
 	}
@@ -309,3 +408,383 @@ func (s *LeaveService) GetLeaveBalance(ctx context.Context, leaveType string) (f
 
 	return remainingBalance, nil
 }
+
+// AddLeaveAttachments uploads supporting documents for a pending leave request owned by userID
+func (s *LeaveService) AddLeaveAttachments(ctx context.Context, leaveID string, userID string, files []*multipart.FileHeader) ([]domain.LeaveAttachment, error) {
+	if len(files) == 0 {
+		return nil, consts.InvalidInput("at least one attachment is required")
+	}
+
+	leave, err := s.repo.GetLeaveRequestByID(ctx, leaveID)
+	if err != nil {
+		return nil, err
+	}
+
+	if leave.UserID != userID {
+		return nil, consts.ErrForbidden
+	}
+
+	if leave.Status != "pending" {
+		return nil, consts.InvalidInput("leave request is not in pending status")
+	}
+
+	contentTypes, err := s.validateAttachments(files)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.storeAttachments(ctx, leaveID, userID, files, contentTypes)
+}
+
+// ListLeaveAttachments returns the documents of a leave request with time-limited download URLs
+func (s *LeaveService) ListLeaveAttachments(ctx context.Context, actor *domain.TokenPayload, leaveID string) ([]dto.LeaveAttachmentResponse, error) {
+	if _, err := s.authorizedLeave(ctx, actor, domain.ActionLeaveRead, leaveID); err != nil {
+		return nil, err
+	}
+
+	attachments, err := s.attachmentRepo.ListLeaveAttachments(ctx, leaveID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get leave attachments: %w", err)
+	}
+
+	expiresAt := time.Now().Add(s.attachmentPolicy.DownloadURLExpiry)
+	responses := make([]dto.LeaveAttachmentResponse, 0, len(attachments))
+	for _, attachment := range attachments {
+		url, err := s.storage.GeneratePresignedDownloadUrl(attachment.ObjectKey, s.attachmentPolicy.DownloadURLExpiry)
+		if err != nil {
+			return nil, err
+		}
+
+		responses = append(responses, dto.LeaveAttachmentResponse{
+			ID:          attachment.ID,
+			FileName:    attachment.FileName,
+			ContentType: attachment.ContentType,
+			Size:        attachment.Size,
+			DownloadURL: url,
+			ExpiresAt:   expiresAt,
+		})
+	}
+
+	return responses, nil
+}
+
+// validateAttachments validates every file before any is stored and returns their content types
+func (s *LeaveService) validateAttachments(files []*multipart.FileHeader) ([]string, error) {
+	contentTypes := make([]string, 0, len(files))
+	for _, file := range files {
+		contentType, err := s.validateAttachment(file)
+		if err != nil {
+			return nil, err
+		}
+		contentTypes = append(contentTypes, contentType)
+	}
+
+	return contentTypes, nil
+}
+
+// validateAttachment checks the file size and sniffs its content type against the attachment policy
+func (s *LeaveService) validateAttachment(file *multipart.FileHeader) (string, error) {
+	if file.Size > s.attachmentPolicy.MaxSize {
+		return "", consts.ErrFileTooLarge
+	}
+
+	f, err := file.Open()
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	head := make([]byte, 512)
+	n, err := f.Read(head)
+	if err != nil && n == 0 {
+		return "", consts.ErrInvalidFileType
+	}
+
+	contentType := http.DetectContentType(head[:n])
+	if i := strings.Index(contentType, ";"); i != -1 {
+		contentType = contentType[:i]
+	}
+
+	if !s.attachmentPolicy.AllowsContentType(contentType) {
+		return "", consts.ErrInvalidFileType
+	}
+
+	return contentType, nil
+}
+
+// storeAttachments uploads the files, with the content types validateAttachments detected, to
+// object storage and records them against the leave request. Objects uploaded before a failure
+// are removed again.
+func (s *LeaveService) storeAttachments(ctx context.Context, leaveID, userID string, files []*multipart.FileHeader, contentTypes []string) ([]domain.LeaveAttachment, error) {
+	var attachments []domain.LeaveAttachment
+
+	cleanup := func() {
+		for _, attachment := range attachments {
+			if err := s.attachmentRepo.DeleteLeaveAttachment(ctx, attachment.ID); err != nil {
+				fmt.Printf("failed to delete leave attachment %s: %v", attachment.ID, err)
+			}
+			if err := s.storage.Delete(ctx, attachment.ObjectKey); err != nil {
+				fmt.Printf("failed to delete leave attachment %s: %v", attachment.ObjectKey, err)
+			}
+		}
+	}
+
+	for i, file := range files {
+		contentType := contentTypes[i]
+		attachmentID := uuid.New().String()
+		objectKey := fmt.Sprintf("leave-attachments/%s/%s%s", leaveID, attachmentID, strings.ToLower(filepath.Ext(file.Filename)))
+
+		f, err := file.Open()
+		if err != nil {
+			cleanup()
+			return nil, err
+		}
+
+		err = s.storage.Upload(ctx, &minio.FileUploadObject{
+			File:        f,
+			FileName:    objectKey,
+			ContentType: contentType,
+			Size:        file.Size,
+		})
+		f.Close()
+		if err != nil {
+			cleanup()
+			return nil, err
+		}
+
+		attachment, err := s.attachmentRepo.CreateLeaveAttachment(ctx, &domain.LeaveAttachment{
+			ID:             attachmentID,
+			LeaveRequestID: leaveID,
+			FileName:       filepath.Base(file.Filename),
+			ObjectKey:      objectKey,
+			ContentType:    contentType,
+			Size:           file.Size,
+			UploadedBy:     userID,
+			CreatedAt:      time.Now(),
+		})
+		if err != nil {
+			if delErr := s.storage.Delete(ctx, objectKey); delErr != nil {
+				fmt.Printf("failed to delete leave attachment %s: %v", objectKey, delErr)
+			}
+			cleanup()
+			return nil, err
+		}
+
+		attachments = append(attachments, *attachment)
+	}
+
+	return attachments, nil
+}
+
+// GetDepartmentLeaveCalendar lists who is out on each day of the period. The department
+// defaults to the one of the requesting user and the period to the current month. Other
+// departments need leave:read:any, the own and team scopes only open the actor's department.
+func (s *LeaveService) GetDepartmentLeaveCalendar(ctx context.Context, actor *domain.TokenPayload, req dto.LeaveCalendarRequest) (*domain.LeaveCalendar, error) {
+	filter, err := s.authz.OwnershipFilter(ctx, actor, domain.ActionLeaveRead)
+	if err != nil {
+		return nil, err
+	}
+	restricted := filter != (domain.OwnershipFilter{})
+
+	departmentID := req.DepartmentID
+	if departmentID == "" || restricted {
+		employee, err := s.employeeRepo.GetEmployeeByUserID(ctx, actor.UserID)
+		if err != nil {
+			if err == consts.ErrDataNotFound && restricted {
+				return nil, consts.ErrForbidden
+			}
+			return nil, err
+		}
+
+		if employee.DepartmentID == "" {
+			if restricted {
+				return nil, consts.ErrForbidden
+			}
+			return nil, consts.ErrDataNotFound
+		}
+
+		if restricted && departmentID != "" && departmentID != employee.DepartmentID {
+			return nil, consts.ErrForbidden
+		}
+		departmentID = employee.DepartmentID
+	}
+
+	now := time.Now()
+	startDate := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	endDate := startDate.AddDate(0, 1, -1)
+
+	if req.StartDate != "" {
+		parsed, err := time.Parse("2006-01-02", req.StartDate)
+		if err != nil {
+			return nil, consts.InvalidInput("invalid start date format")
+		}
+		startDate = parsed
+	}
+
+	if req.EndDate != "" {
+		parsed, err := time.Parse("2006-01-02", req.EndDate)
+		if err != nil {
+			return nil, consts.InvalidInput("invalid end date format")
+		}
+		endDate = parsed
+	}
+
+	if startDate.After(endDate) {
+		return nil, consts.InvalidInput("start date must be before end date")
+	}
+
+	if endDate.Sub(startDate) > maxLeaveCalendarRange {
+		return nil, consts.InvalidInput("calendar period must not exceed %d days", int(maxLeaveCalendarRange.Hours()/24))
+	}
+
+	leaves, err := s.repo.ListDepartmentLeaves(ctx, departmentID, startDate, endDate, []domain.LeaveStatus{domain.Approved, domain.Pending})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get department leaves: %w", err)
+	}
+
+	calendar := &domain.LeaveCalendar{
+		DepartmentID: departmentID,
+		StartDate:    startDate,
+		EndDate:      endDate,
+	}
+
+	for day := startDate; !day.After(endDate); day = day.AddDate(0, 0, 1) {
+		calendarDay := domain.LeaveCalendarDay{
+			Date:     day.Format("2006-01-02"),
+			Absences: []domain.LeaveCalendarEntry{},
+		}
+
+		for _, leave := range leaves {
+			if day.Before(leave.StartDate) || day.After(leave.EndDate) {
+				continue
+			}
+
+			calendarDay.Absences = append(calendarDay.Absences, domain.LeaveCalendarEntry{
+				LeaveID: leave.ID,
+				UserID:  leave.UserID,
+				Name:    leave.EmployeeName,
+				Type:    leave.Type,
+				Status:  leave.Status,
+			})
+		}
+
+		calendar.Days = append(calendar.Days, calendarDay)
+	}
+
+	return calendar, nil
+}
+
+// CheckLeaveCoverage previews the department coverage for each day of a leave request as if it were approved
+func (s *LeaveService) CheckLeaveCoverage(ctx context.Context, actor *domain.TokenPayload, leaveID string) ([]domain.LeaveCoverage, error) {
+	leave, err := s.authorizedLeave(ctx, actor, domain.ActionLeaveApprove, leaveID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.computeCoverage(ctx, leave)
+}
+
+const maxLeaveCalendarRange = 92 * 24 * time.Hour
+
+// computeCoverage counts, for every day of the leave, how many of the people expected at work
+// remain available once the leave and the already approved leaves are taken into account.
+// People scheduled in `schedules` are expected on days that have schedules, otherwise the
+// whole active headcount of the department is.
+func (s *LeaveService) computeCoverage(ctx context.Context, leave *domain.LeaveRequest) ([]domain.LeaveCoverage, error) {
+	employee, err := s.employeeRepo.GetEmployeeByUserID(ctx, leave.UserID)
+	if err != nil {
+		if err == consts.ErrDataNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if employee.DepartmentID == "" {
+		return nil, nil
+	}
+
+	headcount, err := s.employeeRepo.CountActiveEmployeesByDepartment(ctx, employee.DepartmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	approved, err := s.repo.ListDepartmentLeaves(ctx, employee.DepartmentID, leave.StartDate, leave.EndDate, []domain.LeaveStatus{domain.Approved})
+	if err != nil {
+		return nil, err
+	}
+
+	schedules, err := s.scheduleRepo.ListDepartmentSchedules(ctx, employee.DepartmentID, leave.StartDate, leave.EndDate)
+	if err != nil {
+		return nil, err
+	}
+
+	scheduled := make(map[string]map[string]bool)
+	for _, schedule := range schedules {
+		key := schedule.Date.Format("2006-01-02")
+		if scheduled[key] == nil {
+			scheduled[key] = make(map[string]bool)
+		}
+		scheduled[key][schedule.UserID] = true
+	}
+
+	absentees := []domain.LeaveRequest{*leave}
+	for _, other := range approved {
+		if other.ID != leave.ID {
+			absentees = append(absentees, other.LeaveRequest)
+		}
+	}
+
+	var coverage []domain.LeaveCoverage
+	for day := leave.StartDate; !day.After(leave.EndDate); day = day.AddDate(0, 0, 1) {
+		key := day.Format("2006-01-02")
+
+		out := make(map[string]bool)
+		for _, absentee := range absentees {
+			if !day.Before(absentee.StartDate) && !day.After(absentee.EndDate) {
+				out[absentee.UserID] = true
+			}
+		}
+
+		expected, absent := headcount, len(out)
+		if users := scheduled[key]; len(users) > 0 {
+			expected, absent = len(users), 0
+			for userID := range out {
+				if users[userID] {
+					absent++
+				}
+			}
+		}
+
+		available := expected - absent
+		if available < 0 {
+			available = 0
+		}
+
+		ratio := 1.0
+		if expected > 0 {
+			ratio = float64(available) / float64(expected)
+		}
+
+		coverage = append(coverage, domain.LeaveCoverage{
+			Date:         key,
+			Expected:     expected,
+			Absent:       absent,
+			Available:    available,
+			Coverage:     util.RoundFloat(ratio, 2),
+			BelowMinimum: expected > 0 && ratio < s.coveragePolicy.MinCoverage,
+		})
+	}
+
+	return coverage, nil
+}
+
+func belowMinimumCoverage(coverage []domain.LeaveCoverage) []domain.LeaveCoverage {
+	var warnings []domain.LeaveCoverage
+	for _, day := range coverage {
+		if day.BelowMinimum {
+			warnings = append(warnings, day)
+		}
+	}
+
+	return warnings
+}
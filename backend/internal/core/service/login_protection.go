@@ -0,0 +1,192 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aldotp/employee-attendance-system/internal/core/domain"
+	"github.com/aldotp/employee-attendance-system/internal/core/port"
+	"github.com/aldotp/employee-attendance-system/pkg/consts"
+	"github.com/aldotp/employee-attendance-system/pkg/util"
+	"go.uber.org/zap"
+)
+
+type LoginProtectionService struct {
+	cache           port.CacheInterface
+	userRepo        port.UserRepository
+	notificationSvc port.NotificationService
+	policy          domain.LoginProtectionPolicy
+	log             *zap.Logger
+}
+
+func NewLoginProtectionService(cache port.CacheInterface, userRepo port.UserRepository, notificationSvc port.NotificationService, policy domain.LoginProtectionPolicy, log *zap.Logger) *LoginProtectionService {
+	return &LoginProtectionService{
+		cache:           cache,
+		userRepo:        userRepo,
+		notificationSvc: notificationSvc,
+		policy:          policy,
+		log:             log,
+	}
+}
+
+// CheckLogin refuses a login while its email address or client IP is locked out, or while the
+// email address waits out the delay of its previous failures
+func (s *LoginProtectionService) CheckLogin(ctx context.Context, email, clientIP string) error {
+	account := loginAccountKey(email)
+	keys := []string{
+		util.GenerateCacheKey("login_lock", account),
+		util.GenerateCacheKey("login_delay", account),
+	}
+	if clientIP != "" {
+		keys = append(keys, util.GenerateCacheKey("login_lock", loginIPKey(clientIP)))
+	}
+
+	for _, key := range keys {
+		_, err := s.cache.Get(ctx, key)
+		if err == nil {
+			return consts.ErrTooManyLoginAttempts
+		}
+		if err != consts.ErrDataNotFound {
+			s.log.Error("failed to check login lockout", zap.Error(err))
+			return consts.ErrInternal
+		}
+	}
+
+	return nil
+}
+
+// RecordLoginFailure counts a failed login of the email address and the client IP. It delays the
+// next attempt of the email address once it failed often enough, and locks the email address or
+// the IP out when they reach their limit, returning ErrTooManyLoginAttempts.
+func (s *LoginProtectionService) RecordLoginFailure(ctx context.Context, email, clientIP string) error {
+	account := loginAccountKey(email)
+
+	failures, err := s.cache.Increment(ctx, util.GenerateCacheKey("login_failures", account), s.policy.Window)
+	if err != nil {
+		s.log.Error("failed to count failed login", zap.Error(err))
+		return consts.ErrInternal
+	}
+
+	var ipFailures int64
+	if clientIP != "" {
+		ipFailures, err = s.cache.Increment(ctx, util.GenerateCacheKey("login_failures", loginIPKey(clientIP)), s.policy.Window)
+		if err != nil {
+			s.log.Error("failed to count failed login", zap.Error(err))
+			return consts.ErrInternal
+		}
+	}
+
+	locked := false
+
+	if s.policy.AccountLimit > 0 && failures >= int64(s.policy.AccountLimit) {
+		if err := s.lock(ctx, account); err != nil {
+			return err
+		}
+		locked = true
+
+		s.log.Warn("login locked out for email address", zap.String("email", email), zap.Int64("failures", failures))
+		go s.notifyLockout(context.WithoutCancel(ctx), email)
+	} else if delay := s.policy.LoginDelay(int(failures)); delay > 0 {
+		if err := s.cache.Set(ctx, util.GenerateCacheKey("login_delay", account), []byte("1"), delay); err != nil {
+			s.log.Error("failed to delay login", zap.Error(err))
+			return consts.ErrInternal
+		}
+	}
+
+	if s.policy.IPLimit > 0 && ipFailures >= int64(s.policy.IPLimit) {
+		if err := s.lock(ctx, loginIPKey(clientIP)); err != nil {
+			return err
+		}
+		locked = true
+
+		s.log.Warn("login locked out for client IP", zap.String("client_ip", clientIP), zap.Int64("failures", ipFailures))
+	}
+
+	if locked {
+		return consts.ErrTooManyLoginAttempts
+	}
+
+	return nil
+}
+
+// RecordLoginSuccess forgets the failed logins of the email address
+func (s *LoginProtectionService) RecordLoginSuccess(ctx context.Context, email string) error {
+	return s.clear(ctx, loginAccountKey(email))
+}
+
+// UnlockAccount lifts the lockout of the user's email address and forgets its failed logins
+func (s *LoginProtectionService) UnlockAccount(ctx context.Context, userID string) error {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		if err == consts.ErrDataNotFound {
+			return err
+		}
+		s.log.Error("failed to get user by ID", zap.Error(err))
+		return consts.ErrInternal
+	}
+
+	if err := s.clear(ctx, loginAccountKey(user.Email)); err != nil {
+		return err
+	}
+
+	s.log.Info("login lockout lifted", zap.String("user_id", userID))
+
+	return nil
+}
+
+// lock locks the email address or IP out and restarts the count of its failures
+func (s *LoginProtectionService) lock(ctx context.Context, key string) error {
+	if err := s.cache.Set(ctx, util.GenerateCacheKey("login_lock", key), []byte("1"), s.policy.LockoutDuration); err != nil {
+		s.log.Error("failed to lock login", zap.Error(err))
+		return consts.ErrInternal
+	}
+
+	for _, prefix := range []string{"login_failures", "login_delay"} {
+		if err := s.cache.Delete(ctx, util.GenerateCacheKey(prefix, key)); err != nil {
+			s.log.Error("failed to reset failed logins", zap.Error(err))
+			return consts.ErrInternal
+		}
+	}
+
+	return nil
+}
+
+func (s *LoginProtectionService) clear(ctx context.Context, key string) error {
+	for _, prefix := range []string{"login_failures", "login_delay", "login_lock"} {
+		if err := s.cache.Delete(ctx, util.GenerateCacheKey(prefix, key)); err != nil {
+			s.log.Error("failed to reset failed logins", zap.Error(err))
+			return consts.ErrInternal
+		}
+	}
+
+	return nil
+}
+
+// notifyLockout tells the owner of the email address, if anybody has it, that their account was locked
+func (s *LoginProtectionService) notifyLockout(ctx context.Context, email string) {
+	user, err := s.userRepo.GetUserByEmail(ctx, email)
+	if err != nil {
+		if err != consts.ErrDataNotFound {
+			s.log.Error("failed to get user by email", zap.Error(err))
+		}
+		return
+	}
+
+	message := fmt.Sprintf("Your account was locked for %d minutes after %d failed login attempts. If these were not you, change your password once the lock ends.",
+		int(s.policy.LockoutDuration.Minutes()), s.policy.AccountLimit)
+
+	if _, err := s.notificationSvc.CreateNotification(ctx, domain.NewNotification(user.ID, domain.NotificationTypeSecurity, message, time.Now())); err != nil {
+		s.log.Error("failed to send lockout notification", zap.Error(err))
+	}
+}
+
+// loginAccountKey identifies an email address however it is capitalized, without storing it
+func loginAccountKey(email string) string {
+	return "account:" + hashToken(strings.ToLower(strings.TrimSpace(email)))
+}
+
+func loginIPKey(clientIP string) string {
+	return "ip:" + clientIP
+}
@@ -0,0 +1,310 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/aldotp/employee-attendance-system/internal/core/domain"
+	"github.com/aldotp/employee-attendance-system/internal/core/port"
+	"github.com/aldotp/employee-attendance-system/pkg/consts"
+	"github.com/aldotp/employee-attendance-system/pkg/totp"
+	"github.com/aldotp/employee-attendance-system/pkg/util"
+	"go.uber.org/zap"
+)
+
+// mfaSkew is the number of 30 second steps a code may be early or late, absorbing clock drift
+// between the server and the authenticator
+const mfaSkew = 1
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+type MFAService struct {
+	repo     port.MFARepository
+	userRepo port.UserRepository
+	cache    port.CacheInterface
+	policy   domain.MFAPolicy
+	log      *zap.Logger
+}
+
+func NewMFAService(repo port.MFARepository, userRepo port.UserRepository, cache port.CacheInterface, policy domain.MFAPolicy, log *zap.Logger) *MFAService {
+	return &MFAService{
+		repo:     repo,
+		userRepo: userRepo,
+		cache:    cache,
+		policy:   policy,
+		log:      log,
+	}
+}
+
+// MFAStatus tells whether the user has an enabled authenticator and whether their role requires one
+func (s *MFAService) MFAStatus(ctx context.Context, userID string, role domain.UserRole) (bool, bool, error) {
+	mfa, err := s.repo.GetUserMFA(ctx, userID)
+	if err != nil && err != consts.ErrDataNotFound {
+		s.log.Error("failed to get user MFA", zap.Error(err))
+		return false, false, consts.ErrInternal
+	}
+
+	return mfa.Enabled(), s.policy.Required(role), nil
+}
+
+// EnrollMFA generates a new TOTP secret for the user. It only guards logins once ConfirmMFA
+// accepts a first code from it, and enrolling again before that replaces the secret.
+func (s *MFAService) EnrollMFA(ctx context.Context, userID string) (*domain.MFAEnrollment, error) {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		if err == consts.ErrDataNotFound {
+			return nil, err
+		}
+		s.log.Error("failed to get user by ID", zap.Error(err))
+		return nil, consts.ErrInternal
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		s.log.Error("failed to generate TOTP secret", zap.Error(err))
+		return nil, consts.ErrInternal
+	}
+
+	encrypted, err := util.Encrypt(s.policy.EncryptionKey, secret)
+	if err != nil {
+		s.log.Error("failed to encrypt TOTP secret", zap.Error(err))
+		return nil, consts.ErrInternal
+	}
+
+	now := time.Now()
+	err = s.repo.SaveUserMFA(ctx, &domain.UserMFA{
+		UserID:    userID,
+		Secret:    encrypted,
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+	if err != nil {
+		if err == consts.ErrConflictingData {
+			return nil, consts.ErrMFAAlreadyEnabled
+		}
+		s.log.Error("failed to save user MFA", zap.Error(err))
+		return nil, consts.ErrInternal
+	}
+
+	return &domain.MFAEnrollment{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(secret, s.policy.Issuer, user.Email),
+	}, nil
+}
+
+// ConfirmMFA enables the pending enrollment of the user with a first code from their
+// authenticator. It returns the recovery codes, which are only shown this once.
+func (s *MFAService) ConfirmMFA(ctx context.Context, userID, code string) ([]string, error) {
+	mfa, err := s.userMFA(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if mfa.Enabled() {
+		return nil, consts.ErrMFAAlreadyEnabled
+	}
+
+	return s.confirm(ctx, mfa, code)
+}
+
+// DisableMFA removes the authenticator of the user after checking a code from it, or cancels an
+// enrollment that was never confirmed. Roles that require two-factor authentication cannot
+// disable it.
+func (s *MFAService) DisableMFA(ctx context.Context, userID, code string) error {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		if err == consts.ErrDataNotFound {
+			return err
+		}
+		s.log.Error("failed to get user by ID", zap.Error(err))
+		return consts.ErrInternal
+	}
+
+	if s.policy.Required(user.Role) {
+		return consts.ErrMFARequired
+	}
+
+	mfa, err := s.userMFA(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if mfa.Enabled() {
+		if err := s.verify(ctx, mfa, code); err != nil {
+			return err
+		}
+	}
+
+	if err := s.repo.DeleteUserMFA(ctx, userID); err != nil {
+		s.log.Error("failed to delete user MFA", zap.Error(err))
+		return consts.ErrInternal
+	}
+
+	return nil
+}
+
+// VerifyMFACode checks the second factor of a login, a code from the authenticator or an unused
+// recovery code. A user enrolling while logging in confirms the enrollment with it, and gets their
+// recovery codes back.
+func (s *MFAService) VerifyMFACode(ctx context.Context, userID, code string) ([]string, error) {
+	mfa, err := s.userMFA(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !mfa.Enabled() {
+		return s.confirm(ctx, mfa, code)
+	}
+
+	return nil, s.verify(ctx, mfa, code)
+}
+
+// CreateMFAChallenge starts the second step of a login, returning the token the client sends
+// along with the code
+func (s *MFAService) CreateMFAChallenge(ctx context.Context, userID string) (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		s.log.Error("failed to generate MFA challenge", zap.Error(err))
+		return "", consts.ErrInternal
+	}
+	token := hex.EncodeToString(secret)
+
+	if err := s.cache.Set(ctx, mfaChallengeKey(token), []byte(userID), s.policy.ChallengeTTL); err != nil {
+		s.log.Error("failed to store MFA challenge", zap.Error(err))
+		return "", consts.ErrInternal
+	}
+
+	return token, nil
+}
+
+// ResolveMFAChallenge returns the user a challenge was created for. Every call counts as an
+// attempt, and the challenge is dropped once the attempts run out so the next one needs the
+// password again.
+func (s *MFAService) ResolveMFAChallenge(ctx context.Context, token string) (string, error) {
+	userID, err := s.cache.Get(ctx, mfaChallengeKey(token))
+	if err != nil {
+		if err == consts.ErrDataNotFound {
+			return "", consts.ErrInvalidMFAToken
+		}
+		s.log.Error("failed to get MFA challenge", zap.Error(err))
+		return "", consts.ErrInternal
+	}
+
+	allowed, err := allowRequest(ctx, s.cache, util.GenerateCacheKey("mfa_challenge_attempts", hashToken(token)), s.policy.MaxAttempts, s.policy.ChallengeTTL)
+	if err != nil {
+		s.log.Error("failed to count MFA challenge attempt", zap.Error(err))
+		return "", consts.ErrInternal
+	}
+
+	if !allowed {
+		if err := s.DeleteMFAChallenge(ctx, token); err != nil {
+			return "", err
+		}
+		return "", consts.ErrTooManyRequests
+	}
+
+	return string(userID), nil
+}
+
+func (s *MFAService) DeleteMFAChallenge(ctx context.Context, token string) error {
+	if err := s.cache.Delete(ctx, mfaChallengeKey(token)); err != nil {
+		s.log.Error("failed to delete MFA challenge", zap.Error(err))
+		return consts.ErrInternal
+	}
+
+	return nil
+}
+
+func (s *MFAService) userMFA(ctx context.Context, userID string) (*domain.UserMFA, error) {
+	mfa, err := s.repo.GetUserMFA(ctx, userID)
+	if err != nil {
+		if err == consts.ErrDataNotFound {
+			return nil, consts.ErrMFANotEnabled
+		}
+		s.log.Error("failed to get user MFA", zap.Error(err))
+		return nil, consts.ErrInternal
+	}
+
+	return mfa, nil
+}
+
+// confirm enables a pending enrollment when the code matches its secret, issuing new recovery codes
+func (s *MFAService) confirm(ctx context.Context, mfa *domain.UserMFA, code string) ([]string, error) {
+	secret, err := util.Decrypt(s.policy.EncryptionKey, mfa.Secret)
+	if err != nil {
+		s.log.Error("failed to decrypt TOTP secret", zap.Error(err))
+		return nil, consts.ErrInternal
+	}
+
+	now := time.Now()
+	step, ok := totp.Validate(secret, code, now, mfaSkew)
+	if !ok {
+		return nil, consts.ErrInvalidMFACode
+	}
+
+	codes := make([]string, s.policy.RecoveryCodes)
+	hashes := make([]string, s.policy.RecoveryCodes)
+	for i := range codes {
+		raw := make([]byte, 5)
+		if _, err := rand.Read(raw); err != nil {
+			s.log.Error("failed to generate recovery code", zap.Error(err))
+			return nil, consts.ErrInternal
+		}
+
+		encoded := strings.ToLower(recoveryCodeEncoding.EncodeToString(raw))
+		codes[i] = encoded[:4] + "-" + encoded[4:]
+		hashes[i] = hashToken(encoded)
+	}
+
+	if err := s.repo.EnableUserMFA(ctx, mfa.UserID, step, now, hashes); err != nil {
+		if err == consts.ErrConflictingData {
+			return nil, consts.ErrMFAAlreadyEnabled
+		}
+		s.log.Error("failed to enable user MFA", zap.Error(err))
+		return nil, consts.ErrInternal
+	}
+
+	return codes, nil
+}
+
+// verify accepts a code from the enabled authenticator, unless its time step was already used,
+// or an unused recovery code
+func (s *MFAService) verify(ctx context.Context, mfa *domain.UserMFA, code string) error {
+	secret, err := util.Decrypt(s.policy.EncryptionKey, mfa.Secret)
+	if err != nil {
+		s.log.Error("failed to decrypt TOTP secret", zap.Error(err))
+		return consts.ErrInternal
+	}
+
+	now := time.Now()
+	if step, ok := totp.Validate(secret, code, now, mfaSkew); ok {
+		used, err := s.repo.UseMFAStep(ctx, mfa.UserID, step)
+		if err != nil {
+			s.log.Error("failed to record MFA step", zap.Error(err))
+			return consts.ErrInternal
+		}
+		if !used {
+			return consts.ErrInvalidMFACode
+		}
+		return nil
+	}
+
+	recoveryCode := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	if err := s.repo.UseRecoveryCode(ctx, mfa.UserID, hashToken(recoveryCode), now); err != nil {
+		if err == consts.ErrDataNotFound {
+			return consts.ErrInvalidMFACode
+		}
+		s.log.Error("failed to use recovery code", zap.Error(err))
+		return consts.ErrInternal
+	}
+
+	return nil
+}
+
+func mfaChallengeKey(token string) string {
+	return util.GenerateCacheKey("mfa_challenge", hashToken(token))
+}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/aldotp/employee-attendance-system/internal/core/domain"
 	"github.com/aldotp/employee-attendance-system/internal/core/port"
+	"github.com/aldotp/employee-attendance-system/pkg/consts"
 	"github.com/xuri/excelize/v2"
 )
 
@@ -18,13 +19,19 @@ type MonitoringService struct {
 	repo           port.MonitoringRepository
 	userRepo       port.UserRepository
 	attendanceRepo port.AttendanceRepository
+	scheduleRepo   port.ScheduleRepository
+	employeeRepo   port.EmployeeRepository
+	leaveRepo      port.LeaveRequestRepository
 }
 
-func NewMonitoringService(repo port.MonitoringRepository, userRepo port.UserRepository, attendanceRepo port.AttendanceRepository) *MonitoringService {
+func NewMonitoringService(repo port.MonitoringRepository, userRepo port.UserRepository, attendanceRepo port.AttendanceRepository, scheduleRepo port.ScheduleRepository, employeeRepo port.EmployeeRepository, leaveRepo port.LeaveRequestRepository) *MonitoringService {
 	return &MonitoringService{
 		repo:           repo,
 		userRepo:       userRepo,
 		attendanceRepo: attendanceRepo,
+		scheduleRepo:   scheduleRepo,
+		employeeRepo:   employeeRepo,
+		leaveRepo:      leaveRepo,
 	}
 }
 
@@ -117,9 +124,14 @@ func (ms *MonitoringService) GenerateAttendanceReport(ctx context.Context) ([]do
 				return
 			}
 
+			// a violation of either the check-in or the check-out decides the status of a day,
+			// night shifts count under the day they started
 			attendanceMap := make(map[string]domain.AttendanceStatus)
 			for _, att := range attendances {
-				dayStr := att.Time.Format("2006-01-02")
+				dayStr := att.BusinessDate.Format("2006-01-02")
+				if status, ok := attendanceMap[dayStr]; ok && (status != domain.AttendanceStatusPresent || att.Status == domain.AttendanceStatusPresent) {
+					continue
+				}
 				attendanceMap[dayStr] = att.Status
 			}
 
@@ -168,8 +180,118 @@ func (ms *MonitoringService) GenerateAttendanceReport(ctx context.Context) ([]do
 	return reports, nil
 }
 
-func (ms *MonitoringService) DetectAnomalies(ctx context.Context) ([]domain.Anomaly, error) {
-	return []domain.Anomaly{}, nil
+// DetectAnomalies checks the attendance of every scheduled employee on a business date against
+// their schedule. Regular and shift schedules report late check-ins and early check-outs, while
+// flexible schedules report core hours violations and short days. Employees on approved leave
+// are skipped.
+func (ms *MonitoringService) DetectAnomalies(ctx context.Context, date string) ([]domain.Anomaly, error) {
+	day, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return nil, consts.InvalidInput("invalid date format, expected YYYY-MM-DD")
+	}
+
+	anomalies := []domain.Anomaly{}
+
+	schedules, err := ms.scheduleRepo.ListSchedulesByDate(ctx, day)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(schedules) == 0 {
+		return anomalies, nil
+	}
+
+	attendances, err := ms.attendanceRepo.ListAttendancesByBusinessDate(ctx, day)
+	if err != nil {
+		return nil, err
+	}
+
+	userIDs := make([]string, 0, len(schedules))
+	for _, schedule := range schedules {
+		userIDs = append(userIDs, schedule.UserID)
+	}
+
+	leaves, err := ms.leaveRepo.ListUserLeaves(ctx, userIDs, day, day, []domain.LeaveStatus{domain.Approved})
+	if err != nil {
+		return nil, err
+	}
+
+	onLeave := map[string]bool{}
+	for _, leave := range leaves {
+		onLeave[leave.UserID] = true
+	}
+
+	// attendances are ordered by time, so the first check-in and the last check-out of the day win
+	checkIns := map[string]domain.Attendance{}
+	checkOuts := map[string]domain.Attendance{}
+	for _, attendance := range attendances {
+		switch attendance.Type {
+		case domain.AttendanceCheckIn:
+			if _, ok := checkIns[attendance.UserID]; !ok {
+				checkIns[attendance.UserID] = attendance
+			}
+		case domain.AttendanceCheckOut:
+			checkOuts[attendance.UserID] = attendance
+		}
+	}
+
+	now := time.Now()
+	report := func(schedule domain.Schedule, anomalyType, description string) {
+		anomalies = append(anomalies, domain.Anomaly{
+			UserID:      schedule.UserID,
+			ScheduleID:  schedule.ID,
+			Date:        date,
+			Type:        anomalyType,
+			Description: description,
+			DetectedAt:  now,
+		})
+	}
+
+	for _, schedule := range schedules {
+		if onLeave[schedule.UserID] {
+			continue
+		}
+
+		checkIn, ok := checkIns[schedule.UserID]
+		if !ok {
+			report(schedule, domain.AnomalyNotPresent, "no check-in for the scheduled shift")
+			continue
+		}
+
+		// statuses were computed against the schedule when the events were recorded
+		switch checkIn.Status {
+		case domain.AttendanceStatusLate:
+			report(schedule, domain.AnomalyLate, fmt.Sprintf("checked in late at %s", checkIn.Time.Format(time.RFC3339)))
+		case domain.AttendanceStatusCoreViolation:
+			report(schedule, domain.AnomalyCoreHoursViolation, fmt.Sprintf("checked in after the start of core hours %s at %s", schedule.CoreStart, checkIn.Time.Format(time.RFC3339)))
+		}
+
+		checkOut, ok := checkOuts[schedule.UserID]
+		if !ok {
+			continue
+		}
+
+		if schedule.IsFlexible() {
+			switch checkOut.Status {
+			case domain.AttendanceStatusCoreViolation:
+				report(schedule, domain.AnomalyCoreHoursViolation, fmt.Sprintf("checked out before the end of core hours %s at %s", schedule.CoreEnd, checkOut.Time.Format(time.RFC3339)))
+			case domain.AttendanceStatusShortDay:
+				report(schedule, domain.AnomalyShortDay, fmt.Sprintf("worked %.2f hours, below the minimum of %d minutes", checkOut.HoursWorked, schedule.MinDurationMinutes))
+			}
+			continue
+		}
+
+		_, end, err := schedule.Window(employeeLocation(ctx, ms.employeeRepo, schedule.UserID))
+		if err != nil {
+			continue
+		}
+
+		if checkOut.Time.Before(end) {
+			report(schedule, domain.AnomalyLeftEarly, fmt.Sprintf("checked out at %s before the end of the shift at %s", checkOut.Time.Format(time.RFC3339), end.Format(time.RFC3339)))
+		}
+	}
+
+	return anomalies, nil
 }
 
 func (ms *MonitoringService) ExportData(ctx context.Context, req domain.ExportRequest) (*domain.ExportResponse, error) {
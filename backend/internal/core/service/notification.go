@@ -2,26 +2,63 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"slices"
+	"strings"
 	"time"
 
+	"github.com/aldotp/employee-attendance-system/internal/adapter/dto"
 	"github.com/aldotp/employee-attendance-system/internal/core/domain"
 	"github.com/aldotp/employee-attendance-system/internal/core/port"
+	"github.com/aldotp/employee-attendance-system/pkg/consts"
+	"github.com/aldotp/employee-attendance-system/pkg/util"
 	"github.com/google/uuid"
 )
 
 type NotificationService struct {
-	repo     port.NotificationRepository
-	UserRepo port.UserRepository
+	repo         port.NotificationRepository
+	UserRepo     port.UserRepository
+	employeeRepo port.EmployeeRepository
+	channels     map[domain.NotificationChannelType]port.NotificationChannel
+	pubsub       port.PubSubInterface
+	policy       domain.NotificationPolicy
+	// dispatchSlots bounds the deliveries started right away by CreateNotification
+	dispatchSlots chan struct{}
 }
 
-func NewNotificationService(repo port.NotificationRepository, UserRepo port.UserRepository) *NotificationService {
+func NewNotificationService(repo port.NotificationRepository, UserRepo port.UserRepository, employeeRepo port.EmployeeRepository, channels []port.NotificationChannel, pubsub port.PubSubInterface, policy domain.NotificationPolicy) *NotificationService {
+	channelsByType := make(map[domain.NotificationChannelType]port.NotificationChannel, len(channels))
+	for _, channel := range channels {
+		channelsByType[channel.Type()] = channel
+	}
+
 	return &NotificationService{
-		repo:     repo,
-		UserRepo: UserRepo,
+		repo:          repo,
+		UserRepo:      UserRepo,
+		employeeRepo:  employeeRepo,
+		channels:      channelsByType,
+		pubsub:        pubsub,
+		policy:        policy,
+		dispatchSlots: make(chan struct{}, maxImmediateDispatches),
 	}
 }
 
+// notificationClaimLease is how long a claimed notification stays hidden from other dispatch
+// runs, after which it is picked up again if its delivery never completed
+const notificationClaimLease = 5 * time.Minute
+
+// maxImmediateDispatches is how many notifications may be delivered in the background at once,
+// the ones due beyond that wait for the dispatcher
+const maxImmediateDispatches = 16
+
+// CreateNotification enqueues a notification, which the dispatcher delivers once SendAt is reached.
+// Notifications already due are delivered right away in the background while a dispatch slot is
+// free, claimed so the dispatcher leaves them alone and only picks them up again when that
+// delivery does not complete within the claim.
 func (ns *NotificationService) CreateNotification(ctx context.Context, notif *domain.Notification) (string, error) {
 
 	_, err := ns.UserRepo.GetUserByID(ctx, notif.UserID)
@@ -44,29 +81,427 @@ func (ns *NotificationService) CreateNotification(ctx context.Context, notif *do
 		notif.SendAt = time.Now()
 	}
 
-	return ns.repo.CreateNotification(ctx, notif)
+	notif.Status = domain.NotificationPending
+
+	now := time.Now()
+	immediate := false
+	if !notif.SendAt.After(now) {
+		select {
+		case ns.dispatchSlots <- struct{}{}:
+			immediate = true
+			leaseUntil := now.Add(notificationClaimLease)
+			notif.NextAttemptAt = &leaseUntil
+		default:
+		}
+	}
+
+	id, err := ns.repo.CreateNotification(ctx, notif)
+	if err != nil {
+		if immediate {
+			<-ns.dispatchSlots
+		}
+		return "", err
+	}
+
+	if immediate {
+		claimed := *notif
+		go func() {
+			defer func() { <-ns.dispatchSlots }()
+
+			dispatchCtx, cancel := context.WithTimeout(context.Background(), notificationClaimLease)
+			defer cancel()
+
+			ns.dispatch(dispatchCtx, &claimed, now)
+		}()
+	}
+
+	return id, nil
 }
 
-func (ns *NotificationService) GetNotificationByID(ctx context.Context, id string) (*domain.Notification, error) {
-	return ns.repo.GetNotificationByID(ctx, id)
+func (ns *NotificationService) GetNotificationByID(ctx context.Context, userID string, id string) (*domain.Notification, error) {
+	return ns.repo.GetNotificationByID(ctx, userID, id)
 }
 
-func (ns *NotificationService) ListNotifications(ctx context.Context, employeeID string, skip, limit uint64) ([]domain.Notification, error) {
-	if skip == 0 {
-		skip = 0
+// ListNotifications lists a page of the user's in-app notifications, newest first, with the
+// cursor of the next page when there is one
+func (ns *NotificationService) ListNotifications(ctx context.Context, userID string, req dto.ListNotificationRequest) (*dto.ListNotificationResponse, error) {
+	notificationType, err := notificationTypeFilter(req.Type)
+	if err != nil {
+		return nil, err
 	}
 
+	limit := req.Limit
 	if limit == 0 {
 		limit = 10
 	}
 
-	return ns.repo.ListNotifications(ctx, employeeID, skip, limit)
+	filter := domain.NotificationFilter{
+		UserID: userID,
+		Type:   notificationType,
+		IsRead: req.IsRead,
+		// one more than the page tells whether a next page exists
+		Limit: limit + 1,
+	}
+
+	// skip is the page number, starting at 1
+	if req.Skip > 1 {
+		filter.Offset = (req.Skip - 1) * limit
+	}
+
+	if req.Cursor != "" {
+		cursor, err := domain.ParseNotificationCursor(req.Cursor)
+		if err != nil {
+			return nil, consts.ErrInvalidCursor
+		}
+		filter.After = &cursor
+	}
+
+	notifs, err := ns.repo.ListNotifications(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &dto.ListNotificationResponse{Notifications: []domain.Notification{}}
+	if uint64(len(notifs)) > limit {
+		notifs = notifs[:limit]
+		res.NextCursor = domain.NewNotificationCursor(notifs[len(notifs)-1]).String()
+	}
+	res.Notifications = append(res.Notifications, notifs...)
+
+	return res, nil
+}
+
+func (ns *NotificationService) CountUnreadNotifications(ctx context.Context, userID string, notificationType string) (int, error) {
+	filterType, err := notificationTypeFilter(notificationType)
+	if err != nil {
+		return 0, err
+	}
+
+	return ns.repo.CountUnreadNotifications(ctx, userID, filterType)
+}
+
+// MarkNotificationRead marks one of the user's notifications read or unread
+func (ns *NotificationService) MarkNotificationRead(ctx context.Context, userID string, id string, read bool) (*domain.Notification, error) {
+	updated, err := ns.repo.SetNotificationsRead(ctx, userID, []string{id}, read)
+	if err != nil {
+		return nil, err
+	}
+
+	if updated == 0 {
+		return nil, consts.ErrDataNotFound
+	}
+
+	return ns.repo.GetNotificationByID(ctx, userID, id)
+}
+
+// MarkNotificationsRead marks several of the user's notifications read, ignoring the IDs of
+// notifications that are not theirs. It returns how many were marked.
+func (ns *NotificationService) MarkNotificationsRead(ctx context.Context, userID string, ids []string) (int64, error) {
+	return ns.repo.SetNotificationsRead(ctx, userID, ids, true)
+}
+
+func (ns *NotificationService) MarkAllNotificationsRead(ctx context.Context, userID string, notificationType string) (int64, error) {
+	filterType, err := notificationTypeFilter(notificationType)
+	if err != nil {
+		return 0, err
+	}
+
+	return ns.repo.MarkAllNotificationsRead(ctx, userID, filterType)
+}
+
+func (ns *NotificationService) DeleteNotification(ctx context.Context, userID string, id string) error {
+	deleted, err := ns.repo.DeleteNotifications(ctx, userID, []string{id})
+	if err != nil {
+		return err
+	}
+
+	if deleted == 0 {
+		return consts.ErrDataNotFound
+	}
+
+	return nil
+}
+
+// DeleteNotifications deletes several of the user's notifications, ignoring the IDs of
+// notifications that are not theirs. It returns how many were deleted.
+func (ns *NotificationService) DeleteNotifications(ctx context.Context, userID string, ids []string) (int64, error) {
+	return ns.repo.DeleteNotifications(ctx, userID, ids)
+}
+
+// notificationTypeFilter validates an optional notification type filter
+func notificationTypeFilter(value string) (domain.NotificationType, error) {
+	notificationType := domain.NotificationType(value)
+	if notificationType != "" && !slices.Contains(domain.NotificationTypes, notificationType) {
+		return "", consts.InvalidInput("unknown notification type %q", value)
+	}
+
+	return notificationType, nil
+}
+
+// DispatchDueNotifications delivers the pending notifications whose send time has come through
+// the channels their recipients chose. Those other than reminders reaching their recipient during
+// quiet hours, in the recipient's timezone, are held until the quiet hours end, and failed
+// deliveries are retried with an increasing delay.
+func (ns *NotificationService) DispatchDueNotifications(ctx context.Context) (int, error) {
+	now := time.Now()
+	notifs, err := ns.repo.ClaimDueNotifications(ctx, now, now.Add(notificationClaimLease), ns.policy.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	delivered := 0
+	for i := range notifs {
+		if ns.dispatch(ctx, &notifs[i], now) {
+			delivered++
+		}
+	}
+
+	return delivered, nil
+}
+
+// dispatch delivers a claimed notification, holding it during quiet hours and scheduling a retry
+// when it fails. It reports whether the notification was delivered.
+func (ns *NotificationService) dispatch(ctx context.Context, notif *domain.Notification, now time.Time) bool {
+	recipient, loc, err := ns.recipient(ctx, notif.UserID)
+	if err != nil {
+		ns.retry(ctx, notif, now, err)
+		return false
+	}
+
+	// reminders are about something happening soon and cannot wait for the quiet hours to end
+	if until, quiet := ns.policy.QuietUntil(now, loc); quiet && notif.Type != domain.NotificationTypeReminder {
+		// stored like every other timestamp, in the server's timezone
+		until = until.In(now.Location())
+		notif.NextAttemptAt = &until
+		if err := ns.repo.UpdateNotificationDelivery(ctx, notif); err != nil {
+			fmt.Printf("failed to hold notification %s until %s: %v", notif.ID, until, err)
+		}
+		return false
+	}
+
+	pref, err := ns.preference(ctx, notif.UserID, notif.Type)
+	if err != nil {
+		ns.retry(ctx, notif, now, err)
+		return false
+	}
+	recipient.WebhookURL = pref.WebhookURL
+
+	if err := ns.deliver(ctx, notif, pref.Channels, recipient, now); err != nil {
+		ns.retry(ctx, notif, now, err)
+		return false
+	}
+
+	return true
 }
 
-func (ns *NotificationService) UpdateNotificationStatus(ctx context.Context, notificationID string, status string) error {
-	return ns.repo.UpdateNotificationStatus(ctx, notificationID, status)
+// SubscribeNotifications streams the notifications delivered in app to the user until ctx is done
+func (ns *NotificationService) SubscribeNotifications(ctx context.Context, userID string) (<-chan domain.Notification, error) {
+	messages, err := ns.pubsub.Subscribe(ctx, domain.NotificationTopic(userID))
+	if err != nil {
+		return nil, err
+	}
+
+	notifs := make(chan domain.Notification)
+	go func() {
+		defer close(notifs)
+
+		for message := range messages {
+			var notif domain.Notification
+			if err := json.Unmarshal(message, &notif); err != nil {
+				fmt.Printf("failed to decode streamed notification: %v", err)
+				continue
+			}
+
+			select {
+			case notifs <- notif:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return notifs, nil
 }
 
-func (ns *NotificationService) DeleteNotification(ctx context.Context, notificationID string) error {
-	return ns.repo.DeleteNotification(ctx, notificationID)
+// recipient looks up where the user receives notifications and the timezone they live in
+func (ns *NotificationService) recipient(ctx context.Context, userID string) (domain.NotificationRecipient, *time.Location, error) {
+	recipient := domain.NotificationRecipient{UserID: userID}
+	loc := time.UTC
+
+	user, err := ns.UserRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return recipient, nil, err
+	}
+	recipient.Email = user.Email
+
+	employee, err := ns.employeeRepo.GetEmployeeByUserID(ctx, userID)
+	if err != nil && err != consts.ErrDataNotFound {
+		return recipient, nil, err
+	}
+	if employee != nil {
+		recipient.Name = employee.Name
+		loc = timezoneLocation(employee.Timezone)
+	}
+
+	return recipient, loc, nil
+}
+
+// deliver sends the notification through every channel it has not gone out through yet. The
+// channels that succeed are remembered, so a retry after a partial failure does not repeat them.
+func (ns *NotificationService) deliver(ctx context.Context, notif *domain.Notification, channels []domain.NotificationChannelType, recipient domain.NotificationRecipient, now time.Time) error {
+	var failures []string
+	for _, channelType := range channels {
+		if notif.IsDeliveredTo(channelType) {
+			continue
+		}
+
+		// the channel may have been disabled on this server since the preference was saved
+		channel, ok := ns.channels[channelType]
+		if !ok {
+			continue
+		}
+
+		if err := channel.Send(ctx, *notif, recipient); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", channelType, err))
+			continue
+		}
+
+		notif.DeliveredChannels = append(notif.DeliveredChannels, channelType)
+	}
+
+	if len(failures) > 0 {
+		return errors.New(strings.Join(failures, "; "))
+	}
+
+	delivered := *notif
+	delivered.Status = domain.NotificationDelivered
+	delivered.Attempts++
+	delivered.LastError = ""
+	delivered.NextAttemptAt = nil
+	delivered.DeliveredAt = &now
+
+	if err := ns.repo.UpdateNotificationDelivery(ctx, &delivered); err != nil {
+		return err
+	}
+
+	*notif = delivered
+	return nil
+}
+
+// retry records a failed delivery, scheduling the next attempt or giving up after MaxAttempts
+func (ns *NotificationService) retry(ctx context.Context, notif *domain.Notification, now time.Time, cause error) {
+	notif.Attempts++
+	notif.LastError = cause.Error()
+
+	if notif.Attempts >= ns.policy.MaxAttempts {
+		notif.Status = domain.NotificationFailed
+		notif.NextAttemptAt = nil
+	} else {
+		next := now.Add(ns.policy.RetryDelay(notif.Attempts))
+		notif.NextAttemptAt = &next
+	}
+
+	// when this fails too, the notification is retried once its claim lease ends
+	if err := ns.repo.UpdateNotificationDelivery(ctx, notif); err != nil {
+		fmt.Printf("failed to record delivery attempt of notification %s: %v", notif.ID, err)
+	}
+}
+
+// ListNotificationPreferences lists the channels of every notification type, falling back to the
+// default channels for the types the user has not configured
+func (ns *NotificationService) ListNotificationPreferences(ctx context.Context, userID string) ([]domain.NotificationPreference, error) {
+	saved, err := ns.repo.ListNotificationPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	prefs := make([]domain.NotificationPreference, 0, len(domain.NotificationTypes))
+	for _, notificationType := range domain.NotificationTypes {
+		pref := ns.defaultPreference(userID, notificationType)
+		for _, savedPref := range saved {
+			if savedPref.Type == notificationType {
+				pref = savedPref
+			}
+		}
+		prefs = append(prefs, pref)
+	}
+
+	return prefs, nil
+}
+
+func (ns *NotificationService) UpdateNotificationPreference(ctx context.Context, userID string, req dto.NotificationPreferenceRequest) (*domain.NotificationPreference, error) {
+	notificationType := domain.NotificationType(req.Type)
+	if !slices.Contains(domain.NotificationTypes, notificationType) {
+		return nil, consts.InvalidInput("unknown notification type %q", req.Type)
+	}
+
+	var channels []domain.NotificationChannelType
+	for _, name := range req.Channels {
+		channel := domain.NotificationChannelType(name)
+		if _, ok := ns.channels[channel]; !ok {
+			return nil, consts.ErrNotificationChannel
+		}
+		if !slices.Contains(channels, channel) {
+			channels = append(channels, channel)
+		}
+	}
+
+	webhookURL := strings.TrimSpace(req.WebhookURL)
+	if webhookURL != "" || slices.Contains(channels, domain.NotificationChannelWebhook) {
+		parsed, err := url.Parse(webhookURL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Hostname() == "" {
+			return nil, consts.ErrInvalidWebhookURL
+		}
+		if err := checkWebhookHost(ctx, parsed.Hostname()); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	return ns.repo.SaveNotificationPreference(ctx, &domain.NotificationPreference{
+		UserID:     userID,
+		Type:       notificationType,
+		Channels:   channels,
+		WebhookURL: webhookURL,
+		UpdatedAt:  &now,
+	})
+}
+
+// checkWebhookHost refuses webhook hosts resolving to internal addresses, which would let users
+// make the server call its own network. The webhook channel checks the address again when it
+// connects, since the host may resolve differently by then.
+func checkWebhookHost(ctx context.Context, host string) error {
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil || len(addrs) == 0 {
+		return consts.ErrWebhookAddressNotAllowed
+	}
+
+	for _, addr := range addrs {
+		if !util.IsPublicAddr(addr) {
+			return consts.ErrWebhookAddressNotAllowed
+		}
+	}
+
+	return nil
+}
+
+// preference is the user's preference for the notification type, or the default one
+func (ns *NotificationService) preference(ctx context.Context, userID string, notificationType domain.NotificationType) (domain.NotificationPreference, error) {
+	pref, err := ns.repo.GetNotificationPreference(ctx, userID, notificationType)
+	if err != nil {
+		if err == consts.ErrDataNotFound {
+			return ns.defaultPreference(userID, notificationType), nil
+		}
+		return domain.NotificationPreference{}, err
+	}
+
+	return *pref, nil
+}
+
+func (ns *NotificationService) defaultPreference(userID string, notificationType domain.NotificationType) domain.NotificationPreference {
+	return domain.NotificationPreference{
+		UserID:   userID,
+		Type:     notificationType,
+		Channels: ns.policy.DefaultChannels,
+	}
 }
@@ -0,0 +1,258 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/aldotp/employee-attendance-system/internal/adapter/dto"
+	"github.com/aldotp/employee-attendance-system/internal/core/domain"
+	"github.com/aldotp/employee-attendance-system/pkg/consts"
+	"github.com/aldotp/employee-attendance-system/pkg/util"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// StartOIDCLogin starts a single sign-on login, returning the identity provider page the client
+// sends the user to. The PKCE verifier and the nonce stay in the cache until the user returns.
+func (as *AuthService) StartOIDCLogin(ctx context.Context) (*domain.OIDCAuthorization, error) {
+	if as.oidc == nil {
+		return nil, consts.ErrOIDCNotConfigured
+	}
+
+	state := util.GenerateRandomString(43)
+	loginState := domain.OIDCLoginState{
+		Verifier: util.GenerateRandomString(64),
+		Nonce:    util.GenerateRandomString(43),
+	}
+	if state == "" || loginState.Verifier == "" || loginState.Nonce == "" {
+		as.log.Error("failed to generate single sign-on state")
+		return nil, consts.ErrInternal
+	}
+
+	url, err := as.oidc.AuthCodeURL(ctx, state, loginState.Nonce, loginState.Verifier)
+	if err != nil {
+		as.log.Error("failed to build single sign-on URL", zap.Error(err))
+		return nil, consts.ErrInternal
+	}
+
+	serialized, err := util.Serialize(loginState)
+	if err != nil {
+		return nil, consts.ErrInternal
+	}
+
+	if err := as.cache.Set(ctx, oidcStateKey(state), serialized, as.oidcPolicy.StateTTL); err != nil {
+		as.log.Error("failed to store single sign-on state", zap.Error(err))
+		return nil, consts.ErrInternal
+	}
+
+	return &domain.OIDCAuthorization{
+		URL:   url,
+		State: state,
+	}, nil
+}
+
+// LoginOIDC finishes a single sign-on login with the code the identity provider returned. The ID
+// token email signs in the user owning it, or a new employee when provisioning is enabled, and
+// the login then continues like a password login.
+func (as *AuthService) LoginOIDC(ctx context.Context, state, code string) (dto.LoginResponse, error) {
+	if as.oidc == nil {
+		return dto.LoginResponse{}, consts.ErrOIDCNotConfigured
+	}
+
+	loginState, err := as.takeOIDCState(ctx, state)
+	if err != nil {
+		return dto.LoginResponse{}, err
+	}
+
+	identity, err := as.oidc.Exchange(ctx, code, loginState.Verifier, loginState.Nonce)
+	if err != nil {
+		as.log.Warn("single sign-on exchange failed", zap.Error(err))
+		return dto.LoginResponse{}, consts.ErrOIDCLoginFailed
+	}
+
+	if identity.Email == "" {
+		as.log.Warn("single sign-on identity has no email", zap.String("subject", identity.Subject))
+		return dto.LoginResponse{}, consts.ErrOIDCLoginFailed
+	}
+
+	// an unverified address could belong to somebody else's account
+	if as.oidcPolicy.RequireVerifiedEmail && !identity.EmailVerified {
+		return dto.LoginResponse{}, consts.ErrEmailNotVerified
+	}
+
+	user, err := as.repo.GetUserByEmail(ctx, identity.Email)
+	if err != nil {
+		if err != consts.ErrDataNotFound {
+			as.log.Error("failed to get user by email", zap.Error(err))
+			return dto.LoginResponse{}, consts.ErrInternal
+		}
+
+		if !as.oidcPolicy.AutoProvision {
+			return dto.LoginResponse{}, consts.ErrOIDCAccountNotFound
+		}
+
+		user, err = as.provisionOIDCUser(ctx, identity)
+		if err != nil {
+			return dto.LoginResponse{}, err
+		}
+	}
+
+	// the identity provider vouched for the address
+	if user.EmailVerifiedAt == nil {
+		now := time.Now()
+		if _, err := as.repo.UpdateUser(ctx, &domain.User{ID: user.ID, EmailVerifiedAt: &now}); err != nil {
+			as.log.Error("failed to mark email verified", zap.Error(err))
+			return dto.LoginResponse{}, consts.ErrInternal
+		}
+		if err := as.cache.Delete(ctx, util.GenerateCacheKey("user", user.ID)); err != nil {
+			as.log.Error("failed to delete cached user", zap.Error(err))
+			return dto.LoginResponse{}, consts.ErrInternal
+		}
+		user.EmailVerifiedAt = &now
+	}
+
+	return as.completeLogin(ctx, user)
+}
+
+// takeOIDCState loads the state of a login and deletes it, so a code can only be redeemed once
+func (as *AuthService) takeOIDCState(ctx context.Context, state string) (*domain.OIDCLoginState, error) {
+	key := oidcStateKey(state)
+
+	cached, err := as.cache.Get(ctx, key)
+	if err != nil {
+		if err == consts.ErrDataNotFound {
+			return nil, consts.ErrInvalidOIDCState
+		}
+		as.log.Error("failed to get single sign-on state", zap.Error(err))
+		return nil, consts.ErrInternal
+	}
+
+	if err := as.cache.Delete(ctx, key); err != nil {
+		as.log.Error("failed to delete single sign-on state", zap.Error(err))
+		return nil, consts.ErrInternal
+	}
+
+	var loginState domain.OIDCLoginState
+	if err := util.Deserialize(cached, &loginState); err != nil {
+		return nil, consts.ErrInternal
+	}
+
+	return &loginState, nil
+}
+
+// provisionOIDCUser creates the user and employee record of someone signing in through the
+// identity provider for the first time. They get an unusable random password, and can choose one
+// through the password reset flow.
+func (as *AuthService) provisionOIDCUser(ctx context.Context, identity *domain.OIDCIdentity) (user *domain.User, err error) {
+	department, err := as.oidcDepartment(ctx, identity.Department)
+	if err != nil {
+		return nil, err
+	}
+
+	password := util.GenerateRandomString(32)
+	if password == "" {
+		as.log.Error("failed to generate password")
+		return nil, consts.ErrInternal
+	}
+
+	hashedPassword, err := util.HashPassword(password)
+	if err != nil {
+		as.log.Error(err.Error())
+		return nil, consts.ErrInternal
+	}
+
+	name := identity.Name
+	if name == "" {
+		name, _, _ = strings.Cut(identity.Email, "@")
+	}
+
+	tNow := time.Now()
+
+	tx, err := as.repo.BeginTx(ctx)
+	if err != nil {
+		as.log.Error(err.Error())
+		return nil, consts.ErrInternal
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback(ctx)
+			panic(p)
+		} else if err != nil {
+			tx.Rollback(ctx)
+		} else {
+			err = tx.Commit(ctx)
+		}
+	}()
+
+	user = &domain.User{
+		ID:              uuid.New().String(),
+		Email:           identity.Email,
+		Password:        hashedPassword,
+		Role:            domain.Employees,
+		EmailVerifiedAt: &tNow,
+		CreatedAt:       tNow,
+		UpdatedAt:       tNow,
+	}
+
+	user, err = as.repo.CreateUserTx(ctx, tx, user)
+	if err != nil {
+		as.log.Error(err.Error())
+		if err == consts.ErrConflictingData {
+			return nil, err
+		}
+		return nil, consts.ErrInternal
+	}
+
+	employee := &domain.Employee{
+		ID:           uuid.New().String(),
+		UserID:       user.ID,
+		Name:         name,
+		Timezone:     "UTC",
+		Status:       domain.StatusActive,
+		DepartmentID: department.ID,
+		JoinDate:     tNow,
+		CreatedAt:    tNow,
+		UpdatedAt:    tNow,
+	}
+
+	_, err = as.employeeRepo.CreateEmployeeTx(ctx, tx, employee)
+	if err != nil {
+		as.log.Error(err.Error())
+		if err == consts.ErrConflictingData {
+			return nil, err
+		}
+		return nil, consts.ErrInternal
+	}
+
+	as.log.Info("provisioned single sign-on user", zap.String("user_id", user.ID), zap.String("subject", identity.Subject))
+
+	return user, nil
+}
+
+// oidcDepartment finds the department named by the ID token, falling back to the default one
+func (as *AuthService) oidcDepartment(ctx context.Context, name string) (*domain.Department, error) {
+	if name != "" {
+		department, err := as.departmentRepo.GetDepartmentByName(ctx, name)
+		if err == nil {
+			return department, nil
+		}
+		if err != consts.ErrDataNotFound {
+			as.log.Error(err.Error())
+			return nil, consts.ErrInternal
+		}
+		as.log.Warn("unknown single sign-on department, using the default", zap.String("department", name))
+	}
+
+	department, err := as.departmentRepo.GetDepartmentByName(ctx, as.oidcPolicy.DefaultDepartment)
+	if err != nil {
+		as.log.Error("failed to get default single sign-on department", zap.Error(err))
+		return nil, consts.ErrInternal
+	}
+
+	return department, nil
+}
+
+func oidcStateKey(state string) string {
+	return util.GenerateCacheKey("oidc_state", hashToken(state))
+}
@@ -0,0 +1,391 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aldotp/employee-attendance-system/internal/core/domain"
+	"github.com/aldotp/employee-attendance-system/internal/core/port"
+	"github.com/aldotp/employee-attendance-system/pkg/consts"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// memoryCache is a port.CacheInterface kept in memory, ignoring expiry
+type memoryCache struct {
+	mu     sync.Mutex
+	values map[string][]byte
+}
+
+func newMemoryCache() *memoryCache {
+	return &memoryCache{values: map[string][]byte{}}
+}
+
+func (c *memoryCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.values[key] = value
+	return nil
+}
+
+func (c *memoryCache) Get(ctx context.Context, key string) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	value, ok := c.values[key]
+	if !ok {
+		return nil, consts.ErrDataNotFound
+	}
+	return value, nil
+}
+
+func (c *memoryCache) Delete(ctx context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.values, key)
+	return nil
+}
+
+func (c *memoryCache) DeleteByPrefix(ctx context.Context, prefix string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key := range c.values {
+		if strings.HasPrefix(key, strings.TrimSuffix(prefix, "*")) {
+			delete(c.values, key)
+		}
+	}
+	return nil
+}
+
+func (c *memoryCache) Increment(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	return 0, errors.New("not implemented")
+}
+
+func (c *memoryCache) CompareAndSwap(ctx context.Context, key string, old, value []byte, ttl time.Duration) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	current, ok := c.values[key]
+	if !ok || !bytes.Equal(current, old) {
+		return false, nil
+	}
+	c.values[key] = value
+	return true, nil
+}
+
+func (c *memoryCache) Close() error {
+	return nil
+}
+
+// oidcUserRepo keeps users in memory, the methods LoginOIDC does not use are left to the embedded
+// nil interface
+type oidcUserRepo struct {
+	port.UserRepository
+
+	users    map[string]*domain.User
+	verified []string
+}
+
+func (r *oidcUserRepo) GetUserByEmail(ctx context.Context, email string) (*domain.User, error) {
+	user, ok := r.users[email]
+	if !ok {
+		return nil, consts.ErrDataNotFound
+	}
+	copied := *user
+	return &copied, nil
+}
+
+func (r *oidcUserRepo) UpdateUser(ctx context.Context, user *domain.User) (*domain.User, error) {
+	if user.EmailVerifiedAt != nil {
+		r.verified = append(r.verified, user.ID)
+	}
+	return user, nil
+}
+
+func (r *oidcUserRepo) BeginTx(ctx context.Context) (pgx.Tx, error) {
+	return &oidcTx{}, nil
+}
+
+func (r *oidcUserRepo) CreateUserTx(ctx context.Context, tx pgx.Tx, user *domain.User) (*domain.User, error) {
+	r.users[user.Email] = user
+	return user, nil
+}
+
+type oidcTx struct {
+	pgx.Tx
+}
+
+func (tx *oidcTx) Commit(ctx context.Context) error   { return nil }
+func (tx *oidcTx) Rollback(ctx context.Context) error { return nil }
+
+type oidcEmployeeRepo struct {
+	port.EmployeeRepository
+
+	employees map[string]*domain.Employee
+}
+
+func (r *oidcEmployeeRepo) GetEmployeeByUserID(ctx context.Context, userID string) (*domain.Employee, error) {
+	employee, ok := r.employees[userID]
+	if !ok {
+		return nil, consts.ErrDataNotFound
+	}
+	return employee, nil
+}
+
+func (r *oidcEmployeeRepo) CreateEmployeeTx(ctx context.Context, tx pgx.Tx, employee *domain.Employee) (*domain.Employee, error) {
+	r.employees[employee.UserID] = employee
+	return employee, nil
+}
+
+type oidcDepartmentRepo struct {
+	port.DepartmentRepository
+
+	departments []domain.Department
+}
+
+func (r *oidcDepartmentRepo) GetDepartmentByName(ctx context.Context, name string) (*domain.Department, error) {
+	for i := range r.departments {
+		if r.departments[i].Name == name {
+			return &r.departments[i], nil
+		}
+	}
+	return nil, consts.ErrDataNotFound
+}
+
+type oidcTokens struct {
+	port.TokenInterface
+}
+
+func (oidcTokens) GenerateAccessToken(user *domain.User, version int64) (string, error) {
+	return "access:" + user.ID, nil
+}
+
+func (oidcTokens) GenerateRefreshToken(user *domain.User, sessionID, tokenID string) (string, time.Time, error) {
+	return "refresh:" + user.ID, time.Now().Add(time.Hour), nil
+}
+
+type oidcTokenVersion struct {
+	port.TokenVersionService
+}
+
+func (oidcTokenVersion) TokenVersion(ctx context.Context, userID string) (int64, error) {
+	return 0, nil
+}
+
+type oidcMFA struct {
+	port.MFAService
+}
+
+func (oidcMFA) MFAStatus(ctx context.Context, userID string, role domain.UserRole) (bool, bool, error) {
+	return false, false, nil
+}
+
+// fakeOIDCProvider returns its identity for the verifier and nonce of the login it was started with
+type fakeOIDCProvider struct {
+	identity domain.OIDCIdentity
+	verifier string
+	nonce    string
+}
+
+func (p *fakeOIDCProvider) AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error) {
+	p.verifier, p.nonce = verifier, nonce
+	return "https://idp.example.com/authorize?state=" + state, nil
+}
+
+func (p *fakeOIDCProvider) Exchange(ctx context.Context, code, verifier, nonce string) (*domain.OIDCIdentity, error) {
+	if code != "code" || verifier != p.verifier || nonce != p.nonce {
+		return nil, errors.New("invalid_grant")
+	}
+	identity := p.identity
+	return &identity, nil
+}
+
+type oidcFixture struct {
+	service   *AuthService
+	provider  *fakeOIDCProvider
+	users     *oidcUserRepo
+	employees *oidcEmployeeRepo
+}
+
+func newOIDCFixture(policy domain.OIDCPolicy, users ...*domain.User) *oidcFixture {
+	f := &oidcFixture{
+		provider: &fakeOIDCProvider{identity: domain.OIDCIdentity{
+			Subject:       "subject-1",
+			Email:         "jane@example.com",
+			EmailVerified: true,
+			Name:          "Jane Doe",
+		}},
+		users:     &oidcUserRepo{users: map[string]*domain.User{}},
+		employees: &oidcEmployeeRepo{employees: map[string]*domain.Employee{}},
+	}
+	for _, user := range users {
+		f.users.users[user.Email] = user
+	}
+
+	departments := &oidcDepartmentRepo{departments: []domain.Department{
+		{ID: "department-general", Name: "General"},
+		{ID: "department-engineering", Name: "Engineering"},
+	}}
+
+	f.service = NewAuthService(f.users, f.employees, departments, oidcTokens{}, newMemoryCache(), oidcTokenVersion{}, oidcMFA{}, f.provider, policy, nil, zap.NewNop())
+
+	return f
+}
+
+// start begins a login and returns its state
+func (f *oidcFixture) start(t *testing.T) string {
+	t.Helper()
+
+	authorization, err := f.service.StartOIDCLogin(context.Background())
+	if err != nil {
+		t.Fatalf("StartOIDCLogin() error = %v", err)
+	}
+
+	return authorization.State
+}
+
+var testOIDCPolicy = domain.OIDCPolicy{
+	AutoProvision:        true,
+	DefaultDepartment:    "General",
+	RequireVerifiedEmail: true,
+	StateTTL:             5 * time.Minute,
+}
+
+func TestLoginOIDCStateIsSingleUse(t *testing.T) {
+	f := newOIDCFixture(testOIDCPolicy, &domain.User{ID: "user-1", Email: "jane@example.com", Role: domain.Employees})
+	state := f.start(t)
+
+	if _, err := f.service.LoginOIDC(context.Background(), state, "code"); err != nil {
+		t.Fatalf("LoginOIDC() error = %v", err)
+	}
+
+	if _, err := f.service.LoginOIDC(context.Background(), state, "code"); err != consts.ErrInvalidOIDCState {
+		t.Errorf("LoginOIDC() with a used state error = %v, want %v", err, consts.ErrInvalidOIDCState)
+	}
+}
+
+func TestLoginOIDCUnknownState(t *testing.T) {
+	f := newOIDCFixture(testOIDCPolicy)
+	f.start(t)
+
+	if _, err := f.service.LoginOIDC(context.Background(), "unknown", "code"); err != consts.ErrInvalidOIDCState {
+		t.Errorf("LoginOIDC() error = %v, want %v", err, consts.ErrInvalidOIDCState)
+	}
+}
+
+func TestLoginOIDCFailedExchangeUsesState(t *testing.T) {
+	f := newOIDCFixture(testOIDCPolicy, &domain.User{ID: "user-1", Email: "jane@example.com", Role: domain.Employees})
+	state := f.start(t)
+
+	if _, err := f.service.LoginOIDC(context.Background(), state, "wrong-code"); err != consts.ErrOIDCLoginFailed {
+		t.Fatalf("LoginOIDC() error = %v, want %v", err, consts.ErrOIDCLoginFailed)
+	}
+
+	if _, err := f.service.LoginOIDC(context.Background(), state, "code"); err != consts.ErrInvalidOIDCState {
+		t.Errorf("LoginOIDC() retrying the state error = %v, want %v", err, consts.ErrInvalidOIDCState)
+	}
+}
+
+func TestLoginOIDCRefusesUnverifiedEmail(t *testing.T) {
+	f := newOIDCFixture(testOIDCPolicy, &domain.User{ID: "user-1", Email: "jane@example.com", Role: domain.Employees})
+	f.provider.identity.EmailVerified = false
+	state := f.start(t)
+
+	if _, err := f.service.LoginOIDC(context.Background(), state, "code"); err != consts.ErrEmailNotVerified {
+		t.Errorf("LoginOIDC() error = %v, want %v", err, consts.ErrEmailNotVerified)
+	}
+	if len(f.users.verified) > 0 {
+		t.Errorf("users %v were marked verified", f.users.verified)
+	}
+}
+
+func TestLoginOIDCLinksExistingUser(t *testing.T) {
+	f := newOIDCFixture(testOIDCPolicy, &domain.User{ID: "user-1", Email: "jane@example.com", Role: domain.Employees})
+	state := f.start(t)
+
+	res, err := f.service.LoginOIDC(context.Background(), state, "code")
+	if err != nil {
+		t.Fatalf("LoginOIDC() error = %v", err)
+	}
+
+	if res.AccessToken != "access:user-1" || res.RefreshToken != "refresh:user-1" {
+		t.Errorf("LoginOIDC() = %+v, want the tokens of user-1", res)
+	}
+	if len(f.employees.employees) > 0 {
+		t.Error("an employee was provisioned for an existing user")
+	}
+	if len(f.users.verified) != 1 || f.users.verified[0] != "user-1" {
+		t.Errorf("verified users = %v, want [user-1]", f.users.verified)
+	}
+}
+
+func TestLoginOIDCWithoutProvisioning(t *testing.T) {
+	policy := testOIDCPolicy
+	policy.AutoProvision = false
+	f := newOIDCFixture(policy)
+	state := f.start(t)
+
+	if _, err := f.service.LoginOIDC(context.Background(), state, "code"); err != consts.ErrOIDCAccountNotFound {
+		t.Errorf("LoginOIDC() error = %v, want %v", err, consts.ErrOIDCAccountNotFound)
+	}
+}
+
+func TestLoginOIDCProvisionsUser(t *testing.T) {
+	tests := []struct {
+		name           string
+		department     string
+		wantDepartment string
+	}{
+		{"department from the claim", "Engineering", "department-engineering"},
+		{"unknown department", "Marketing", "department-general"},
+		{"no department claim", "", "department-general"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newOIDCFixture(testOIDCPolicy)
+			f.provider.identity.Department = tt.department
+			state := f.start(t)
+
+			res, err := f.service.LoginOIDC(context.Background(), state, "code")
+			if err != nil {
+				t.Fatalf("LoginOIDC() error = %v", err)
+			}
+
+			user, ok := f.users.users["jane@example.com"]
+			if !ok {
+				t.Fatal("no user was provisioned")
+			}
+			if user.Role != domain.Employees || user.EmailVerifiedAt == nil {
+				t.Errorf("provisioned user = %+v", user)
+			}
+			if res.AccessToken != "access:"+user.ID {
+				t.Errorf("LoginOIDC() = %+v, want the tokens of %s", res, user.ID)
+			}
+
+			employee, ok := f.employees.employees[user.ID]
+			if !ok {
+				t.Fatal("no employee was provisioned")
+			}
+			if employee.DepartmentID != tt.wantDepartment || employee.Name != "Jane Doe" || employee.Status != domain.StatusActive {
+				t.Errorf("provisioned employee = %+v, want department %s", employee, tt.wantDepartment)
+			}
+		})
+	}
+}
+
+func TestLoginOIDCNotConfigured(t *testing.T) {
+	service := NewAuthService(nil, nil, nil, nil, newMemoryCache(), nil, nil, nil, testOIDCPolicy, nil, zap.NewNop())
+
+	if _, err := service.LoginOIDC(context.Background(), "state", "code"); err != consts.ErrOIDCNotConfigured {
+		t.Errorf("LoginOIDC() error = %v, want %v", err, consts.ErrOIDCNotConfigured)
+	}
+}
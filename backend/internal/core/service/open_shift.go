@@ -0,0 +1,265 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aldotp/employee-attendance-system/internal/adapter/dto"
+	"github.com/aldotp/employee-attendance-system/internal/core/domain"
+	"github.com/aldotp/employee-attendance-system/pkg/consts"
+	"github.com/google/uuid"
+)
+
+// PostOpenShift offers a schedule to the active employees of a department and notifies them
+func (s *ScheduleService) PostOpenShift(ctx context.Context, postedBy string, req dto.OpenShiftRequest) (*domain.OpenShift, error) {
+	schedule, err := s.repo.GetSchedule(ctx, req.ScheduleID)
+	if err != nil {
+		return nil, err
+	}
+
+	today := time.Now().Truncate(24 * time.Hour)
+	if schedule.Date.Before(today) {
+		return nil, consts.InvalidInput("past schedules cannot be posted as open shifts")
+	}
+
+	departmentID := req.DepartmentID
+	if departmentID == "" {
+		owner, err := s.employeeRepo.GetEmployeeByUserID(ctx, schedule.UserID)
+		if err != nil && err != consts.ErrDataNotFound {
+			return nil, err
+		}
+		if owner != nil {
+			departmentID = owner.DepartmentID
+		}
+	}
+
+	if departmentID == "" {
+		return nil, consts.InvalidInput("department_id is required when the schedule owner has no department")
+	}
+
+	workLocationID := req.WorkLocationID
+	if workLocationID == "" {
+		workLocationID = schedule.WorkLocationID
+	}
+
+	now := time.Now()
+	openShift, err := s.openShiftRepo.CreateOpenShift(ctx, &domain.OpenShift{
+		ID:             uuid.New().String(),
+		ScheduleID:     schedule.ID,
+		DepartmentID:   departmentID,
+		WorkLocationID: workLocationID,
+		Status:         domain.OpenShiftOpen,
+		Note:           req.Note,
+		PostedBy:       postedBy,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	userIDs, err := s.employeeRepo.ListActiveUserIDsByDepartment(ctx, departmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	message := fmt.Sprintf("An open shift on %s from %s to %s is available to claim.", openShift.Date.Format("2006-01-02"), openShift.ShiftStart, openShift.ShiftEnd)
+	for _, userID := range userIDs {
+		if userID != schedule.UserID && userID != postedBy {
+			s.sendSwapNotification(ctx, userID, message)
+		}
+	}
+
+	return openShift, nil
+}
+
+func (s *ScheduleService) ListOpenShifts(ctx context.Context, departmentID string, status domain.OpenShiftStatus) ([]domain.OpenShift, error) {
+	return s.openShiftRepo.ListOpenShifts(ctx, departmentID, status)
+}
+
+// ListAvailableOpenShifts lists the open shifts of the employee's own department
+func (s *ScheduleService) ListAvailableOpenShifts(ctx context.Context, userID string) ([]domain.OpenShift, error) {
+	employee, err := s.employeeRepo.GetEmployeeByUserID(ctx, userID)
+	if err != nil {
+		if err == consts.ErrDataNotFound {
+			return nil, consts.ErrOpenShiftNotEligible
+		}
+		return nil, err
+	}
+
+	return s.openShiftRepo.ListOpenShifts(ctx, employee.DepartmentID, domain.OpenShiftOpen)
+}
+
+// ClaimOpenShift records the employee's interest in an open shift once they are eligible for it.
+// When the shift would break the scheduling rules, the violations are returned together with
+// consts.ErrScheduleRuleViolation.
+func (s *ScheduleService) ClaimOpenShift(ctx context.Context, openShiftID string, userID string) (*domain.OpenShiftClaim, []domain.ScheduleViolation, error) {
+	openShift, err := s.openShiftRepo.GetOpenShift(ctx, openShiftID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if openShift.Status != domain.OpenShiftOpen {
+		return nil, nil, consts.ErrOpenShiftClosed
+	}
+
+	_, violations, err := s.checkOpenShiftEligibility(ctx, openShift, userID)
+	if err != nil {
+		return nil, violations, err
+	}
+
+	now := time.Now()
+	claim, err := s.openShiftRepo.CreateOpenShiftClaim(ctx, &domain.OpenShiftClaim{
+		ID:          uuid.New().String(),
+		OpenShiftID: openShift.ID,
+		UserID:      userID,
+		Status:      domain.ClaimPending,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if openShift.PostedBy != "" {
+		s.sendSwapNotification(ctx, openShift.PostedBy, fmt.Sprintf("The open shift on %s has a new claim waiting for confirmation.", openShift.Date.Format("2006-01-02")))
+	}
+
+	return claim, nil, nil
+}
+
+func (s *ScheduleService) ListOpenShiftClaims(ctx context.Context, openShiftID string) ([]domain.OpenShiftClaim, error) {
+	if _, err := s.openShiftRepo.GetOpenShift(ctx, openShiftID); err != nil {
+		return nil, err
+	}
+
+	return s.openShiftRepo.ListOpenShiftClaims(ctx, openShiftID)
+}
+
+// ConfirmOpenShiftClaim assigns the schedule to the claimant, rejecting the other claims. The
+// claimant is checked again since their schedules may have changed since they claimed.
+func (s *ScheduleService) ConfirmOpenShiftClaim(ctx context.Context, claimID string, reviewerID string) (*domain.OpenShift, []domain.ScheduleViolation, error) {
+	claim, err := s.openShiftRepo.GetOpenShiftClaim(ctx, claimID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if claim.UserID == reviewerID {
+		return nil, nil, consts.ErrForbidden
+	}
+
+	openShift, err := s.openShiftRepo.GetOpenShift(ctx, claim.OpenShiftID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if openShift.Status != domain.OpenShiftOpen || claim.Status != domain.ClaimPending {
+		return nil, nil, consts.ErrOpenShiftClosed
+	}
+
+	schedule, violations, err := s.checkOpenShiftEligibility(ctx, openShift, claim.UserID)
+	if err != nil {
+		return nil, violations, err
+	}
+
+	claims, err := s.openShiftRepo.ListOpenShiftClaims(ctx, openShift.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := s.openShiftRepo.ConfirmOpenShiftClaim(ctx, claim.ID, reviewerID); err != nil {
+		return nil, nil, err
+	}
+
+	date := openShift.Date.Format("2006-01-02")
+	s.sendSwapNotification(ctx, claim.UserID, fmt.Sprintf("Your claim for the open shift on %s has been confirmed and added to your schedule.", date))
+	for _, other := range claims {
+		if other.ID != claim.ID && other.Status == domain.ClaimPending {
+			s.sendSwapNotification(ctx, other.UserID, fmt.Sprintf("The open shift on %s has been filled by another employee.", date))
+		}
+	}
+	if schedule.UserID != "" {
+		s.sendSwapNotification(ctx, schedule.UserID, fmt.Sprintf("Your shift on %s has been taken over by another employee.", date))
+	}
+
+	openShift, err = s.openShiftRepo.GetOpenShift(ctx, openShift.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return openShift, nil, nil
+}
+
+// CancelOpenShift withdraws an open shift, leaving its schedule with the current owner
+func (s *ScheduleService) CancelOpenShift(ctx context.Context, openShiftID string, reviewerID string) (*domain.OpenShift, error) {
+	claims, err := s.ListOpenShiftClaims(ctx, openShiftID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.openShiftRepo.CancelOpenShift(ctx, openShiftID, reviewerID); err != nil {
+		return nil, err
+	}
+
+	openShift, err := s.openShiftRepo.GetOpenShift(ctx, openShiftID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, claim := range claims {
+		if claim.Status == domain.ClaimPending {
+			s.sendSwapNotification(ctx, claim.UserID, fmt.Sprintf("The open shift on %s you claimed has been cancelled.", openShift.Date.Format("2006-01-02")))
+		}
+	}
+
+	return openShift, nil
+}
+
+// checkOpenShiftEligibility checks that an active employee of the open shift's department, other
+// than the schedule's owner and not on approved leave that day, can take the schedule without
+// breaking the scheduling rules. It returns the schedule as currently assigned.
+func (s *ScheduleService) checkOpenShiftEligibility(ctx context.Context, openShift *domain.OpenShift, userID string) (*domain.Schedule, []domain.ScheduleViolation, error) {
+	schedule, err := s.repo.GetSchedule(ctx, openShift.ScheduleID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if schedule.UserID == userID {
+		return nil, nil, consts.ErrOpenShiftNotEligible
+	}
+
+	employee, err := s.employeeRepo.GetEmployeeByUserID(ctx, userID)
+	if err != nil {
+		if err == consts.ErrDataNotFound {
+			return nil, nil, consts.ErrOpenShiftNotEligible
+		}
+		return nil, nil, err
+	}
+
+	if employee.Status != domain.StatusActive || employee.DepartmentID != openShift.DepartmentID {
+		return nil, nil, consts.ErrOpenShiftNotEligible
+	}
+
+	leaves, err := s.leaveRepo.ListUserLeaves(ctx, []string{userID}, schedule.Date, schedule.Date, []domain.LeaveStatus{domain.Approved})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(leaves) > 0 {
+		return nil, nil, consts.ErrOpenShiftNotEligible
+	}
+
+	candidate := *schedule
+	candidate.UserID = userID
+	violations, err := s.checkScheduleRules(ctx, candidate)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(violations) > 0 {
+		return nil, violations, consts.ErrScheduleRuleViolation
+	}
+
+	return schedule, nil, nil
+}
@@ -0,0 +1,194 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aldotp/employee-attendance-system/internal/core/domain"
+	"github.com/aldotp/employee-attendance-system/internal/core/port"
+	"github.com/aldotp/employee-attendance-system/pkg/consts"
+	"github.com/aldotp/employee-attendance-system/pkg/email"
+	"github.com/aldotp/employee-attendance-system/pkg/util"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type PasswordResetService struct {
+	repo         port.PasswordResetRepository
+	userRepo     port.UserRepository
+	cache        port.CacheInterface
+	tokenVersion port.TokenVersionService
+	sender       *email.Email
+	policy       domain.PasswordResetPolicy
+	log          *zap.Logger
+}
+
+func NewPasswordResetService(repo port.PasswordResetRepository, userRepo port.UserRepository, cache port.CacheInterface, tokenVersion port.TokenVersionService, sender *email.Email, policy domain.PasswordResetPolicy, log *zap.Logger) *PasswordResetService {
+	return &PasswordResetService{
+		repo:         repo,
+		userRepo:     userRepo,
+		cache:        cache,
+		tokenVersion: tokenVersion,
+		sender:       sender,
+		policy:       policy,
+		log:          log,
+	}
+}
+
+type passwordResetEmailPayload struct {
+	AppName   string
+	Subject   string
+	ResetURL  string
+	ExpiresIn string
+}
+
+// ForgotPassword emails a password reset link to the address when it belongs to a user. The
+// lookup and the email happen in the background, so callers cannot tell from the result or from
+// the response time whether the address is registered. Only the rate limits are reported.
+func (s *PasswordResetService) ForgotPassword(ctx context.Context, email, clientIP string) error {
+	email = strings.TrimSpace(email)
+
+	if err := s.checkRateLimit(ctx, "ip:"+clientIP, s.policy.IPLimit); err != nil {
+		return err
+	}
+	if err := s.checkRateLimit(ctx, "email:"+strings.ToLower(email), s.policy.EmailLimit); err != nil {
+		return err
+	}
+
+	go s.sendPasswordReset(context.WithoutCancel(ctx), email)
+
+	return nil
+}
+
+// ResetPassword sets a new password with a reset token. The token is used up, and every session
+// and access token of the user is revoked so whoever knew the old password is signed out.
+func (s *PasswordResetService) ResetPassword(ctx context.Context, token, password, clientIP string) error {
+	if err := s.checkRateLimit(ctx, "reset_ip:"+clientIP, s.policy.IPLimit); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	resetToken, err := s.repo.UsePasswordResetToken(ctx, hashToken(token), now)
+	if err != nil {
+		if err == consts.ErrDataNotFound {
+			return consts.ErrInvalidResetToken
+		}
+		s.log.Error("failed to use password reset token", zap.Error(err))
+		return consts.ErrInternal
+	}
+
+	hashedPassword, err := util.HashPassword(password)
+	if err != nil {
+		s.log.Error("failed to hash password", zap.Error(err))
+		return consts.ErrInternal
+	}
+
+	if _, err := s.userRepo.UpdateUser(ctx, &domain.User{ID: resetToken.UserID, Password: hashedPassword}); err != nil {
+		s.log.Error("failed to update password", zap.Error(err))
+		return consts.ErrInternal
+	}
+
+	// other links sent before this one must not reset the new password
+	if err := s.repo.DeletePasswordResetTokens(ctx, resetToken.UserID); err != nil {
+		s.log.Error("failed to delete password reset tokens", zap.Error(err))
+	}
+
+	if err := s.tokenVersion.RevokeAllTokens(ctx, resetToken.UserID); err != nil {
+		s.log.Error("failed to revoke tokens", zap.Error(err))
+		return consts.ErrInternal
+	}
+
+	return nil
+}
+
+// sendPasswordReset replaces the outstanding reset tokens of the user owning the address with a
+// new one and emails it
+func (s *PasswordResetService) sendPasswordReset(ctx context.Context, address string) {
+	user, err := s.userRepo.GetUserByEmail(ctx, address)
+	if err != nil {
+		if err != consts.ErrDataNotFound {
+			s.log.Error("failed to get user by email", zap.Error(err))
+		}
+		return
+	}
+
+	if s.sender == nil {
+		s.log.Warn("password reset requested but SMTP is not configured", zap.String("user_id", user.ID))
+		return
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		s.log.Error("failed to generate password reset token", zap.Error(err))
+		return
+	}
+	token := hex.EncodeToString(secret)
+
+	resetURL, err := tokenLink(s.policy.URL, token)
+	if err != nil {
+		s.log.Error("invalid password reset URL", zap.Error(err))
+		return
+	}
+
+	if err := s.repo.DeletePasswordResetTokens(ctx, user.ID); err != nil {
+		s.log.Error("failed to delete password reset tokens", zap.Error(err))
+		return
+	}
+
+	now := time.Now()
+	err = s.repo.CreatePasswordResetToken(ctx, &domain.PasswordResetToken{
+		ID:        uuid.New().String(),
+		UserID:    user.ID,
+		TokenHash: hashToken(token),
+		ExpiresAt: now.Add(s.policy.TokenTTL),
+		CreatedAt: now,
+	})
+	if err != nil {
+		s.log.Error("failed to create password reset token", zap.Error(err))
+		return
+	}
+
+	subject := fmt.Sprintf("[%s] Reset your password", s.policy.AppName)
+	err = s.sender.SendEmail(passwordResetEmailPayload{
+		AppName:   s.policy.AppName,
+		Subject:   subject,
+		ResetURL:  resetURL,
+		ExpiresIn: strconv.Itoa(int(s.policy.TokenTTL.Minutes())),
+	}, s.policy.EmailTemplate, []string{user.Email}, subject)
+	if err != nil {
+		s.log.Error("failed to send password reset email", zap.Error(err))
+	}
+}
+
+func (s *PasswordResetService) checkRateLimit(ctx context.Context, key string, limit int) error {
+	allowed, err := allowRequest(ctx, s.cache, util.GenerateCacheKey("password_reset", key), limit, s.policy.RateWindow)
+	if err != nil {
+		s.log.Error("failed to check password reset rate limit", zap.Error(err))
+		return consts.ErrInternal
+	}
+	if !allowed {
+		return consts.ErrTooManyRequests
+	}
+
+	return nil
+}
+
+// tokenLink appends the token to the page of the client application as the token query parameter
+func tokenLink(page, token string) (string, error) {
+	link, err := url.Parse(page)
+	if err != nil {
+		return "", err
+	}
+
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+
+	return link.String(), nil
+}
@@ -0,0 +1,23 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/aldotp/employee-attendance-system/internal/core/port"
+)
+
+// allowRequest counts a request against key and reports whether it stays within limit requests
+// per window. A limit of 0 allows every request.
+func allowRequest(ctx context.Context, cache port.CacheInterface, key string, limit int, window time.Duration) (bool, error) {
+	if limit <= 0 {
+		return true, nil
+	}
+
+	count, err := cache.Increment(ctx, key, window)
+	if err != nil {
+		return false, err
+	}
+
+	return count <= int64(limit), nil
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aldotp/employee-attendance-system/internal/adapter/dto"
+	"github.com/aldotp/employee-attendance-system/internal/core/domain"
+	"github.com/aldotp/employee-attendance-system/internal/core/port"
+	"github.com/aldotp/employee-attendance-system/pkg/consts"
+	"github.com/google/uuid"
+)
+
+// maxRotationCycle bounds the length of a rotation cycle in days
+const maxRotationCycle = 366
+
+type RotationService struct {
+	repo port.RotationRepository
+}
+
+func NewRotationService(repo port.RotationRepository) *RotationService {
+	return &RotationService{
+		repo: repo,
+	}
+}
+
+func (s *RotationService) CreateRotationPattern(ctx context.Context, req dto.RotationPatternRequest) (*domain.RotationPattern, error) {
+	days := req.Days
+	if len(days) == 0 {
+		if req.OnDays <= 0 || req.OffDays < 0 {
+			return nil, consts.InvalidInput("either days or on_days and off_days must be provided")
+		}
+
+		for i := 0; i < req.OnDays+req.OffDays; i++ {
+			day := domain.RotationDay{Work: i < req.OnDays}
+			if day.Work {
+				day.ShiftStart, day.ShiftEnd = req.ShiftStart, req.ShiftEnd
+				day.BreakStart, day.BreakEnd = req.BreakStart, req.BreakEnd
+			}
+			days = append(days, day)
+		}
+	}
+
+	if len(days) > maxRotationCycle {
+		return nil, consts.InvalidInput("rotation cycle must not exceed %d days", maxRotationCycle)
+	}
+
+	working := 0
+	for i, day := range days {
+		if !day.Work {
+			days[i] = domain.RotationDay{}
+			continue
+		}
+
+		if err := validateRotationDay(day); err != nil {
+			return nil, fmt.Errorf("day %d: %w", i+1, err)
+		}
+		working++
+	}
+
+	if working == 0 {
+		return nil, consts.InvalidInput("rotation must contain at least one working day")
+	}
+
+	now := time.Now()
+	return s.repo.CreateRotationPattern(ctx, &domain.RotationPattern{
+		ID:          uuid.New().String(),
+		Name:        strings.TrimSpace(req.Name),
+		Description: req.Description,
+		Days:        days,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	})
+}
+
+func (s *RotationService) ListRotationPatterns(ctx context.Context) ([]domain.RotationPattern, error) {
+	return s.repo.ListRotationPatterns(ctx)
+}
+
+func (s *RotationService) GetRotationPattern(ctx context.Context, id string) (*domain.RotationPattern, error) {
+	return s.repo.GetRotationPattern(ctx, id)
+}
+
+func (s *RotationService) DeleteRotationPattern(ctx context.Context, id string) error {
+	return s.repo.DeleteRotationPattern(ctx, id)
+}
+
+// AssignRotation starts a rotation pattern for an employee. An employee follows at most
+// one rotation at a time, so the period must not overlap an existing assignment.
+func (s *RotationService) AssignRotation(ctx context.Context, req dto.RotationAssignmentRequest) (*domain.RotationAssignment, error) {
+	startDate, err := time.Parse("2006-01-02", req.StartDate)
+	if err != nil {
+		return nil, consts.InvalidInput("invalid start date format")
+	}
+
+	var endDate *time.Time
+	if req.EndDate != "" {
+		parsed, err := time.Parse("2006-01-02", req.EndDate)
+		if err != nil {
+			return nil, consts.InvalidInput("invalid end date format")
+		}
+
+		if parsed.Before(startDate) {
+			return nil, consts.InvalidInput("start date must be before end date")
+		}
+		endDate = &parsed
+	}
+
+	pattern, err := s.repo.GetRotationPattern(ctx, req.RotationPatternID)
+	if err != nil {
+		return nil, err
+	}
+
+	until := time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)
+	if endDate != nil {
+		until = *endDate
+	}
+
+	existing, err := s.repo.ListRotationAssignments(ctx, req.UserID, startDate, until)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(existing) > 0 {
+		return nil, consts.ErrConflictingData
+	}
+
+	now := time.Now()
+	assignment, err := s.repo.CreateRotationAssignment(ctx, &domain.RotationAssignment{
+		ID:                uuid.New().String(),
+		UserID:            req.UserID,
+		RotationPatternID: pattern.ID,
+		StartDate:         startDate,
+		EndDate:           endDate,
+		WorkLocationID:    req.WorkLocationID,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	assignment.Pattern = pattern
+	return assignment, nil
+}
+
+// ListRotationAssignments lists the current and future rotation assignments of an employee
+func (s *RotationService) ListRotationAssignments(ctx context.Context, userID string) ([]domain.RotationAssignment, error) {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	return s.repo.ListRotationAssignments(ctx, userID, today, time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC))
+}
+
+func (s *RotationService) DeleteRotationAssignment(ctx context.Context, id string) error {
+	return s.repo.DeleteRotationAssignment(ctx, id)
+}
+
+func validateRotationDay(day domain.RotationDay) error {
+	return validateShiftTimes(day.ShiftStart, day.ShiftEnd, day.BreakStart, day.BreakEnd)
+}
@@ -2,53 +2,860 @@ package service
 
 import (
 	"context"
+	"fmt"
+	"strings"
+	"time"
 
+	"github.com/aldotp/employee-attendance-system/internal/adapter/dto"
 	"github.com/aldotp/employee-attendance-system/internal/core/domain"
 	"github.com/aldotp/employee-attendance-system/internal/core/port"
+	"github.com/aldotp/employee-attendance-system/pkg/consts"
 	"github.com/google/uuid"
 )
 
 type ScheduleService struct {
-	repo port.ScheduleRepository
+	repo            port.ScheduleRepository
+	rotationRepo    port.RotationRepository
+	templateRepo    port.ShiftTemplateRepository
+	ruleRepo        port.ScheduleRuleRepository
+	openShiftRepo   port.OpenShiftRepository
+	employeeRepo    port.EmployeeRepository
+	leaveRepo       port.LeaveRequestRepository
+	notificationSvc port.NotificationService
+	authz           port.AuthorizationService
+	defaultRule     domain.ScheduleRule
 }
 
-func NewScheduleService(repo port.ScheduleRepository) *ScheduleService {
+func NewScheduleService(repo port.ScheduleRepository, rotationRepo port.RotationRepository, templateRepo port.ShiftTemplateRepository, ruleRepo port.ScheduleRuleRepository, openShiftRepo port.OpenShiftRepository, employeeRepo port.EmployeeRepository, leaveRepo port.LeaveRequestRepository, notificationService port.NotificationService, authz port.AuthorizationService, defaultRule domain.ScheduleRule) *ScheduleService {
 	return &ScheduleService{
-		repo: repo,
+		repo:            repo,
+		rotationRepo:    rotationRepo,
+		templateRepo:    templateRepo,
+		ruleRepo:        ruleRepo,
+		openShiftRepo:   openShiftRepo,
+		employeeRepo:    employeeRepo,
+		leaveRepo:       leaveRepo,
+		notificationSvc: notificationService,
+		authz:           authz,
+		defaultRule:     defaultRule,
 	}
 }
 
-func (s *ScheduleService) ListSchedules(ctx context.Context) ([]domain.Schedule, error) {
-	return s.repo.ListSchedules(ctx)
+// ListSchedules lists the schedules the actor may read
+func (s *ScheduleService) ListSchedules(ctx context.Context, actor *domain.TokenPayload) ([]domain.Schedule, error) {
+	filter, err := s.authz.OwnershipFilter(ctx, actor, domain.ActionScheduleRead)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.ListSchedules(ctx, filter)
 }
 
-func (s *ScheduleService) CreateSchedule(ctx context.Context, schedule *domain.Schedule) (*domain.Schedule, error) {
+// CreateSchedule creates a schedule once it passes the scheduling rules. When it does not, the
+// violations are returned together with consts.ErrScheduleRuleViolation.
+func (s *ScheduleService) CreateSchedule(ctx context.Context, actor *domain.TokenPayload, schedule *domain.Schedule) (*domain.Schedule, []domain.ScheduleViolation, error) {
+	if err := s.authz.Authorize(ctx, actor, domain.ActionScheduleWrite, schedule.UserID); err != nil {
+		return nil, nil, err
+	}
+
+	if err := validateShiftTimes(schedule.ShiftStart, schedule.ShiftEnd, schedule.BreakStart, schedule.BreakEnd); err != nil {
+		return nil, nil, err
+	}
+
+	if err := validateFlexibleHours(schedule.ScheduleType, schedule.ShiftStart, schedule.ShiftEnd, schedule.CoreStart, schedule.CoreEnd, schedule.MinDurationMinutes); err != nil {
+		return nil, nil, err
+	}
+
+	violations, err := s.checkScheduleRules(ctx, *schedule)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(violations) > 0 {
+		return nil, violations, consts.ErrScheduleRuleViolation
+	}
+
 	if schedule.ID == "" {
 		schedule.ID = uuid.New().String()
 	}
 
-	return s.repo.CreateSchedule(ctx, schedule)
+	schedule, err = s.repo.CreateSchedule(ctx, schedule)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return schedule, nil, nil
 }
 
-func (s *ScheduleService) GetSchedule(ctx context.Context, id string) (*domain.Schedule, error) {
-	return s.repo.GetSchedule(ctx, id)
+func (s *ScheduleService) GetSchedule(ctx context.Context, actor *domain.TokenPayload, id string) (*domain.Schedule, error) {
+	return s.authorizedSchedule(ctx, actor, domain.ActionScheduleRead, id)
 }
 
-func (s *ScheduleService) UpdateSchedule(ctx context.Context, id string, schedule *domain.Schedule) (*domain.Schedule, error) {
-	return s.repo.UpdateSchedule(ctx, id, schedule)
+// UpdateSchedule applies the given fields to the schedule, leaving empty ones unchanged, and
+// validates the result against the scheduling rules like CreateSchedule does
+func (s *ScheduleService) UpdateSchedule(ctx context.Context, actor *domain.TokenPayload, id string, schedule *domain.Schedule) (*domain.Schedule, []domain.ScheduleViolation, error) {
+	updated, err := s.authorizedSchedule(ctx, actor, domain.ActionScheduleWrite, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// handing the schedule to someone else needs the right to schedule them too
+	if schedule.UserID != "" && schedule.UserID != updated.UserID {
+		if err := s.authz.Authorize(ctx, actor, domain.ActionScheduleWrite, schedule.UserID); err != nil {
+			return nil, nil, err
+		}
+		updated.UserID = schedule.UserID
+	}
+	if !schedule.Date.IsZero() {
+		updated.Date = schedule.Date
+	}
+	if schedule.ShiftStart != "" {
+		updated.ShiftStart = schedule.ShiftStart
+	}
+	if schedule.ShiftEnd != "" {
+		updated.ShiftEnd = schedule.ShiftEnd
+	}
+	if schedule.BreakStart != "" {
+		updated.BreakStart = schedule.BreakStart
+	}
+	if schedule.BreakEnd != "" {
+		updated.BreakEnd = schedule.BreakEnd
+	}
+	if schedule.CoreStart != "" {
+		updated.CoreStart = schedule.CoreStart
+	}
+	if schedule.CoreEnd != "" {
+		updated.CoreEnd = schedule.CoreEnd
+	}
+	if schedule.MinDurationMinutes != 0 {
+		updated.MinDurationMinutes = schedule.MinDurationMinutes
+	}
+	if schedule.WorkLocationID != "" {
+		updated.WorkLocationID = schedule.WorkLocationID
+	}
+	if schedule.ScheduleType != "" {
+		updated.ScheduleType = schedule.ScheduleType
+	}
+
+	if err := validateShiftTimes(updated.ShiftStart, updated.ShiftEnd, updated.BreakStart, updated.BreakEnd); err != nil {
+		return nil, nil, err
+	}
+
+	if err := validateFlexibleHours(updated.ScheduleType, updated.ShiftStart, updated.ShiftEnd, updated.CoreStart, updated.CoreEnd, updated.MinDurationMinutes); err != nil {
+		return nil, nil, err
+	}
+
+	violations, err := s.checkScheduleRules(ctx, *updated)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(violations) > 0 {
+		return nil, violations, consts.ErrScheduleRuleViolation
+	}
+
+	updated, err = s.repo.UpdateSchedule(ctx, id, updated)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return updated, nil, nil
 }
 
-func (s *ScheduleService) DeleteSchedule(ctx context.Context, id string) error {
+func (s *ScheduleService) DeleteSchedule(ctx context.Context, actor *domain.TokenPayload, id string) error {
+	if _, err := s.authorizedSchedule(ctx, actor, domain.ActionScheduleWrite, id); err != nil {
+		return err
+	}
+
 	return s.repo.DeleteSchedule(ctx, id)
 }
-func (s *ScheduleService) GetWorkRotation(ctx context.Context, employeeID string) (*domain.Schedule, error) {
-	return s.repo.GetWorkRotation(ctx, employeeID)
+
+// authorizedSchedule loads a schedule the actor may perform the action on
+func (s *ScheduleService) authorizedSchedule(ctx context.Context, actor *domain.TokenPayload, action domain.PermissionAction, id string) (*domain.Schedule, error) {
+	schedule, err := s.repo.GetSchedule(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.authz.Authorize(ctx, actor, action, schedule.UserID); err != nil {
+		return nil, err
+	}
+
+	return schedule, nil
+}
+
+// rotationLookahead is how far ahead GetWorkRotation projects the rotation to find the next shift
+const rotationLookahead = 366
+
+// GetWorkRotation returns the rotation an employee currently follows together with their next
+// shift, whether it is an explicit schedule or a day projected from the rotation
+func (s *ScheduleService) GetWorkRotation(ctx context.Context, userID string) (*domain.WorkRotation, error) {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	rotation := &domain.WorkRotation{}
+
+	assignments, err := s.rotationRepo.ListRotationAssignments(ctx, userID, today, today.AddDate(0, 0, rotationLookahead))
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range assignments {
+		if assignments[i].Covers(today) {
+			rotation.Assignment = &assignments[i]
+			break
+		}
+	}
+
+	next, err := s.repo.GetWorkRotation(ctx, userID)
+	if err != nil && err != consts.ErrDataNotFound {
+		return nil, err
+	}
+
+	for day := today; day.Before(today.AddDate(0, 0, rotationLookahead)); day = day.AddDate(0, 0, 1) {
+		if next != nil && !next.Date.After(day) {
+			break
+		}
+
+		if schedule, ok := projectRotation(assignments, day); ok {
+			rotation.NextShift = &domain.WorkCalendarDay{
+				Date:     day.Format("2006-01-02"),
+				Source:   domain.CalendarSourceRotation,
+				Schedule: schedule,
+			}
+			return rotation, nil
+		}
+	}
+
+	if next != nil {
+		rotation.NextShift = &domain.WorkCalendarDay{
+			Date:     next.Date.Format("2006-01-02"),
+			Source:   domain.CalendarSourceSchedule,
+			Schedule: *next,
+		}
+	}
+
+	return rotation, nil
+}
+
+// GetWorkCalendar lists the working days of an employee in a month. Materialized schedules take
+// precedence, the remaining days are projected from the employee's rotation assignments.
+func (s *ScheduleService) GetWorkCalendar(ctx context.Context, userID string, month int, year int) ([]domain.WorkCalendarDay, error) {
+	if month < 1 || month > 12 {
+		return nil, consts.InvalidInput("invalid month")
+	}
+
+	startDate := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+	endDate := startDate.AddDate(0, 1, -1)
+
+	schedules, err := s.repo.GetWorkCalendar(ctx, userID, month, year)
+	if err != nil {
+		return nil, err
+	}
+
+	assignments, err := s.rotationRepo.ListRotationAssignments(ctx, userID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	scheduled := make(map[string][]domain.Schedule)
+	for _, schedule := range schedules {
+		key := schedule.Date.Format("2006-01-02")
+		scheduled[key] = append(scheduled[key], schedule)
+	}
+
+	calendar := []domain.WorkCalendarDay{}
+	for day := startDate; !day.After(endDate); day = day.AddDate(0, 0, 1) {
+		key := day.Format("2006-01-02")
+
+		if daySchedules, ok := scheduled[key]; ok {
+			for _, schedule := range daySchedules {
+				calendar = append(calendar, domain.WorkCalendarDay{
+					Date:     key,
+					Source:   domain.CalendarSourceSchedule,
+					Schedule: schedule,
+				})
+			}
+			continue
+		}
+
+		if schedule, ok := projectRotation(assignments, day); ok {
+			calendar = append(calendar, domain.WorkCalendarDay{
+				Date:     key,
+				Source:   domain.CalendarSourceRotation,
+				Schedule: schedule,
+			})
+		}
+	}
+
+	return calendar, nil
+}
+
+func projectRotation(assignments []domain.RotationAssignment, day time.Time) (domain.Schedule, bool) {
+	for _, assignment := range assignments {
+		if schedule, ok := assignment.Project(day); ok {
+			return schedule, true
+		}
+	}
+
+	return domain.Schedule{}, false
+}
+
+// RequestScheduleSwap asks the owner of the target schedule to trade it for one of the requestor's own schedules
+func (s *ScheduleService) RequestScheduleSwap(ctx context.Context, requestorID string, req dto.ScheduleSwapRequest) (*domain.ScheduleSwapRequest, error) {
+	if req.ProposedScheduleID == req.TargetScheduleID {
+		return nil, consts.InvalidInput("cannot swap a schedule with itself")
+	}
+
+	proposed, err := s.repo.GetSchedule(ctx, req.ProposedScheduleID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get proposed schedule: %w", err)
+	}
+
+	target, err := s.repo.GetSchedule(ctx, req.TargetScheduleID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get target schedule: %w", err)
+	}
+
+	if proposed.UserID != requestorID {
+		return nil, consts.ErrForbidden
+	}
+
+	if target.UserID == requestorID {
+		return nil, consts.InvalidInput("target schedule already belongs to the requestor")
+	}
+
+	today := time.Now().Truncate(24 * time.Hour)
+	if proposed.Date.Before(today) || target.Date.Before(today) {
+		return nil, consts.InvalidInput("past schedules cannot be swapped")
+	}
+
+	open, err := s.repo.CountOpenScheduleSwaps(ctx, proposed.ID, target.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if open > 0 {
+		return nil, consts.ErrConflictingData
+	}
+
+	now := time.Now()
+	swap, err := s.repo.RequestScheduleSwap(ctx, &domain.ScheduleSwapRequest{
+		ID:                 uuid.New().String(),
+		RequestorID:        requestorID,
+		TargetUserID:       target.UserID,
+		ProposedScheduleID: proposed.ID,
+		TargetScheduleID:   target.ID,
+		Reason:             req.Reason,
+		Status:             domain.SwapPending,
+		CreatedAt:          now,
+		UpdatedAt:          now,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.sendSwapNotification(ctx, swap.TargetUserID, fmt.Sprintf("You have a new request to swap your schedule on %s.", target.Date.Format("2006-01-02")))
+
+	return swap, nil
+}
+
+func (s *ScheduleService) ListScheduleSwapRequests(ctx context.Context, userID string, status domain.SwapStatus) ([]domain.ScheduleSwapRequest, error) {
+	return s.repo.ListScheduleSwapRequests(ctx, userID, status)
+}
+
+// RespondScheduleSwap records the decision of the colleague asked to swap. An accepted
+// request still needs a manager's approval before the schedules change hands.
+func (s *ScheduleService) RespondScheduleSwap(ctx context.Context, swapID string, userID string, accept bool) (*domain.ScheduleSwapRequest, error) {
+	swap, err := s.repo.GetScheduleSwapRequest(ctx, swapID)
+	if err != nil {
+		return nil, err
+	}
+
+	if swap.TargetUserID != userID {
+		return nil, consts.ErrForbidden
+	}
+
+	now := time.Now()
+	swap.RespondedAt = &now
+	swap.Status = domain.SwapDeclined
+	if accept {
+		swap.Status = domain.SwapAccepted
+	}
+
+	if err := s.repo.UpdateScheduleSwapStatus(ctx, swap, domain.SwapPending); err != nil {
+		return nil, err
+	}
+
+	message := "Your schedule swap request has been declined."
+	if accept {
+		message = "Your schedule swap request has been accepted and is waiting for manager approval."
+	}
+	s.sendSwapNotification(ctx, swap.RequestorID, message)
+
+	return swap, nil
+}
+
+// CancelScheduleSwap withdraws a swap request that has not been decided by a manager yet
+func (s *ScheduleService) CancelScheduleSwap(ctx context.Context, swapID string, userID string) (*domain.ScheduleSwapRequest, error) {
+	swap, err := s.repo.GetScheduleSwapRequest(ctx, swapID)
+	if err != nil {
+		return nil, err
+	}
+
+	if swap.RequestorID != userID {
+		return nil, consts.ErrForbidden
+	}
+
+	if !swap.IsOpen() {
+		return nil, consts.ErrScheduleSwapClosed
+	}
+
+	from := swap.Status
+	swap.Status = domain.SwapCancelled
+	if err := s.repo.UpdateScheduleSwapStatus(ctx, swap, from); err != nil {
+		return nil, err
+	}
+
+	s.sendSwapNotification(ctx, swap.TargetUserID, "A schedule swap request sent to you has been cancelled.")
+
+	return swap, nil
+}
+
+// ApproveScheduleSwap approves an accepted swap request, exchanging the owners of both schedules.
+// When either employee would break the scheduling rules with the other's schedule, the violations
+// are returned together with consts.ErrScheduleRuleViolation.
+func (s *ScheduleService) ApproveScheduleSwap(ctx context.Context, swapID string, reviewerID string, note string) (*domain.ScheduleSwapRequest, []domain.ScheduleViolation, error) {
+	swap, err := s.repo.GetScheduleSwapRequest(ctx, swapID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if swap.RequestorID == reviewerID || swap.TargetUserID == reviewerID {
+		return nil, nil, consts.ErrForbidden
+	}
+
+	violations, err := s.checkSwapRules(ctx, swap)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(violations) > 0 {
+		return nil, violations, consts.ErrScheduleRuleViolation
+	}
+
+	if err := s.repo.ApproveScheduleSwap(ctx, swapID, reviewerID, note); err != nil {
+		return nil, nil, err
+	}
+
+	swap, err = s.repo.GetScheduleSwapRequest(ctx, swapID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, userID := range []string{swap.RequestorID, swap.TargetUserID} {
+		s.sendSwapNotification(ctx, userID, "Your schedule swap has been approved and your schedule has been updated.")
+	}
+
+	return swap, nil, nil
+}
+
+// checkSwapRules checks both schedules of a swap against the scheduling rules of their new
+// owners, leaving out the schedule each of them gives away
+func (s *ScheduleService) checkSwapRules(ctx context.Context, swap *domain.ScheduleSwapRequest) ([]domain.ScheduleViolation, error) {
+	proposed, err := s.repo.GetSchedule(ctx, swap.ProposedScheduleID)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := s.repo.GetSchedule(ctx, swap.TargetScheduleID)
+	if err != nil {
+		return nil, err
+	}
+
+	var violations []domain.ScheduleViolation
+	for _, candidate := range []struct {
+		schedule domain.Schedule
+		owner    string
+		givenUp  string
+	}{
+		{*proposed, target.UserID, target.ID},
+		{*target, proposed.UserID, proposed.ID},
+	} {
+		schedule := candidate.schedule
+		schedule.UserID = candidate.owner
+		found, err := s.checkScheduleRules(ctx, schedule, candidate.givenUp)
+		if err != nil {
+			return nil, err
+		}
+		violations = append(violations, found...)
+	}
+
+	return violations, nil
 }
 
-func (s *ScheduleService) GetWorkCalendar(ctx context.Context, userID string, month int, year int) ([]domain.Schedule, error) {
-	return s.repo.GetWorkCalendar(ctx, userID, month, year)
+// RejectScheduleSwap rejects an accepted swap request, leaving both schedules untouched
+func (s *ScheduleService) RejectScheduleSwap(ctx context.Context, swapID string, reviewerID string, note string) (*domain.ScheduleSwapRequest, error) {
+	swap, err := s.repo.GetScheduleSwapRequest(ctx, swapID)
+	if err != nil {
+		return nil, err
+	}
+
+	if swap.RequestorID == reviewerID || swap.TargetUserID == reviewerID {
+		return nil, consts.ErrForbidden
+	}
+
+	now := time.Now()
+	swap.Status = domain.SwapRejected
+	swap.ReviewedBy = reviewerID
+	swap.ReviewedAt = &now
+	swap.Note = note
+
+	if err := s.repo.UpdateScheduleSwapStatus(ctx, swap, domain.SwapAccepted); err != nil {
+		return nil, err
+	}
+
+	for _, userID := range []string{swap.RequestorID, swap.TargetUserID} {
+		s.sendSwapNotification(ctx, userID, "Your schedule swap has been rejected by a manager.")
+	}
+
+	return swap, nil
 }
 
-func (s *ScheduleService) RequestScheduleSwap(ctx context.Context, requestorID string, targetScheduleID string, proposedScheduleID string) error {
-	return s.repo.RequestScheduleSwap(ctx, requestorID, targetScheduleID, proposedScheduleID)
+func (s *ScheduleService) sendSwapNotification(ctx context.Context, userID string, message string) {
+	if _, err := s.notificationSvc.CreateNotification(ctx, domain.NewNotification(userID, domain.NotificationTypeInfo, message, time.Now())); err != nil {
+		fmt.Printf("failed to send notification: %v", err)
+	}
+}
+
+func (s *ScheduleService) CreateShiftTemplate(ctx context.Context, req dto.ShiftTemplateRequest) (*domain.ShiftTemplate, error) {
+	if err := validateShiftTimes(req.ShiftStart, req.ShiftEnd, req.BreakStart, req.BreakEnd); err != nil {
+		return nil, err
+	}
+
+	scheduleType := req.ScheduleType
+	if scheduleType == "" {
+		scheduleType = domain.ScheduleTypeRegular
+	}
+
+	if err := validateFlexibleHours(scheduleType, req.ShiftStart, req.ShiftEnd, req.CoreStart, req.CoreEnd, req.MinDurationMinutes); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	return s.templateRepo.CreateShiftTemplate(ctx, &domain.ShiftTemplate{
+		ID:                 uuid.New().String(),
+		Name:               strings.TrimSpace(req.Name),
+		ShiftStart:         req.ShiftStart,
+		ShiftEnd:           req.ShiftEnd,
+		BreakStart:         req.BreakStart,
+		BreakEnd:           req.BreakEnd,
+		CoreStart:          req.CoreStart,
+		CoreEnd:            req.CoreEnd,
+		MinDurationMinutes: req.MinDurationMinutes,
+		WorkLocationID:     req.WorkLocationID,
+		ScheduleType:       scheduleType,
+		CreatedAt:          now,
+		UpdatedAt:          now,
+	})
+}
+
+func (s *ScheduleService) ListShiftTemplates(ctx context.Context) ([]domain.ShiftTemplate, error) {
+	return s.templateRepo.ListShiftTemplates(ctx)
+}
+
+func (s *ScheduleService) DeleteShiftTemplate(ctx context.Context, id string) error {
+	return s.templateRepo.DeleteShiftTemplate(ctx, id)
+}
+
+const (
+	// maxBulkScheduleRange bounds the period a single bulk generation may cover
+	maxBulkScheduleRange = 93
+	// bulkSchedulePreview is the number of generated schedules returned by a dry run
+	bulkSchedulePreview = 50
+	// maxBulkSchedules bounds the schedules a single bulk generation may create
+	maxBulkSchedules = 10000
+)
+
+// GenerateSchedules creates schedules from a shift template for every selected employee on the
+// matching weekdays of the period. Days on which an employee already has a schedule, is on
+// approved leave, or would break the scheduling rules are reported as conflicts and skipped. A dry
+// run only reports what would be created.
+func (s *ScheduleService) GenerateSchedules(ctx context.Context, req dto.BulkScheduleRequest) (*domain.BulkScheduleResult, error) {
+	startDate, err := time.Parse("2006-01-02", req.StartDate)
+	if err != nil {
+		return nil, consts.InvalidInput("invalid start date format")
+	}
+
+	endDate, err := time.Parse("2006-01-02", req.EndDate)
+	if err != nil {
+		return nil, consts.InvalidInput("invalid end date format")
+	}
+
+	if startDate.After(endDate) {
+		return nil, consts.InvalidInput("start date must be before end date")
+	}
+
+	if endDate.Sub(startDate) > maxBulkScheduleRange*24*time.Hour {
+		return nil, consts.InvalidInput("period must not exceed %d days", maxBulkScheduleRange)
+	}
+
+	weekdays := map[time.Weekday]bool{}
+	for _, weekday := range req.Weekdays {
+		if weekday < 0 || weekday > 6 {
+			return nil, consts.InvalidInput("invalid weekday %d, expected 0 (sunday) to 6 (saturday)", weekday)
+		}
+		weekdays[time.Weekday(weekday)] = true
+	}
+
+	if len(weekdays) == 0 {
+		for weekday := time.Monday; weekday <= time.Friday; weekday++ {
+			weekdays[weekday] = true
+		}
+	}
+
+	template, err := s.templateRepo.GetShiftTemplate(ctx, req.TemplateID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.DepartmentID != "" && len(req.UserIDs) > 0 {
+		return nil, consts.InvalidInput("department_id and user_ids cannot be combined")
+	}
+
+	userIDs := req.UserIDs
+	if req.DepartmentID != "" {
+		userIDs, err = s.employeeRepo.ListActiveUserIDsByDepartment(ctx, req.DepartmentID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(userIDs) == 0 {
+		return nil, consts.InvalidInput("either department_id or user_ids must select at least one employee")
+	}
+
+	days := 0
+	for day := startDate; !day.After(endDate); day = day.AddDate(0, 0, 1) {
+		if weekdays[day.Weekday()] {
+			days++
+		}
+	}
+	if len(userIDs)*days > maxBulkSchedules {
+		return nil, consts.InvalidInput("generation must not exceed %d schedules, select fewer employees or a shorter period", maxBulkSchedules)
+	}
+
+	rules := make(map[string]scheduleRuleContext, len(userIDs))
+	span := 0
+	for _, userID := range userIDs {
+		ruleContext, err := s.scheduleRuleContext(ctx, userID, template.WorkLocationID)
+		if err != nil {
+			return nil, err
+		}
+		rules[userID] = ruleContext
+		span = max(span, ruleContext.span())
+	}
+
+	// the schedules around the period count towards the rules of the generated ones
+	existing, err := s.repo.ListSchedulesByUsers(ctx, userIDs, startDate.AddDate(0, 0, -span), endDate.AddDate(0, 0, span))
+	if err != nil {
+		return nil, err
+	}
+
+	leaves, err := s.leaveRepo.ListUserLeaves(ctx, userIDs, startDate, endDate, []domain.LeaveStatus{domain.Approved})
+	if err != nil {
+		return nil, err
+	}
+
+	scheduled := make(map[string]bool, len(existing))
+	userSchedules := make(map[string][]domain.Schedule)
+	for _, schedule := range existing {
+		scheduled[schedule.UserID+schedule.Date.Format("2006-01-02")] = true
+		userSchedules[schedule.UserID] = append(userSchedules[schedule.UserID], schedule)
+	}
+
+	onLeave := make(map[string][]domain.LeaveRequest)
+	for _, leave := range leaves {
+		onLeave[leave.UserID] = append(onLeave[leave.UserID], leave)
+	}
+
+	result := &domain.BulkScheduleResult{
+		DryRun:    req.DryRun,
+		Employees: len(userIDs),
+		Conflicts: []domain.ScheduleConflict{},
+	}
+
+	now := time.Now()
+	var schedules []domain.Schedule
+	for _, userID := range userIDs {
+		for day := startDate; !day.After(endDate); day = day.AddDate(0, 0, 1) {
+			if !weekdays[day.Weekday()] {
+				continue
+			}
+
+			key := day.Format("2006-01-02")
+			if scheduled[userID+key] {
+				result.Conflicts = append(result.Conflicts, domain.ScheduleConflict{UserID: userID, Date: key, Reason: domain.ConflictExistingSchedule})
+				continue
+			}
+
+			if isOnLeave(onLeave[userID], day) {
+				result.Conflicts = append(result.Conflicts, domain.ScheduleConflict{UserID: userID, Date: key, Reason: domain.ConflictApprovedLeave})
+				continue
+			}
+
+			schedule := template.Schedule(userID, day)
+			schedule.ID = uuid.New().String()
+			schedule.CreatedAt = now
+			schedule.UpdatedAt = now
+
+			ruleContext := rules[userID]
+			if violations := evaluateScheduleRules(schedule, userSchedules[userID], ruleContext.rule, ruleContext.loc); len(violations) > 0 {
+				result.Conflicts = append(result.Conflicts, domain.ScheduleConflict{UserID: userID, Date: key, Reason: domain.ConflictScheduleRules, Violations: violations})
+				continue
+			}
+
+			userSchedules[userID] = append(userSchedules[userID], schedule)
+			schedules = append(schedules, schedule)
+		}
+	}
+
+	if req.DryRun {
+		result.Generated = len(schedules)
+		result.Preview = schedules[:min(len(schedules), bulkSchedulePreview)]
+		return result, nil
+	}
+
+	result.Generated, err = s.repo.BulkCreateSchedules(ctx, schedules)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create schedules: %w", err)
+	}
+
+	return result, nil
+}
+
+func isOnLeave(leaves []domain.LeaveRequest, day time.Time) bool {
+	for _, leave := range leaves {
+		if !day.Before(leave.StartDate) && !day.After(leave.EndDate) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// validateShiftTimes checks the HH:MM times of a shift and that its break lies within it. A shift
+// ending before it starts runs overnight and ends on the next day.
+func validateShiftTimes(shiftStart, shiftEnd, breakStart, breakEnd string) error {
+	start, err := domain.ParseClock(shiftStart)
+	if err != nil {
+		return consts.InvalidInput("invalid shift start format, expected HH:MM")
+	}
+
+	end, err := domain.ParseClock(shiftEnd)
+	if err != nil {
+		return consts.InvalidInput("invalid shift end format, expected HH:MM")
+	}
+
+	if end == start {
+		return consts.InvalidInput("shift end must differ from shift start")
+	}
+
+	if end < start {
+		end += 24 * time.Hour
+	}
+
+	if breakStart == "" && breakEnd == "" {
+		return nil
+	}
+
+	breakFrom, err := domain.ParseClock(breakStart)
+	if err != nil {
+		return consts.InvalidInput("invalid break start format, expected HH:MM")
+	}
+
+	breakTo, err := domain.ParseClock(breakEnd)
+	if err != nil {
+		return consts.InvalidInput("invalid break end format, expected HH:MM")
+	}
+
+	// breaks after midnight of an overnight shift belong to the next day as well
+	if breakFrom < start {
+		breakFrom += 24 * time.Hour
+	}
+	if breakTo < start {
+		breakTo += 24 * time.Hour
+	}
+
+	if breakTo > end || breakTo <= breakFrom {
+		return consts.InvalidInput("break must be within the shift")
+	}
+
+	return nil
+}
+
+// validateFlexibleHours checks the core hours and minimum duration of a schedule. They only apply
+// to flexible schedules, which need at least one of them. Core hours must lie within the shift
+// and the minimum duration must fit in it.
+func validateFlexibleHours(scheduleType, shiftStart, shiftEnd, coreStart, coreEnd string, minDurationMinutes int) error {
+	hasCore := coreStart != "" || coreEnd != ""
+
+	if scheduleType != domain.ScheduleTypeFlexible {
+		if hasCore || minDurationMinutes != 0 {
+			return consts.InvalidInput("core hours and minimum duration only apply to flexible schedules")
+		}
+		return nil
+	}
+
+	if !hasCore && minDurationMinutes == 0 {
+		return consts.InvalidInput("flexible schedule requires core hours or a minimum duration")
+	}
+
+	if minDurationMinutes < 0 {
+		return consts.InvalidInput("minimum duration must be positive")
+	}
+
+	start, err := domain.ParseClock(shiftStart)
+	if err != nil {
+		return consts.InvalidInput("invalid shift start format, expected HH:MM")
+	}
+
+	end, err := domain.ParseClock(shiftEnd)
+	if err != nil {
+		return consts.InvalidInput("invalid shift end format, expected HH:MM")
+	}
+
+	if end <= start {
+		end += 24 * time.Hour
+	}
+
+	if time.Duration(minDurationMinutes)*time.Minute > end-start {
+		return consts.InvalidInput("minimum duration must not exceed the shift")
+	}
+
+	if !hasCore {
+		return nil
+	}
+
+	coreFrom, err := domain.ParseClock(coreStart)
+	if err != nil {
+		return consts.InvalidInput("invalid core start format, expected HH:MM")
+	}
+
+	coreTo, err := domain.ParseClock(coreEnd)
+	if err != nil {
+		return consts.InvalidInput("invalid core end format, expected HH:MM")
+	}
+
+	// core hours after midnight of an overnight shift belong to the next day
+	if coreFrom < start {
+		coreFrom += 24 * time.Hour
+	}
+	if coreTo < start {
+		coreTo += 24 * time.Hour
+	}
+
+	if coreTo > end || coreTo <= coreFrom {
+		return consts.InvalidInput("core hours must be within the shift")
+	}
+
+	return nil
 }
@@ -0,0 +1,279 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aldotp/employee-attendance-system/internal/adapter/dto"
+	"github.com/aldotp/employee-attendance-system/internal/core/domain"
+	"github.com/aldotp/employee-attendance-system/pkg/consts"
+	"github.com/google/uuid"
+)
+
+func (s *ScheduleService) CreateScheduleRule(ctx context.Context, req dto.ScheduleRuleRequest) (*domain.ScheduleRule, error) {
+	country := strings.TrimSpace(req.Country)
+	if (req.DepartmentID == "") == (country == "") {
+		return nil, consts.InvalidInput("a rule applies to either a department_id or a country")
+	}
+
+	now := time.Now()
+	return s.ruleRepo.CreateScheduleRule(ctx, &domain.ScheduleRule{
+		ID:                 uuid.New().String(),
+		DepartmentID:       req.DepartmentID,
+		Country:            country,
+		MinRestHours:       req.MinRestHours,
+		MaxWeeklyHours:     req.MaxWeeklyHours,
+		MaxConsecutiveDays: req.MaxConsecutiveDays,
+		CreatedAt:          now,
+		UpdatedAt:          now,
+	})
+}
+
+func (s *ScheduleService) ListScheduleRules(ctx context.Context) ([]domain.ScheduleRule, error) {
+	return s.ruleRepo.ListScheduleRules(ctx)
+}
+
+func (s *ScheduleService) DeleteScheduleRule(ctx context.Context, id string) error {
+	return s.ruleRepo.DeleteScheduleRule(ctx, id)
+}
+
+// scheduleRuleContext is the rule a schedule of an employee is checked against and the timezone
+// its shifts are placed in
+type scheduleRuleContext struct {
+	rule domain.ScheduleRule
+	loc  *time.Location
+}
+
+// span is enough days around a schedule to count its week and its run of consecutive working days
+func (c scheduleRuleContext) span() int {
+	return max(7, c.rule.MaxConsecutiveDays) + 1
+}
+
+// scheduleRuleContext finds the rule of the employee's department, else the rule of the work
+// location's country, else the default
+func (s *ScheduleService) scheduleRuleContext(ctx context.Context, userID, workLocationID string) (scheduleRuleContext, error) {
+	var departmentID string
+	ruleContext := scheduleRuleContext{rule: s.defaultRule, loc: time.UTC}
+
+	employee, err := s.employeeRepo.GetEmployeeByUserID(ctx, userID)
+	if err != nil && err != consts.ErrDataNotFound {
+		return ruleContext, err
+	}
+	if employee != nil {
+		departmentID = employee.DepartmentID
+		ruleContext.loc = timezoneLocation(employee.Timezone)
+	}
+
+	found, err := s.ruleRepo.FindScheduleRule(ctx, departmentID, workLocationID)
+	if err != nil && err != consts.ErrDataNotFound {
+		return ruleContext, err
+	}
+	if found != nil {
+		ruleContext.rule = *found
+	}
+
+	return ruleContext, nil
+}
+
+// checkScheduleRules validates a schedule against the other schedules of its employee, apart from
+// the excluded ones the employee is about to give up
+func (s *ScheduleService) checkScheduleRules(ctx context.Context, schedule domain.Schedule, excludeIDs ...string) ([]domain.ScheduleViolation, error) {
+	ruleContext, err := s.scheduleRuleContext(ctx, schedule.UserID, schedule.WorkLocationID)
+	if err != nil {
+		return nil, err
+	}
+
+	span := ruleContext.span()
+	schedules, err := s.repo.ListSchedulesByUsers(ctx, []string{schedule.UserID}, schedule.Date.AddDate(0, 0, -span), schedule.Date.AddDate(0, 0, span))
+	if err != nil {
+		return nil, err
+	}
+
+	others := make([]domain.Schedule, 0, len(schedules))
+	for _, other := range schedules {
+		if other.ID != schedule.ID && !slices.Contains(excludeIDs, other.ID) {
+			others = append(others, other)
+		}
+	}
+
+	return evaluateScheduleRules(schedule, others, ruleContext.rule, ruleContext.loc), nil
+}
+
+// scheduleRuleCheck reports the violations of one rule by schedule, given the other schedules of
+// the same employee
+type scheduleRuleCheck func(schedule domain.Schedule, others []domain.Schedule, rule domain.ScheduleRule, loc *time.Location) []domain.ScheduleViolation
+
+var scheduleRuleChecks = []scheduleRuleCheck{
+	checkNoOverlap,
+	checkMinRest,
+	checkMaxWeeklyHours,
+	checkMaxConsecutiveDays,
+}
+
+func evaluateScheduleRules(schedule domain.Schedule, others []domain.Schedule, rule domain.ScheduleRule, loc *time.Location) []domain.ScheduleViolation {
+	var violations []domain.ScheduleViolation
+	for _, check := range scheduleRuleChecks {
+		violations = append(violations, check(schedule, others, rule, loc)...)
+	}
+
+	return violations
+}
+
+func checkNoOverlap(schedule domain.Schedule, others []domain.Schedule, _ domain.ScheduleRule, loc *time.Location) []domain.ScheduleViolation {
+	start, end, err := schedule.Window(loc)
+	if err != nil {
+		return nil
+	}
+
+	var violations []domain.ScheduleViolation
+	for _, other := range others {
+		otherStart, otherEnd, err := other.Window(loc)
+		if err != nil {
+			continue
+		}
+
+		if start.Before(otherEnd) && otherStart.Before(end) {
+			violations = append(violations, domain.ScheduleViolation{
+				Rule:                  domain.RuleNoOverlap,
+				Date:                  other.Date.Format("2006-01-02"),
+				ConflictingScheduleID: other.ID,
+				Message:               fmt.Sprintf("overlaps the shift from %s to %s", otherStart.Format(time.RFC3339), otherEnd.Format(time.RFC3339)),
+			})
+		}
+	}
+
+	return violations
+}
+
+func checkMinRest(schedule domain.Schedule, others []domain.Schedule, rule domain.ScheduleRule, loc *time.Location) []domain.ScheduleViolation {
+	if rule.MinRestHours == 0 {
+		return nil
+	}
+
+	start, end, err := schedule.Window(loc)
+	if err != nil {
+		return nil
+	}
+
+	minRest := time.Duration(rule.MinRestHours) * time.Hour
+
+	var violations []domain.ScheduleViolation
+	for _, other := range others {
+		otherStart, otherEnd, err := other.Window(loc)
+		if err != nil {
+			continue
+		}
+
+		var rest time.Duration
+		switch {
+		case !otherEnd.After(start):
+			rest = start.Sub(otherEnd)
+		case !end.After(otherStart):
+			rest = otherStart.Sub(end)
+		default:
+			// overlapping shifts are reported by checkNoOverlap
+			continue
+		}
+
+		if rest < minRest {
+			violations = append(violations, domain.ScheduleViolation{
+				Rule:                  domain.RuleMinRest,
+				Date:                  other.Date.Format("2006-01-02"),
+				ConflictingScheduleID: other.ID,
+				Message:               fmt.Sprintf("only %s of rest next to the shift on %s, at least %d hours required", rest, other.Date.Format("2006-01-02"), rule.MinRestHours),
+			})
+		}
+	}
+
+	return violations
+}
+
+// checkMaxWeeklyHours sums the hours worked, breaks excluded, from Monday to Sunday of the
+// schedule's week
+func checkMaxWeeklyHours(schedule domain.Schedule, others []domain.Schedule, rule domain.ScheduleRule, loc *time.Location) []domain.ScheduleViolation {
+	if rule.MaxWeeklyHours == 0 {
+		return nil
+	}
+
+	weekStart := schedule.Date.AddDate(0, 0, -((int(schedule.Date.Weekday()) + 6) % 7))
+	weekEnd := weekStart.AddDate(0, 0, 7)
+
+	total := workedDuration(schedule, loc)
+	for _, other := range others {
+		if !other.Date.Before(weekStart) && other.Date.Before(weekEnd) {
+			total += workedDuration(other, loc)
+		}
+	}
+
+	if total <= time.Duration(rule.MaxWeeklyHours)*time.Hour {
+		return nil
+	}
+
+	return []domain.ScheduleViolation{{
+		Rule:    domain.RuleMaxWeeklyHours,
+		Date:    schedule.Date.Format("2006-01-02"),
+		Message: fmt.Sprintf("%.1f hours scheduled in the week starting %s, at most %d allowed", total.Hours(), weekStart.Format("2006-01-02"), rule.MaxWeeklyHours),
+	}}
+}
+
+func checkMaxConsecutiveDays(schedule domain.Schedule, others []domain.Schedule, rule domain.ScheduleRule, _ *time.Location) []domain.ScheduleViolation {
+	if rule.MaxConsecutiveDays == 0 {
+		return nil
+	}
+
+	workDays := map[string]bool{}
+	for _, other := range others {
+		workDays[other.Date.Format("2006-01-02")] = true
+	}
+
+	first, last := schedule.Date, schedule.Date
+	for workDays[first.AddDate(0, 0, -1).Format("2006-01-02")] {
+		first = first.AddDate(0, 0, -1)
+	}
+	for workDays[last.AddDate(0, 0, 1).Format("2006-01-02")] {
+		last = last.AddDate(0, 0, 1)
+	}
+
+	days := int(last.Sub(first).Hours()/24) + 1
+	if days <= rule.MaxConsecutiveDays {
+		return nil
+	}
+
+	return []domain.ScheduleViolation{{
+		Rule:    domain.RuleMaxConsecutiveDays,
+		Date:    schedule.Date.Format("2006-01-02"),
+		Message: fmt.Sprintf("%d consecutive working days from %s to %s, at most %d allowed", days, first.Format("2006-01-02"), last.Format("2006-01-02"), rule.MaxConsecutiveDays),
+	}}
+}
+
+// workedDuration is the length of a shift without its break
+func workedDuration(schedule domain.Schedule, loc *time.Location) time.Duration {
+	start, end, err := schedule.Window(loc)
+	if err != nil {
+		return 0
+	}
+
+	worked := end.Sub(start)
+	if schedule.BreakStart == "" || schedule.BreakEnd == "" {
+		return worked
+	}
+
+	breakStart, err := domain.ParseClock(schedule.BreakStart)
+	if err != nil {
+		return worked
+	}
+
+	breakEnd, err := domain.ParseClock(schedule.BreakEnd)
+	if err != nil {
+		return worked
+	}
+
+	if breakEnd < breakStart {
+		breakEnd += 24 * time.Hour
+	}
+
+	return worked - (breakEnd - breakStart)
+}
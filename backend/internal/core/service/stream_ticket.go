@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/aldotp/employee-attendance-system/internal/core/domain"
+	"github.com/aldotp/employee-attendance-system/internal/core/port"
+	"github.com/aldotp/employee-attendance-system/pkg/consts"
+	"github.com/aldotp/employee-attendance-system/pkg/util"
+	"go.uber.org/zap"
+)
+
+// streamTicketTTL is how long a client has to open the stream with a ticket
+const streamTicketTTL = 30 * time.Second
+
+type StreamTicketService struct {
+	cache port.CacheInterface
+	log   *zap.Logger
+}
+
+func NewStreamTicketService(cache port.CacheInterface, log *zap.Logger) *StreamTicketService {
+	return &StreamTicketService{
+		cache: cache,
+		log:   log,
+	}
+}
+
+// CreateStreamTicket issues a ticket standing for the access token of the payload
+func (s *StreamTicketService) CreateStreamTicket(ctx context.Context, payload *domain.TokenPayload) (*domain.StreamTicket, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		s.log.Error("failed to generate stream ticket", zap.Error(err))
+		return nil, consts.ErrInternal
+	}
+	ticket := hex.EncodeToString(secret)
+
+	value, err := json.Marshal(payload)
+	if err != nil {
+		s.log.Error("failed to encode stream ticket", zap.Error(err))
+		return nil, consts.ErrInternal
+	}
+
+	if err := s.cache.Set(ctx, streamTicketKey(ticket), value, streamTicketTTL); err != nil {
+		s.log.Error("failed to store stream ticket", zap.Error(err))
+		return nil, consts.ErrInternal
+	}
+
+	return &domain.StreamTicket{Ticket: ticket, ExpiresAt: time.Now().Add(streamTicketTTL)}, nil
+}
+
+// RedeemStreamTicket returns the payload a ticket was issued for and uses the ticket up
+func (s *StreamTicketService) RedeemStreamTicket(ctx context.Context, ticket string) (*domain.TokenPayload, error) {
+	value, err := s.cache.Get(ctx, streamTicketKey(ticket))
+	if err != nil {
+		if err == consts.ErrDataNotFound {
+			return nil, consts.ErrInvalidStreamTicket
+		}
+		s.log.Error("failed to get stream ticket", zap.Error(err))
+		return nil, consts.ErrInternal
+	}
+
+	if err := s.cache.Delete(ctx, streamTicketKey(ticket)); err != nil {
+		s.log.Error("failed to delete stream ticket", zap.Error(err))
+		return nil, consts.ErrInternal
+	}
+
+	var payload domain.TokenPayload
+	if err := json.Unmarshal(value, &payload); err != nil {
+		s.log.Error("failed to decode stream ticket", zap.Error(err))
+		return nil, consts.ErrInternal
+	}
+
+	return &payload, nil
+}
+
+func streamTicketKey(ticket string) string {
+	return util.GenerateCacheKey("stream_ticket", hashToken(ticket))
+}
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aldotp/employee-attendance-system/internal/core/domain"
+	"github.com/aldotp/employee-attendance-system/pkg/consts"
+	"go.uber.org/zap"
+)
+
+func TestStreamTicketIsSingleUse(t *testing.T) {
+	ctx := context.Background()
+	svc := NewStreamTicketService(newMemoryCache(), zap.NewNop())
+
+	ticket, err := svc.CreateStreamTicket(ctx, &domain.TokenPayload{UserID: "user-1", Role: domain.Employees, Version: 3})
+	if err != nil {
+		t.Fatalf("CreateStreamTicket() error = %v", err)
+	}
+
+	payload, err := svc.RedeemStreamTicket(ctx, ticket.Ticket)
+	if err != nil {
+		t.Fatalf("RedeemStreamTicket() error = %v", err)
+	}
+	if payload.UserID != "user-1" || payload.Role != domain.Employees || payload.Version != 3 {
+		t.Errorf("RedeemStreamTicket() = %+v", payload)
+	}
+
+	if _, err := svc.RedeemStreamTicket(ctx, ticket.Ticket); err != consts.ErrInvalidStreamTicket {
+		t.Errorf("RedeemStreamTicket() a second time error = %v, want %v", err, consts.ErrInvalidStreamTicket)
+	}
+}
+
+func TestStreamTicketUnknown(t *testing.T) {
+	svc := NewStreamTicketService(newMemoryCache(), zap.NewNop())
+
+	if _, err := svc.RedeemStreamTicket(context.Background(), "unknown"); err != consts.ErrInvalidStreamTicket {
+		t.Errorf("RedeemStreamTicket() error = %v, want %v", err, consts.ErrInvalidStreamTicket)
+	}
+}
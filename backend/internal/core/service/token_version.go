@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/aldotp/employee-attendance-system/internal/core/port"
+	"github.com/aldotp/employee-attendance-system/pkg/consts"
+	"github.com/aldotp/employee-attendance-system/pkg/util"
+)
+
+// TokenVersionService tracks the version of each user's access tokens in the cache. Access
+// tokens carry the version they were issued with, and bumping it revokes every access token
+// issued before.
+type TokenVersionService struct {
+	cache port.CacheInterface
+}
+
+func NewTokenVersionService(cache port.CacheInterface) *TokenVersionService {
+	return &TokenVersionService{
+		cache: cache,
+	}
+}
+
+// TokenVersion returns the version the user's access tokens must carry, zero until the first revocation
+func (s *TokenVersionService) TokenVersion(ctx context.Context, userID string) (int64, error) {
+	cached, err := s.cache.Get(ctx, tokenVersionKey(userID))
+	if err != nil {
+		if err == consts.ErrDataNotFound {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	return strconv.ParseInt(string(cached), 10, 64)
+}
+
+// RevokeAccessTokens revokes the user's access tokens. Their refresh sessions are kept, so
+// clients pick up a new role with their next refresh.
+func (s *TokenVersionService) RevokeAccessTokens(ctx context.Context, userID string) error {
+	// bumped atomically so concurrent revocations cannot both write the same version, and kept
+	// without expiry since access tokens are checked against it for as long as they live
+	_, err := s.cache.Increment(ctx, tokenVersionKey(userID), 0)
+	return err
+}
+
+// RevokeAllTokens revokes the user's access tokens and ends their refresh sessions, for users
+// who may no longer sign in
+func (s *TokenVersionService) RevokeAllTokens(ctx context.Context, userID string) error {
+	if err := s.RevokeAccessTokens(ctx, userID); err != nil {
+		return err
+	}
+
+	return s.cache.DeleteByPrefix(ctx, refreshSessionKey(userID, "*"))
+}
+
+func tokenVersionKey(userID string) string {
+	return util.GenerateCacheKey("token_version", userID)
+}
@@ -20,22 +20,41 @@ type UserService struct {
 	employeeRepo   port.EmployeeRepository
 	departmentRepo port.DepartmentRepository
 	token          port.TokenInterface
+	tokenVersion   port.TokenVersionService
+	verification   port.EmailVerificationService
 	log            *zap.Logger
 }
 
-func NewUserService(repo port.UserRepository, employeeRepo port.EmployeeRepository, departmentRepo port.DepartmentRepository, cache port.CacheInterface, token port.TokenInterface, log *zap.Logger) *UserService {
+func NewUserService(repo port.UserRepository, employeeRepo port.EmployeeRepository, departmentRepo port.DepartmentRepository, cache port.CacheInterface, token port.TokenInterface, tokenVersion port.TokenVersionService, verification port.EmailVerificationService, log *zap.Logger) *UserService {
 	return &UserService{
 		cache:          cache,
 		repo:           repo,
 		employeeRepo:   employeeRepo,
 		departmentRepo: departmentRepo,
 		token:          token,
+		tokenVersion:   tokenVersion,
+		verification:   verification,
 		log:            log,
 	}
 }
 
-// Register creates a new user
+// Register creates a new user with an unverified email address and sends them the link verifying it
 func (us *UserService) Register(ctx context.Context, input *dto.RegisterRequest) (*domain.User, error) {
+	user, err := us.register(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	// the account exists either way, a failed email can be sent again from the resend endpoint
+	if err := us.verification.SendVerificationEmail(ctx, user); err != nil {
+		us.log.Error("failed to send verification email", zap.Error(err))
+	}
+
+	return user, nil
+}
+
+// register creates the user and their employee record in one transaction
+func (us *UserService) register(ctx context.Context, input *dto.RegisterRequest) (user *domain.User, err error) {
 	exist, err := us.repo.ExistEmail(ctx, input.Email)
 	if err != nil {
 		us.log.Error(err.Error())
@@ -70,14 +89,13 @@ func (us *UserService) Register(ctx context.Context, input *dto.RegisterRequest)
 		}
 	}()
 
-	user := &domain.User{
-		ID:              uuid.New().String(),
-		Email:           input.Email,
-		Password:        hashedPassword,
-		EmailVerifiedAt: &tNow,
-		Role:            domain.Employees,
-		CreatedAt:       tNow,
-		UpdatedAt:       tNow,
+	user = &domain.User{
+		ID:        uuid.New().String(),
+		Email:     input.Email,
+		Password:  hashedPassword,
+		Role:      domain.Employees,
+		CreatedAt: tNow,
+		UpdatedAt: tNow,
 	}
 
 	user, err = us.repo.CreateUserTx(ctx, tx, user)
@@ -265,10 +283,13 @@ func (s *UserService) CreateUser(ctx context.Context, req dto.CreateUserRequest)
 		return nil, consts.ErrInternal
 	}
 
+	// accounts created by an admin skip email verification
+	now := time.Now()
 	user := &domain.User{
-		Email:    req.Email,
-		Password: hashedPassword,
-		Role:     req.Role,
+		Email:           req.Email,
+		Password:        hashedPassword,
+		Role:            req.Role,
+		EmailVerifiedAt: &now,
 	}
 
 	user, err = s.repo.Create(ctx, user)
@@ -306,16 +327,63 @@ func (s *UserService) UpdateUserByID(ctx context.Context, id string, req dto.Upd
 		user.Password = hashedPassword
 	}
 
+	roleChanged := req.Role != "" && req.Role != user.Role
 	if req.Role != "" {
 		user.Role = req.Role
 	}
 
-	if req.Password != "" {
-		user.Password = req.Password
+	user, err = s.repo.Update(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	// access tokens carry the role, those issued before the change must not keep it
+	if roleChanged {
+		if err := s.tokenVersion.RevokeAccessTokens(ctx, id); err != nil {
+			s.log.Error("failed to revoke access tokens", zap.Error(err))
+			return nil, consts.ErrInternal
+		}
+	}
+
+	if req.Status != "" {
+		if err := s.updateEmployeeStatus(ctx, id, domain.EmployeeStatus(req.Status)); err != nil {
+			return nil, err
+		}
 	}
-	user.Role = req.Role
 
-	return s.repo.Update(ctx, user)
+	return user, nil
+}
+
+// updateEmployeeStatus changes the status of the user's employee record, signing the user out
+// everywhere when they are no longer active
+func (s *UserService) updateEmployeeStatus(ctx context.Context, userID string, status domain.EmployeeStatus) error {
+	employee, err := s.employeeRepo.GetEmployeeByUserID(ctx, userID)
+	if err != nil {
+		if err == consts.ErrDataNotFound {
+			return err
+		}
+		s.log.Error("failed to get employee by user ID", zap.Error(err))
+		return consts.ErrInternal
+	}
+
+	if employee.Status == status {
+		return nil
+	}
+
+	employee.Status = status
+	if _, err := s.employeeRepo.UpdateEmployee(ctx, employee); err != nil {
+		s.log.Error("failed to update employee status", zap.Error(err))
+		return consts.ErrInternal
+	}
+
+	if status != domain.StatusActive {
+		if err := s.tokenVersion.RevokeAllTokens(ctx, userID); err != nil {
+			s.log.Error("failed to revoke tokens", zap.Error(err))
+			return consts.ErrInternal
+		}
+	}
+
+	return nil
 }
 
 func (s *UserService) DeleteUserByID(ctx context.Context, id string) error {
@@ -325,6 +393,12 @@ func (s *UserService) DeleteUserByID(ctx context.Context, id string) error {
 		return err
 	}
 
+	// Sign the user out everywhere
+	err = s.tokenVersion.RevokeAllTokens(ctx, id)
+	if err != nil {
+		return consts.ErrInternal
+	}
+
 	// Delete individual user cache
 	userCacheKey := util.GenerateCacheKey("user", id)
 	err = s.cache.Delete(ctx, userCacheKey)
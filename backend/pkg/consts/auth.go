@@ -2,4 +2,6 @@ package consts
 
 const (
 	AuthorizationKey = "user"
+	// APIKeyPayloadKey holds the payload of an API key until a route that accepts keys checks it
+	APIKeyPayloadKey = "api_key"
 )
@@ -2,6 +2,7 @@ package consts
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 )
 
@@ -25,8 +26,48 @@ var (
 	ErrForbidden                  = errors.New("user is forbidden to access the resource")
 	ErrEmailNotVerified           = errors.New("email is not verified")
 	ErrInvalidSignature           = errors.New("invalid signature")
+	ErrInvalidRefreshToken        = errors.New("refresh token is invalid or has been revoked")
+	ErrRevokedToken               = errors.New("access token has been revoked")
+	ErrAccountInactive            = errors.New("account is not active")
+	ErrInvalidResetToken          = errors.New("password reset token is invalid or has expired")
+	ErrInvalidActivationToken     = errors.New("email verification token is invalid or has expired")
+	ErrInvalidMFACode             = errors.New("authentication code is invalid")
+	ErrInvalidMFAToken            = errors.New("two-factor challenge is invalid or has expired")
+	ErrMFAAlreadyEnabled          = errors.New("two-factor authentication is already enabled")
+	ErrMFANotEnabled              = errors.New("two-factor authentication is not enabled")
+	ErrMFARequired                = errors.New("two-factor authentication is required for your role")
+	ErrTooManyRequests            = errors.New("too many requests, please try again later")
+	ErrTooManyLoginAttempts       = errors.New("too many failed login attempts, please try again later")
+	ErrOIDCNotConfigured          = errors.New("single sign-on is not configured")
+	ErrEmailNotConfigured         = errors.New("email delivery is not configured")
+	ErrInvalidOIDCState           = errors.New("single sign-on state is invalid or has expired")
+	ErrOIDCLoginFailed            = errors.New("single sign-on login failed")
+	ErrOIDCAccountNotFound        = errors.New("no account matches the single sign-on email")
+	ErrUnknownRole                = errors.New("role is not known")
+	ErrUnknownPermission          = errors.New("permission is not known")
+	ErrPermissionLockout          = errors.New("the admin role must keep permissions:manage:any")
+	ErrInvalidAPIKey              = errors.New("api key is invalid, revoked or expired")
+	ErrAPIKeyPermission           = errors.New("api keys can only hold permissions in the any scope that the creator holds, other than managing permissions and api keys")
+	ErrAPIKeyRouteNotAllowed      = errors.New("api keys cannot call endpoints that act as a user")
+	ErrInvalidStreamTicket        = errors.New("stream ticket is invalid or has expired")
+	ErrInvalidAPIKeyExpiry        = errors.New("api key expiry must be in the future and within the maximum lifetime")
 	ErrNotImplemented             = errors.New("not implemented")
 	ErrEmptyCart                  = errors.New("cart is empty")
+	ErrLeaveAttachmentRequired    = errors.New("supporting document is required for this leave request")
+	ErrInvalidFileType            = errors.New("file type is not allowed")
+	ErrFileTooLarge               = errors.New("file exceeds the maximum allowed size")
+	ErrInsufficientCoverage       = errors.New("approval would drop department coverage below the minimum")
+	ErrScheduleSwapClosed         = errors.New("schedule swap request is no longer open")
+	ErrScheduleSwapConflict       = errors.New("schedules of the swap request have changed")
+	ErrScheduleRuleViolation      = errors.New("schedule violates the scheduling rules")
+	ErrOpenShiftClosed            = errors.New("open shift is no longer available")
+	ErrOpenShiftNotEligible       = errors.New("employee is not eligible for this open shift")
+	ErrNotificationChannel        = errors.New("notification channel is not available")
+	ErrInvalidWebhookURL          = errors.New("webhook url must be an absolute http or https url")
+	ErrWebhookAddressNotAllowed   = errors.New("webhook url must resolve to public addresses only")
+	ErrInvalidCursor              = errors.New("invalid cursor")
+	// ErrInvalidInput is matched through errors.Is by the errors InvalidInput returns
+	ErrInvalidInput = errors.New("invalid input")
 )
 
 var ErrorToHTTPStatusCode = map[error]int{
@@ -40,10 +81,68 @@ var ErrorToHTTPStatusCode = map[error]int{
 	ErrInvalidAuthorizationType:   http.StatusUnauthorized,
 	ErrInvalidToken:               http.StatusUnauthorized,
 	ErrExpiredToken:               http.StatusUnauthorized,
+	ErrInvalidRefreshToken:        http.StatusUnauthorized,
+	ErrRevokedToken:               http.StatusUnauthorized,
+	ErrAccountInactive:            http.StatusForbidden,
+	ErrInvalidResetToken:          http.StatusBadRequest,
+	ErrInvalidActivationToken:     http.StatusBadRequest,
+	ErrInvalidMFACode:             http.StatusUnauthorized,
+	ErrInvalidMFAToken:            http.StatusUnauthorized,
+	ErrMFAAlreadyEnabled:          http.StatusConflict,
+	ErrMFANotEnabled:              http.StatusBadRequest,
+	ErrMFARequired:                http.StatusForbidden,
+	ErrTooManyRequests:            http.StatusTooManyRequests,
+	ErrTooManyLoginAttempts:       http.StatusTooManyRequests,
+	ErrOIDCNotConfigured:          http.StatusNotFound,
+	ErrEmailNotConfigured:         http.StatusServiceUnavailable,
+	ErrInvalidOIDCState:           http.StatusBadRequest,
+	ErrOIDCLoginFailed:            http.StatusUnauthorized,
+	ErrOIDCAccountNotFound:        http.StatusForbidden,
+	ErrUnknownRole:                http.StatusBadRequest,
+	ErrUnknownPermission:          http.StatusBadRequest,
+	ErrPermissionLockout:          http.StatusConflict,
+	ErrInvalidAPIKey:              http.StatusUnauthorized,
+	ErrAPIKeyPermission:           http.StatusBadRequest,
+	ErrAPIKeyRouteNotAllowed:      http.StatusForbidden,
+	ErrInvalidStreamTicket:        http.StatusUnauthorized,
+	ErrInvalidAPIKeyExpiry:        http.StatusBadRequest,
 	ErrForbidden:                  http.StatusForbidden,
 	ErrNoUpdatedData:              http.StatusBadRequest,
 	ErrInsufficientStock:          http.StatusBadRequest,
 	ErrInsufficientPayment:        http.StatusBadRequest,
 	ErrTokenCreation:              http.StatusInternalServerError,
 	ErrTokenDuration:              http.StatusInternalServerError,
+	ErrLeaveAttachmentRequired:    http.StatusBadRequest,
+	ErrInvalidFileType:            http.StatusBadRequest,
+	ErrFileTooLarge:               http.StatusRequestEntityTooLarge,
+	ErrInsufficientCoverage:       http.StatusConflict,
+	ErrScheduleSwapClosed:         http.StatusConflict,
+	ErrScheduleSwapConflict:       http.StatusConflict,
+	ErrScheduleRuleViolation:      http.StatusUnprocessableEntity,
+	ErrOpenShiftClosed:            http.StatusConflict,
+	ErrOpenShiftNotEligible:       http.StatusForbidden,
+	ErrNotificationChannel:        http.StatusBadRequest,
+	ErrInvalidWebhookURL:          http.StatusBadRequest,
+	ErrWebhookAddressNotAllowed:   http.StatusBadRequest,
+	ErrInvalidCursor:              http.StatusBadRequest,
+	ErrInvalidInput:               http.StatusBadRequest,
+}
+
+// inputError describes what is wrong with a request, keeping the message as it is while
+// matching ErrInvalidInput
+type inputError struct {
+	message string
+}
+
+func (e *inputError) Error() string {
+	return e.message
+}
+
+func (e *inputError) Is(target error) bool {
+	return target == ErrInvalidInput
+}
+
+// InvalidInput returns an error about the request itself, answered with 400 Bad Request
+func InvalidInput(format string, args ...any) error {
+	return &inputError{message: fmt.Sprintf(format, args...)}
 }
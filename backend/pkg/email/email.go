@@ -6,6 +6,7 @@ import (
 	"html/template"
 	"net/smtp"
 	"path/filepath"
+	"strings"
 )
 
 type Email struct {
@@ -27,6 +28,9 @@ func NewEmailSender(host string, port string, username string, password string,
 }
 
 func (e *Email) SendEmail(payload interface{}, pathTemplate string, to []string, subject string) error {
+	if len(to) == 0 {
+		return fmt.Errorf("no recipient given")
+	}
 
 	absPath, err := filepath.Abs(pathTemplate)
 	if err != nil {
@@ -43,7 +47,9 @@ func (e *Email) SendEmail(payload interface{}, pathTemplate string, to []string,
 		return fmt.Errorf("error executing template: %v", err)
 	}
 
-	msg := "To: " + to[0] + "\r\n" +
+	// list every recipient in the header, SendMail only uses them for the envelope
+	msg := "From: " + e.SMTPFrom + "\r\n" +
+		"To: " + strings.Join(to, ", ") + "\r\n" +
 		"Subject: " + subject + "\r\n" +
 		"MIME-version: 1.0;\r\n" +
 		"Content-Type: text/html; charset=\"UTF-8\";\r\n" +
@@ -17,12 +17,15 @@ type StorageInterface interface {
 	Delete(ctx context.Context, fileName string) error
 	GenerateUrl(fileName string) string
 	GeneratePresignedUrl(fileName string, expiration time.Duration) (string, error)
+	GeneratePresignedDownloadUrl(fileName string, expiration time.Duration) (string, error)
 }
 
 // FileUploadObject represents a file to be uploaded
 type FileUploadObject struct {
-	File     io.Reader
-	FileName string
+	File        io.Reader
+	FileName    string
+	ContentType string
+	Size        int64
 }
 
 // MinioClient implements StorageInterface for MinIO
@@ -52,7 +55,14 @@ func (m *MinioClient) Upload(ctx context.Context, object *FileUploadObject) erro
 	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
 	defer cancel()
 
-	_, err := m.client.PutObject(ctx, m.bucket, object.FileName, object.File, -1, minio.PutObjectOptions{})
+	size := object.Size
+	if size <= 0 {
+		size = -1
+	}
+
+	_, err := m.client.PutObject(ctx, m.bucket, object.FileName, object.File, size, minio.PutObjectOptions{
+		ContentType: object.ContentType,
+	})
 	if err != nil {
 		return fmt.Errorf("failed to upload object: %w", err)
 	}
@@ -109,4 +119,17 @@ func (m *MinioClient) GeneratePresignedUrl(fileName string, expiration time.Dura
 	return url.String(), nil
 }
 
+// GeneratePresignedDownloadUrl returns a time-limited URL that allows reading the object
+func (m *MinioClient) GeneratePresignedDownloadUrl(fileName string, expiration time.Duration) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	url, err := m.client.PresignedGetObject(ctx, m.bucket, fileName, expiration, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate presigned download URL: %w", err)
+	}
+
+	return url.String(), nil
+}
+
 var _ StorageInterface = &MinioClient{}
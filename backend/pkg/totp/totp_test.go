@@ -0,0 +1,107 @@
+package totp
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfc6238Secret is the SHA1 seed of the RFC 6238 test vectors, "12345678901234567890" in base32
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+// TestCodeRFC6238 checks the SHA1 test vectors of RFC 6238 appendix B, truncated to the last six
+// of their eight digits
+func TestCodeRFC6238(t *testing.T) {
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	for _, tt := range tests {
+		t.Run(time.Unix(tt.unix, 0).UTC().Format(time.RFC3339), func(t *testing.T) {
+			got, err := Code(rfc6238Secret, Step(time.Unix(tt.unix, 0)))
+			if err != nil {
+				t.Fatalf("Code() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Code() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCodeAcceptsLowercasePaddedSecret(t *testing.T) {
+	got, err := Code(strings.ToLower(rfc6238Secret)+"====", Step(time.Unix(59, 0)))
+	if err != nil {
+		t.Fatalf("Code() error = %v", err)
+	}
+	if got != "287082" {
+		t.Errorf("Code() = %s, want 287082", got)
+	}
+}
+
+func TestCodeInvalidSecret(t *testing.T) {
+	if _, err := Code("not base32!", 1); err == nil {
+		t.Error("Code() error = nil, want an error")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+
+	tests := []struct {
+		name     string
+		code     string
+		at       time.Time
+		skew     int64
+		wantStep int64
+		wantOK   bool
+	}{
+		{"current step", "050471", now, 0, Step(now), true},
+		{"surrounding spaces", " 050471 ", now, 0, Step(now), true},
+		{"previous step within skew", "050471", now.Add(Period), 1, Step(now), true},
+		{"previous step without skew", "050471", now.Add(Period), 0, 0, false},
+		{"beyond skew", "050471", now.Add(2 * Period), 1, 0, false},
+		{"wrong code", "000000", now, 1, 0, false},
+		{"wrong length", "50471", now, 1, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := Validate(rfc6238Secret, tt.code, tt.at, tt.skew)
+			if ok != tt.wantOK || step != tt.wantStep {
+				t.Errorf("Validate() = (%d, %v), want (%d, %v)", step, ok, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatalf("GenerateSecret() error = %v", err)
+	}
+
+	key, err := encoding.DecodeString(secret)
+	if err != nil {
+		t.Fatalf("secret %q is not base32: %v", secret, err)
+	}
+	if len(key) != secretSize {
+		t.Errorf("secret is %d bytes, want %d", len(key), secretSize)
+	}
+}
+
+func TestProvisioningURI(t *testing.T) {
+	got := ProvisioningURI(rfc6238Secret, "Acme HR", "jane@example.com")
+	want := "otpauth://totp/Acme%20HR:jane@example.com?algorithm=SHA1&digits=6&issuer=Acme+HR&period=30&secret=" + rfc6238Secret
+	if got != want {
+		t.Errorf("ProvisioningURI() = %s, want %s", got, want)
+	}
+}
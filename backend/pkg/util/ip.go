@@ -0,0 +1,31 @@
+package util
+
+import "net/netip"
+
+// reservedPrefixes are ranges that are neither private nor loopback yet do not reach the public
+// internet either
+var reservedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+}
+
+// IsPublicAddr reports whether the address is a public unicast address, so not loopback,
+// private, link-local, unspecified, multicast or otherwise reserved. Requests the server makes
+// to addresses given by users must only go to public ones.
+func IsPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+
+	for _, prefix := range reservedPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+
+	return true
+}